@@ -0,0 +1,27 @@
+package blobs
+
+import (
+	"errors"
+	"regexp"
+)
+
+var (
+	ErrBlobNotFound = errors.New("blob_not_found")
+	ErrInvalidKey   = errors.New("invalid_blob_key")
+)
+
+// BlobStore is the contract a storage backend has to fulfil to keep the files
+// attached to appointments. Keys are generated by the agenda and never come
+// from the uploaded file name.
+type BlobStore interface {
+	Put(key string, data []byte) error
+	// Get fails with ErrBlobNotFound when nothing is stored under the key.
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+var keyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func validKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
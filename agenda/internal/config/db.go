@@ -23,7 +23,12 @@ func (c *DBConfig) ConnectDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&models.Slot{}, &models.Appointment{}); err != nil {
+	if err := db.AutoMigrate(&models.Slot{}, &models.Appointment{}, &models.Resource{},
+		&models.ResourceReservation{}, &models.Service{}, &models.ProfessionalSettings{},
+		&models.AvailabilityRule{}, &models.TimeOff{}, &models.Payment{}, &models.ActionLink{},
+		&models.DigestSettings{}, &models.IntakeForm{}, &models.IntakeResponse{},
+		&models.AppointmentNote{}, &models.Attachment{}, &models.Package{}, &models.ClientPackage{},
+		&models.CreditTransaction{}); err != nil {
 		log.Printf("Error in DB migration: %v", err)
 		return nil, err
 	}
@@ -17,7 +17,7 @@ func NewAgendaHandler(svc services.AgendaService) *AgendaHandler {
 }
 
 func (h *AgendaHandler) CreateSlot(ctx context.Context, req *pb.CreateSlotRequest) (*pb.CreateSlotResponse, error) {
-	return h.Service.CreateSlot(req)
+	return h.Service.CreateSlot(ctx, req)
 }
 
 func (h *AgendaHandler) ListAvailableSlots(ctx context.Context, req *pb.ListAvailableSlotsRequest) (*pb.ListAvailableSlotsResponse, error) {
@@ -25,9 +25,45 @@ func (h *AgendaHandler) ListAvailableSlots(ctx context.Context, req *pb.ListAvai
 }
 
 func (h *AgendaHandler) BookAppointment(ctx context.Context, req *pb.BookAppointmentRequest) (*pb.BookAppointmentResponse, error) {
-	return h.Service.BookAppointment(req)
+	return h.Service.BookAppointment(ctx, req)
 }
 
 func (h *AgendaHandler) ListAppointments(ctx context.Context, req *pb.ListAppointmentsRequest) (*pb.ListAppointmentsResponse, error) {
-	return h.Service.ListAppointments(req)
+	return h.Service.ListAppointments(ctx, req)
+}
+
+func (h *AgendaHandler) GetAppointment(ctx context.Context, req *pb.GetAppointmentRequest) (*pb.GetAppointmentResponse, error) {
+	return h.Service.GetAppointment(ctx, req)
+}
+
+func (h *AgendaHandler) CompleteAppointment(ctx context.Context, req *pb.CompleteAppointmentRequest) (*pb.CompleteAppointmentResponse, error) {
+	return h.Service.CompleteAppointment(ctx, req)
+}
+
+func (h *AgendaHandler) CancelAppointment(ctx context.Context, req *pb.CancelAppointmentRequest) (*pb.CancelAppointmentResponse, error) {
+	return h.Service.CancelAppointment(ctx, req)
+}
+
+func (h *AgendaHandler) ApproveAppointment(ctx context.Context, req *pb.ApproveAppointmentRequest) (*pb.ApproveAppointmentResponse, error) {
+	return h.Service.ApproveAppointment(ctx, req)
+}
+
+func (h *AgendaHandler) DeclineAppointment(ctx context.Context, req *pb.DeclineAppointmentRequest) (*pb.DeclineAppointmentResponse, error) {
+	return h.Service.DeclineAppointment(ctx, req)
+}
+
+func (h *AgendaHandler) ConfirmAppointment(ctx context.Context, req *pb.ConfirmAppointmentRequest) (*pb.ConfirmAppointmentResponse, error) {
+	return h.Service.ConfirmAppointment(ctx, req)
+}
+
+func (h *AgendaHandler) RescheduleAppointment(ctx context.Context, req *pb.RescheduleAppointmentRequest) (*pb.RescheduleAppointmentResponse, error) {
+	return h.Service.RescheduleAppointment(ctx, req)
+}
+
+func (h *AgendaHandler) ReassignAppointments(ctx context.Context, req *pb.ReassignAppointmentsRequest) (*pb.ReassignAppointmentsResponse, error) {
+	return h.Service.ReassignAppointments(ctx, req)
+}
+
+func (h *AgendaHandler) GetIntakeAnswers(ctx context.Context, req *pb.GetIntakeAnswersRequest) (*pb.GetIntakeAnswersResponse, error) {
+	return h.Service.GetIntakeAnswers(ctx, req)
 }
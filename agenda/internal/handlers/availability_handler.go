@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+type AvailabilityHandler struct {
+	pb.UnimplementedAvailabilityServiceServer
+	Service services.AvailabilityService
+}
+
+func NewAvailabilityHandler(svc services.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{Service: svc}
+}
+
+func (h *AvailabilityHandler) UpdateAvailabilitySettings(ctx context.Context, req *pb.UpdateAvailabilitySettingsRequest) (*pb.UpdateAvailabilitySettingsResponse, error) {
+	return h.Service.UpdateAvailabilitySettings(ctx, req)
+}
+
+func (h *AvailabilityHandler) GetAvailabilitySettings(ctx context.Context, req *pb.GetAvailabilitySettingsRequest) (*pb.GetAvailabilitySettingsResponse, error) {
+	return h.Service.GetAvailabilitySettings(ctx, req)
+}
+
+func (h *AvailabilityHandler) SetAvailabilityRules(ctx context.Context, req *pb.SetAvailabilityRulesRequest) (*pb.SetAvailabilityRulesResponse, error) {
+	return h.Service.SetAvailabilityRules(ctx, req)
+}
+
+func (h *AvailabilityHandler) CreateTimeOff(ctx context.Context, req *pb.CreateTimeOffRequest) (*pb.CreateTimeOffResponse, error) {
+	return h.Service.CreateTimeOff(ctx, req)
+}
+
+func (h *AvailabilityHandler) SetApprovalMode(ctx context.Context, req *pb.SetApprovalModeRequest) (*pb.SetApprovalModeResponse, error) {
+	return h.Service.SetApprovalMode(ctx, req)
+}
+
+func (h *AvailabilityHandler) SetDigestPreferences(ctx context.Context, req *pb.SetDigestPreferencesRequest) (*pb.SetDigestPreferencesResponse, error) {
+	return h.Service.SetDigestPreferences(ctx, req)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+type NoteHandler struct {
+	pb.UnimplementedNoteServiceServer
+	Service services.NoteService
+}
+
+func NewNoteHandler(svc services.NoteService) *NoteHandler {
+	return &NoteHandler{Service: svc}
+}
+
+func (h *NoteHandler) AddNote(ctx context.Context, req *pb.AddNoteRequest) (*pb.AddNoteResponse, error) {
+	return h.Service.AddNote(ctx, req)
+}
+
+func (h *NoteHandler) ListNotes(ctx context.Context, req *pb.ListNotesRequest) (*pb.ListNotesResponse, error) {
+	return h.Service.ListNotes(ctx, req)
+}
+
+func (h *NoteHandler) UploadAttachment(ctx context.Context, req *pb.UploadAttachmentRequest) (*pb.UploadAttachmentResponse, error) {
+	return h.Service.UploadAttachment(ctx, req)
+}
+
+func (h *NoteHandler) ListAttachments(ctx context.Context, req *pb.ListAttachmentsRequest) (*pb.ListAttachmentsResponse, error) {
+	return h.Service.ListAttachments(ctx, req)
+}
+
+func (h *NoteHandler) DownloadAttachment(ctx context.Context, req *pb.DownloadAttachmentRequest) (*pb.DownloadAttachmentResponse, error) {
+	return h.Service.DownloadAttachment(ctx, req)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+type PackageHandler struct {
+	pb.UnimplementedPackageServiceServer
+	Service services.PackageService
+}
+
+func NewPackageHandler(svc services.PackageService) *PackageHandler {
+	return &PackageHandler{Service: svc}
+}
+
+func (h *PackageHandler) CreatePackage(ctx context.Context, req *pb.CreatePackageRequest) (*pb.CreatePackageResponse, error) {
+	return h.Service.CreatePackage(req)
+}
+
+func (h *PackageHandler) ListPackages(ctx context.Context, req *pb.ListPackagesRequest) (*pb.ListPackagesResponse, error) {
+	return h.Service.ListPackages(req)
+}
+
+func (h *PackageHandler) PurchasePackage(ctx context.Context, req *pb.PurchasePackageRequest) (*pb.PurchasePackageResponse, error) {
+	return h.Service.PurchasePackage(ctx, req)
+}
+
+func (h *PackageHandler) GetCreditBalance(ctx context.Context, req *pb.GetCreditBalanceRequest) (*pb.GetCreditBalanceResponse, error) {
+	return h.Service.GetCreditBalance(ctx, req)
+}
+
+func (h *PackageHandler) ListCreditHistory(ctx context.Context, req *pb.ListCreditHistoryRequest) (*pb.ListCreditHistoryResponse, error) {
+	return h.Service.ListCreditHistory(ctx, req)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+type PaymentHandler struct {
+	pb.UnimplementedPaymentServiceServer
+	Service services.PaymentService
+}
+
+func NewPaymentHandler(svc services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{Service: svc}
+}
+
+func (h *PaymentHandler) HandlePaymentWebhook(ctx context.Context, req *pb.PaymentWebhookRequest) (*pb.PaymentWebhookResponse, error) {
+	return h.Service.HandlePaymentWebhook(req)
+}
+
+func (h *PaymentHandler) GetPayment(ctx context.Context, req *pb.GetPaymentRequest) (*pb.GetPaymentResponse, error) {
+	return h.Service.GetPayment(ctx, req)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import "github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+
+// Permissions lists the roles allowed to call each method, admins and the
+// other services can call all of them.
+var Permissions = rbac.Permissions{
+	"/pb.AgendaService/CreateSlot":            {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AgendaService/ListAvailableSlots":    {rbac.Authenticated},
+	"/pb.AgendaService/BookAppointment":       {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.AgendaService/ListAppointments":      {rbac.Authenticated},
+	"/pb.AgendaService/GetAppointment":        {rbac.Authenticated},
+	"/pb.AgendaService/CompleteAppointment":   {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AgendaService/CancelAppointment":     {rbac.Authenticated},
+	"/pb.AgendaService/ApproveAppointment":    {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AgendaService/DeclineAppointment":    {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AgendaService/ConfirmAppointment":    {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.AgendaService/RescheduleAppointment": {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.AgendaService/ReassignAppointments":  {rbac.RoleStaff},
+	"/pb.AgendaService/GetIntakeAnswers":      {rbac.RoleStaff, rbac.RoleProfessional},
+
+	"/pb.ResourceService/CreateResource":   {rbac.RoleStaff},
+	"/pb.ResourceService/ListResources":    {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.ResourceService/BlockResource":    {rbac.RoleStaff},
+	"/pb.ResourceService/CreateService":    {rbac.RoleStaff},
+	"/pb.ResourceService/GetService":       {rbac.Authenticated},
+	"/pb.ResourceService/ListServices":     {rbac.Authenticated},
+	"/pb.ResourceService/CreateIntakeForm": {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.ResourceService/GetIntakeForm":    {rbac.Authenticated},
+
+	"/pb.AvailabilityService/UpdateAvailabilitySettings": {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AvailabilityService/GetAvailabilitySettings":    {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AvailabilityService/SetAvailabilityRules":       {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AvailabilityService/CreateTimeOff":              {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AvailabilityService/SetApprovalMode":            {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.AvailabilityService/SetDigestPreferences":       {rbac.RoleStaff, rbac.RoleProfessional},
+
+	// El proveedor de pagos no tiene token, el webhook se valida con su firma
+	"/pb.PaymentService/HandlePaymentWebhook": {rbac.Public},
+	"/pb.PaymentService/GetPayment":           {rbac.Authenticated},
+
+	"/pb.NoteService/AddNote":            {rbac.Authenticated},
+	"/pb.NoteService/ListNotes":          {rbac.Authenticated},
+	"/pb.NoteService/UploadAttachment":   {rbac.Authenticated},
+	"/pb.NoteService/ListAttachments":    {rbac.Authenticated},
+	"/pb.NoteService/DownloadAttachment": {rbac.Authenticated},
+
+	"/pb.PackageService/CreatePackage":     {rbac.RoleStaff},
+	"/pb.PackageService/ListPackages":      {rbac.Authenticated},
+	"/pb.PackageService/PurchasePackage":   {rbac.RoleStaff},
+	"/pb.PackageService/GetCreditBalance":  {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.PackageService/ListCreditHistory": {rbac.RoleStaff, rbac.RoleClient},
+
+	"/pb.ReconcileService/Reconcile": {rbac.RoleAdmin},
+}
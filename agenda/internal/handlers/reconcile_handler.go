@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+type ReconcileHandler struct {
+	pb.UnimplementedReconcileServiceServer
+	Service services.ReconcileService
+}
+
+func NewReconcileHandler(svc services.ReconcileService) *ReconcileHandler {
+	return &ReconcileHandler{Service: svc}
+}
+
+func (h *ReconcileHandler) Reconcile(ctx context.Context, req *pb.ReconcileRequest) (*pb.ReconcileResponse, error) {
+	return h.Service.Reconcile(req)
+}
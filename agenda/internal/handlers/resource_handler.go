@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+type ResourceHandler struct {
+	pb.UnimplementedResourceServiceServer
+	Service services.ResourceService
+}
+
+func NewResourceHandler(svc services.ResourceService) *ResourceHandler {
+	return &ResourceHandler{Service: svc}
+}
+
+func (h *ResourceHandler) CreateResource(ctx context.Context, req *pb.CreateResourceRequest) (*pb.CreateResourceResponse, error) {
+	return h.Service.CreateResource(req)
+}
+
+func (h *ResourceHandler) ListResources(ctx context.Context, req *pb.ListResourcesRequest) (*pb.ListResourcesResponse, error) {
+	return h.Service.ListResources(req)
+}
+
+func (h *ResourceHandler) BlockResource(ctx context.Context, req *pb.BlockResourceRequest) (*pb.BlockResourceResponse, error) {
+	return h.Service.BlockResource(ctx, req)
+}
+
+func (h *ResourceHandler) CreateService(ctx context.Context, req *pb.CreateServiceRequest) (*pb.CreateServiceResponse, error) {
+	return h.Service.CreateService(req)
+}
+
+func (h *ResourceHandler) GetService(ctx context.Context, req *pb.GetServiceRequest) (*pb.GetServiceResponse, error) {
+	return h.Service.GetService(req)
+}
+
+func (h *ResourceHandler) ListServices(ctx context.Context, req *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+	return h.Service.ListServices(req)
+}
+
+func (h *ResourceHandler) CreateIntakeForm(ctx context.Context, req *pb.CreateIntakeFormRequest) (*pb.CreateIntakeFormResponse, error) {
+	return h.Service.CreateIntakeForm(ctx, req)
+}
+
+func (h *ResourceHandler) GetIntakeForm(ctx context.Context, req *pb.GetIntakeFormRequest) (*pb.GetIntakeFormResponse, error) {
+	return h.Service.GetIntakeForm(req)
+}
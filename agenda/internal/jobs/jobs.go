@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// Every runs fn now and then once per interval, forever. Errors are logged
+// and never stop the loop, so a failed run is simply retried on the next tick.
+func Every(name string, interval time.Duration, fn func(now time.Time) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := time.Now(); ; now = <-ticker.C {
+		if err := fn(now); err != nil {
+			log.Printf("Error running %s job: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package meetings
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FakeProvider keeps rooms in memory. It's meant for tests.
+type FakeProvider struct {
+	mu    sync.Mutex
+	seq   int
+	rooms map[string]bool // room id -> still active
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{rooms: map[string]bool{}}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateRoom(reference string) (*Room, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	id := fmt.Sprintf("fake_room_%d", p.seq)
+	p.rooms[id] = true
+	return &Room{ID: id, URL: "https://meet.example.com/" + id}, nil
+}
+
+func (p *FakeProvider) RevokeRoom(roomID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.rooms[roomID]; !ok {
+		return ErrRoomNotFound
+	}
+	p.rooms[roomID] = false
+	return nil
+}
+
+// Active reports whether the room exists and wasn't revoked.
+func (p *FakeProvider) Active(roomID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rooms[roomID]
+}
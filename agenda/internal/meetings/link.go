@@ -0,0 +1,35 @@
+package meetings
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// LinkProvider builds a unique, hard to guess URL under a base for every room,
+// ie: "https://meet.jit.si/agenda-<id>". Rooms have no state with the
+// provider, revoking one only drops the link from its appointment.
+type LinkProvider struct {
+	baseURL string
+}
+
+func NewLinkProvider(baseURL string) *LinkProvider {
+	return &LinkProvider{baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (p *LinkProvider) Name() string {
+	return "link"
+}
+
+func (p *LinkProvider) CreateRoom(reference string) (*Room, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(b)
+	return &Room{ID: id, URL: p.baseURL + "/" + id}, nil
+}
+
+func (p *LinkProvider) RevokeRoom(roomID string) error {
+	return nil
+}
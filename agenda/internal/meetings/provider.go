@@ -0,0 +1,22 @@
+package meetings
+
+import "errors"
+
+var ErrRoomNotFound = errors.New("room_not_found")
+
+// Room is where a remote appointment takes place, URL is what the client and
+// the professional open to join it.
+type Room struct {
+	ID  string
+	URL string
+}
+
+// MeetingProvider is the contract a video conferencing service has to fulfil
+// to host remote appointments.
+type MeetingProvider interface {
+	// Name identifies the provider in the logs.
+	Name() string
+	CreateRoom(reference string) (*Room, error)
+	// RevokeRoom closes the room so its URL can't be used anymore.
+	RevokeRoom(roomID string) error
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Actions the self-service links sent to clients can carry
+const (
+	ActionConfirm    = "confirm"
+	ActionCancel     = "cancel"
+	ActionReschedule = "reschedule"
+)
+
+// ActionLink records a self-service link that was already used. Its ID is the
+// one carried by the signed link, so a second use hits the primary key.
+type ActionLink struct {
+	ID            string `gorm:"primaryKey"`
+	AppointmentID uint   `gorm:"not null;index"`
+	Action        string `gorm:"not null"`
+	UsedAt        time.Time
+}
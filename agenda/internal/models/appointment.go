@@ -1,8 +1,45 @@
 package models
 
+import "time"
+
+const (
+	AppointmentPaymentPending  = "payment_pending"
+	AppointmentPendingApproval = "pending_approval"
+	AppointmentBooked          = "booked"
+	AppointmentCompleted       = "completed"
+	AppointmentCancelled       = "cancelled"
+	AppointmentDeclined        = "declined"
+	// AppointmentExpired is set when the payment or the professional's
+	// approval doesn't arrive in time and the slot is released
+	AppointmentExpired = "expired"
+
+	ModalityInPerson = "in_person"
+	ModalityRemote   = "remote"
+)
+
 type Appointment struct {
-	ID             uint `gorm:"primaryKey"`
-	ClientID       uint `gorm:"not null"`
-	SlotID         uint `gorm:"not null;unique"`
-	ProfessionalID uint `gorm:"not null"`
+	ID                uint `gorm:"primaryKey"`
+	ClientID          uint `gorm:"not null"`
+	DependentID       uint // family member the client booked for, 0 when the client attends
+	SlotID            uint `gorm:"not null;index"`
+	ProfessionalID    uint `gorm:"not null"`
+	ServiceID         uint
+	LocationID        uint
+	Status            string `gorm:"not null;default:booked;index"`
+	ReviewRequestedAt *time.Time
+	// ApprovalDeadline is only set when the professional has to approve the
+	// booking, which then expires at that time if left unanswered
+	ApprovalDeadline *time.Time
+	// ConfirmedAt is set when the client confirms they'll attend
+	ConfirmedAt *time.Time
+	CancelledAt *time.Time
+	Modality    string `gorm:"not null;default:in_person"`
+	// MeetingID and MeetingURL identify the room of a remote appointment, the
+	// URL is cleared once the room is revoked
+	MeetingID  string
+	MeetingURL string
+	Payment    *Payment        `gorm:"foreignKey:AppointmentID"`
+	Intake     *IntakeResponse `gorm:"foreignKey:AppointmentID"`
+	// Credit is set when the appointment is paid with a package credit
+	Credit *CreditTransaction `gorm:"foreignKey:AppointmentID"`
 }
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+const (
+	AvailabilityMaterialized = "materialized"
+	AvailabilityComputed     = "computed"
+)
+
+// ProfessionalSettings holds per-professional agenda preferences. Professionals
+// without a row use materialized slots in UTC.
+type ProfessionalSettings struct {
+	ProfessionalID         uint   `gorm:"primaryKey;autoIncrement:false"`
+	AvailabilityMode       string `gorm:"not null;default:materialized"`
+	Timezone               string `gorm:"not null;default:UTC"`
+	DefaultDurationMinutes uint   `gorm:"not null;default:30"`
+	StepMinutes            uint
+	BufferMinutes          uint
+	// RequiresApproval makes bookings wait for the professional to approve them
+	RequiresApproval bool
+}
+
+func (s *ProfessionalSettings) Computed() bool {
+	return s.AvailabilityMode == AvailabilityComputed
+}
+
+func (s *ProfessionalSettings) Buffer() time.Duration {
+	return time.Duration(s.BufferMinutes) * time.Minute
+}
+
+// TimeLocation returns the timezone the availability rules are written in,
+// falling back to UTC when it can't be loaded.
+func (s *ProfessionalSettings) TimeLocation() *time.Location {
+	tz, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return tz
+}
+
+// AvailabilityRule is a recurring working period of a professional in computed
+// mode, expressed in the professional's timezone.
+type AvailabilityRule struct {
+	ID             uint   `gorm:"primaryKey"`
+	ProfessionalID uint   `gorm:"not null;index"`
+	Weekday        uint   `gorm:"not null"`
+	StartTime      string `gorm:"not null"` // "HH:MM"
+	EndTime        string `gorm:"not null"` // "HH:MM"
+	LocationID     uint
+}
+
+type TimeOff struct {
+	ID             uint      `gorm:"primaryKey"`
+	ProfessionalID uint      `gorm:"not null;index"`
+	StartTime      time.Time `gorm:"not null"`
+	EndTime        time.Time `gorm:"not null"`
+	Reason         string
+}
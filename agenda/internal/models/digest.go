@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// DigestSettings holds a professional's daily agenda digest preferences and
+// the local date of the last digest sent, which keeps restarts from sending
+// it twice. Professionals without a row get it at the default time.
+type DigestSettings struct {
+	ProfessionalID uint   `gorm:"primaryKey;autoIncrement:false"`
+	SendTime       string `gorm:"not null;default:07:00"` // "HH:MM" in the professional timezone
+	OptOut         bool
+	LastSentDate   string // "YYYY-MM-DD" in the professional timezone
+	LastSentAt     *time.Time
+}
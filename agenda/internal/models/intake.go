@@ -0,0 +1,163 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	IntakeFieldText    = "text"
+	IntakeFieldChoice  = "choice"
+	IntakeFieldDate    = "date"
+	IntakeFieldConsent = "consent"
+
+	// IntakeDateLayout is the format of the answers to date fields
+	IntakeDateLayout = "2006-01-02"
+	// IntakeTextMaxLength limits the answers to text fields
+	IntakeTextMaxLength = 2000
+)
+
+// IntakeField is a question of an intake form, forms keep their fields as a
+// JSON document so a new version doesn't need a migration.
+type IntakeField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// IntakeForm is a version of the questionnaire of a service or a professional,
+// only one of ServiceID and ProfessionalID is set. Saving a form again creates
+// the next version instead of overwriting the previous one.
+type IntakeForm struct {
+	ID             uint   `gorm:"primaryKey"`
+	ServiceID      uint   `gorm:"uniqueIndex:idx_intake_form_version"`
+	ProfessionalID uint   `gorm:"uniqueIndex:idx_intake_form_version"`
+	Version        uint   `gorm:"not null;uniqueIndex:idx_intake_form_version"`
+	Title          string `gorm:"not null"`
+	Schema         string `gorm:"type:jsonb;not null"`
+	CreatedAt      time.Time
+}
+
+// IntakeResponse holds the answers given when booking, by field key.
+type IntakeResponse struct {
+	ID            uint   `gorm:"primaryKey"`
+	AppointmentID uint   `gorm:"not null;uniqueIndex"`
+	FormID        uint   `gorm:"not null;index"`
+	FormVersion   uint   `gorm:"not null"`
+	Answers       string `gorm:"type:jsonb;not null"`
+	CreatedAt     time.Time
+}
+
+func (f *IntakeForm) Fields() ([]IntakeField, error) {
+	var fields []IntakeField
+	if err := json.Unmarshal([]byte(f.Schema), &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (f *IntakeForm) SetFields(fields []IntakeField) error {
+	schema, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	f.Schema = string(schema)
+	return nil
+}
+
+func (r *IntakeResponse) Values() (map[string]string, error) {
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(r.Answers), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ValidateIntakeSchema checks the fields of a form before it's saved.
+func ValidateIntakeSchema(fields []IntakeField) error {
+	if len(fields) == 0 {
+		return errors.New("the form needs at least one field")
+	}
+	keys := map[string]bool{}
+	for _, field := range fields {
+		if field.Key == "" || field.Label == "" {
+			return errors.New("every field needs a key and a label")
+		}
+		if keys[field.Key] {
+			return fmt.Errorf("field %s is duplicated", field.Key)
+		}
+		keys[field.Key] = true
+
+		switch field.Type {
+		case IntakeFieldChoice:
+			if len(field.Options) == 0 {
+				return fmt.Errorf("choice field %s needs options", field.Key)
+			}
+		case IntakeFieldText, IntakeFieldDate, IntakeFieldConsent:
+			if len(field.Options) > 0 {
+				return fmt.Errorf("only choice fields have options, %s is %s", field.Key, field.Type)
+			}
+		default:
+			return fmt.Errorf("field %s has an unknown type %q", field.Key, field.Type)
+		}
+	}
+	return nil
+}
+
+// ValidateIntakeAnswers checks the answers against the fields of the form and
+// returns the ones to store. Unknown keys are rejected and empty answers to
+// optional fields are dropped.
+func ValidateIntakeAnswers(fields []IntakeField, answers map[string]string) (map[string]string, error) {
+	known := make(map[string]bool, len(fields))
+	values := map[string]string{}
+	for _, field := range fields {
+		known[field.Key] = true
+		value := answers[field.Key]
+		if value == "" || (field.Type == IntakeFieldConsent && value == "false") {
+			if field.Required {
+				return nil, fmt.Errorf("%s is required", field.Key)
+			}
+			continue
+		}
+		if err := field.validate(value); err != nil {
+			return nil, err
+		}
+		values[field.Key] = value
+	}
+	for key := range answers {
+		if !known[key] {
+			return nil, fmt.Errorf("%s is not a field of the form", key)
+		}
+	}
+	return values, nil
+}
+
+func (field IntakeField) validate(value string) error {
+	switch field.Type {
+	case IntakeFieldText:
+		if utf8.RuneCountInString(value) > IntakeTextMaxLength {
+			return fmt.Errorf("%s can't be longer than %d characters", field.Key, IntakeTextMaxLength)
+		}
+	case IntakeFieldChoice:
+		for _, option := range field.Options {
+			if option == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of the options", field.Key)
+	case IntakeFieldDate:
+		if _, err := time.Parse(IntakeDateLayout, value); err != nil {
+			return fmt.Errorf("%s must be a date in YYYY-MM-DD format", field.Key)
+		}
+	case IntakeFieldConsent:
+		if value != "true" {
+			return fmt.Errorf("%s must be true or false", field.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	// NotePrivate notes and attachments are only seen by the professional
+	NotePrivate = "private"
+	// NoteShared notes and attachments are also seen by the client
+	NoteShared = "shared"
+
+	AuthorProfessional = "professional"
+	AuthorClient       = "client"
+)
+
+type AppointmentNote struct {
+	ID            uint   `gorm:"primaryKey"`
+	AppointmentID uint   `gorm:"not null;index"`
+	AuthorRole    string `gorm:"not null"`
+	AuthorID      uint   `gorm:"not null"`
+	Visibility    string `gorm:"not null;default:private"`
+	Body          string `gorm:"not null"`
+	CreatedAt     time.Time
+}
+
+// Attachment is a file uploaded to an appointment, its content lives in the
+// blob store under BlobKey.
+type Attachment struct {
+	ID            uint   `gorm:"primaryKey"`
+	AppointmentID uint   `gorm:"not null;index"`
+	UploaderRole  string `gorm:"not null"`
+	UploaderID    uint   `gorm:"not null"`
+	Visibility    string `gorm:"not null;default:private"`
+	FileName      string `gorm:"not null"`
+	ContentType   string `gorm:"not null"`
+	SizeBytes     int64  `gorm:"not null"`
+	BlobKey       string `gorm:"not null;uniqueIndex"`
+	CreatedAt     time.Time
+}
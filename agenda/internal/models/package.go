@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	CreditPurchase = "purchase"
+	CreditUse      = "use"
+	CreditRefund   = "refund"
+)
+
+// Package is a bundle of credits for a set of services, ie: "10 sessions".
+// Clients buy it once and each booking of one of its services takes a credit.
+type Package struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"not null"`
+	Credits    uint   `gorm:"not null"`
+	ValidDays  uint   `gorm:"not null"`
+	PriceCents uint
+	Currency   string    `gorm:"not null;default:USD"`
+	Services   []Service `gorm:"many2many:package_services;"`
+}
+
+// ClientPackage is a package bought by a client. Its credits can be used until
+// ExpiresAt, the appointment has to start before that time.
+type ClientPackage struct {
+	ID        uint      `gorm:"primaryKey"`
+	ClientID  uint      `gorm:"not null;index"`
+	PackageID uint      `gorm:"not null"`
+	Package   Package   `gorm:"foreignKey:PackageID"`
+	Credits   uint      `gorm:"not null"`
+	Remaining uint      `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// CreditTransaction is a movement of the credits of a client package. An
+// appointment has at most one use and one refund.
+type CreditTransaction struct {
+	ID              uint   `gorm:"primaryKey"`
+	ClientPackageID uint   `gorm:"not null;index"`
+	ClientID        uint   `gorm:"not null;index"`
+	AppointmentID   *uint  `gorm:"uniqueIndex:idx_credit_appointment_kind"`
+	Kind            string `gorm:"not null;uniqueIndex:idx_credit_appointment_kind"`
+	Delta           int    `gorm:"not null"`
+	CreatedAt       time.Time
+}
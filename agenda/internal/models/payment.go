@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	PaymentPending   = "pending"
+	PaymentCaptured  = "captured"
+	PaymentRefunded  = "refunded"
+	PaymentExpired   = "expired"
+	PaymentCancelled = "cancelled"
+)
+
+type Payment struct {
+	ID            uint   `gorm:"primaryKey"`
+	AppointmentID uint   `gorm:"not null;uniqueIndex"`
+	Provider      string `gorm:"not null"`
+	IntentID      string `gorm:"not null;uniqueIndex"`
+	AmountCents   uint   `gorm:"not null"`
+	Currency      string `gorm:"not null"`
+	Status        string `gorm:"not null;default:pending;index"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
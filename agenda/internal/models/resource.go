@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const (
+	ResourceTypeRoom      = "room"
+	ResourceTypeEquipment = "equipment"
+)
+
+type Resource struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"not null"`
+	Type string `gorm:"not null"`
+}
+
+// ResourceReservation occupies a resource for an interval. Reservations with
+// AppointmentID 0 are manual blocks (maintenance, cleaning, ...).
+type ResourceReservation struct {
+	ID            uint      `gorm:"primaryKey"`
+	ResourceID    uint      `gorm:"not null;index"`
+	AppointmentID uint      `gorm:"index"`
+	StartTime     time.Time `gorm:"not null"`
+	EndTime       time.Time `gorm:"not null"`
+	Reason        string
+}
+
+// Overlaps reports whether the reservation intersects [start, end).
+func (r ResourceReservation) Overlaps(start, end time.Time) bool {
+	return r.StartTime.Before(end) && start.Before(r.EndTime)
+}
@@ -0,0 +1,30 @@
+package models
+
+type Service struct {
+	ID              uint       `gorm:"primaryKey"`
+	Name            string     `gorm:"not null"`
+	DurationMinutes uint       `gorm:"not null"`
+	Resources       []Resource `gorm:"many2many:service_resources;"`
+	PriceCents      uint
+	// DepositCents is charged when booking, it equals PriceCents for a full
+	// prepayment and is 0 when the service doesn't require paying in advance
+	DepositCents uint
+	Currency     string `gorm:"not null;default:USD"`
+	// Modality is how the service is delivered unless the booking says otherwise
+	Modality string `gorm:"not null;default:in_person"`
+	// RequiresCredits makes bookings take a credit from a package of the
+	// client instead of a payment
+	RequiresCredits bool
+}
+
+func (s *Service) RequiresPayment() bool {
+	return s.DepositCents > 0
+}
+
+func (s *Service) ResourceIDs() []uint {
+	ids := make([]uint, len(s.Resources))
+	for i, res := range s.Resources {
+		ids[i] = res.ID
+	}
+	return ids
+}
@@ -8,4 +8,9 @@ type Slot struct {
 	StartTime      time.Time `gorm:"not null"`
 	EndTime        time.Time `gorm:"not null"`
 	Available      bool      `gorm:"default:true"`
+	LocationID     uint      `gorm:"index"`
+	// Released marks a slot materialized for an appointment of a professional
+	// in computed mode once the appointment let it go. It's kept as the
+	// appointment's record but it's neither offered nor taken.
+	Released bool `gorm:"not null;default:false"`
 }
@@ -0,0 +1,117 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// FakeProvider keeps intents in memory and signs its webhooks with HMAC-SHA256.
+// It's meant for tests and local development.
+type FakeProvider struct {
+	mu      sync.Mutex
+	secret  []byte
+	seq     int
+	intents map[string]*Intent
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{secret: []byte(webhookSecret), intents: map[string]*Intent{}}
+}
+
+func (p *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+func (p *FakeProvider) CreateIntent(amountCents uint, currency, reference string) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	intent := &Intent{
+		ID:           fmt.Sprintf("fake_pi_%d", p.seq),
+		ClientSecret: fmt.Sprintf("fake_pi_%d_secret", p.seq),
+		AmountCents:  amountCents,
+		Currency:     currency,
+		Status:       IntentRequiresPayment,
+	}
+	p.intents[intent.ID] = intent
+	copied := *intent
+	return &copied, nil
+}
+
+// Authorize simulates the client paying the intent and returns the signed
+// webhook the provider would send.
+func (p *FakeProvider) Authorize(intentID string) ([]byte, string, error) {
+	if err := p.transition(intentID, IntentRequiresPayment, IntentAuthorized); err != nil {
+		return nil, "", err
+	}
+	payload, _ := json.Marshal(Event{Type: EventAuthorized, IntentID: intentID})
+	return payload, p.Sign(payload), nil
+}
+
+func (p *FakeProvider) Capture(intentID string) error {
+	return p.transition(intentID, IntentAuthorized, IntentCaptured)
+}
+
+func (p *FakeProvider) Refund(intentID string, amountCents uint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return ErrIntentNotFound
+	}
+	if intent.Status != IntentCaptured || amountCents > intent.AmountCents {
+		return ErrInvalidState
+	}
+	intent.Status = IntentRefunded
+	return nil
+}
+
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	if !hmac.Equal([]byte(p.Sign(payload)), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload.
+func (p *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Status returns the current state of an intent, or "" when it doesn't exist.
+func (p *FakeProvider) Status(intentID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if intent, ok := p.intents[intentID]; ok {
+		return intent.Status
+	}
+	return ""
+}
+
+func (p *FakeProvider) transition(intentID, from, to string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return ErrIntentNotFound
+	}
+	if intent.Status != from {
+		return ErrInvalidState
+	}
+	intent.Status = to
+	return nil
+}
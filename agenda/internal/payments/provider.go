@@ -0,0 +1,91 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	IntentRequiresPayment = "requires_payment"
+	IntentAuthorized      = "authorized"
+	IntentCaptured        = "captured"
+	IntentRefunded        = "refunded"
+)
+
+// Providers selected with PAYMENT_PROVIDER.
+const (
+	ProviderFake   = "fake"
+	ProviderStripe = "stripe"
+)
+
+// DefaultWebhookSecret is the development fallback of PAYMENT_WEBHOOK_SECRET,
+// it's in the repo so anyone could forge a webhook signed with it.
+const DefaultWebhookSecret = "dev-webhook-secret"
+
+// EventAuthorized is sent when the client has paid and the funds are held
+// until they are captured.
+const EventAuthorized = "payment.authorized"
+
+var (
+	ErrIntentNotFound   = errors.New("intent_not_found")
+	ErrInvalidState     = errors.New("invalid_intent_state")
+	ErrInvalidSignature = errors.New("invalid_webhook_signature")
+)
+
+// Intent is a payment the client has to complete with the provider.
+// ClientSecret is handed to the client so it can pay the intent.
+type Intent struct {
+	ID           string
+	ClientSecret string
+	AmountCents  uint
+	Currency     string
+	Status       string
+}
+
+// Event is a verified webhook notification about an intent.
+type Event struct {
+	Type     string `json:"type"`
+	IntentID string `json:"intent_id"`
+}
+
+// PaymentProvider is the contract a payment gateway has to fulfil to take
+// deposits and prepayments for appointments.
+type PaymentProvider interface {
+	// Name identifies the provider in the stored payments.
+	Name() string
+	CreateIntent(amountCents uint, currency, reference string) (*Intent, error)
+	Capture(intentID string) error
+	Refund(intentID string, amountCents uint) error
+	// VerifyWebhook checks the signature of a webhook payload and decodes it.
+	VerifyWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// CheckWebhookSecret refuses an empty secret, and the default one unless
+// running in development mode.
+func CheckWebhookSecret(secret string, devMode bool) error {
+	if secret == "" {
+		return errors.New("PAYMENT_WEBHOOK_SECRET is empty")
+	}
+	if secret == DefaultWebhookSecret && !devMode {
+		return errors.New("PAYMENT_WEBHOOK_SECRET is the default one, set it or DEV_MODE=true")
+	}
+	return nil
+}
+
+// NewProvider returns the provider named by PAYMENT_PROVIDER. The fake one
+// authorizes whatever it's asked, so it only runs in development mode.
+func NewProvider(name, apiKey, webhookSecret string, devMode bool) (PaymentProvider, error) {
+	switch name {
+	case ProviderStripe:
+		if apiKey == "" {
+			return nil, errors.New("PAYMENT_API_KEY is required by the stripe provider")
+		}
+		return NewStripeProvider(apiKey, webhookSecret), nil
+	case ProviderFake:
+		if !devMode {
+			return nil, errors.New("the fake payment provider needs DEV_MODE=true")
+		}
+		return NewFakeProvider(webhookSecret), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", name)
+}
@@ -0,0 +1,198 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const stripeAPI = "https://api.stripe.com"
+
+// stripeAuthorized is sent once a manual capture intent has been paid.
+const stripeAuthorized = "payment_intent.amount_capturable_updated"
+
+// StripeProvider takes the payments with Stripe's PaymentIntents API. Intents
+// are created with manual capture, so the funds are only held until the
+// webhook confirms the appointment can still be booked.
+type StripeProvider struct {
+	apiKey        string
+	webhookSecret []byte
+	client        *http.Client
+	// BaseURL is Stripe's API, tests point it to a local server
+	BaseURL string
+	// Tolerance is how old a webhook can be, older ones are taken as replays
+	Tolerance time.Duration
+}
+
+func NewStripeProvider(apiKey, webhookSecret string) *StripeProvider {
+	return &StripeProvider{apiKey: apiKey,
+		webhookSecret: []byte(webhookSecret),
+		client:        &http.Client{Timeout: 10 * time.Second},
+		BaseURL:       stripeAPI,
+		Tolerance:     5 * time.Minute}
+}
+
+func (p *StripeProvider) Name() string {
+	return ProviderStripe
+}
+
+type stripeIntent struct {
+	ID           string `json:"id"`
+	ClientSecret string `json:"client_secret"`
+	Amount       uint   `json:"amount"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+}
+
+type stripeError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *StripeProvider) CreateIntent(amountCents uint, currency, reference string) (*Intent, error) {
+	var intent stripeIntent
+	err := p.post("/v1/payment_intents", url.Values{
+		"amount":               {strconv.FormatUint(uint64(amountCents), 10)},
+		"currency":             {strings.ToLower(currency)},
+		"capture_method":       {"manual"},
+		"description":          {reference},
+		"metadata[reference]":  {reference},
+		"payment_method_types": {"card"},
+	}, "", &intent)
+	if err != nil {
+		return nil, err
+	}
+	return &Intent{
+		ID:           intent.ID,
+		ClientSecret: intent.ClientSecret,
+		AmountCents:  intent.Amount,
+		Currency:     strings.ToUpper(intent.Currency),
+		Status:       intentStatus(intent.Status),
+	}, nil
+}
+
+func (p *StripeProvider) Capture(intentID string) error {
+	return p.post("/v1/payment_intents/"+url.PathEscape(intentID)+"/capture", url.Values{},
+		"capture-"+intentID, nil)
+}
+
+func (p *StripeProvider) Refund(intentID string, amountCents uint) error {
+	return p.post("/v1/refunds", url.Values{
+		"payment_intent": {intentID},
+		"amount":         {strconv.FormatUint(uint64(amountCents), 10)},
+	}, fmt.Sprintf("refund-%s-%d", intentID, amountCents), nil)
+}
+
+// VerifyWebhook checks the Stripe-Signature header, "t=<unix>,v1=<hex>", the
+// HMAC-SHA256 of "<t>.<payload>" with the endpoint's signing secret.
+func (p *StripeProvider) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(sent, 0)); age > p.Tolerance || age < -p.Tolerance {
+		return nil, ErrInvalidSignature
+	}
+	expected := []byte(p.Sign(sent, payload))
+	valid := false
+	for _, v1 := range signatures {
+		if hmac.Equal(expected, []byte(v1)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	var event struct {
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				ID string `json:"id"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	eventType := event.Type
+	if eventType == stripeAuthorized {
+		eventType = EventAuthorized
+	}
+	return &Event{Type: eventType, IntentID: event.Data.Object.ID}, nil
+}
+
+// Sign returns the v1 signature Stripe sends for a payload at a given time.
+func (p *StripeProvider) Sign(timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, p.webhookSecret)
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// post sends a form to the API and decodes the answer into out when given.
+// Captures and refunds carry an idempotency key so a retry never charges or
+// refunds twice.
+func (p *StripeProvider) post(path string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, p.BaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.apiKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr stripeError
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		switch apiErr.Error.Code {
+		case "resource_missing":
+			return ErrIntentNotFound
+		case "payment_intent_unexpected_state", "charge_already_refunded":
+			return ErrInvalidState
+		}
+		return fmt.Errorf("stripe %s: %d %s", path, resp.StatusCode, apiErr.Error.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func intentStatus(status string) string {
+	switch status {
+	case "requires_capture":
+		return IntentAuthorized
+	case "succeeded":
+		return IntentCaptured
+	}
+	return IntentRequiresPayment
+}
@@ -1,19 +1,63 @@
 package repositories
 
 import (
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrSlotNotAvailable     = errors.New("slot_not_available")
+	ErrResourceNotAvailable = errors.New("resource_not_available")
+	ErrStatusChanged        = errors.New("appointment_status_changed")
+	ErrLinkUsed             = errors.New("action_link_used")
+	ErrNoCredits            = errors.New("no_credits_left")
+)
+
+// AppointmentMove takes an appointment to a slot, a stored one when it has an
+// ID or a computed interval otherwise.
+type AppointmentMove struct {
+	AppointmentID uint
+	Slot          *models.Slot
+	ResourceIDs   []uint
+}
+
 type AgendaRepository interface {
 	CreateSlot(slot *models.Slot) error
-	ListAvailableSlots(professionalID uint, date time.Time) ([]models.Slot, error)
+	ListAvailableSlots(professionalID, locationID uint, from, to time.Time) ([]models.Slot, error)
 	CreateAppointment(appointment *models.Appointment) error
 	UpdateSlotAvailability(slotID uint, available bool) error
 	ListAppointments(clientID, professionalID uint) ([]models.Appointment, error)
 	GetSlotByID(slotID uint) (*models.Slot, error) // Método añadido para obtener un slot por ID
+	BookSlot(appointment *models.Appointment, resourceIDs []uint) (*models.Slot, error)
+	ListBookedSlots(professionalID uint, from, to time.Time) ([]models.Slot, error)
+	BookComputedSlot(appointment *models.Appointment, slot *models.Slot, resourceIDs []uint) error
+	GetAppointmentByID(id uint) (*models.Appointment, error)
+	UpdateAppointmentStatus(id uint, status string) error
+	ListReviewRequestsDue(endedBefore time.Time) ([]models.Appointment, error)
+	MarkReviewRequested(id uint, at time.Time) error
+	ReleaseAppointment(id uint, from []string, status string) error
+	ConfirmPayment(payment *models.Payment) (*models.Appointment, error)
+	TransitionAppointment(id uint, from []string, status string) error
+	ListExpiredApprovals(now time.Time) ([]models.Appointment, error)
+	GetPaymentByIntent(intentID string) (*models.Payment, error)
+	GetPaymentByAppointment(appointmentID uint) (*models.Payment, error)
+	UpdatePaymentStatus(id uint, status string) error
+	ListPendingPayments(createdBefore time.Time) ([]models.Payment, error)
+	UseActionLink(link *models.ActionLink) error
+	ReleaseActionLink(id string) error
+	ConfirmAppointment(id uint, from []string, at time.Time) error
+	RescheduleAppointment(id uint, from []string, slot *models.Slot, resourceIDs []uint) error
+	ReassignAppointments(from []string, moves []AppointmentMove) error
+	ListProfessionalAppointments(professionalID uint, statuses []string, from, to time.Time) ([]models.Appointment, error)
+	ListCancellations(professionalID uint, since time.Time) ([]models.Appointment, error)
+	GetIntakeResponse(appointmentID uint) (*models.IntakeResponse, error)
+	ClearMeetingURL(id uint) error
+	RefundCredit(appointmentID uint) (bool, error)
 }
 
 type AgendaRepositoryImpl struct {
@@ -28,12 +72,19 @@ func (r *AgendaRepositoryImpl) CreateSlot(slot *models.Slot) error {
 	return r.DB.Create(slot).Error
 }
 
-func (r *AgendaRepositoryImpl) ListAvailableSlots(professionalID uint, date time.Time) ([]models.Slot, error) {
+// ListAvailableSlots returns the free slots starting within [from, to).
+// professionalID and locationID are optional filters when set to 0.
+func (r *AgendaRepositoryImpl) ListAvailableSlots(professionalID, locationID uint, from, to time.Time) ([]models.Slot, error) {
 	var slots []models.Slot
-	startOfDay := date.Truncate(24 * time.Hour)
-	endOfDay := startOfDay.Add(24 * time.Hour)
-	err := r.DB.Where("professional_id = ? AND start_time >= ? AND start_time < ? AND available = ?",
-		professionalID, startOfDay, endOfDay, true).Find(&slots).Error
+	query := r.DB.Model(&models.Slot{})
+	if professionalID != 0 {
+		query = query.Where("professional_id = ?", professionalID)
+	}
+	if locationID != 0 {
+		query = query.Where("location_id = ?", locationID)
+	}
+	err := query.Where("start_time >= ? AND start_time < ? AND available = ?", from, to, true).
+		Find(&slots).Error
 	return slots, err
 }
 
@@ -58,6 +109,33 @@ func (r *AgendaRepositoryImpl) ListAppointments(clientID, professionalID uint) (
 	return appointments, err
 }
 
+func (r *AgendaRepositoryImpl) GetAppointmentByID(id uint) (*models.Appointment, error) {
+	var appointment models.Appointment
+	err := r.DB.First(&appointment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+func (r *AgendaRepositoryImpl) UpdateAppointmentStatus(id uint, status string) error {
+	return r.DB.Model(&models.Appointment{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// ListReviewRequestsDue returns the completed appointments whose slot ended
+// before the given time and whose client hasn't been asked for a review yet.
+func (r *AgendaRepositoryImpl) ListReviewRequestsDue(endedBefore time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.DB.Joins("JOIN slots ON slots.id = appointments.slot_id").
+		Where("appointments.status = ? AND appointments.review_requested_at IS NULL AND slots.end_time <= ?", models.AppointmentCompleted, endedBefore).
+		Find(&appointments).Error
+	return appointments, err
+}
+
+func (r *AgendaRepositoryImpl) MarkReviewRequested(id uint, at time.Time) error {
+	return r.DB.Model(&models.Appointment{}).Where("id = ?", id).Update("review_requested_at", at).Error
+}
+
 func (r *AgendaRepositoryImpl) GetSlotByID(slotID uint) (*models.Slot, error) {
 	var slot models.Slot
 	err := r.DB.First(&slot, slotID).Error
@@ -66,3 +144,563 @@ func (r *AgendaRepositoryImpl) GetSlotByID(slotID uint) (*models.Slot, error) {
 	}
 	return &slot, nil
 }
+
+// BookSlot creates the appointment, takes its slot and reserves the given
+// resources in a single transaction. The slot and resource rows are locked
+// so concurrent bookings competing for any of them are serialized.
+func (r *AgendaRepositoryImpl) BookSlot(appointment *models.Appointment, resourceIDs []uint) (*models.Slot, error) {
+	var slot models.Slot
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&slot, appointment.SlotID).Error; err != nil {
+			return err
+		}
+		if !slot.Available {
+			return ErrSlotNotAvailable
+		}
+
+		if err := lockFreeResources(tx, resourceIDs, slot.StartTime, slot.EndTime); err != nil {
+			return err
+		}
+		if err := createAppointment(tx, appointment, &slot, resourceIDs); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Slot{}).Where("id = ?", slot.ID).Update("available", false).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	slot.Available = false
+	return &slot, nil
+}
+
+// ListBookedSlots returns the professional's taken slots intersecting [from, to).
+func (r *AgendaRepositoryImpl) ListBookedSlots(professionalID uint, from, to time.Time) ([]models.Slot, error) {
+	var slots []models.Slot
+	err := r.DB.Where("professional_id = ? AND available = ? AND released = ? AND start_time < ? AND end_time > ?",
+		professionalID, false, false, to, from).
+		Find(&slots).Error
+	return slots, err
+}
+
+// BookComputedSlot books an interval computed from the working-hour rules of a
+// professional in computed mode. The interval is checked under the settings
+// lock before it's materialized as an unavailable slot holding the appointment.
+func (r *AgendaRepositoryImpl) BookComputedSlot(appointment *models.Appointment, slot *models.Slot, resourceIDs []uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockComputedInterval(tx, slot, 0); err != nil {
+			return err
+		}
+
+		if err := lockFreeResources(tx, resourceIDs, slot.StartTime, slot.EndTime); err != nil {
+			return err
+		}
+
+		// Available tiene default:true, por eso se marca como tomado después de insertarlo
+		slot.Available = true
+		if err := tx.Create(slot).Error; err != nil {
+			return err
+		}
+		appointment.SlotID = slot.ID
+		if err := createAppointment(tx, appointment, slot, resourceIDs); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Slot{}).Where("id = ?", slot.ID).Update("available", false).Error; err != nil {
+			return err
+		}
+		slot.Available = false
+		return nil
+	})
+}
+
+// ClearMeetingURL drops the link of a revoked meeting, the room id is kept.
+func (r *AgendaRepositoryImpl) ClearMeetingURL(id uint) error {
+	return r.DB.Model(&models.Appointment{}).Where("id = ?", id).Update("meeting_url", "").Error
+}
+
+// RefundCredit gives back to its package the credit used by the appointment.
+// It returns false when the appointment wasn't paid with a credit or the
+// credit was already refunded.
+func (r *AgendaRepositoryImpl) RefundCredit(appointmentID uint) (bool, error) {
+	refunded := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var use models.CreditTransaction
+		err := tx.Where("appointment_id = ? AND kind = ?", appointmentID, models.CreditUse).First(&use).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.CreditTransaction{}).
+			Where("appointment_id = ? AND kind = ?", appointmentID, models.CreditRefund).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.ClientPackage{}).Where("id = ?", use.ClientPackageID).
+			Update("remaining", gorm.Expr("remaining + 1")).Error; err != nil {
+			return err
+		}
+		refund := &models.CreditTransaction{
+			ClientPackageID: use.ClientPackageID,
+			ClientID:        use.ClientID,
+			AppointmentID:   &appointmentID,
+			Kind:            models.CreditRefund,
+			Delta:           1,
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+		refunded = true
+		return nil
+	})
+	return refunded, err
+}
+
+func (r *AgendaRepositoryImpl) GetIntakeResponse(appointmentID uint) (*models.IntakeResponse, error) {
+	var response models.IntakeResponse
+	err := r.DB.Where("appointment_id = ?", appointmentID).First(&response).Error
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ReleaseAppointment moves the appointment to the given status, frees its slot
+// and drops its resource reservations. It fails with ErrStatusChanged when the
+// appointment isn't in one of the from statuses anymore.
+func (r *AgendaRepositoryImpl) ReleaseAppointment(id uint, from []string, status string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		appointment, err := lockAppointment(tx, id, from)
+		if err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{"status": status}
+		if status == models.AppointmentCancelled {
+			changes["cancelled_at"] = time.Now()
+		}
+		if err := tx.Model(&models.Appointment{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+			return err
+		}
+		if err := freeSlot(tx, appointment.SlotID, appointment.ProfessionalID); err != nil {
+			return err
+		}
+		return tx.Where("appointment_id = ?", id).Delete(&models.ResourceReservation{}).Error
+	})
+}
+
+// ConfirmPayment marks the payment as captured and moves its appointment on,
+// to pending_approval when the professional has to approve it or to booked
+// otherwise. It fails with ErrStatusChanged when the appointment stopped
+// waiting for the payment.
+func (r *AgendaRepositoryImpl) ConfirmPayment(payment *models.Payment) (*models.Appointment, error) {
+	var appointment *models.Appointment
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		appointment, err = lockAppointment(tx, payment.AppointmentID, []string{models.AppointmentPaymentPending})
+		if err != nil {
+			return err
+		}
+
+		appointment.Status = models.AppointmentBooked
+		if appointment.ApprovalDeadline != nil {
+			appointment.Status = models.AppointmentPendingApproval
+		}
+		if err := tx.Model(&models.Appointment{}).Where("id = ?", appointment.ID).
+			Update("status", appointment.Status).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Payment{}).Where("id = ?", payment.ID).Update("status", models.PaymentCaptured).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return appointment, nil
+}
+
+// TransitionAppointment changes the status of an appointment that is still in
+// one of the from statuses, failing with ErrStatusChanged otherwise.
+func (r *AgendaRepositoryImpl) TransitionAppointment(id uint, from []string, status string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockAppointment(tx, id, from); err != nil {
+			return err
+		}
+		return tx.Model(&models.Appointment{}).Where("id = ?", id).Update("status", status).Error
+	})
+}
+
+// ListExpiredApprovals returns the requests nobody answered before their
+// approval deadline.
+func (r *AgendaRepositoryImpl) ListExpiredApprovals(now time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.DB.Where("status = ? AND approval_deadline <= ?", models.AppointmentPendingApproval, now).
+		Find(&appointments).Error
+	return appointments, err
+}
+
+func (r *AgendaRepositoryImpl) GetPaymentByIntent(intentID string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.DB.Where("intent_id = ?", intentID).First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *AgendaRepositoryImpl) GetPaymentByAppointment(appointmentID uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.DB.Where("appointment_id = ?", appointmentID).First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *AgendaRepositoryImpl) UpdatePaymentStatus(id uint, status string) error {
+	return r.DB.Model(&models.Payment{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// ListPendingPayments returns the payments still waiting for the client that
+// were created before the given time.
+func (r *AgendaRepositoryImpl) ListPendingPayments(createdBefore time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.DB.Where("status = ? AND created_at < ?", models.PaymentPending, createdBefore).Find(&payments).Error
+	return payments, err
+}
+
+// UseActionLink records the use of a self-service link, failing with
+// ErrLinkUsed when it was already used.
+func (r *AgendaRepositoryImpl) UseActionLink(link *models.ActionLink) error {
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(link)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLinkUsed
+	}
+	return nil
+}
+
+// ReleaseActionLink forgets the use of a link whose action didn't go through,
+// so the client can try again.
+func (r *AgendaRepositoryImpl) ReleaseActionLink(id string) error {
+	return r.DB.Where("id = ?", id).Delete(&models.ActionLink{}).Error
+}
+
+// ConfirmAppointment records the client's confirmation of an appointment that
+// is still in one of the from statuses, failing with ErrStatusChanged otherwise.
+func (r *AgendaRepositoryImpl) ConfirmAppointment(id uint, from []string, at time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockAppointment(tx, id, from); err != nil {
+			return err
+		}
+		return tx.Model(&models.Appointment{}).Where("id = ?", id).Update("confirmed_at", at).Error
+	})
+}
+
+// RescheduleAppointment moves the appointment to another slot of the same
+// professional in a single transaction, see moveAppointment. The slot is
+// filled in with the stored values.
+func (r *AgendaRepositoryImpl) RescheduleAppointment(id uint, from []string, slot *models.Slot, resourceIDs []uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return moveAppointment(tx, id, from, slot, 0, resourceIDs)
+	})
+}
+
+// ReassignAppointments applies all the moves in a single transaction, so
+// either every appointment changes professional or none does. The target
+// professional is the one of each slot.
+func (r *AgendaRepositoryImpl) ReassignAppointments(from []string, moves []AppointmentMove) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, move := range moves {
+			if err := moveAppointment(tx, move.AppointmentID, from, move.Slot, move.Slot.ProfessionalID, move.ResourceIDs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListProfessionalAppointments returns the professional's appointments in the
+// given statuses whose slot starts within [from, to), earliest first.
+func (r *AgendaRepositoryImpl) ListProfessionalAppointments(professionalID uint, statuses []string, from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.DB.Joins("JOIN slots ON slots.id = appointments.slot_id").
+		Where("appointments.professional_id = ? AND appointments.status IN ? AND slots.start_time >= ? AND slots.start_time < ?",
+			professionalID, statuses, from, to).
+		Order("slots.start_time").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// ListCancellations returns the professional's appointments cancelled after
+// the given time.
+func (r *AgendaRepositoryImpl) ListCancellations(professionalID uint, since time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.DB.Where("professional_id = ? AND status = ? AND cancelled_at > ?", professionalID, models.AppointmentCancelled, since).
+		Order("cancelled_at").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// lockAppointment locks the appointment row and checks it's in one of the
+// given statuses.
+func lockAppointment(tx *gorm.DB, id uint, statuses []string) (*models.Appointment, error) {
+	var appointment models.Appointment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&appointment, id).Error; err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if appointment.Status == status {
+			return &appointment, nil
+		}
+	}
+	return nil, ErrStatusChanged
+}
+
+// moveAppointment moves the appointment to another slot: the new slot is
+// taken, the resources are reserved again for its times and the old slot is
+// freed. A slot with an ID must be an available slot of the professional, the
+// appointment's one when professionalID is 0; one without is a computed
+// interval that gets materialized like in BookComputedSlot. The slot is filled
+// in with the stored values.
+func moveAppointment(tx *gorm.DB, id uint, from []string, slot *models.Slot, professionalID uint, resourceIDs []uint) error {
+	appointment, err := lockAppointment(tx, id, from)
+	if err != nil {
+		return err
+	}
+	if professionalID == 0 {
+		professionalID = appointment.ProfessionalID
+	}
+
+	if slot.ID != 0 {
+		var stored models.Slot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, slot.ID).Error; err != nil {
+			return err
+		}
+		if !stored.Available || stored.ProfessionalID != professionalID {
+			return ErrSlotNotAvailable
+		}
+		*slot = stored
+	} else if err := lockComputedInterval(tx, slot, appointment.SlotID); err != nil {
+		return err
+	}
+
+	// Las reservas actuales no deben chocar con las del nuevo horario
+	if err := tx.Where("appointment_id = ?", id).Delete(&models.ResourceReservation{}).Error; err != nil {
+		return err
+	}
+	if err := lockFreeResources(tx, resourceIDs, slot.StartTime, slot.EndTime); err != nil {
+		return err
+	}
+
+	if slot.ID == 0 {
+		slot.Available = true
+		if err := tx.Create(slot).Error; err != nil {
+			return err
+		}
+	}
+	if err := reserveResources(tx, id, slot, resourceIDs); err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Appointment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"slot_id":         slot.ID,
+		"professional_id": slot.ProfessionalID,
+		"location_id":     slot.LocationID,
+	}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Slot{}).Where("id = ?", slot.ID).Update("available", false).Error; err != nil {
+		return err
+	}
+	slot.Available = false
+	return freeSlot(tx, appointment.SlotID, appointment.ProfessionalID)
+}
+
+// freeSlot gives back the slot an appointment let go. Professionals in computed
+// mode don't offer slots, theirs was materialized for the appointment so it's
+// released instead of becoming bookable by its ID.
+func freeSlot(tx *gorm.DB, slotID, professionalID uint) error {
+	column, err := freeSlotColumn(tx, professionalID)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Slot{}).Where("id = ?", slotID).Update(column, true).Error
+}
+
+// freeSlotColumn returns the flag that frees the professional's slots,
+// "released" in computed mode and "available" otherwise.
+func freeSlotColumn(tx *gorm.DB, professionalID uint) (string, error) {
+	var settings models.ProfessionalSettings
+	err := tx.Where("professional_id = ?", professionalID).First(&settings).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	if settings.Computed() {
+		return "released", nil
+	}
+	return "available", nil
+}
+
+// lockComputedInterval locks the settings row of a professional in computed
+// mode, serializing their bookings, and fails with ErrSlotNotAvailable when the
+// interval (widened by the buffer) overlaps a taken slot other than the ignored
+// one or falls in their time off.
+func lockComputedInterval(tx *gorm.DB, slot *models.Slot, ignoredSlotID uint) error {
+	var settings models.ProfessionalSettings
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("professional_id = ?", slot.ProfessionalID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSlotNotAvailable
+	}
+	if err != nil {
+		return err
+	}
+	if !settings.Computed() {
+		return ErrSlotNotAvailable
+	}
+
+	from, to := slot.StartTime.Add(-settings.Buffer()), slot.EndTime.Add(settings.Buffer())
+	taken := tx.Model(&models.Slot{}).
+		Where("professional_id = ? AND available = ? AND released = ? AND start_time < ? AND end_time > ?",
+			slot.ProfessionalID, false, false, to, from)
+	if ignoredSlotID != 0 {
+		taken = taken.Where("id <> ?", ignoredSlotID)
+	}
+	var conflicts int64
+	if err := taken.Count(&conflicts).Error; err != nil {
+		return err
+	}
+	if conflicts == 0 {
+		if err := tx.Model(&models.TimeOff{}).
+			Where("professional_id = ? AND start_time < ? AND end_time > ?", slot.ProfessionalID, slot.EndTime, slot.StartTime).
+			Count(&conflicts).Error; err != nil {
+			return err
+		}
+	}
+	if conflicts > 0 {
+		return ErrSlotNotAvailable
+	}
+	return nil
+}
+
+// lockFreeResources locks the resource rows and fails with
+// ErrResourceNotAvailable when any of them is reserved during [start, end).
+func lockFreeResources(tx *gorm.DB, resourceIDs []uint, start, end time.Time) error {
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+
+	// Un recurso repetido se bloquea una vez, sino faltarían filas al comparar
+	resourceIDs = uniqueIDs(resourceIDs)
+	var resources []models.Resource
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", resourceIDs).
+		Order("id").Find(&resources).Error; err != nil {
+		return err
+	}
+	if len(resources) != len(resourceIDs) {
+		return gorm.ErrRecordNotFound
+	}
+
+	var overlapping int64
+	if err := tx.Model(&models.ResourceReservation{}).
+		Where("resource_id IN ? AND start_time < ? AND end_time > ?", resourceIDs, end, start).
+		Count(&overlapping).Error; err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrResourceNotAvailable
+	}
+	return nil
+}
+
+// createAppointment inserts the appointment for the slot together with its
+// payment or credit, if any, and the reservations of its resources.
+func createAppointment(tx *gorm.DB, appointment *models.Appointment, slot *models.Slot, resourceIDs []uint) error {
+	appointment.ProfessionalID = slot.ProfessionalID
+	appointment.LocationID = slot.LocationID
+	if appointment.Modality == "" {
+		appointment.Modality = models.ModalityInPerson
+	}
+	if err := tx.Omit("Payment", "Intake", "Credit").Create(appointment).Error; err != nil {
+		return err
+	}
+	if appointment.Payment != nil {
+		appointment.Payment.AppointmentID = appointment.ID
+		if err := tx.Create(appointment.Payment).Error; err != nil {
+			return err
+		}
+	}
+	if appointment.Intake != nil {
+		appointment.Intake.AppointmentID = appointment.ID
+		if err := tx.Create(appointment.Intake).Error; err != nil {
+			return err
+		}
+	}
+	if appointment.Credit != nil {
+		if err := useCredit(tx, appointment, slot); err != nil {
+			return err
+		}
+	}
+
+	return reserveResources(tx, appointment.ID, slot, resourceIDs)
+}
+
+// useCredit takes the appointment's credit from the client's package of its
+// service that expires first. The package has to be valid when the slot
+// starts, it fails with ErrNoCredits when there is none.
+func useCredit(tx *gorm.DB, appointment *models.Appointment, slot *models.Slot) error {
+	var clientPackage models.ClientPackage
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "client_packages"}}).
+		Joins("JOIN package_services ON package_services.package_id = client_packages.package_id").
+		Where("client_packages.client_id = ? AND package_services.service_id = ? AND client_packages.remaining > 0 AND client_packages.expires_at > ?",
+			appointment.ClientID, appointment.ServiceID, slot.StartTime).
+		Order("client_packages.expires_at").First(&clientPackage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoCredits
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.ClientPackage{}).Where("id = ?", clientPackage.ID).
+		Update("remaining", gorm.Expr("remaining - 1")).Error; err != nil {
+		return err
+	}
+	appointment.Credit.ClientPackageID = clientPackage.ID
+	appointment.Credit.ClientID = appointment.ClientID
+	appointment.Credit.AppointmentID = &appointment.ID
+	appointment.Credit.Kind = models.CreditUse
+	appointment.Credit.Delta = -1
+	return tx.Create(appointment.Credit).Error
+}
+
+// reserveResources reserves the resources for the appointment during the slot.
+func reserveResources(tx *gorm.DB, appointmentID uint, slot *models.Slot, resourceIDs []uint) error {
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+	resourceIDs = uniqueIDs(resourceIDs)
+	reservations := make([]models.ResourceReservation, len(resourceIDs))
+	for i, resourceID := range resourceIDs {
+		reservations[i] = models.ResourceReservation{
+			ResourceID:    resourceID,
+			AppointmentID: appointmentID,
+			StartTime:     slot.StartTime,
+			EndTime:       slot.EndTime,
+		}
+	}
+	return tx.Create(&reservations).Error
+}
+
+// uniqueIDs returns the IDs sorted and without repeats, leaving ids untouched.
+func uniqueIDs(ids []uint) []uint {
+	unique := slices.Clone(ids)
+	slices.Sort(unique)
+	return slices.Compact(unique)
+}
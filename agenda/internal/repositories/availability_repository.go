@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDigestSent = errors.New("digest_already_sent")
+
+type AvailabilityRepository interface {
+	GetSettings(professionalID uint) (*models.ProfessionalSettings, error)
+	SaveSettings(settings *models.ProfessionalSettings) error
+	ListComputedProfessionals(locationID uint) ([]uint, error)
+	ReplaceRules(professionalID uint, rules []models.AvailabilityRule) error
+	ListRules(professionalID uint) ([]models.AvailabilityRule, error)
+	CreateTimeOff(timeOff *models.TimeOff) error
+	ListTimeOff(professionalID uint, from, to time.Time) ([]models.TimeOff, error)
+	GetDigestSettings(professionalID uint) (*models.DigestSettings, error)
+	SaveDigestPreferences(digest *models.DigestSettings) error
+	ClaimDigest(professionalID uint, date string, at time.Time) (*time.Time, error)
+	UnclaimDigest(professionalID uint, date string, at *time.Time) error
+}
+
+type AvailabilityRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewAvailabilityRepository(db *gorm.DB) AvailabilityRepository {
+	return &AvailabilityRepositoryImpl{DB: db}
+}
+
+// GetSettings returns the professional's settings, or the defaults when none
+// have been saved yet.
+func (r *AvailabilityRepositoryImpl) GetSettings(professionalID uint) (*models.ProfessionalSettings, error) {
+	var settings models.ProfessionalSettings
+	err := r.DB.Where(models.ProfessionalSettings{ProfessionalID: professionalID}).
+		Attrs(models.ProfessionalSettings{
+			AvailabilityMode:       models.AvailabilityMaterialized,
+			Timezone:               "UTC",
+			DefaultDurationMinutes: 30,
+		}).
+		FirstOrInit(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *AvailabilityRepositoryImpl) SaveSettings(settings *models.ProfessionalSettings) error {
+	return r.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(settings).Error
+}
+
+// ListComputedProfessionals returns the professionals in computed mode with at
+// least one rule at the location.
+func (r *AvailabilityRepositoryImpl) ListComputedProfessionals(locationID uint) ([]uint, error) {
+	var ids []uint
+	atLocation := r.DB.Model(&models.AvailabilityRule{}).Select("professional_id").Where("location_id = ?", locationID)
+	err := r.DB.Model(&models.ProfessionalSettings{}).
+		Where("availability_mode = ? AND professional_id IN (?)", models.AvailabilityComputed, atLocation).
+		Pluck("professional_id", &ids).Error
+	return ids, err
+}
+
+func (r *AvailabilityRepositoryImpl) ReplaceRules(professionalID uint, rules []models.AvailabilityRule) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("professional_id = ?", professionalID).Delete(&models.AvailabilityRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+}
+
+func (r *AvailabilityRepositoryImpl) ListRules(professionalID uint) ([]models.AvailabilityRule, error) {
+	var rules []models.AvailabilityRule
+	err := r.DB.Where("professional_id = ?", professionalID).Find(&rules).Error
+	return rules, err
+}
+
+func (r *AvailabilityRepositoryImpl) CreateTimeOff(timeOff *models.TimeOff) error {
+	return r.DB.Create(timeOff).Error
+}
+
+// ListTimeOff returns the professional's time off intersecting [from, to).
+func (r *AvailabilityRepositoryImpl) ListTimeOff(professionalID uint, from, to time.Time) ([]models.TimeOff, error) {
+	var timeOff []models.TimeOff
+	err := r.DB.Where("professional_id = ? AND start_time < ? AND end_time > ?", professionalID, to, from).
+		Find(&timeOff).Error
+	return timeOff, err
+}
+
+// GetDigestSettings returns the professional's digest settings, or the
+// defaults when none have been saved yet.
+func (r *AvailabilityRepositoryImpl) GetDigestSettings(professionalID uint) (*models.DigestSettings, error) {
+	var digest models.DigestSettings
+	err := r.DB.Where(models.DigestSettings{ProfessionalID: professionalID}).
+		Attrs(models.DigestSettings{SendTime: "07:00"}).
+		FirstOrInit(&digest).Error
+	if err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+// SaveDigestPreferences stores the send time and opt out, leaving the record
+// of the last digest sent untouched.
+func (r *AvailabilityRepositoryImpl) SaveDigestPreferences(digest *models.DigestSettings) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "professional_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"send_time", "opt_out"}),
+	}).Create(digest).Error
+}
+
+// ClaimDigest records that the digest of the given local date is being sent and
+// returns when the previous one was, nil if never. It fails with ErrDigestSent
+// when that date's digest was already claimed, so only one run sends it.
+func (r *AvailabilityRepositoryImpl) ClaimDigest(professionalID uint, date string, at time.Time) (*time.Time, error) {
+	var previous *time.Time
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var digest models.DigestSettings
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("professional_id = ?", professionalID).First(&digest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&models.DigestSettings{ProfessionalID: professionalID, SendTime: "07:00",
+				LastSentDate: date, LastSentAt: &at}).Error
+		}
+		if err != nil {
+			return err
+		}
+		if digest.LastSentDate == date {
+			return ErrDigestSent
+		}
+
+		previous = digest.LastSentAt
+		return tx.Model(&models.DigestSettings{}).Where("professional_id = ?", professionalID).
+			Updates(map[string]interface{}{"last_sent_date": date, "last_sent_at": at}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// UnclaimDigest puts back the record of the last digest sent when the claimed
+// one couldn't be delivered, so it's retried.
+func (r *AvailabilityRepositoryImpl) UnclaimDigest(professionalID uint, date string, at *time.Time) error {
+	return r.DB.Model(&models.DigestSettings{}).Where("professional_id = ?", professionalID).
+		Updates(map[string]interface{}{"last_sent_date": date, "last_sent_at": at}).Error
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"gorm.io/gorm"
+)
+
+type NoteRepository interface {
+	CreateNote(note *models.AppointmentNote) error
+	ListNotes(appointmentID uint, visibilities []string) ([]models.AppointmentNote, error)
+	CreateAttachment(attachment *models.Attachment) error
+	ListAttachments(appointmentID uint, visibilities []string) ([]models.Attachment, error)
+	GetAttachmentByID(id uint) (*models.Attachment, error)
+}
+
+type NoteRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewNoteRepository(db *gorm.DB) NoteRepository {
+	return &NoteRepositoryImpl{DB: db}
+}
+
+func (r *NoteRepositoryImpl) CreateNote(note *models.AppointmentNote) error {
+	return r.DB.Create(note).Error
+}
+
+// ListNotes returns the appointment's notes with one of the visibilities,
+// oldest first.
+func (r *NoteRepositoryImpl) ListNotes(appointmentID uint, visibilities []string) ([]models.AppointmentNote, error) {
+	var notes []models.AppointmentNote
+	err := r.DB.Where("appointment_id = ? AND visibility IN ?", appointmentID, visibilities).
+		Order("created_at").Find(&notes).Error
+	return notes, err
+}
+
+func (r *NoteRepositoryImpl) CreateAttachment(attachment *models.Attachment) error {
+	return r.DB.Create(attachment).Error
+}
+
+// ListAttachments returns the appointment's attachments with one of the
+// visibilities, oldest first.
+func (r *NoteRepositoryImpl) ListAttachments(appointmentID uint, visibilities []string) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.DB.Where("appointment_id = ? AND visibility IN ?", appointmentID, visibilities).
+		Order("created_at").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *NoteRepositoryImpl) GetAttachmentByID(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.DB.First(&attachment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"gorm.io/gorm"
+)
+
+type PackageRepository interface {
+	CreatePackage(pkg *models.Package) error
+	GetPackageByID(id uint) (*models.Package, error)
+	ListPackages() ([]models.Package, error)
+	GetServicesByIDs(ids []uint) ([]models.Service, error)
+	PurchasePackage(clientPackage *models.ClientPackage) error
+	ListActivePackages(clientID uint, at time.Time) ([]models.ClientPackage, error)
+	ListCreditTransactions(clientID uint) ([]models.CreditTransaction, error)
+}
+
+type PackageRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewPackageRepository(db *gorm.DB) PackageRepository {
+	return &PackageRepositoryImpl{DB: db}
+}
+
+func (r *PackageRepositoryImpl) CreatePackage(pkg *models.Package) error {
+	return r.DB.Create(pkg).Error
+}
+
+func (r *PackageRepositoryImpl) GetPackageByID(id uint) (*models.Package, error) {
+	var pkg models.Package
+	err := r.DB.Preload("Services").First(&pkg, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+func (r *PackageRepositoryImpl) ListPackages() ([]models.Package, error) {
+	var packages []models.Package
+	err := r.DB.Preload("Services").Find(&packages).Error
+	return packages, err
+}
+
+func (r *PackageRepositoryImpl) GetServicesByIDs(ids []uint) ([]models.Service, error) {
+	var services []models.Service
+	err := r.DB.Where("id IN ?", ids).Find(&services).Error
+	return services, err
+}
+
+// PurchasePackage gives the client the credits of the package and records
+// the purchase in their history.
+func (r *PackageRepositoryImpl) PurchasePackage(clientPackage *models.ClientPackage) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Package").Create(clientPackage).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CreditTransaction{
+			ClientPackageID: clientPackage.ID,
+			ClientID:        clientPackage.ClientID,
+			Kind:            models.CreditPurchase,
+			Delta:           int(clientPackage.Credits),
+		}).Error
+	})
+}
+
+// ListActivePackages returns the client's packages with credits left that
+// haven't expired at the given time, the ones expiring first go first.
+func (r *PackageRepositoryImpl) ListActivePackages(clientID uint, at time.Time) ([]models.ClientPackage, error) {
+	var packages []models.ClientPackage
+	err := r.DB.Preload("Package.Services").
+		Where("client_id = ? AND remaining > 0 AND expires_at > ?", clientID, at).
+		Order("expires_at").Find(&packages).Error
+	return packages, err
+}
+
+// ListCreditTransactions returns the credit history of the client, newest first.
+func (r *PackageRepositoryImpl) ListCreditTransactions(clientID uint) ([]models.CreditTransaction, error) {
+	var transactions []models.CreditTransaction
+	err := r.DB.Where("client_id = ?", clientID).Order("created_at DESC").Find(&transactions).Error
+	return transactions, err
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"gorm.io/gorm"
+)
+
+// SlotHoldingStatuses are the appointment statuses that keep their slot taken.
+var SlotHoldingStatuses = []string{
+	models.AppointmentPaymentPending,
+	models.AppointmentPendingApproval,
+	models.AppointmentBooked,
+	models.AppointmentCompleted,
+}
+
+const holdingAppointment = "SELECT 1 FROM appointments WHERE appointments.slot_id = slots.id AND appointments.status IN ?"
+
+type ReconcileRepository interface {
+	ListOrphanSlots() ([]models.Slot, error)
+	ListFreeBookedSlots() ([]models.Appointment, error)
+	ListAppointmentsWithoutSlot() ([]models.Appointment, error)
+	FreeOrphanSlot(slotID, professionalID uint) (bool, error)
+	TakeBookedSlot(slotID uint) (bool, error)
+}
+
+type ReconcileRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewReconcileRepository(db *gorm.DB) ReconcileRepository {
+	return &ReconcileRepositoryImpl{DB: db}
+}
+
+// ListOrphanSlots returns the taken slots no active appointment holds.
+func (r *ReconcileRepositoryImpl) ListOrphanSlots() ([]models.Slot, error) {
+	var slots []models.Slot
+	err := r.DB.Where("available = ? AND released = ? AND NOT EXISTS ("+holdingAppointment+")", false, false, SlotHoldingStatuses).
+		Order("id").Find(&slots).Error
+	return slots, err
+}
+
+// ListFreeBookedSlots returns the active appointments whose slot is still
+// marked as available.
+func (r *ReconcileRepositoryImpl) ListFreeBookedSlots() ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.DB.Joins("JOIN slots ON slots.id = appointments.slot_id").
+		Where("slots.available = ? AND appointments.status IN ?", true, SlotHoldingStatuses).
+		Order("appointments.id").Find(&appointments).Error
+	return appointments, err
+}
+
+// ListAppointmentsWithoutSlot returns the appointments, whatever their
+// status, pointing at a slot that doesn't exist.
+func (r *ReconcileRepositoryImpl) ListAppointmentsWithoutSlot() ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.DB.Joins("LEFT JOIN slots ON slots.id = appointments.slot_id").
+		Where("slots.id IS NULL").
+		Order("appointments.id").Find(&appointments).Error
+	return appointments, err
+}
+
+// FreeOrphanSlot frees the slot unless an appointment took it since it was
+// listed, reporting whether it was freed. Slots of professionals in computed
+// mode are released like when their appointment ends.
+func (r *ReconcileRepositoryImpl) FreeOrphanSlot(slotID, professionalID uint) (bool, error) {
+	column, err := freeSlotColumn(r.DB, professionalID)
+	if err != nil {
+		return false, err
+	}
+	result := r.DB.Model(&models.Slot{}).
+		Where("id = ? AND available = ? AND released = ? AND NOT EXISTS ("+holdingAppointment+")", slotID, false, false, SlotHoldingStatuses).
+		Update(column, true)
+	return result.RowsAffected > 0, result.Error
+}
+
+// TakeBookedSlot marks the slot as taken while an active appointment still
+// holds it, reporting whether it was changed.
+func (r *ReconcileRepositoryImpl) TakeBookedSlot(slotID uint) (bool, error) {
+	result := r.DB.Model(&models.Slot{}).
+		Where("id = ? AND available = ? AND EXISTS ("+holdingAppointment+")", slotID, true, SlotHoldingStatuses).
+		Update("available", false)
+	return result.RowsAffected > 0, result.Error
+}
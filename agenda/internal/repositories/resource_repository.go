@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"gorm.io/gorm"
+)
+
+type ResourceRepository interface {
+	CreateResource(resource *models.Resource) error
+	ListResources(resourceType string) ([]models.Resource, error)
+	GetResourcesByIDs(ids []uint) ([]models.Resource, error)
+	CreateReservation(reservation *models.ResourceReservation) error
+	ListReservations(resourceIDs []uint, from, to time.Time) ([]models.ResourceReservation, error)
+	CreateService(service *models.Service) error
+	GetServiceByID(id uint) (*models.Service, error)
+	ListServices() ([]models.Service, error)
+	CreateIntakeForm(form *models.IntakeForm) error
+	GetLatestIntakeForm(serviceID, professionalID uint) (*models.IntakeForm, error)
+	GetIntakeFormByID(id uint) (*models.IntakeForm, error)
+}
+
+type ResourceRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewResourceRepository(db *gorm.DB) ResourceRepository {
+	return &ResourceRepositoryImpl{DB: db}
+}
+
+func (r *ResourceRepositoryImpl) CreateResource(resource *models.Resource) error {
+	return r.DB.Create(resource).Error
+}
+
+func (r *ResourceRepositoryImpl) ListResources(resourceType string) ([]models.Resource, error) {
+	var resources []models.Resource
+	query := r.DB.Model(&models.Resource{})
+	if resourceType != "" {
+		query = query.Where("type = ?", resourceType)
+	}
+	err := query.Find(&resources).Error
+	return resources, err
+}
+
+func (r *ResourceRepositoryImpl) GetResourcesByIDs(ids []uint) ([]models.Resource, error) {
+	var resources []models.Resource
+	err := r.DB.Where("id IN ?", ids).Find(&resources).Error
+	return resources, err
+}
+
+func (r *ResourceRepositoryImpl) CreateReservation(reservation *models.ResourceReservation) error {
+	return r.DB.Create(reservation).Error
+}
+
+// ListReservations returns the reservations of the given resources that overlap [from, to).
+func (r *ResourceRepositoryImpl) ListReservations(resourceIDs []uint, from, to time.Time) ([]models.ResourceReservation, error) {
+	var reservations []models.ResourceReservation
+	err := r.DB.Where("resource_id IN ? AND start_time < ? AND end_time > ?", resourceIDs, to, from).
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *ResourceRepositoryImpl) CreateService(service *models.Service) error {
+	return r.DB.Create(service).Error
+}
+
+func (r *ResourceRepositoryImpl) GetServiceByID(id uint) (*models.Service, error) {
+	var service models.Service
+	err := r.DB.Preload("Resources").First(&service, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+func (r *ResourceRepositoryImpl) ListServices() ([]models.Service, error) {
+	var services []models.Service
+	err := r.DB.Preload("Resources").Find(&services).Error
+	return services, err
+}
+
+// CreateIntakeForm saves the form as the next version of the service's or
+// professional's form. The unique index on the version makes a concurrent
+// save fail instead of publishing the same version twice.
+func (r *ResourceRepositoryImpl) CreateIntakeForm(form *models.IntakeForm) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var latest uint
+		err := tx.Model(&models.IntakeForm{}).
+			Where("service_id = ? AND professional_id = ?", form.ServiceID, form.ProfessionalID).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		form.Version = latest + 1
+		return tx.Create(form).Error
+	})
+}
+
+// GetLatestIntakeForm returns the current version of the form of a service,
+// or of a professional when serviceID is 0.
+func (r *ResourceRepositoryImpl) GetLatestIntakeForm(serviceID, professionalID uint) (*models.IntakeForm, error) {
+	var form models.IntakeForm
+	err := r.DB.Where("service_id = ? AND professional_id = ?", serviceID, professionalID).
+		Order("version DESC").
+		First(&form).Error
+	if err != nil {
+		return nil, err
+	}
+	return &form, nil
+}
+
+func (r *ResourceRepositoryImpl) GetIntakeFormByID(id uint) (*models.IntakeForm, error) {
+	var form models.IntakeForm
+	err := r.DB.First(&form, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &form, nil
+}
@@ -1,33 +1,74 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/meetings"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/payments"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 type AgendaService interface {
-	CreateSlot(req *pb.CreateSlotRequest) (*pb.CreateSlotResponse, error)
+	CreateSlot(ctx context.Context, req *pb.CreateSlotRequest) (*pb.CreateSlotResponse, error)
 	ListAvailableSlots(req *pb.ListAvailableSlotsRequest) (*pb.ListAvailableSlotsResponse, error)
-	BookAppointment(req *pb.BookAppointmentRequest) (*pb.BookAppointmentResponse, error)
-	ListAppointments(req *pb.ListAppointmentsRequest) (*pb.ListAppointmentsResponse, error)
+	BookAppointment(ctx context.Context, req *pb.BookAppointmentRequest) (*pb.BookAppointmentResponse, error)
+	ListAppointments(ctx context.Context, req *pb.ListAppointmentsRequest) (*pb.ListAppointmentsResponse, error)
+	GetAppointment(ctx context.Context, req *pb.GetAppointmentRequest) (*pb.GetAppointmentResponse, error)
+	CompleteAppointment(ctx context.Context, req *pb.CompleteAppointmentRequest) (*pb.CompleteAppointmentResponse, error)
+	CancelAppointment(ctx context.Context, req *pb.CancelAppointmentRequest) (*pb.CancelAppointmentResponse, error)
+	ApproveAppointment(ctx context.Context, req *pb.ApproveAppointmentRequest) (*pb.ApproveAppointmentResponse, error)
+	DeclineAppointment(ctx context.Context, req *pb.DeclineAppointmentRequest) (*pb.DeclineAppointmentResponse, error)
+	ConfirmAppointment(ctx context.Context, req *pb.ConfirmAppointmentRequest) (*pb.ConfirmAppointmentResponse, error)
+	RescheduleAppointment(ctx context.Context, req *pb.RescheduleAppointmentRequest) (*pb.RescheduleAppointmentResponse, error)
+	ReassignAppointments(ctx context.Context, req *pb.ReassignAppointmentsRequest) (*pb.ReassignAppointmentsResponse, error)
+	GetIntakeAnswers(ctx context.Context, req *pb.GetIntakeAnswersRequest) (*pb.GetIntakeAnswersResponse, error)
+	ExpireApprovalRequests(now time.Time) error
+	SendReviewRequests(endedBefore time.Time) error
+	SendDailyDigests(now time.Time) error
 }
 
 type AgendaServiceImpl struct {
-	Repo        repositories.AgendaRepository
-	NotifClient pb.NotificationServiceClient
+	Repo             repositories.AgendaRepository
+	ResourceRepo     repositories.ResourceRepository
+	AvailabilityRepo repositories.AvailabilityRepository
+	PaymentProvider  payments.PaymentProvider
+	Meetings         meetings.MeetingProvider
+	Policy           BookingPolicy
+	NotifClient      pb.NotificationServiceClient
+	ProfClient       pb.ProfessionalServiceClient
+	LocationClient   pb.LocationServiceClient
+	ClientClient     pb.ClientServiceClient
 }
 
-func NewAgendaService(repo repositories.AgendaRepository, notifConn *grpc.ClientConn) AgendaService {
+func NewAgendaService(repo repositories.AgendaRepository, resourceRepo repositories.ResourceRepository,
+	availabilityRepo repositories.AvailabilityRepository, provider payments.PaymentProvider,
+	meetingProvider meetings.MeetingProvider, policy BookingPolicy, notifConn, profConn, clientConn *grpc.ClientConn) AgendaService {
 	return &AgendaServiceImpl{Repo: repo,
-		NotifClient: pb.NewNotificationServiceClient(notifConn)}
+		ResourceRepo:     resourceRepo,
+		AvailabilityRepo: availabilityRepo,
+		PaymentProvider:  provider,
+		Meetings:         meetingProvider,
+		Policy:           policy,
+		NotifClient:      pb.NewNotificationServiceClient(notifConn),
+		ProfClient:       pb.NewProfessionalServiceClient(profConn),
+		LocationClient:   pb.NewLocationServiceClient(profConn),
+		ClientClient:     pb.NewClientServiceClient(clientConn)}
 }
 
-func (s *AgendaServiceImpl) CreateSlot(req *pb.CreateSlotRequest) (*pb.CreateSlotResponse, error) {
+func (s *AgendaServiceImpl) CreateSlot(ctx context.Context, req *pb.CreateSlotRequest) (*pb.CreateSlotResponse, error) {
+	if !callerOwns(ctx, 0, uint(req.ProfessionalId)) {
+		return &pb.CreateSlotResponse{Message: "Not allowed", Success: false}, nil
+	}
 	startTime, err := time.Parse(time.RFC3339, req.StartTime)
 	if err != nil {
 		return &pb.CreateSlotResponse{Message: "start_time invalid format", Success: false}, err
@@ -37,11 +78,18 @@ func (s *AgendaServiceImpl) CreateSlot(req *pb.CreateSlotRequest) (*pb.CreateSlo
 		return &pb.CreateSlotResponse{Message: "end_time invalid format", Success: false}, err
 	}
 
+	if req.LocationId != 0 {
+		if msg, err := s.checkSlotLocation(req.ProfessionalId, req.LocationId, startTime, endTime); msg != "" {
+			return &pb.CreateSlotResponse{Message: msg, Success: false}, err
+		}
+	}
+
 	slot := &models.Slot{
 		ProfessionalID: uint(req.ProfessionalId),
 		StartTime:      startTime,
 		EndTime:        endTime,
 		Available:      true,
+		LocationID:     uint(req.LocationId),
 	}
 	if err := s.Repo.CreateSlot(slot); err != nil {
 		return &pb.CreateSlotResponse{Message: "Error creating slot", Success: false}, err
@@ -55,15 +103,79 @@ func (s *AgendaServiceImpl) CreateSlot(req *pb.CreateSlotRequest) (*pb.CreateSlo
 }
 
 func (s *AgendaServiceImpl) ListAvailableSlots(req *pb.ListAvailableSlotsRequest) (*pb.ListAvailableSlotsResponse, error) {
-	date, err := time.Parse("2006-01-02", req.Date)
-	if err != nil {
-		return &pb.ListAvailableSlotsResponse{Success: false}, err
+	if req.ProfessionalId == 0 && req.LocationId == 0 {
+		return &pb.ListAvailableSlotsResponse{Success: false}, nil
 	}
 
-	slots, err := s.Repo.ListAvailableSlots(uint(req.ProfessionalId), date)
+	// Sin sucursal el día se interpreta en UTC, con sucursal en su zona horaria
+	tz := time.UTC
+	if req.LocationId != 0 {
+		locResp, err := s.LocationClient.GetLocation(context.Background(), &pb.GetLocationRequest{Id: req.LocationId})
+		if err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+		if tz, err = time.LoadLocation(locResp.Location.Timezone); err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", req.Date, tz)
 	if err != nil {
 		return &pb.ListAvailableSlotsResponse{Success: false}, err
 	}
+	from, to := date, date.AddDate(0, 0, 1)
+
+	var service *models.Service
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if req.ServiceId != 0 {
+		if service, err = s.ResourceRepo.GetServiceByID(uint(req.ServiceId)); err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+		duration = time.Duration(service.DurationMinutes) * time.Minute
+	}
+
+	var slots []models.Slot
+	if req.ProfessionalId != 0 {
+		settings, err := s.AvailabilityRepo.GetSettings(uint(req.ProfessionalId))
+		if err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+		if settings.Computed() {
+			slots, err = s.computedSlots(settings, from, to, duration, uint(req.LocationId), 0)
+		} else {
+			slots, err = s.Repo.ListAvailableSlots(uint(req.ProfessionalId), uint(req.LocationId), from, to)
+		}
+		if err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+	} else {
+		// En una sucursal se mezclan los slots guardados con los calculados
+		if slots, err = s.Repo.ListAvailableSlots(0, uint(req.LocationId), from, to); err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+		professionalIDs, err := s.AvailabilityRepo.ListComputedProfessionals(uint(req.LocationId))
+		if err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+		for _, professionalID := range professionalIDs {
+			settings, err := s.AvailabilityRepo.GetSettings(professionalID)
+			if err != nil {
+				return &pb.ListAvailableSlotsResponse{Success: false}, err
+			}
+			computed, err := s.computedSlots(settings, from, to, duration, uint(req.LocationId), 0)
+			if err != nil {
+				return &pb.ListAvailableSlotsResponse{Success: false}, err
+			}
+			slots = append(slots, computed...)
+		}
+	}
+
+	if service != nil {
+		slots, err = s.filterByServiceResources(slots, service)
+		if err != nil {
+			return &pb.ListAvailableSlotsResponse{Success: false}, err
+		}
+	}
 
 	pbSlots := make([]*pb.Slot, len(slots))
 	for i, slot := range slots {
@@ -73,6 +185,7 @@ func (s *AgendaServiceImpl) ListAvailableSlots(req *pb.ListAvailableSlotsRequest
 			StartTime:      slot.StartTime.Format(time.RFC3339),
 			EndTime:        slot.EndTime.Format(time.RFC3339),
 			Available:      slot.Available,
+			LocationId:     uint32(slot.LocationID),
 		}
 	}
 
@@ -82,43 +195,164 @@ func (s *AgendaServiceImpl) ListAvailableSlots(req *pb.ListAvailableSlotsRequest
 	}, nil
 }
 
-func (s *AgendaServiceImpl) BookAppointment(req *pb.BookAppointmentRequest) (*pb.BookAppointmentResponse, error) {
-	// Verificar si el slot está disponible usando GetSlotByID
-	slot, err := s.Repo.GetSlotByID(uint(req.SlotId))
-	if err != nil {
-		return &pb.BookAppointmentResponse{Message: "Slot not found", Success: false}, err
+func (s *AgendaServiceImpl) BookAppointment(ctx context.Context, req *pb.BookAppointmentRequest) (*pb.BookAppointmentResponse, error) {
+	if !callerOwns(ctx, uint(req.ClientId), 0) {
+		return &pb.BookAppointmentResponse{Message: "Not allowed", Success: false}, nil
 	}
-	if !slot.Available {
-		return &pb.BookAppointmentResponse{Message: "This slot is not available", Success: false}, nil
+	if msg, err := s.checkDependent(req.ClientId, req.DependentId); msg != "" {
+		return &pb.BookAppointmentResponse{Message: msg, Success: false}, err
+	}
+
+	var service *models.Service
+	var resourceIDs []uint
+	if req.ServiceId != 0 {
+		var err error
+		service, err = s.ResourceRepo.GetServiceByID(uint(req.ServiceId))
+		if err != nil {
+			return &pb.BookAppointmentResponse{Message: "Service not found", Success: false}, err
+		}
+		resourceIDs = service.ResourceIDs()
+	}
+
+	// Se busca el slot antes de reservar para conocer la configuración del profesional
+	var slot *models.Slot
+	var settings *models.ProfessionalSettings
+	var err error
+	computed := req.SlotId == 0 && req.ProfessionalId != 0
+	if computed {
+		if settings, err = s.AvailabilityRepo.GetSettings(uint(req.ProfessionalId)); err != nil {
+			return &pb.BookAppointmentResponse{Message: "Error generating appointment", Success: false}, err
+		}
+		// Los profesionales en modo calculado se reservan por hora de inicio
+		duration := time.Duration(req.DurationMinutes) * time.Minute
+		if service != nil && service.DurationMinutes != 0 {
+			duration = time.Duration(service.DurationMinutes) * time.Minute
+		}
+		var msg string
+		if slot, msg, err = s.computedSlotAt(settings, req.StartTime, duration, 0); msg != "" {
+			return &pb.BookAppointmentResponse{Message: msg, Success: false}, err
+		}
+	} else {
+		if slot, err = s.Repo.GetSlotByID(uint(req.SlotId)); err != nil {
+			return &pb.BookAppointmentResponse{Message: "Slot not found", Success: false}, err
+		}
+		if settings, err = s.AvailabilityRepo.GetSettings(slot.ProfessionalID); err != nil {
+			return &pb.BookAppointmentResponse{Message: "Error generating appointment", Success: false}, err
+		}
+	}
+
+	// Las respuestas se validan contra la versión vigente del formulario
+	intake, msg, err := s.intakeResponse(uint(req.ServiceId), settings.ProfessionalID, req.IntakeAnswers)
+	if msg != "" {
+		return &pb.BookAppointmentResponse{Message: msg, Success: false}, err
+	}
+
+	modality := req.Modality
+	if modality == "" && service != nil {
+		modality = service.Modality
+	}
+	if modality == "" {
+		modality = models.ModalityInPerson
+	}
+	if modality != models.ModalityInPerson && modality != models.ModalityRemote {
+		return &pb.BookAppointmentResponse{Message: "modality must be 'in_person' or 'remote'", Success: false}, nil
+	}
+	useCredits := req.UseCredits || (service != nil && service.RequiresCredits)
+	if useCredits && service == nil {
+		return &pb.BookAppointmentResponse{Message: "Credits can only be used to book a service", Success: false}, nil
 	}
 
 	appointment := &models.Appointment{
-		ClientID:       uint(req.ClientId),
-		SlotID:         uint(req.SlotId),
-		ProfessionalID: slot.ProfessionalID,
+		ClientID:    uint(req.ClientId),
+		DependentID: uint(req.DependentId),
+		SlotID:      uint(req.SlotId),
+		ServiceID:   uint(req.ServiceId),
+		Status:      models.AppointmentBooked,
+		Modality:    modality,
+		Intake:      intake,
+	}
+	if settings.RequiresApproval {
+		deadline := s.Policy.ApprovalDeadline(slot.StartTime, time.Now())
+		appointment.ApprovalDeadline = &deadline
+		appointment.Status = models.AppointmentPendingApproval
+	}
+	var intent *payments.Intent
+	if useCredits {
+		// El crédito se descuenta del paquete en la misma transacción de la reserva
+		appointment.Credit = &models.CreditTransaction{}
+	} else if service != nil && service.RequiresPayment() {
+		// Si la reserva falla el intent queda sin pagar y el proveedor lo descarta
+		intent, err = s.PaymentProvider.CreateIntent(service.DepositCents, service.Currency,
+			fmt.Sprintf("service %d for client %d", service.ID, req.ClientId))
+		if err != nil {
+			return &pb.BookAppointmentResponse{Message: "Error creating payment", Success: false}, err
+		}
+		// La aprobación, si corresponde, se pide una vez capturado el pago
+		appointment.Status = models.AppointmentPaymentPending
+		appointment.Payment = &models.Payment{
+			Provider:    s.PaymentProvider.Name(),
+			IntentID:    intent.ID,
+			AmountCents: intent.AmountCents,
+			Currency:    intent.Currency,
+			Status:      models.PaymentPending,
+		}
 	}
-	if err := s.Repo.CreateAppointment(appointment); err != nil {
+	if modality == models.ModalityRemote {
+		room, err := s.Meetings.CreateRoom(fmt.Sprintf("client %d with professional %d", req.ClientId, settings.ProfessionalID))
+		if err != nil {
+			return &pb.BookAppointmentResponse{Message: "Error creating meeting", Success: false}, err
+		}
+		appointment.MeetingID, appointment.MeetingURL = room.ID, room.URL
+	}
+	// El slot y los recursos del servicio se reservan en una sola transacción
+	if computed {
+		err = s.Repo.BookComputedSlot(appointment, slot, resourceIDs)
+	} else {
+		slot, err = s.Repo.BookSlot(appointment, resourceIDs)
+	}
+	if err != nil && appointment.MeetingID != "" {
+		// La sala no quedó asociada a ninguna cita
+		if err := s.Meetings.RevokeRoom(appointment.MeetingID); err != nil {
+			log.Printf("Error revoking meeting %s: %v", appointment.MeetingID, err)
+		}
+	}
+	switch {
+	case errors.Is(err, repositories.ErrSlotNotAvailable):
+		return &pb.BookAppointmentResponse{Message: "This slot is not available", Success: false}, nil
+	case errors.Is(err, repositories.ErrResourceNotAvailable):
+		return &pb.BookAppointmentResponse{Message: "A required resource is not available", Success: false}, nil
+	case errors.Is(err, repositories.ErrNoCredits):
+		return &pb.BookAppointmentResponse{Message: "No credits left for this service", Success: false}, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &pb.BookAppointmentResponse{Message: "Slot not found", Success: false}, err
+	case err != nil:
 		return &pb.BookAppointmentResponse{Message: "Error generating appointment", Success: false}, err
 	}
 
-	// Marcar el slot como no disponible
-	if err := s.Repo.UpdateSlotAvailability(uint(req.SlotId), false); err != nil {
-		return &pb.BookAppointmentResponse{Message: "Error updating slot", Success: false}, err
+	if intent != nil {
+		// La cita se confirma y notifica cuando se captura el pago
+		return &pb.BookAppointmentResponse{
+			Message:             "Appointment pending payment",
+			Success:             true,
+			AppointmentId:       uint32(appointment.ID),
+			PaymentIntentId:     intent.ID,
+			PaymentClientSecret: intent.ClientSecret,
+			AmountCents:         uint32(intent.AmountCents),
+			Currency:            intent.Currency,
+		}, nil
 	}
 
-	r, err := s.NotifClient.SendAppointmentNotification(nil, &pb.SendAppointmentNotificationRequest{
-		ClientId:       req.ClientId,
-		ProfessionalId: uint32(slot.ProfessionalID),
-		AppointmentId:  uint32(appointment.ID),
-		StartTime:      slot.StartTime.Format(time.RFC3339),
-		EndTime:        slot.EndTime.Format(time.RFC3339),
-	})
-	if err != nil {
-		log.Printf("Error sending notification: %v", err)
-	} else {
-		log.Println(r)
+	if appointment.Status == models.AppointmentPendingApproval {
+		sendAppointmentUpdate(s.NotifClient, appointment, slot, "requested", "")
+		return &pb.BookAppointmentResponse{
+			Message:       "Appointment request sent, waiting for approval",
+			Success:       true,
+			AppointmentId: uint32(appointment.ID),
+		}, nil
 	}
 
+	sendBookingNotification(s.NotifClient, appointment, slot)
+
 	return &pb.BookAppointmentResponse{
 		Message:       "Appointment successfully generated",
 		Success:       true,
@@ -126,26 +360,23 @@ func (s *AgendaServiceImpl) BookAppointment(req *pb.BookAppointmentRequest) (*pb
 	}, nil
 }
 
-func (s *AgendaServiceImpl) ListAppointments(req *pb.ListAppointmentsRequest) (*pb.ListAppointmentsResponse, error) {
+func (s *AgendaServiceImpl) ListAppointments(ctx context.Context, req *pb.ListAppointmentsRequest) (*pb.ListAppointmentsResponse, error) {
+	// Los filtros se combinan, basta con que uno sea del que llama
+	if !callerOwns(ctx, uint(req.ClientId), uint(req.ProfessionalId)) {
+		return &pb.ListAppointmentsResponse{Success: false}, rbac.ErrNotAllowed
+	}
 	appointments, err := s.Repo.ListAppointments(uint(req.ClientId), uint(req.ProfessionalId))
 	if err != nil {
 		return &pb.ListAppointmentsResponse{Success: false}, err
 	}
 
 	pbAppointments := make([]*pb.Appointment, len(appointments))
-	for i, appt := range appointments {
-		slot, err := s.Repo.GetSlotByID(appt.SlotID)
+	for i := range appointments {
+		slot, err := s.Repo.GetSlotByID(appointments[i].SlotID)
 		if err != nil {
 			return &pb.ListAppointmentsResponse{Success: false}, err
 		}
-		pbAppointments[i] = &pb.Appointment{
-			Id:             uint32(appt.ID),
-			ClientId:       uint32(appt.ClientID),
-			SlotId:         uint32(appt.SlotID),
-			StartTime:      slot.StartTime.Format(time.RFC3339),
-			EndTime:        slot.EndTime.Format(time.RFC3339),
-			ProfessionalId: uint32(appt.ProfessionalID),
-		}
+		pbAppointments[i] = toPbAppointment(&appointments[i], slot)
 	}
 
 	return &pb.ListAppointmentsResponse{
@@ -153,3 +384,984 @@ func (s *AgendaServiceImpl) ListAppointments(req *pb.ListAppointmentsRequest) (*
 		Success:      true,
 	}, nil
 }
+
+func (s *AgendaServiceImpl) GetAppointment(ctx context.Context, req *pb.GetAppointmentRequest) (*pb.GetAppointmentResponse, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(req.Id))
+	if err != nil {
+		return &pb.GetAppointmentResponse{Success: false}, err
+	}
+	if !callerOwns(ctx, appointment.ClientID, appointment.ProfessionalID) {
+		return &pb.GetAppointmentResponse{Success: false}, rbac.ErrNotAllowed
+	}
+	slot, err := s.Repo.GetSlotByID(appointment.SlotID)
+	if err != nil {
+		return &pb.GetAppointmentResponse{Success: false}, err
+	}
+
+	return &pb.GetAppointmentResponse{
+		Appointment: toPbAppointment(appointment, slot),
+		Success:     true,
+	}, nil
+}
+
+func (s *AgendaServiceImpl) CompleteAppointment(ctx context.Context, req *pb.CompleteAppointmentRequest) (*pb.CompleteAppointmentResponse, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.CompleteAppointmentResponse{Message: "Appointment not found", Success: false}, err
+	}
+	if !callerOwns(ctx, 0, appointment.ProfessionalID) {
+		return &pb.CompleteAppointmentResponse{Message: "Not allowed", Success: false}, nil
+	}
+	if appointment.Status != models.AppointmentBooked {
+		return &pb.CompleteAppointmentResponse{Message: "Only booked appointments can be completed", Success: false}, nil
+	}
+
+	if err := s.Repo.UpdateAppointmentStatus(appointment.ID, models.AppointmentCompleted); err != nil {
+		return &pb.CompleteAppointmentResponse{Message: "Error completing appointment", Success: false}, err
+	}
+
+	return &pb.CompleteAppointmentResponse{Message: "Appointment completed", Success: true}, nil
+}
+
+// CancelAppointment frees the slot of a booked or payment pending appointment.
+// A captured payment or the package credit used is refunded when the
+// cancellation policy allows it.
+func (s *AgendaServiceImpl) CancelAppointment(ctx context.Context, req *pb.CancelAppointmentRequest) (*pb.CancelAppointmentResponse, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.CancelAppointmentResponse{Message: "Appointment not found", Success: false}, err
+	}
+	if !callerOwns(ctx, appointment.ClientID, appointment.ProfessionalID) {
+		return &pb.CancelAppointmentResponse{Message: "Not allowed", Success: false}, nil
+	}
+	slot, err := s.Repo.GetSlotByID(appointment.SlotID)
+	if err != nil {
+		return &pb.CancelAppointmentResponse{Message: "Error cancelling appointment", Success: false}, err
+	}
+	if msg, err := s.claimLink(req.LinkId, appointment.ID, models.ActionCancel); msg != "" {
+		return &pb.CancelAppointmentResponse{Message: msg, Success: false}, err
+	}
+
+	err = s.Repo.ReleaseAppointment(appointment.ID, []string{models.AppointmentBooked,
+		models.AppointmentPaymentPending, models.AppointmentPendingApproval}, models.AppointmentCancelled)
+	if err != nil {
+		s.releaseLink(req.LinkId)
+	}
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		return &pb.CancelAppointmentResponse{Message: "Only booked appointments can be cancelled", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.CancelAppointmentResponse{Message: "Error cancelling appointment", Success: false}, err
+	}
+	revokeMeeting(s.Meetings, s.Repo, appointment)
+
+	refundable := s.Policy.Refundable(slot.StartTime, time.Now())
+	refunded, err := s.settlePayment(appointment.ID, refundable)
+	if err != nil {
+		return &pb.CancelAppointmentResponse{Message: "Appointment cancelled, error refunding payment", Success: false}, err
+	}
+	credits, err := s.refundCredit(appointment, refundable)
+	if err != nil {
+		return &pb.CancelAppointmentResponse{Message: "Appointment cancelled, error refunding credit", Success: false}, err
+	}
+
+	return &pb.CancelAppointmentResponse{
+		Message:         "Appointment cancelled",
+		Success:         true,
+		RefundedCents:   uint32(refunded),
+		RefundedCredits: credits,
+	}, nil
+}
+
+func (s *AgendaServiceImpl) ApproveAppointment(ctx context.Context, req *pb.ApproveAppointmentRequest) (*pb.ApproveAppointmentResponse, error) {
+	appointment, slot, msg, err := s.pendingRequest(ctx, req.AppointmentId, req.ProfessionalId)
+	if msg != "" {
+		return &pb.ApproveAppointmentResponse{Message: msg, Success: false}, err
+	}
+
+	err = s.Repo.TransitionAppointment(appointment.ID, []string{models.AppointmentPendingApproval}, models.AppointmentBooked)
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		return &pb.ApproveAppointmentResponse{Message: "Only pending requests can be approved", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ApproveAppointmentResponse{Message: "Error approving appointment", Success: false}, err
+	}
+
+	sendAppointmentUpdate(s.NotifClient, appointment, slot, "approved", "")
+	return &pb.ApproveAppointmentResponse{Message: "Appointment approved", Success: true}, nil
+}
+
+// DeclineAppointment releases the slot of a pending request and refunds its
+// payment in full or its credit, whatever the cancellation policy says.
+func (s *AgendaServiceImpl) DeclineAppointment(ctx context.Context, req *pb.DeclineAppointmentRequest) (*pb.DeclineAppointmentResponse, error) {
+	appointment, slot, msg, err := s.pendingRequest(ctx, req.AppointmentId, req.ProfessionalId)
+	if msg != "" {
+		return &pb.DeclineAppointmentResponse{Message: msg, Success: false}, err
+	}
+
+	err = s.Repo.ReleaseAppointment(appointment.ID, []string{models.AppointmentPendingApproval}, models.AppointmentDeclined)
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		return &pb.DeclineAppointmentResponse{Message: "Only pending requests can be declined", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.DeclineAppointmentResponse{Message: "Error declining appointment", Success: false}, err
+	}
+	revokeMeeting(s.Meetings, s.Repo, appointment)
+
+	refunded, err := s.settlePayment(appointment.ID, true)
+	if err != nil {
+		return &pb.DeclineAppointmentResponse{Message: "Appointment declined, error refunding payment", Success: false}, err
+	}
+	credits, err := s.refundCredit(appointment, true)
+	if err != nil {
+		return &pb.DeclineAppointmentResponse{Message: "Appointment declined, error refunding credit", Success: false}, err
+	}
+
+	sendAppointmentUpdate(s.NotifClient, appointment, slot, "declined", req.Reason)
+	return &pb.DeclineAppointmentResponse{
+		Message:         "Appointment declined",
+		Success:         true,
+		RefundedCents:   uint32(refunded),
+		RefundedCredits: credits,
+	}, nil
+}
+
+// ExpireApprovalRequests releases the requests whose approval deadline passed,
+// refunding their payments and letting the clients know.
+func (s *AgendaServiceImpl) ExpireApprovalRequests(now time.Time) error {
+	appointments, err := s.Repo.ListExpiredApprovals(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range appointments {
+		appointment := &appointments[i]
+		err := s.Repo.ReleaseAppointment(appointment.ID, []string{models.AppointmentPendingApproval}, models.AppointmentExpired)
+		if errors.Is(err, repositories.ErrStatusChanged) {
+			// El profesional respondió entre la consulta y el bloqueo
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		revokeMeeting(s.Meetings, s.Repo, appointment)
+		if _, err := s.settlePayment(appointment.ID, true); err != nil {
+			log.Printf("Error refunding expired appointment %d: %v", appointment.ID, err)
+		}
+		if _, err := s.refundCredit(appointment, true); err != nil {
+			log.Printf("Error refunding credit of expired appointment %d: %v", appointment.ID, err)
+		}
+
+		slot, err := s.Repo.GetSlotByID(appointment.SlotID)
+		if err != nil {
+			return err
+		}
+		sendAppointmentUpdate(s.NotifClient, appointment, slot, "expired", "")
+	}
+	return nil
+}
+
+// ConfirmAppointment records that the client will attend a booked appointment
+// or a request still waiting for approval.
+func (s *AgendaServiceImpl) ConfirmAppointment(ctx context.Context, req *pb.ConfirmAppointmentRequest) (*pb.ConfirmAppointmentResponse, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.ConfirmAppointmentResponse{Message: "Appointment not found", Success: false}, err
+	}
+	if !callerOwns(ctx, appointment.ClientID, 0) {
+		return &pb.ConfirmAppointmentResponse{Message: "Not allowed", Success: false}, nil
+	}
+	if appointment.ConfirmedAt != nil {
+		return &pb.ConfirmAppointmentResponse{Message: "Appointment already confirmed", Success: true}, nil
+	}
+	if msg, err := s.claimLink(req.LinkId, appointment.ID, models.ActionConfirm); msg != "" {
+		return &pb.ConfirmAppointmentResponse{Message: msg, Success: false}, err
+	}
+
+	err = s.Repo.ConfirmAppointment(appointment.ID, []string{models.AppointmentBooked, models.AppointmentPendingApproval}, time.Now())
+	if err != nil {
+		s.releaseLink(req.LinkId)
+	}
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		return &pb.ConfirmAppointmentResponse{Message: "Only booked appointments can be confirmed", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ConfirmAppointmentResponse{Message: "Error confirming appointment", Success: false}, err
+	}
+
+	return &pb.ConfirmAppointmentResponse{Message: "Appointment confirmed", Success: true}, nil
+}
+
+// RescheduleAppointment moves a booked appointment, or a request waiting for
+// approval, to another slot of the same professional keeping its service
+// resources. Professionals in computed mode are rescheduled by start time,
+// keeping the appointment's length.
+func (s *AgendaServiceImpl) RescheduleAppointment(ctx context.Context, req *pb.RescheduleAppointmentRequest) (*pb.RescheduleAppointmentResponse, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.RescheduleAppointmentResponse{Message: "Appointment not found", Success: false}, err
+	}
+	if !callerOwns(ctx, appointment.ClientID, 0) {
+		return &pb.RescheduleAppointmentResponse{Message: "Not allowed", Success: false}, nil
+	}
+	current, err := s.Repo.GetSlotByID(appointment.SlotID)
+	if err != nil {
+		return &pb.RescheduleAppointmentResponse{Message: "Error rescheduling appointment", Success: false}, err
+	}
+	var resourceIDs []uint
+	if appointment.ServiceID != 0 {
+		service, err := s.ResourceRepo.GetServiceByID(appointment.ServiceID)
+		if err != nil {
+			return &pb.RescheduleAppointmentResponse{Message: "Error rescheduling appointment", Success: false}, err
+		}
+		resourceIDs = service.ResourceIDs()
+	}
+
+	slot := &models.Slot{ID: uint(req.SlotId)}
+	if req.SlotId == 0 {
+		settings, err := s.AvailabilityRepo.GetSettings(appointment.ProfessionalID)
+		if err != nil {
+			return &pb.RescheduleAppointmentResponse{Message: "Error rescheduling appointment", Success: false}, err
+		}
+		var msg string
+		if slot, msg, err = s.computedSlotAt(settings, req.StartTime, current.EndTime.Sub(current.StartTime), current.ID); msg != "" {
+			return &pb.RescheduleAppointmentResponse{Message: msg, Success: false}, err
+		}
+	}
+	if msg, err := s.claimLink(req.LinkId, appointment.ID, models.ActionReschedule); msg != "" {
+		return &pb.RescheduleAppointmentResponse{Message: msg, Success: false}, err
+	}
+
+	err = s.Repo.RescheduleAppointment(appointment.ID, []string{models.AppointmentBooked, models.AppointmentPendingApproval}, slot, resourceIDs)
+	if err != nil {
+		s.releaseLink(req.LinkId)
+	}
+	switch {
+	case errors.Is(err, repositories.ErrStatusChanged):
+		return &pb.RescheduleAppointmentResponse{Message: "Only booked appointments can be rescheduled", Success: false}, nil
+	case errors.Is(err, repositories.ErrSlotNotAvailable):
+		return &pb.RescheduleAppointmentResponse{Message: "This slot is not available", Success: false}, nil
+	case errors.Is(err, repositories.ErrResourceNotAvailable):
+		return &pb.RescheduleAppointmentResponse{Message: "A required resource is not available", Success: false}, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &pb.RescheduleAppointmentResponse{Message: "Slot not found", Success: false}, err
+	case err != nil:
+		return &pb.RescheduleAppointmentResponse{Message: "Error rescheduling appointment", Success: false}, err
+	}
+
+	sendAppointmentUpdate(s.NotifClient, appointment, slot, "rescheduled", "")
+	return &pb.RescheduleAppointmentResponse{
+		Message:   "Appointment rescheduled",
+		Success:   true,
+		StartTime: slot.StartTime.Format(time.RFC3339),
+		EndTime:   slot.EndTime.Format(time.RFC3339),
+	}, nil
+}
+
+// ReassignAppointments moves the source professional's active appointments
+// starting within the range to free slots of the target professional with the
+// same times, and location when they have one. Every placed appointment moves
+// in a single transaction and its client is notified; the rest stay with the
+// source professional and are reported as unplaced.
+func (s *AgendaServiceImpl) ReassignAppointments(ctx context.Context, req *pb.ReassignAppointmentsRequest) (*pb.ReassignAppointmentsResponse, error) {
+	if req.SourceProfessionalId == 0 || req.TargetProfessionalId == 0 || req.SourceProfessionalId == req.TargetProfessionalId {
+		return &pb.ReassignAppointmentsResponse{Message: "Source and target professionals must be different", Success: false}, nil
+	}
+	if !callerOwns(ctx, 0, uint(req.SourceProfessionalId)) || !callerOwns(ctx, 0, uint(req.TargetProfessionalId)) {
+		return &pb.ReassignAppointmentsResponse{Message: "Not allowed", Success: false}, nil
+	}
+	from, err := time.Parse(time.RFC3339, req.From)
+	if err != nil {
+		return &pb.ReassignAppointmentsResponse{Message: "from invalid format", Success: false}, err
+	}
+	to, err := time.Parse(time.RFC3339, req.To)
+	if err != nil {
+		return &pb.ReassignAppointmentsResponse{Message: "to invalid format", Success: false}, err
+	}
+	if !to.After(from) {
+		return &pb.ReassignAppointmentsResponse{Message: "to must be after from", Success: false}, nil
+	}
+
+	active := []string{models.AppointmentBooked, models.AppointmentPendingApproval}
+	appointments, err := s.Repo.ListProfessionalAppointments(uint(req.SourceProfessionalId), active, from, to)
+	if err != nil {
+		return &pb.ReassignAppointmentsResponse{Message: "Error reassigning appointments", Success: false}, err
+	}
+	settings, err := s.AvailabilityRepo.GetSettings(uint(req.TargetProfessionalId))
+	if err != nil {
+		return &pb.ReassignAppointmentsResponse{Message: "Error reassigning appointments", Success: false}, err
+	}
+	var free []models.Slot
+	if !settings.Computed() {
+		if free, err = s.Repo.ListAvailableSlots(settings.ProfessionalID, 0, from, to); err != nil {
+			return &pb.ReassignAppointmentsResponse{Message: "Error reassigning appointments", Success: false}, err
+		}
+	}
+
+	resp := &pb.ReassignAppointmentsResponse{Success: true}
+	var moves []repositories.AppointmentMove
+	var moved []*models.Appointment
+	var planned []models.Slot
+	for i := range appointments {
+		appointment := &appointments[i]
+		current, err := s.Repo.GetSlotByID(appointment.SlotID)
+		if err != nil {
+			return &pb.ReassignAppointmentsResponse{Message: "Error reassigning appointments", Success: false}, err
+		}
+		report := &pb.ReassignedAppointment{
+			AppointmentId: uint32(appointment.ID),
+			ClientId:      uint32(appointment.ClientID),
+			StartTime:     current.StartTime.Format(time.RFC3339),
+			EndTime:       current.EndTime.Format(time.RFC3339),
+		}
+
+		var slot *models.Slot
+		if settings.Computed() {
+			slot, err = s.equivalentComputedSlot(settings, current, planned)
+		} else {
+			slot = equivalentSlot(free, current, planned)
+		}
+		if err != nil {
+			return &pb.ReassignAppointmentsResponse{Message: "Error reassigning appointments", Success: false}, err
+		}
+		if slot == nil {
+			report.Reason = "No free slot of the target professional at that time"
+			resp.Unplaced = append(resp.Unplaced, report)
+			continue
+		}
+
+		var resourceIDs []uint
+		if appointment.ServiceID != 0 {
+			service, err := s.ResourceRepo.GetServiceByID(appointment.ServiceID)
+			if err != nil {
+				return &pb.ReassignAppointmentsResponse{Message: "Error reassigning appointments", Success: false}, err
+			}
+			resourceIDs = service.ResourceIDs()
+		}
+		planned = append(planned, *slot)
+		moves = append(moves, repositories.AppointmentMove{AppointmentID: appointment.ID, Slot: slot, ResourceIDs: resourceIDs})
+		moved = append(moved, appointment)
+		resp.Moved = append(resp.Moved, report)
+	}
+
+	if len(moves) > 0 {
+		err = s.Repo.ReassignAppointments(active, moves)
+		if errors.Is(err, repositories.ErrStatusChanged) || errors.Is(err, repositories.ErrSlotNotAvailable) ||
+			errors.Is(err, repositories.ErrResourceNotAvailable) {
+			return &pb.ReassignAppointmentsResponse{Message: "The agenda changed while reassigning, try again", Success: false}, nil
+		}
+		if err != nil {
+			return &pb.ReassignAppointmentsResponse{Message: "Error reassigning appointments", Success: false}, err
+		}
+	}
+
+	for i, move := range moves {
+		resp.Moved[i].SlotId = uint32(move.Slot.ID)
+		sendAppointmentUpdate(s.NotifClient, moved[i], move.Slot, "reassigned", "")
+	}
+	resp.Message = fmt.Sprintf("%d appointments reassigned, %d could not be placed", len(resp.Moved), len(resp.Unplaced))
+	return resp, nil
+}
+
+// GetIntakeAnswers returns the answers given to the intake form when booking,
+// labelled with the fields of the form version they answered.
+func (s *AgendaServiceImpl) GetIntakeAnswers(ctx context.Context, req *pb.GetIntakeAnswersRequest) (*pb.GetIntakeAnswersResponse, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.GetIntakeAnswersResponse{Message: "Appointment not found", Success: false}, err
+	}
+	if !callerOwns(ctx, 0, appointment.ProfessionalID) {
+		return &pb.GetIntakeAnswersResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	if appointment.ProfessionalID != uint(req.ProfessionalId) {
+		return &pb.GetIntakeAnswersResponse{Message: "Appointment belongs to another professional", Success: false}, nil
+	}
+
+	response, err := s.Repo.GetIntakeResponse(appointment.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.GetIntakeAnswersResponse{Message: "The appointment has no intake answers", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.GetIntakeAnswersResponse{Message: "Error getting intake answers", Success: false}, err
+	}
+	form, err := s.ResourceRepo.GetIntakeFormByID(response.FormID)
+	if err != nil {
+		return &pb.GetIntakeAnswersResponse{Message: "Error getting intake form", Success: false}, err
+	}
+	fields, err := form.Fields()
+	if err != nil {
+		return &pb.GetIntakeAnswersResponse{Message: "Error getting intake form", Success: false}, err
+	}
+	values, err := response.Values()
+	if err != nil {
+		return &pb.GetIntakeAnswersResponse{Message: "Error getting intake answers", Success: false}, err
+	}
+
+	answers := make([]*pb.IntakeAnswer, 0, len(values))
+	for _, field := range fields {
+		if value, ok := values[field.Key]; ok {
+			answers = append(answers, &pb.IntakeAnswer{
+				Key:   field.Key,
+				Label: field.Label,
+				Type:  field.Type,
+				Value: value,
+			})
+		}
+	}
+
+	return &pb.GetIntakeAnswersResponse{
+		Message:     "Intake answers found",
+		Success:     true,
+		FormId:      uint32(form.ID),
+		FormVersion: uint32(response.FormVersion),
+		Title:       form.Title,
+		Answers:     answers,
+	}, nil
+}
+
+// SendReviewRequests asks the clients of the completed appointments that ended
+// before the given time to review them. Each appointment is asked only once;
+// failed notifications are retried on the next run.
+func (s *AgendaServiceImpl) SendReviewRequests(endedBefore time.Time) error {
+	appointments, err := s.Repo.ListReviewRequestsDue(endedBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, appt := range appointments {
+		_, err := s.NotifClient.SendReviewRequest(context.Background(), &pb.SendReviewRequestRequest{
+			ClientId:       uint32(appt.ClientID),
+			ProfessionalId: uint32(appt.ProfessionalID),
+			AppointmentId:  uint32(appt.ID),
+		})
+		if err != nil {
+			log.Printf("Error sending review request for appointment %d: %v", appt.ID, err)
+			continue
+		}
+		if err := s.Repo.MarkReviewRequested(appt.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendDailyDigests sends each professional who didn't opt out the digest of
+// their day once its local send time has passed. Every professional is tried
+// even when another one fails.
+func (s *AgendaServiceImpl) SendDailyDigests(now time.Time) error {
+	profResp, err := s.ProfClient.ListProfessionals(context.Background(), &pb.ListProfessionalsRequest{})
+	if err != nil {
+		return err
+	}
+
+	for _, professional := range profResp.Professionals {
+		if err := s.sendDailyDigest(uint(professional.Id), now); err != nil {
+			log.Printf("Error sending daily digest to professional %d: %v", professional.Id, err)
+		}
+	}
+	return nil
+}
+
+// sendDailyDigest sends the professional the appointments of their local day
+// and the cancellations since the previous digest, if it's due.
+func (s *AgendaServiceImpl) sendDailyDigest(professionalID uint, now time.Time) error {
+	digest, err := s.AvailabilityRepo.GetDigestSettings(professionalID)
+	if err != nil || digest.OptOut {
+		return err
+	}
+	settings, err := s.AvailabilityRepo.GetSettings(professionalID)
+	if err != nil {
+		return err
+	}
+	tz := settings.TimeLocation()
+	local := now.In(tz)
+	date := local.Format("2006-01-02")
+	if digest.LastSentDate == date || local.Format("15:04") < digest.SendTime {
+		return nil
+	}
+
+	// Se reclama antes de enviar para que un reinicio no lo mande dos veces
+	previous, err := s.AvailabilityRepo.ClaimDigest(professionalID, date, now)
+	if errors.Is(err, repositories.ErrDigestSent) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	since := now.Add(-24 * time.Hour)
+	if previous != nil {
+		since = *previous
+	}
+
+	req, err := s.digestRequest(professionalID, settings.Timezone, local, since)
+	if err == nil {
+		_, err = s.NotifClient.SendDailyDigest(context.Background(), req)
+	}
+	if err != nil {
+		if unclaimErr := s.AvailabilityRepo.UnclaimDigest(professionalID, digest.LastSentDate, previous); unclaimErr != nil {
+			log.Printf("Error unclaiming daily digest of professional %d: %v", professionalID, unclaimErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *AgendaServiceImpl) digestRequest(professionalID uint, timezone string, local, since time.Time) (*pb.SendDailyDigestRequest, error) {
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	appointments, err := s.Repo.ListProfessionalAppointments(professionalID,
+		[]string{models.AppointmentBooked, models.AppointmentPendingApproval}, dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	cancellations, err := s.Repo.ListCancellations(professionalID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pb.SendDailyDigestRequest{
+		ProfessionalId: uint32(professionalID),
+		Date:           local.Format("2006-01-02"),
+		Timezone:       timezone,
+	}
+	if req.Appointments, err = s.digestEntries(appointments); err != nil {
+		return nil, err
+	}
+	if req.Cancellations, err = s.digestEntries(cancellations); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *AgendaServiceImpl) digestEntries(appointments []models.Appointment) ([]*pb.DigestEntry, error) {
+	entries := make([]*pb.DigestEntry, len(appointments))
+	for i, appt := range appointments {
+		slot, err := s.Repo.GetSlotByID(appt.SlotID)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = &pb.DigestEntry{
+			AppointmentId: uint32(appt.ID),
+			ClientId:      uint32(appt.ClientID),
+			StartTime:     slot.StartTime.Format(time.RFC3339),
+			EndTime:       slot.EndTime.Format(time.RFC3339),
+			Status:        appt.Status,
+			LocationId:    uint32(appt.LocationID),
+			MeetingUrl:    appt.MeetingURL,
+			DependentId:   uint32(appt.DependentID),
+		}
+	}
+	return entries, nil
+}
+
+// equivalentSlot returns the free slot with the same times as the current one,
+// and the same location when it has one, that isn't planned for another
+// appointment yet.
+func equivalentSlot(free []models.Slot, current *models.Slot, planned []models.Slot) *models.Slot {
+	for i := range free {
+		slot := &free[i]
+		if !slot.StartTime.Equal(current.StartTime) || !slot.EndTime.Equal(current.EndTime) ||
+			(current.LocationID != 0 && slot.LocationID != current.LocationID) {
+			continue
+		}
+		taken := false
+		for _, other := range planned {
+			if other.ID == slot.ID {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return slot
+		}
+	}
+	return nil
+}
+
+// equivalentComputedSlot returns the computed interval of a professional in
+// computed mode with the same times and location as the current slot, unless
+// it overlaps one already planned for another appointment.
+func (s *AgendaServiceImpl) equivalentComputedSlot(settings *models.ProfessionalSettings, current *models.Slot, planned []models.Slot) (*models.Slot, error) {
+	duration := current.EndTime.Sub(current.StartTime)
+	slots, err := s.computedSlots(settings, current.StartTime, current.StartTime.Add(time.Minute), duration, current.LocationID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range slots {
+		if !slots[i].StartTime.Equal(current.StartTime) {
+			continue
+		}
+		for _, other := range planned {
+			if other.StartTime.Before(slots[i].EndTime.Add(settings.Buffer())) && other.EndTime.Add(settings.Buffer()).After(slots[i].StartTime) {
+				return nil, nil
+			}
+		}
+		return &slots[i], nil
+	}
+	return nil, nil
+}
+
+// intakeResponse validates the answers against the intake form of the service
+// or professional and returns what to store with the appointment, nil when no
+// form applies. It returns a message when the answers are rejected.
+func (s *AgendaServiceImpl) intakeResponse(serviceID, professionalID uint, answers map[string]string) (*models.IntakeResponse, string, error) {
+	form, err := intakeFormFor(s.ResourceRepo, serviceID, professionalID)
+	if err != nil {
+		return nil, "Error generating appointment", err
+	}
+	if form == nil {
+		if len(answers) > 0 {
+			return nil, "There is no intake form to answer", nil
+		}
+		return nil, "", nil
+	}
+
+	fields, err := form.Fields()
+	if err != nil {
+		return nil, "Error generating appointment", err
+	}
+	values, err := models.ValidateIntakeAnswers(fields, answers)
+	if err != nil {
+		return nil, "Invalid intake answers: " + err.Error(), nil
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, "Error generating appointment", err
+	}
+	return &models.IntakeResponse{
+		FormID:      form.ID,
+		FormVersion: form.Version,
+		Answers:     string(encoded),
+	}, "", nil
+}
+
+// claimLink records the use of the self-service link the request came with, if
+// any. It returns a message when the link was already used.
+func (s *AgendaServiceImpl) claimLink(linkID string, appointmentID uint, action string) (string, error) {
+	if linkID == "" {
+		return "", nil
+	}
+	err := s.Repo.UseActionLink(&models.ActionLink{ID: linkID, AppointmentID: appointmentID, Action: action, UsedAt: time.Now()})
+	if errors.Is(err, repositories.ErrLinkUsed) {
+		return "This link was already used", nil
+	}
+	if err != nil {
+		return "Error checking link", err
+	}
+	return "", nil
+}
+
+// releaseLink lets the link be used again when its action didn't go through.
+func (s *AgendaServiceImpl) releaseLink(linkID string) {
+	if linkID == "" {
+		return
+	}
+	if err := s.Repo.ReleaseActionLink(linkID); err != nil {
+		log.Printf("Error releasing action link %s: %v", linkID, err)
+	}
+}
+
+// pendingRequest loads a request awaiting the given professional's approval,
+// who must be the caller unless it acts for anyone. It returns a message when
+// the request can't be answered.
+func (s *AgendaServiceImpl) pendingRequest(ctx context.Context, appointmentID, professionalID uint32) (*models.Appointment, *models.Slot, string, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(appointmentID))
+	if err != nil {
+		return nil, nil, "Appointment not found", err
+	}
+	if !callerOwns(ctx, 0, appointment.ProfessionalID) {
+		return nil, nil, "Not allowed", nil
+	}
+	if appointment.ProfessionalID != uint(professionalID) {
+		return nil, nil, "Appointment belongs to another professional", nil
+	}
+	if appointment.Status != models.AppointmentPendingApproval {
+		return nil, nil, "Appointment is not pending approval", nil
+	}
+	slot, err := s.Repo.GetSlotByID(appointment.SlotID)
+	if err != nil {
+		return nil, nil, "Error getting slot", err
+	}
+	return appointment, slot, "", nil
+}
+
+// settlePayment closes the payment of a released appointment: a pending one is
+// cancelled and a captured one is refunded when refund is set. It returns the
+// refunded amount.
+func (s *AgendaServiceImpl) settlePayment(appointmentID uint, refund bool) (uint, error) {
+	payment, err := s.Repo.GetPaymentByAppointment(appointmentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case payment.Status == models.PaymentPending:
+		return 0, s.Repo.UpdatePaymentStatus(payment.ID, models.PaymentCancelled)
+	case payment.Status == models.PaymentCaptured && refund:
+		if err := s.PaymentProvider.Refund(payment.IntentID, payment.AmountCents); err != nil {
+			return 0, err
+		}
+		return payment.AmountCents, s.Repo.UpdatePaymentStatus(payment.ID, models.PaymentRefunded)
+	}
+	return 0, nil
+}
+
+// checkDependent verifies that the dependent, if any, belongs to the client
+// booking for them. It returns a message when it doesn't.
+func (s *AgendaServiceImpl) checkDependent(clientID, dependentID uint32) (string, error) {
+	if dependentID == 0 {
+		return "", nil
+	}
+	resp, err := s.ClientClient.GetDependent(context.Background(), &pb.GetDependentRequest{Id: dependentID})
+	if err != nil {
+		return "Dependent not found", err
+	}
+	// Un cliente solo puede reservar para los miembros de su grupo familiar
+	if resp.Dependent.ClientId != clientID {
+		return "Dependent belongs to another client", nil
+	}
+	return "", nil
+}
+
+// refundCredit gives back the package credit of a released appointment when
+// refund is set. It returns the number of credits refunded.
+func (s *AgendaServiceImpl) refundCredit(appointment *models.Appointment, refund bool) (uint32, error) {
+	// Solo las citas de un servicio se pagan con créditos
+	if !refund || appointment.ServiceID == 0 {
+		return 0, nil
+	}
+	refunded, err := s.Repo.RefundCredit(appointment.ID)
+	if err != nil || !refunded {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func sendBookingNotification(client pb.NotificationServiceClient, appointment *models.Appointment, slot *models.Slot) {
+	r, err := client.SendAppointmentNotification(context.Background(), &pb.SendAppointmentNotificationRequest{
+		ClientId:       uint32(appointment.ClientID),
+		ProfessionalId: uint32(slot.ProfessionalID),
+		AppointmentId:  uint32(appointment.ID),
+		StartTime:      slot.StartTime.Format(time.RFC3339),
+		EndTime:        slot.EndTime.Format(time.RFC3339),
+		LocationId:     uint32(slot.LocationID),
+		MeetingUrl:     appointment.MeetingURL,
+		DependentId:    uint32(appointment.DependentID),
+	})
+	if err != nil {
+		log.Printf("Error sending notification: %v", err)
+	} else {
+		log.Println(r)
+	}
+}
+
+// sendAppointmentUpdate tells the client how their booking request evolved.
+// Failures are only logged, the change was already applied.
+func sendAppointmentUpdate(client pb.NotificationServiceClient, appointment *models.Appointment, slot *models.Slot, event, reason string) {
+	_, err := client.SendAppointmentUpdate(context.Background(), &pb.SendAppointmentUpdateRequest{
+		ClientId:       uint32(appointment.ClientID),
+		ProfessionalId: uint32(slot.ProfessionalID),
+		AppointmentId:  uint32(appointment.ID),
+		Event:          event,
+		StartTime:      slot.StartTime.Format(time.RFC3339),
+		EndTime:        slot.EndTime.Format(time.RFC3339),
+		LocationId:     uint32(slot.LocationID),
+		Reason:         reason,
+		MeetingUrl:     appointment.MeetingURL,
+		DependentId:    uint32(appointment.DependentID),
+	})
+	if err != nil {
+		log.Printf("Error sending %s update for appointment %d: %v", event, appointment.ID, err)
+	}
+}
+
+func toPbAppointment(appt *models.Appointment, slot *models.Slot) *pb.Appointment {
+	return &pb.Appointment{
+		Id:               uint32(appt.ID),
+		ClientId:         uint32(appt.ClientID),
+		SlotId:           uint32(appt.SlotID),
+		StartTime:        slot.StartTime.Format(time.RFC3339),
+		EndTime:          slot.EndTime.Format(time.RFC3339),
+		ProfessionalId:   uint32(appt.ProfessionalID),
+		ServiceId:        uint32(appt.ServiceID),
+		LocationId:       uint32(appt.LocationID),
+		Status:           appt.Status,
+		ApprovalDeadline: formatOptionalTime(appt.ApprovalDeadline),
+		ConfirmedAt:      formatOptionalTime(appt.ConfirmedAt),
+		Modality:         appt.Modality,
+		MeetingUrl:       appt.MeetingURL,
+		DependentId:      uint32(appt.DependentID),
+	}
+}
+
+// revokeMeeting closes the room of a released remote appointment and drops its
+// link. Failures are only logged, the appointment was already released.
+func revokeMeeting(provider meetings.MeetingProvider, repo repositories.AgendaRepository, appointment *models.Appointment) {
+	if appointment.MeetingURL == "" {
+		return
+	}
+	if err := provider.RevokeRoom(appointment.MeetingID); err != nil {
+		log.Printf("Error revoking meeting of appointment %d: %v", appointment.ID, err)
+		return
+	}
+	if err := repo.ClearMeetingURL(appointment.ID); err != nil {
+		log.Printf("Error clearing meeting of appointment %d: %v", appointment.ID, err)
+	}
+	appointment.MeetingURL = ""
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// filterByServiceResources keeps only the slots during which every resource
+// required by the service is free.
+func (s *AgendaServiceImpl) filterByServiceResources(slots []models.Slot, service *models.Service) ([]models.Slot, error) {
+	resourceIDs := service.ResourceIDs()
+	if len(resourceIDs) == 0 || len(slots) == 0 {
+		return slots, nil
+	}
+
+	from, to := slots[0].StartTime, slots[0].EndTime
+	for _, slot := range slots[1:] {
+		if slot.StartTime.Before(from) {
+			from = slot.StartTime
+		}
+		if slot.EndTime.After(to) {
+			to = slot.EndTime
+		}
+	}
+	reservations, err := s.ResourceRepo.ListReservations(resourceIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	free := make([]models.Slot, 0, len(slots))
+	for _, slot := range slots {
+		busy := false
+		for _, reservation := range reservations {
+			if reservation.Overlaps(slot.StartTime, slot.EndTime) {
+				busy = true
+				break
+			}
+		}
+		if !busy {
+			free = append(free, slot)
+		}
+	}
+	return free, nil
+}
+
+// checkSlotLocation verifies that the professional is assigned to the location
+// on the slot's local weekday and that the slot fits in its opening hours.
+// It returns an empty message when the slot is valid.
+func (s *AgendaServiceImpl) checkSlotLocation(professionalID, locationID uint32, start, end time.Time) (string, error) {
+	locResp, err := s.LocationClient.GetLocation(context.Background(), &pb.GetLocationRequest{Id: locationID})
+	if err != nil {
+		return "Location not found", err
+	}
+	tz, err := time.LoadLocation(locResp.Location.Timezone)
+	if err != nil {
+		return "Invalid location timezone", err
+	}
+	localStart, localEnd := start.In(tz), end.In(tz)
+	weekday := uint32(localStart.Weekday())
+
+	profResp, err := s.ProfClient.GetProfessional(context.Background(), &pb.GetProfessionalRequest{Id: professionalID})
+	if err != nil {
+		return "Professional not found", err
+	}
+	assigned := false
+	for _, assignment := range profResp.Professional.Locations {
+		if assignment.LocationId == locationID && assignment.Weekday == weekday {
+			assigned = true
+			break
+		}
+	}
+	if !assigned {
+		return "Professional is not assigned to this location on that day", nil
+	}
+
+	if localStart.YearDay() == localEnd.YearDay() {
+		for _, hours := range locResp.Location.OpeningHours {
+			if hours.Weekday == weekday && localStart.Format("15:04") >= hours.Opens && localEnd.Format("15:04") <= hours.Closes {
+				return "", nil
+			}
+		}
+	}
+	return "Slot is outside the location opening hours", nil
+}
+
+// computedSlots loads what the availability engine needs for a professional in
+// computed mode and returns the bookable intervals starting within [from, to).
+// A zero duration falls back to the professional's default one. The ignored
+// slot, if any, isn't counted as taken.
+func (s *AgendaServiceImpl) computedSlots(settings *models.ProfessionalSettings, from, to time.Time, duration time.Duration, locationID, ignoredSlotID uint) ([]models.Slot, error) {
+	if duration <= 0 {
+		duration = time.Duration(settings.DefaultDurationMinutes) * time.Minute
+	}
+
+	rules, err := s.AvailabilityRepo.ListRules(settings.ProfessionalID)
+	if err != nil {
+		return nil, err
+	}
+	timeOff, err := s.AvailabilityRepo.ListTimeOff(settings.ProfessionalID, from, to.Add(duration))
+	if err != nil {
+		return nil, err
+	}
+	booked, err := s.Repo.ListBookedSlots(settings.ProfessionalID, from.Add(-settings.Buffer()), to.Add(duration+settings.Buffer()))
+	if err != nil {
+		return nil, err
+	}
+	if ignoredSlotID != 0 {
+		kept := booked[:0]
+		for _, slot := range booked {
+			if slot.ID != ignoredSlotID {
+				kept = append(kept, slot)
+			}
+		}
+		booked = kept
+	}
+
+	return computeSlots(settings, rules, timeOff, booked, from, to, time.Now(), duration, locationID), nil
+}
+
+// computedSlotAt returns the computed interval starting at the given time, not
+// counting the ignored slot as taken. It returns a message when the interval
+// can't be booked.
+func (s *AgendaServiceImpl) computedSlotAt(settings *models.ProfessionalSettings, startTime string, duration time.Duration, ignoredSlotID uint) (*models.Slot, string, error) {
+	if !settings.Computed() {
+		return nil, "Professional does not use computed availability", nil
+	}
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, "start_time invalid format", err
+	}
+
+	slots, err := s.computedSlots(settings, start, start.Add(time.Minute), duration, 0, ignoredSlotID)
+	if err != nil {
+		return nil, "Error generating appointment", err
+	}
+	for i := range slots {
+		if slots[i].StartTime.Equal(start) {
+			return &slots[i], "", nil
+		}
+	}
+	return nil, "This slot is not available", nil
+}
+
+// callerOwns reports whether the caller can act on the records of the client
+// or the professional, 0 being none. Calls without claims come from the
+// service's own jobs, the interceptor already turned away anonymous callers.
+func callerOwns(ctx context.Context, clientID, professionalID uint) bool {
+	claims := rbac.FromContext(ctx)
+	if claims == nil {
+		return true
+	}
+	return claims.OwnsClient(clientID) || claims.OwnsProfessional(professionalID)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+)
+
+// computeSlots cuts the working-hour rules of a professional in computed mode
+// into bookable intervals of the given duration starting within [from, to).
+// Intervals starting before now, overlapping time off or closer to a taken
+// slot than the buffer are skipped. Rules are restricted to locationID when
+// it's set. The resulting slots have no ID since they aren't stored.
+func computeSlots(settings *models.ProfessionalSettings, rules []models.AvailabilityRule, timeOff []models.TimeOff,
+	booked []models.Slot, from, to, now time.Time, duration time.Duration, locationID uint) []models.Slot {
+	if duration <= 0 {
+		return nil
+	}
+	step := time.Duration(settings.StepMinutes) * time.Minute
+	if step <= 0 {
+		step = duration
+	}
+	buffer := settings.Buffer()
+	tz := settings.TimeLocation()
+
+	// Se recorre desde el día anterior para cubrir diferencias de zona horaria
+	first := from.In(tz)
+	slots := []models.Slot{}
+	for day := time.Date(first.Year(), first.Month(), first.Day()-1, 0, 0, 0, 0, tz); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, rule := range rules {
+			if rule.Weekday != uint(day.Weekday()) || (locationID != 0 && rule.LocationID != locationID) {
+				continue
+			}
+			opens, okOpens := clockOn(day, rule.StartTime)
+			closes, okCloses := clockOn(day, rule.EndTime)
+			if !okOpens || !okCloses {
+				continue
+			}
+
+			for start := opens; !start.Add(duration).After(closes); start = start.Add(step) {
+				end := start.Add(duration)
+				if start.Before(from) || !start.Before(to) || start.Before(now) {
+					continue
+				}
+				if overlapsTimeOff(timeOff, start, end) || overlapsBooked(booked, start.Add(-buffer), end.Add(buffer)) {
+					continue
+				}
+				slots = append(slots, models.Slot{
+					ProfessionalID: settings.ProfessionalID,
+					StartTime:      start.UTC(),
+					EndTime:        end.UTC(),
+					Available:      true,
+					LocationID:     rule.LocationID,
+				})
+			}
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartTime.Before(slots[j].StartTime) })
+	return slots
+}
+
+// clockOn returns the instant of an "HH:MM" clock time on the given day.
+func clockOn(day time.Time, clock string) (time.Time, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), true
+}
+
+func overlapsTimeOff(timeOff []models.TimeOff, start, end time.Time) bool {
+	for _, off := range timeOff {
+		if off.StartTime.Before(end) && start.Before(off.EndTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsBooked(booked []models.Slot, start, end time.Time) bool {
+	for _, slot := range booked {
+		if slot.StartTime.Before(end) && start.Before(slot.EndTime) {
+			return true
+		}
+	}
+	return false
+}
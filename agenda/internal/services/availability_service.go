@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+)
+
+type AvailabilityService interface {
+	UpdateAvailabilitySettings(ctx context.Context, req *pb.UpdateAvailabilitySettingsRequest) (*pb.UpdateAvailabilitySettingsResponse, error)
+	GetAvailabilitySettings(ctx context.Context, req *pb.GetAvailabilitySettingsRequest) (*pb.GetAvailabilitySettingsResponse, error)
+	SetAvailabilityRules(ctx context.Context, req *pb.SetAvailabilityRulesRequest) (*pb.SetAvailabilityRulesResponse, error)
+	CreateTimeOff(ctx context.Context, req *pb.CreateTimeOffRequest) (*pb.CreateTimeOffResponse, error)
+	SetApprovalMode(ctx context.Context, req *pb.SetApprovalModeRequest) (*pb.SetApprovalModeResponse, error)
+	SetDigestPreferences(ctx context.Context, req *pb.SetDigestPreferencesRequest) (*pb.SetDigestPreferencesResponse, error)
+}
+
+type AvailabilityServiceImpl struct {
+	Repo repositories.AvailabilityRepository
+}
+
+func NewAvailabilityService(repo repositories.AvailabilityRepository) AvailabilityService {
+	return &AvailabilityServiceImpl{Repo: repo}
+}
+
+func (s *AvailabilityServiceImpl) UpdateAvailabilitySettings(ctx context.Context, req *pb.UpdateAvailabilitySettingsRequest) (*pb.UpdateAvailabilitySettingsResponse, error) {
+	in := req.Settings
+	if in == nil || in.ProfessionalId == 0 {
+		return &pb.UpdateAvailabilitySettingsResponse{Message: "professional_id is required", Success: false}, nil
+	}
+	if !callerOwns(ctx, 0, uint(in.ProfessionalId)) {
+		return &pb.UpdateAvailabilitySettingsResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	if in.Mode != models.AvailabilityMaterialized && in.Mode != models.AvailabilityComputed {
+		return &pb.UpdateAvailabilitySettingsResponse{Message: "mode must be 'materialized' or 'computed'", Success: false}, nil
+	}
+	if _, err := time.LoadLocation(in.Timezone); err != nil || in.Timezone == "" {
+		return &pb.UpdateAvailabilitySettingsResponse{Message: "Invalid timezone", Success: false}, nil
+	}
+	if in.DefaultDurationMinutes == 0 {
+		return &pb.UpdateAvailabilitySettingsResponse{Message: "default_duration_minutes must be greater than 0", Success: false}, nil
+	}
+
+	// Se parte de la configuración guardada para no pisar campos de otras funcionalidades
+	settings, err := s.Repo.GetSettings(uint(in.ProfessionalId))
+	if err != nil {
+		return &pb.UpdateAvailabilitySettingsResponse{Message: "Error updating settings", Success: false}, err
+	}
+	settings.AvailabilityMode = in.Mode
+	settings.Timezone = in.Timezone
+	settings.DefaultDurationMinutes = uint(in.DefaultDurationMinutes)
+	settings.StepMinutes = uint(in.StepMinutes)
+	settings.BufferMinutes = uint(in.BufferMinutes)
+	if err := s.Repo.SaveSettings(settings); err != nil {
+		return &pb.UpdateAvailabilitySettingsResponse{Message: "Error updating settings", Success: false}, err
+	}
+
+	return &pb.UpdateAvailabilitySettingsResponse{Message: "Settings updated", Success: true}, nil
+}
+
+func (s *AvailabilityServiceImpl) GetAvailabilitySettings(ctx context.Context, req *pb.GetAvailabilitySettingsRequest) (*pb.GetAvailabilitySettingsResponse, error) {
+	if !callerOwns(ctx, 0, uint(req.ProfessionalId)) {
+		return &pb.GetAvailabilitySettingsResponse{Success: false}, rbac.ErrNotAllowed
+	}
+	settings, err := s.Repo.GetSettings(uint(req.ProfessionalId))
+	if err != nil {
+		return &pb.GetAvailabilitySettingsResponse{Success: false}, err
+	}
+	digest, err := s.Repo.GetDigestSettings(uint(req.ProfessionalId))
+	if err != nil {
+		return &pb.GetAvailabilitySettingsResponse{Success: false}, err
+	}
+
+	return &pb.GetAvailabilitySettingsResponse{
+		Settings: &pb.AvailabilitySettings{
+			ProfessionalId:         uint32(settings.ProfessionalID),
+			Mode:                   settings.AvailabilityMode,
+			Timezone:               settings.Timezone,
+			DefaultDurationMinutes: uint32(settings.DefaultDurationMinutes),
+			StepMinutes:            uint32(settings.StepMinutes),
+			BufferMinutes:          uint32(settings.BufferMinutes),
+			RequiresApproval:       settings.RequiresApproval,
+			DigestSendTime:         digest.SendTime,
+			DigestOptOut:           digest.OptOut,
+		},
+		Success: true,
+	}, nil
+}
+
+func (s *AvailabilityServiceImpl) SetAvailabilityRules(ctx context.Context, req *pb.SetAvailabilityRulesRequest) (*pb.SetAvailabilityRulesResponse, error) {
+	if !callerOwns(ctx, 0, uint(req.ProfessionalId)) {
+		return &pb.SetAvailabilityRulesResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	rules := make([]models.AvailabilityRule, len(req.Rules))
+	for i, r := range req.Rules {
+		opens, errOpens := time.Parse("15:04", r.StartTime)
+		closes, errCloses := time.Parse("15:04", r.EndTime)
+		if r.Weekday > 6 || errOpens != nil || errCloses != nil || !closes.After(opens) {
+			return &pb.SetAvailabilityRulesResponse{Message: "Invalid availability rule", Success: false}, nil
+		}
+		rules[i] = models.AvailabilityRule{
+			ProfessionalID: uint(req.ProfessionalId),
+			Weekday:        uint(r.Weekday),
+			StartTime:      r.StartTime,
+			EndTime:        r.EndTime,
+			LocationID:     uint(r.LocationId),
+		}
+	}
+
+	if err := s.Repo.ReplaceRules(uint(req.ProfessionalId), rules); err != nil {
+		return &pb.SetAvailabilityRulesResponse{Message: "Error saving rules", Success: false}, err
+	}
+
+	return &pb.SetAvailabilityRulesResponse{Message: "Rules saved", Success: true}, nil
+}
+
+func (s *AvailabilityServiceImpl) CreateTimeOff(ctx context.Context, req *pb.CreateTimeOffRequest) (*pb.CreateTimeOffResponse, error) {
+	if !callerOwns(ctx, 0, uint(req.ProfessionalId)) {
+		return &pb.CreateTimeOffResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return &pb.CreateTimeOffResponse{Message: "start_time invalid format", Success: false}, err
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return &pb.CreateTimeOffResponse{Message: "end_time invalid format", Success: false}, err
+	}
+	if !endTime.After(startTime) {
+		return &pb.CreateTimeOffResponse{Message: "end_time must be after start_time", Success: false}, nil
+	}
+
+	timeOff := &models.TimeOff{
+		ProfessionalID: uint(req.ProfessionalId),
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Reason:         req.Reason,
+	}
+	if err := s.Repo.CreateTimeOff(timeOff); err != nil {
+		return &pb.CreateTimeOffResponse{Message: "Error creating time off", Success: false}, err
+	}
+
+	return &pb.CreateTimeOffResponse{
+		Message:   "Time off created",
+		Success:   true,
+		TimeOffId: uint32(timeOff.ID),
+	}, nil
+}
+
+func (s *AvailabilityServiceImpl) SetApprovalMode(ctx context.Context, req *pb.SetApprovalModeRequest) (*pb.SetApprovalModeResponse, error) {
+	if req.ProfessionalId == 0 {
+		return &pb.SetApprovalModeResponse{Message: "professional_id is required", Success: false}, nil
+	}
+	if !callerOwns(ctx, 0, uint(req.ProfessionalId)) {
+		return &pb.SetApprovalModeResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+
+	settings, err := s.Repo.GetSettings(uint(req.ProfessionalId))
+	if err != nil {
+		return &pb.SetApprovalModeResponse{Message: "Error updating settings", Success: false}, err
+	}
+	settings.RequiresApproval = req.RequiresApproval
+	if err := s.Repo.SaveSettings(settings); err != nil {
+		return &pb.SetApprovalModeResponse{Message: "Error updating settings", Success: false}, err
+	}
+
+	return &pb.SetApprovalModeResponse{Message: "Approval mode updated", Success: true}, nil
+}
+
+func (s *AvailabilityServiceImpl) SetDigestPreferences(ctx context.Context, req *pb.SetDigestPreferencesRequest) (*pb.SetDigestPreferencesResponse, error) {
+	if req.ProfessionalId == 0 {
+		return &pb.SetDigestPreferencesResponse{Message: "professional_id is required", Success: false}, nil
+	}
+	if !callerOwns(ctx, 0, uint(req.ProfessionalId)) {
+		return &pb.SetDigestPreferencesResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	sendTime, err := time.Parse("15:04", req.SendTime)
+	if err != nil {
+		return &pb.SetDigestPreferencesResponse{Message: "send_time must be in HH:MM format", Success: false}, nil
+	}
+
+	// La hora se normaliza porque se compara como texto con la hora local
+	digest := &models.DigestSettings{
+		ProfessionalID: uint(req.ProfessionalId),
+		SendTime:       sendTime.Format("15:04"),
+		OptOut:         req.OptOut,
+	}
+	if err := s.Repo.SaveDigestPreferences(digest); err != nil {
+		return &pb.SetDigestPreferencesResponse{Message: "Error updating digest preferences", Success: false}, err
+	}
+
+	return &pb.SetDigestPreferencesResponse{Message: "Digest preferences updated", Success: true}, nil
+}
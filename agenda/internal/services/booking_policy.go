@@ -0,0 +1,26 @@
+package services
+
+import "time"
+
+// BookingPolicy holds the time limits applied to appointments once booked.
+type BookingPolicy struct {
+	// RefundWindow is how long before the start a cancellation still gets its
+	// payment back
+	RefundWindow time.Duration
+	// ApprovalTimeout is how long a professional has to answer a booking
+	// request before it expires
+	ApprovalTimeout time.Duration
+}
+
+func (p BookingPolicy) Refundable(start, now time.Time) bool {
+	return !now.Add(p.RefundWindow).After(start)
+}
+
+// ApprovalDeadline returns when a request made now for an appointment starting
+// at start expires. Requests never outlive the appointment start.
+func (p BookingPolicy) ApprovalDeadline(start, now time.Time) time.Time {
+	if deadline := now.Add(p.ApprovalTimeout); deadline.Before(start) {
+		return deadline
+	}
+	return start
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/blobs"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"gorm.io/gorm"
+)
+
+const noteMaxLength = 5000
+
+type NoteService interface {
+	AddNote(ctx context.Context, req *pb.AddNoteRequest) (*pb.AddNoteResponse, error)
+	ListNotes(ctx context.Context, req *pb.ListNotesRequest) (*pb.ListNotesResponse, error)
+	UploadAttachment(ctx context.Context, req *pb.UploadAttachmentRequest) (*pb.UploadAttachmentResponse, error)
+	ListAttachments(ctx context.Context, req *pb.ListAttachmentsRequest) (*pb.ListAttachmentsResponse, error)
+	DownloadAttachment(ctx context.Context, req *pb.DownloadAttachmentRequest) (*pb.DownloadAttachmentResponse, error)
+}
+
+// AttachmentLimits restricts what can be uploaded. AllowedTypes are media
+// types without parameters, ie: "application/pdf".
+type AttachmentLimits struct {
+	MaxBytes     int
+	AllowedTypes []string
+}
+
+func (l AttachmentLimits) allows(contentType string) bool {
+	for _, allowed := range l.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+type NoteServiceImpl struct {
+	Repo       repositories.NoteRepository
+	AgendaRepo repositories.AgendaRepository
+	Store      blobs.BlobStore
+	Limits     AttachmentLimits
+}
+
+func NewNoteService(repo repositories.NoteRepository, agendaRepo repositories.AgendaRepository, store blobs.BlobStore,
+	limits AttachmentLimits) NoteService {
+	return &NoteServiceImpl{Repo: repo,
+		AgendaRepo: agendaRepo,
+		Store:      store,
+		Limits:     limits}
+}
+
+// requester is who a request is made on behalf of.
+type requester struct {
+	role string
+	id   uint
+}
+
+// visibilities returns what the requester is allowed to see.
+func (r requester) visibilities() []string {
+	if r.role == models.AuthorClient {
+		return []string{models.NoteShared}
+	}
+	return []string{models.NotePrivate, models.NoteShared}
+}
+
+// visibility resolves the visibility asked for a new note or attachment. It
+// returns a message when the requester can't use it.
+func (r requester) visibility(asked string) (string, string) {
+	switch {
+	case asked == "" && r.role == models.AuthorClient:
+		return models.NoteShared, ""
+	case asked == "":
+		return models.NotePrivate, ""
+	case asked != models.NotePrivate && asked != models.NoteShared:
+		return "", "visibility must be 'private' or 'shared'"
+	case asked == models.NotePrivate && r.role == models.AuthorClient:
+		return "", "Clients can only share with the professional"
+	}
+	return asked, ""
+}
+
+func (s *NoteServiceImpl) AddNote(ctx context.Context, req *pb.AddNoteRequest) (*pb.AddNoteResponse, error) {
+	who, msg, err := s.requester(ctx, req.AppointmentId, req.ProfessionalId, req.ClientId)
+	if msg != "" {
+		return &pb.AddNoteResponse{Message: msg, Success: false}, err
+	}
+	visibility, msg := who.visibility(req.Visibility)
+	if msg != "" {
+		return &pb.AddNoteResponse{Message: msg, Success: false}, nil
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return &pb.AddNoteResponse{Message: "body is required", Success: false}, nil
+	}
+	if len([]rune(body)) > noteMaxLength {
+		return &pb.AddNoteResponse{Message: fmt.Sprintf("body can't be longer than %d characters", noteMaxLength), Success: false}, nil
+	}
+
+	note := &models.AppointmentNote{
+		AppointmentID: uint(req.AppointmentId),
+		AuthorRole:    who.role,
+		AuthorID:      who.id,
+		Visibility:    visibility,
+		Body:          body,
+	}
+	if err := s.Repo.CreateNote(note); err != nil {
+		return &pb.AddNoteResponse{Message: "Error adding note", Success: false}, err
+	}
+
+	return &pb.AddNoteResponse{
+		Message: "Note added",
+		Success: true,
+		NoteId:  uint32(note.ID),
+	}, nil
+}
+
+func (s *NoteServiceImpl) ListNotes(ctx context.Context, req *pb.ListNotesRequest) (*pb.ListNotesResponse, error) {
+	who, msg, err := s.requester(ctx, req.AppointmentId, req.ProfessionalId, req.ClientId)
+	if msg != "" {
+		return &pb.ListNotesResponse{Message: msg, Success: false}, err
+	}
+
+	notes, err := s.Repo.ListNotes(uint(req.AppointmentId), who.visibilities())
+	if err != nil {
+		return &pb.ListNotesResponse{Message: "Error getting notes", Success: false}, err
+	}
+
+	pbNotes := make([]*pb.Note, len(notes))
+	for i, note := range notes {
+		pbNotes[i] = &pb.Note{
+			Id:            uint32(note.ID),
+			AppointmentId: uint32(note.AppointmentID),
+			AuthorRole:    note.AuthorRole,
+			AuthorId:      uint32(note.AuthorID),
+			Visibility:    note.Visibility,
+			Body:          note.Body,
+			CreatedAt:     note.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &pb.ListNotesResponse{
+		Message: "Notes found",
+		Success: true,
+		Notes:   pbNotes,
+	}, nil
+}
+
+// UploadAttachment stores the file in the blob store before recording it, the
+// blob is removed again if the record can't be saved.
+func (s *NoteServiceImpl) UploadAttachment(ctx context.Context, req *pb.UploadAttachmentRequest) (*pb.UploadAttachmentResponse, error) {
+	who, msg, err := s.requester(ctx, req.AppointmentId, req.ProfessionalId, req.ClientId)
+	if msg != "" {
+		return &pb.UploadAttachmentResponse{Message: msg, Success: false}, err
+	}
+	visibility, msg := who.visibility(req.Visibility)
+	if msg != "" {
+		return &pb.UploadAttachmentResponse{Message: msg, Success: false}, nil
+	}
+	if len(req.Data) == 0 {
+		return &pb.UploadAttachmentResponse{Message: "The file is empty", Success: false}, nil
+	}
+	if len(req.Data) > s.Limits.MaxBytes {
+		return &pb.UploadAttachmentResponse{Message: fmt.Sprintf("The file can't be larger than %d bytes", s.Limits.MaxBytes), Success: false}, nil
+	}
+	// El tipo se detecta del contenido, no se confía en el que manda el cliente
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(req.Data))
+	if err != nil || !s.Limits.allows(contentType) {
+		return &pb.UploadAttachmentResponse{Message: "File type not allowed", Success: false}, nil
+	}
+
+	key, err := newBlobKey()
+	if err != nil {
+		return &pb.UploadAttachmentResponse{Message: "Error uploading attachment", Success: false}, err
+	}
+	if err := s.Store.Put(key, req.Data); err != nil {
+		return &pb.UploadAttachmentResponse{Message: "Error uploading attachment", Success: false}, err
+	}
+	attachment := &models.Attachment{
+		AppointmentID: uint(req.AppointmentId),
+		UploaderRole:  who.role,
+		UploaderID:    who.id,
+		Visibility:    visibility,
+		FileName:      attachmentName(req.FileName),
+		ContentType:   contentType,
+		SizeBytes:     int64(len(req.Data)),
+		BlobKey:       key,
+	}
+	if err := s.Repo.CreateAttachment(attachment); err != nil {
+		if delErr := s.Store.Delete(key); delErr != nil {
+			log.Printf("Error deleting orphan blob %s: %v", key, delErr)
+		}
+		return &pb.UploadAttachmentResponse{Message: "Error uploading attachment", Success: false}, err
+	}
+
+	return &pb.UploadAttachmentResponse{
+		Message:      "Attachment uploaded",
+		Success:      true,
+		AttachmentId: uint32(attachment.ID),
+		ContentType:  contentType,
+	}, nil
+}
+
+func (s *NoteServiceImpl) ListAttachments(ctx context.Context, req *pb.ListAttachmentsRequest) (*pb.ListAttachmentsResponse, error) {
+	who, msg, err := s.requester(ctx, req.AppointmentId, req.ProfessionalId, req.ClientId)
+	if msg != "" {
+		return &pb.ListAttachmentsResponse{Message: msg, Success: false}, err
+	}
+
+	attachments, err := s.Repo.ListAttachments(uint(req.AppointmentId), who.visibilities())
+	if err != nil {
+		return &pb.ListAttachmentsResponse{Message: "Error getting attachments", Success: false}, err
+	}
+
+	pbAttachments := make([]*pb.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		pbAttachments[i] = &pb.Attachment{
+			Id:            uint32(attachment.ID),
+			AppointmentId: uint32(attachment.AppointmentID),
+			UploaderRole:  attachment.UploaderRole,
+			UploaderId:    uint32(attachment.UploaderID),
+			Visibility:    attachment.Visibility,
+			FileName:      attachment.FileName,
+			ContentType:   attachment.ContentType,
+			SizeBytes:     attachment.SizeBytes,
+			CreatedAt:     attachment.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &pb.ListAttachmentsResponse{
+		Message:     "Attachments found",
+		Success:     true,
+		Attachments: pbAttachments,
+	}, nil
+}
+
+// DownloadAttachment returns the file to the appointment's professional, or
+// to its client when it's shared. Private attachments are reported as not
+// found to the client.
+func (s *NoteServiceImpl) DownloadAttachment(ctx context.Context, req *pb.DownloadAttachmentRequest) (*pb.DownloadAttachmentResponse, error) {
+	attachment, err := s.Repo.GetAttachmentByID(uint(req.Id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.DownloadAttachmentResponse{Message: "Attachment not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.DownloadAttachmentResponse{Message: "Error getting attachment", Success: false}, err
+	}
+	who, msg, err := s.requester(ctx, uint32(attachment.AppointmentID), req.ProfessionalId, req.ClientId)
+	if msg != "" {
+		return &pb.DownloadAttachmentResponse{Message: msg, Success: false}, err
+	}
+	if who.role == models.AuthorClient && attachment.Visibility != models.NoteShared {
+		return &pb.DownloadAttachmentResponse{Message: "Attachment not found", Success: false}, nil
+	}
+
+	data, err := s.Store.Get(attachment.BlobKey)
+	if err != nil {
+		return &pb.DownloadAttachmentResponse{Message: "Error getting attachment", Success: false}, err
+	}
+
+	return &pb.DownloadAttachmentResponse{
+		Message:     "Attachment found",
+		Success:     true,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Data:        data,
+	}, nil
+}
+
+// requester resolves who the request is made by from the caller's claims, the
+// appointment's professional or client. Staff and the other services act on
+// behalf of the professional_id or client_id given. It returns a message when
+// the caller isn't part of the appointment.
+func (s *NoteServiceImpl) requester(ctx context.Context, appointmentID, professionalID, clientID uint32) (requester, string, error) {
+	claims := rbac.FromContext(ctx)
+	onBehalf := claims == nil || claims.ActsForAnyone()
+	if onBehalf && (professionalID == 0) == (clientID == 0) {
+		return requester{}, "Either professional_id or client_id is required", nil
+	}
+	appointment, err := s.AgendaRepo.GetAppointmentByID(uint(appointmentID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return requester{}, "Appointment not found", nil
+	}
+	if err != nil {
+		return requester{}, "Error getting appointment", err
+	}
+
+	if !onBehalf {
+		// Los ids de la petición se ignoran, no se puede escribir en nombre de otro
+		switch {
+		case claims.OwnsProfessional(appointment.ProfessionalID):
+			return requester{role: models.AuthorProfessional, id: appointment.ProfessionalID}, "", nil
+		case claims.OwnsClient(appointment.ClientID):
+			return requester{role: models.AuthorClient, id: appointment.ClientID}, "", nil
+		}
+		return requester{}, "Not allowed", rbac.ErrNotAllowed
+	}
+	if professionalID != 0 {
+		if appointment.ProfessionalID != uint(professionalID) {
+			return requester{}, "Appointment belongs to another professional", nil
+		}
+		return requester{role: models.AuthorProfessional, id: uint(professionalID)}, "", nil
+	}
+	if appointment.ClientID != uint(clientID) {
+		return requester{}, "Appointment belongs to another client", nil
+	}
+	return requester{role: models.AuthorClient, id: uint(clientID)}, "", nil
+}
+
+func newBlobKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// attachmentName keeps only the base name of the uploaded file, it's shown
+// when downloading but never used as a path.
+func attachmentName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"gorm.io/gorm"
+)
+
+type PackageService interface {
+	CreatePackage(req *pb.CreatePackageRequest) (*pb.CreatePackageResponse, error)
+	ListPackages(req *pb.ListPackagesRequest) (*pb.ListPackagesResponse, error)
+	PurchasePackage(ctx context.Context, req *pb.PurchasePackageRequest) (*pb.PurchasePackageResponse, error)
+	GetCreditBalance(ctx context.Context, req *pb.GetCreditBalanceRequest) (*pb.GetCreditBalanceResponse, error)
+	ListCreditHistory(ctx context.Context, req *pb.ListCreditHistoryRequest) (*pb.ListCreditHistoryResponse, error)
+}
+
+type PackageServiceImpl struct {
+	Repo repositories.PackageRepository
+}
+
+func NewPackageService(repo repositories.PackageRepository) PackageService {
+	return &PackageServiceImpl{Repo: repo}
+}
+
+func (s *PackageServiceImpl) CreatePackage(req *pb.CreatePackageRequest) (*pb.CreatePackageResponse, error) {
+	switch {
+	case req.Name == "":
+		return &pb.CreatePackageResponse{Message: "name is required", Success: false}, nil
+	case req.Credits == 0:
+		return &pb.CreatePackageResponse{Message: "credits must be greater than 0", Success: false}, nil
+	case req.ValidDays == 0:
+		return &pb.CreatePackageResponse{Message: "valid_days must be greater than 0", Success: false}, nil
+	case len(req.ServiceIds) == 0:
+		return &pb.CreatePackageResponse{Message: "The package needs at least one service", Success: false}, nil
+	}
+
+	ids := make([]uint, len(req.ServiceIds))
+	for i, id := range req.ServiceIds {
+		ids[i] = uint(id)
+	}
+	services, err := s.Repo.GetServicesByIDs(ids)
+	if err != nil {
+		return &pb.CreatePackageResponse{Message: "Error getting services", Success: false}, err
+	}
+	if len(services) != len(ids) {
+		return &pb.CreatePackageResponse{Message: "Service not found", Success: false}, nil
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	pkg := &models.Package{
+		Name:       req.Name,
+		Credits:    uint(req.Credits),
+		ValidDays:  uint(req.ValidDays),
+		PriceCents: uint(req.PriceCents),
+		Currency:   currency,
+		Services:   services,
+	}
+	if err := s.Repo.CreatePackage(pkg); err != nil {
+		return &pb.CreatePackageResponse{Message: "Error creating package", Success: false}, err
+	}
+
+	return &pb.CreatePackageResponse{Message: "Package created", Success: true, PackageId: uint32(pkg.ID)}, nil
+}
+
+func (s *PackageServiceImpl) ListPackages(req *pb.ListPackagesRequest) (*pb.ListPackagesResponse, error) {
+	packages, err := s.Repo.ListPackages()
+	if err != nil {
+		return &pb.ListPackagesResponse{Success: false}, err
+	}
+
+	pbPackages := make([]*pb.Package, len(packages))
+	for i := range packages {
+		pbPackages[i] = toPbPackage(&packages[i])
+	}
+	return &pb.ListPackagesResponse{Packages: pbPackages, Success: true}, nil
+}
+
+// PurchasePackage records a sale staff charged outside the app, no payment goes
+// through the provider. The credits last ValidDays from now unless the request
+// sets another expiration.
+func (s *PackageServiceImpl) PurchasePackage(ctx context.Context, req *pb.PurchasePackageRequest) (*pb.PurchasePackageResponse, error) {
+	if req.ClientId == 0 {
+		return &pb.PurchasePackageResponse{Message: "client_id is required", Success: false}, nil
+	}
+	if !callerOwns(ctx, uint(req.ClientId), 0) {
+		return &pb.PurchasePackageResponse{Message: "Not allowed", Success: false}, nil
+	}
+	pkg, err := s.Repo.GetPackageByID(uint(req.PackageId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.PurchasePackageResponse{Message: "Package not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.PurchasePackageResponse{Message: "Error getting package", Success: false}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.AddDate(0, 0, int(pkg.ValidDays))
+	if req.ExpiresAt != "" {
+		expiresAt, err = time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return &pb.PurchasePackageResponse{Message: "Invalid expires_at format", Success: false}, nil
+		}
+		if !expiresAt.After(now) {
+			return &pb.PurchasePackageResponse{Message: "expires_at must be in the future", Success: false}, nil
+		}
+	}
+
+	clientPackage := &models.ClientPackage{
+		ClientID:  uint(req.ClientId),
+		PackageID: pkg.ID,
+		Credits:   pkg.Credits,
+		Remaining: pkg.Credits,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.Repo.PurchasePackage(clientPackage); err != nil {
+		return &pb.PurchasePackageResponse{Message: "Error purchasing package", Success: false}, err
+	}
+
+	return &pb.PurchasePackageResponse{
+		Message:         "Package purchased",
+		Success:         true,
+		ClientPackageId: uint32(clientPackage.ID),
+		ExpiresAt:       expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// GetCreditBalance returns the credits the client can still use, optionally
+// only the ones valid for a service.
+func (s *PackageServiceImpl) GetCreditBalance(ctx context.Context, req *pb.GetCreditBalanceRequest) (*pb.GetCreditBalanceResponse, error) {
+	if !callerOwns(ctx, uint(req.ClientId), 0) {
+		return &pb.GetCreditBalanceResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	packages, err := s.Repo.ListActivePackages(uint(req.ClientId), time.Now())
+	if err != nil {
+		return &pb.GetCreditBalanceResponse{Message: "Error getting credits", Success: false}, err
+	}
+
+	var total uint32
+	pbPackages := []*pb.ClientPackage{}
+	for _, clientPackage := range packages {
+		if req.ServiceId != 0 && !includesService(&clientPackage.Package, uint(req.ServiceId)) {
+			continue
+		}
+		total += uint32(clientPackage.Remaining)
+		pbPackages = append(pbPackages, &pb.ClientPackage{
+			Id:         uint32(clientPackage.ID),
+			PackageId:  uint32(clientPackage.PackageID),
+			Name:       clientPackage.Package.Name,
+			Credits:    uint32(clientPackage.Credits),
+			Remaining:  uint32(clientPackage.Remaining),
+			ExpiresAt:  clientPackage.ExpiresAt.Format(time.RFC3339),
+			ServiceIds: packageServiceIDs(&clientPackage.Package),
+		})
+	}
+
+	return &pb.GetCreditBalanceResponse{
+		Message:      "Credit balance found",
+		Success:      true,
+		TotalCredits: total,
+		Packages:     pbPackages,
+	}, nil
+}
+
+func (s *PackageServiceImpl) ListCreditHistory(ctx context.Context, req *pb.ListCreditHistoryRequest) (*pb.ListCreditHistoryResponse, error) {
+	if !callerOwns(ctx, uint(req.ClientId), 0) {
+		return &pb.ListCreditHistoryResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	transactions, err := s.Repo.ListCreditTransactions(uint(req.ClientId))
+	if err != nil {
+		return &pb.ListCreditHistoryResponse{Message: "Error getting credit history", Success: false}, err
+	}
+
+	pbTransactions := make([]*pb.CreditTransaction, len(transactions))
+	for i, transaction := range transactions {
+		pbTransactions[i] = &pb.CreditTransaction{
+			Id:              uint32(transaction.ID),
+			ClientPackageId: uint32(transaction.ClientPackageID),
+			Kind:            transaction.Kind,
+			Delta:           int32(transaction.Delta),
+			CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
+		}
+		if transaction.AppointmentID != nil {
+			pbTransactions[i].AppointmentId = uint32(*transaction.AppointmentID)
+		}
+	}
+	return &pb.ListCreditHistoryResponse{Message: "Credit history found", Success: true, Transactions: pbTransactions}, nil
+}
+
+func includesService(pkg *models.Package, serviceID uint) bool {
+	for _, service := range pkg.Services {
+		if service.ID == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+func packageServiceIDs(pkg *models.Package) []uint32 {
+	serviceIDs := make([]uint32, len(pkg.Services))
+	for i, service := range pkg.Services {
+		serviceIDs[i] = uint32(service.ID)
+	}
+	return serviceIDs
+}
+
+func toPbPackage(pkg *models.Package) *pb.Package {
+	return &pb.Package{
+		Id:         uint32(pkg.ID),
+		Name:       pkg.Name,
+		Credits:    uint32(pkg.Credits),
+		ValidDays:  uint32(pkg.ValidDays),
+		PriceCents: uint32(pkg.PriceCents),
+		Currency:   pkg.Currency,
+		ServiceIds: packageServiceIDs(pkg),
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/meetings"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/payments"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"google.golang.org/grpc"
+)
+
+type PaymentService interface {
+	HandlePaymentWebhook(req *pb.PaymentWebhookRequest) (*pb.PaymentWebhookResponse, error)
+	GetPayment(ctx context.Context, req *pb.GetPaymentRequest) (*pb.GetPaymentResponse, error)
+	ReleaseUnpaid(createdBefore time.Time) error
+}
+
+type PaymentServiceImpl struct {
+	Repo        repositories.AgendaRepository
+	Provider    payments.PaymentProvider
+	Meetings    meetings.MeetingProvider
+	NotifClient pb.NotificationServiceClient
+}
+
+func NewPaymentService(repo repositories.AgendaRepository, provider payments.PaymentProvider,
+	meetingProvider meetings.MeetingProvider, notifConn *grpc.ClientConn) PaymentService {
+	return &PaymentServiceImpl{Repo: repo,
+		Provider:    provider,
+		Meetings:    meetingProvider,
+		NotifClient: pb.NewNotificationServiceClient(notifConn)}
+}
+
+// HandlePaymentWebhook captures the authorized payments and books their
+// appointments. Providers may deliver a webhook more than once, so payments
+// already processed are acknowledged without doing anything.
+func (s *PaymentServiceImpl) HandlePaymentWebhook(req *pb.PaymentWebhookRequest) (*pb.PaymentWebhookResponse, error) {
+	event, err := s.Provider.VerifyWebhook(req.Payload, req.Signature)
+	if err != nil {
+		return &pb.PaymentWebhookResponse{Message: "Invalid webhook", Success: false}, nil
+	}
+	if event.Type != payments.EventAuthorized {
+		return &pb.PaymentWebhookResponse{Message: "Event ignored", Success: true}, nil
+	}
+
+	payment, err := s.Repo.GetPaymentByIntent(event.IntentID)
+	if err != nil {
+		return &pb.PaymentWebhookResponse{Message: "Payment not found", Success: false}, err
+	}
+	if payment.Status != models.PaymentPending {
+		return &pb.PaymentWebhookResponse{Message: "Payment already processed", Success: true}, nil
+	}
+
+	if err := s.Provider.Capture(payment.IntentID); err != nil {
+		return &pb.PaymentWebhookResponse{Message: "Error capturing payment", Success: false}, err
+	}
+	appointment, err := s.Repo.ConfirmPayment(payment)
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		// La cita expiró o se canceló mientras se pagaba, se devuelve el dinero
+		if err := s.Provider.Refund(payment.IntentID, payment.AmountCents); err != nil {
+			return &pb.PaymentWebhookResponse{Message: "Error refunding payment", Success: false}, err
+		}
+		if err := s.Repo.UpdatePaymentStatus(payment.ID, models.PaymentRefunded); err != nil {
+			return &pb.PaymentWebhookResponse{Message: "Error refunding payment", Success: false}, err
+		}
+		return &pb.PaymentWebhookResponse{Message: "Appointment no longer pending, payment refunded", Success: true}, nil
+	}
+	if err != nil {
+		// Ya se cobró pero la cita no quedó reservada: se devuelve el dinero y se
+		// libera aquí, ReleaseUnpaid solo ve los pagos pendientes
+		log.Printf("Error confirming payment %d, refunding it: %v", payment.ID, err)
+		if err := s.Provider.Refund(payment.IntentID, payment.AmountCents); err != nil {
+			log.Printf("Payment %d captured but not refunded, refund it manually: %v", payment.ID, err)
+			return &pb.PaymentWebhookResponse{Message: "Error refunding payment", Success: false}, err
+		}
+		if err := s.Repo.UpdatePaymentStatus(payment.ID, models.PaymentRefunded); err != nil {
+			return &pb.PaymentWebhookResponse{Message: "Error refunding payment", Success: false}, err
+		}
+		s.releaseRefunded(payment)
+		return &pb.PaymentWebhookResponse{Message: "Appointment could not be confirmed, payment refunded", Success: true}, nil
+	}
+
+	slot, err := s.Repo.GetSlotByID(appointment.SlotID)
+	if err != nil {
+		log.Printf("Error notifying booking of appointment %d: %v", appointment.ID, err)
+	} else if appointment.Status == models.AppointmentPendingApproval {
+		sendAppointmentUpdate(s.NotifClient, appointment, slot, "requested", "")
+	} else {
+		sendBookingNotification(s.NotifClient, appointment, slot)
+	}
+
+	return &pb.PaymentWebhookResponse{Message: "Payment captured", Success: true}, nil
+}
+
+// releaseRefunded expires the appointment of a payment refunded before it
+// could be confirmed. Failures are only logged, the payment is already settled.
+func (s *PaymentServiceImpl) releaseRefunded(payment *models.Payment) {
+	err := s.Repo.ReleaseAppointment(payment.AppointmentID,
+		[]string{models.AppointmentPaymentPending}, models.AppointmentExpired)
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		return
+	}
+	if err != nil {
+		log.Printf("Payment %d refunded but appointment %d still holds its slot, release it manually: %v",
+			payment.ID, payment.AppointmentID, err)
+		return
+	}
+	appointment, err := s.Repo.GetAppointmentByID(payment.AppointmentID)
+	if err != nil {
+		log.Printf("Error loading appointment %d: %v", payment.AppointmentID, err)
+		return
+	}
+	revokeMeeting(s.Meetings, s.Repo, appointment)
+}
+
+// GetPayment returns the payment of an appointment to its client, its
+// professional or staff.
+func (s *PaymentServiceImpl) GetPayment(ctx context.Context, req *pb.GetPaymentRequest) (*pb.GetPaymentResponse, error) {
+	appointment, err := s.Repo.GetAppointmentByID(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.GetPaymentResponse{Success: false}, err
+	}
+	if !callerOwns(ctx, appointment.ClientID, appointment.ProfessionalID) {
+		return &pb.GetPaymentResponse{Success: false}, rbac.ErrNotAllowed
+	}
+	payment, err := s.Repo.GetPaymentByAppointment(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.GetPaymentResponse{Success: false}, err
+	}
+
+	return &pb.GetPaymentResponse{
+		Payment: &pb.Payment{
+			Id:            uint32(payment.ID),
+			AppointmentId: uint32(payment.AppointmentID),
+			Provider:      payment.Provider,
+			IntentId:      payment.IntentID,
+			AmountCents:   uint32(payment.AmountCents),
+			Currency:      payment.Currency,
+			Status:        payment.Status,
+		},
+		Success: true,
+	}, nil
+}
+
+// ReleaseUnpaid expires the appointments whose payment was created before the
+// given time and is still pending, freeing their slots.
+func (s *PaymentServiceImpl) ReleaseUnpaid(createdBefore time.Time) error {
+	pending, err := s.Repo.ListPendingPayments(createdBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range pending {
+		err := s.Repo.ReleaseAppointment(payment.AppointmentID,
+			[]string{models.AppointmentPaymentPending}, models.AppointmentExpired)
+		if errors.Is(err, repositories.ErrStatusChanged) {
+			// Se pagó o canceló entre la consulta y el bloqueo
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.Repo.UpdatePaymentStatus(payment.ID, models.PaymentExpired); err != nil {
+			return err
+		}
+		appointment, err := s.Repo.GetAppointmentByID(payment.AppointmentID)
+		if err != nil {
+			return err
+		}
+		revokeMeeting(s.Meetings, s.Repo, appointment)
+		log.Printf("Appointment %d expired without payment", payment.AppointmentID)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+const (
+	// IssueOrphanSlot is a taken slot that no active appointment holds
+	IssueOrphanSlot = "orphan_slot"
+	// IssueFreeBookedSlot is an active appointment whose slot is available
+	IssueFreeBookedSlot = "free_booked_slot"
+	// IssueMissingSlot is an appointment pointing at a slot that doesn't
+	// exist. Its times are lost, so it's only reported.
+	IssueMissingSlot = "missing_slot"
+)
+
+// ReconcilePolicy tells which kinds of inconsistencies get repaired, the
+// others are only reported.
+type ReconcilePolicy struct {
+	RepairOrphanSlots     bool
+	RepairFreeBookedSlots bool
+}
+
+// ParseReconcilePolicy reads a comma separated list of the issue kinds to
+// repair, ie: "orphan_slot,free_booked_slot". An empty list only reports.
+func ParseReconcilePolicy(kinds string) (ReconcilePolicy, error) {
+	var policy ReconcilePolicy
+	for _, kind := range strings.Split(kinds, ",") {
+		switch strings.TrimSpace(kind) {
+		case "":
+		case IssueOrphanSlot:
+			policy.RepairOrphanSlots = true
+		case IssueFreeBookedSlot:
+			policy.RepairFreeBookedSlots = true
+		default:
+			return policy, fmt.Errorf("can't repair %q", kind)
+		}
+	}
+	return policy, nil
+}
+
+type ReconcileService interface {
+	Reconcile(req *pb.ReconcileRequest) (*pb.ReconcileResponse, error)
+	RunReconcile(now time.Time) error
+}
+
+type ReconcileServiceImpl struct {
+	Repo   repositories.ReconcileRepository
+	Policy ReconcilePolicy
+}
+
+func NewReconcileService(repo repositories.ReconcileRepository, policy ReconcilePolicy) ReconcileService {
+	return &ReconcileServiceImpl{Repo: repo, Policy: policy}
+}
+
+func (s *ReconcileServiceImpl) Reconcile(req *pb.ReconcileRequest) (*pb.ReconcileResponse, error) {
+	issues, err := s.reconcile(!req.DryRun)
+	if err != nil {
+		return &pb.ReconcileResponse{Message: "Error reconciling agenda", Success: false, Issues: issues}, err
+	}
+
+	return &pb.ReconcileResponse{
+		Message: fmt.Sprintf("%d issues found", len(issues)),
+		Success: true,
+		Issues:  issues,
+	}, nil
+}
+
+// RunReconcile is the periodic job, it applies the policy and logs what it
+// found.
+func (s *ReconcileServiceImpl) RunReconcile(now time.Time) error {
+	issues, err := s.reconcile(true)
+	for _, issue := range issues {
+		log.Printf("Agenda inconsistency %s: slot %d, appointment %d, repaired %t",
+			issue.Kind, issue.SlotId, issue.AppointmentId, issue.Repaired)
+	}
+	return err
+}
+
+// reconcile looks for slots and appointments that disagree and, when repair is
+// set, fixes the kinds the policy allows. The issues found until a failure are
+// returned along with the error.
+func (s *ReconcileServiceImpl) reconcile(repair bool) ([]*pb.ReconcileIssue, error) {
+	issues := []*pb.ReconcileIssue{}
+
+	slots, err := s.Repo.ListOrphanSlots()
+	if err != nil {
+		return issues, err
+	}
+	for _, slot := range slots {
+		issue := &pb.ReconcileIssue{Kind: IssueOrphanSlot, SlotId: uint32(slot.ID)}
+		if repair && s.Policy.RepairOrphanSlots {
+			// Si alguien reservó el slot entre la consulta y la reparación, se deja como está
+			if issue.Repaired, err = s.Repo.FreeOrphanSlot(slot.ID, slot.ProfessionalID); err != nil {
+				return issues, err
+			}
+		}
+		issues = append(issues, issue)
+	}
+
+	appointments, err := s.Repo.ListFreeBookedSlots()
+	if err != nil {
+		return issues, err
+	}
+	for _, appointment := range appointments {
+		issue := &pb.ReconcileIssue{Kind: IssueFreeBookedSlot, SlotId: uint32(appointment.SlotID), AppointmentId: uint32(appointment.ID)}
+		if repair && s.Policy.RepairFreeBookedSlots {
+			if issue.Repaired, err = s.Repo.TakeBookedSlot(appointment.SlotID); err != nil {
+				return issues, err
+			}
+		}
+		issues = append(issues, issue)
+	}
+
+	appointments, err = s.Repo.ListAppointmentsWithoutSlot()
+	if err != nil {
+		return issues, err
+	}
+	for _, appointment := range appointments {
+		issues = append(issues, &pb.ReconcileIssue{Kind: IssueMissingSlot, SlotId: uint32(appointment.SlotID), AppointmentId: uint32(appointment.ID)})
+	}
+	return issues, nil
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"gorm.io/gorm"
+)
+
+type ResourceService interface {
+	CreateResource(req *pb.CreateResourceRequest) (*pb.CreateResourceResponse, error)
+	ListResources(req *pb.ListResourcesRequest) (*pb.ListResourcesResponse, error)
+	BlockResource(ctx context.Context, req *pb.BlockResourceRequest) (*pb.BlockResourceResponse, error)
+	CreateService(req *pb.CreateServiceRequest) (*pb.CreateServiceResponse, error)
+	GetService(req *pb.GetServiceRequest) (*pb.GetServiceResponse, error)
+	ListServices(req *pb.ListServicesRequest) (*pb.ListServicesResponse, error)
+	CreateIntakeForm(ctx context.Context, req *pb.CreateIntakeFormRequest) (*pb.CreateIntakeFormResponse, error)
+	GetIntakeForm(req *pb.GetIntakeFormRequest) (*pb.GetIntakeFormResponse, error)
+}
+
+type ResourceServiceImpl struct {
+	Repo repositories.ResourceRepository
+}
+
+func NewResourceService(repo repositories.ResourceRepository) ResourceService {
+	return &ResourceServiceImpl{Repo: repo}
+}
+
+func (s *ResourceServiceImpl) CreateResource(req *pb.CreateResourceRequest) (*pb.CreateResourceResponse, error) {
+	if req.Type != models.ResourceTypeRoom && req.Type != models.ResourceTypeEquipment {
+		return &pb.CreateResourceResponse{Message: "type must be 'room' or 'equipment'", Success: false}, nil
+	}
+
+	resource := &models.Resource{
+		Name: req.Name,
+		Type: req.Type,
+	}
+	if err := s.Repo.CreateResource(resource); err != nil {
+		return &pb.CreateResourceResponse{Message: "Error creating resource", Success: false}, err
+	}
+
+	return &pb.CreateResourceResponse{
+		Message:    "Resource created",
+		Success:    true,
+		ResourceId: uint32(resource.ID),
+	}, nil
+}
+
+func (s *ResourceServiceImpl) ListResources(req *pb.ListResourcesRequest) (*pb.ListResourcesResponse, error) {
+	resources, err := s.Repo.ListResources(req.Type)
+	if err != nil {
+		return &pb.ListResourcesResponse{Success: false}, err
+	}
+
+	pbResources := make([]*pb.Resource, len(resources))
+	for i, resource := range resources {
+		pbResources[i] = &pb.Resource{
+			Id:   uint32(resource.ID),
+			Name: resource.Name,
+			Type: resource.Type,
+		}
+	}
+
+	return &pb.ListResourcesResponse{
+		Resources: pbResources,
+		Success:   true,
+	}, nil
+}
+
+// BlockResource reserves a resource for something other than an appointment.
+// Resources are shared by every professional, so only staff can block them.
+func (s *ResourceServiceImpl) BlockResource(ctx context.Context, req *pb.BlockResourceRequest) (*pb.BlockResourceResponse, error) {
+	if claims := rbac.FromContext(ctx); claims != nil && !claims.ActsForAnyone() {
+		return &pb.BlockResourceResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return &pb.BlockResourceResponse{Message: "start_time invalid format", Success: false}, err
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return &pb.BlockResourceResponse{Message: "end_time invalid format", Success: false}, err
+	}
+	if !endTime.After(startTime) {
+		return &pb.BlockResourceResponse{Message: "end_time must be after start_time", Success: false}, nil
+	}
+
+	reservation := &models.ResourceReservation{
+		ResourceID: uint(req.ResourceId),
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Reason:     req.Reason,
+	}
+	if err := s.Repo.CreateReservation(reservation); err != nil {
+		return &pb.BlockResourceResponse{Message: "Error blocking resource", Success: false}, err
+	}
+
+	return &pb.BlockResourceResponse{
+		Message:       "Resource blocked",
+		Success:       true,
+		ReservationId: uint32(reservation.ID),
+	}, nil
+}
+
+func (s *ResourceServiceImpl) CreateService(req *pb.CreateServiceRequest) (*pb.CreateServiceResponse, error) {
+	if req.DepositCents > req.PriceCents {
+		return &pb.CreateServiceResponse{Message: "deposit_cents can't be greater than price_cents", Success: false}, nil
+	}
+	modality := req.Modality
+	if modality == "" {
+		modality = models.ModalityInPerson
+	}
+	if modality != models.ModalityInPerson && modality != models.ModalityRemote {
+		return &pb.CreateServiceResponse{Message: "modality must be 'in_person' or 'remote'", Success: false}, nil
+	}
+
+	var resources []models.Resource
+	if len(req.ResourceIds) > 0 {
+		ids := make([]uint, len(req.ResourceIds))
+		for i, id := range req.ResourceIds {
+			ids[i] = uint(id)
+		}
+		found, err := s.Repo.GetResourcesByIDs(ids)
+		if err != nil {
+			return &pb.CreateServiceResponse{Message: "Error getting resources", Success: false}, err
+		}
+		if len(found) != len(ids) {
+			return &pb.CreateServiceResponse{Message: "Resource not found", Success: false}, nil
+		}
+		resources = found
+	}
+
+	service := &models.Service{
+		Name:            req.Name,
+		DurationMinutes: uint(req.DurationMinutes),
+		Resources:       resources,
+		PriceCents:      uint(req.PriceCents),
+		DepositCents:    uint(req.DepositCents),
+		Currency:        req.Currency,
+		Modality:        modality,
+		RequiresCredits: req.RequiresCredits,
+	}
+	if err := s.Repo.CreateService(service); err != nil {
+		return &pb.CreateServiceResponse{Message: "Error creating service", Success: false}, err
+	}
+
+	return &pb.CreateServiceResponse{
+		Message:   "Service created",
+		Success:   true,
+		ServiceId: uint32(service.ID),
+	}, nil
+}
+
+func (s *ResourceServiceImpl) GetService(req *pb.GetServiceRequest) (*pb.GetServiceResponse, error) {
+	service, err := s.Repo.GetServiceByID(uint(req.Id))
+	if err != nil {
+		return &pb.GetServiceResponse{Success: false}, err
+	}
+
+	return &pb.GetServiceResponse{
+		Service: toPbService(service),
+		Success: true,
+	}, nil
+}
+
+func (s *ResourceServiceImpl) ListServices(req *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+	services, err := s.Repo.ListServices()
+	if err != nil {
+		return &pb.ListServicesResponse{Success: false}, err
+	}
+
+	pbServices := make([]*pb.Service, len(services))
+	for i := range services {
+		pbServices[i] = toPbService(&services[i])
+	}
+
+	return &pb.ListServicesResponse{
+		Services: pbServices,
+		Success:  true,
+	}, nil
+}
+
+func (s *ResourceServiceImpl) CreateIntakeForm(ctx context.Context, req *pb.CreateIntakeFormRequest) (*pb.CreateIntakeFormResponse, error) {
+	if (req.ServiceId == 0) == (req.ProfessionalId == 0) {
+		return &pb.CreateIntakeFormResponse{Message: "Either service_id or professional_id is required", Success: false}, nil
+	}
+	// Los formularios de un servicio son de todos, solo staff los crea
+	if !callerOwns(ctx, 0, uint(req.ProfessionalId)) {
+		return &pb.CreateIntakeFormResponse{Message: "Not allowed", Success: false}, rbac.ErrNotAllowed
+	}
+	if req.Title == "" {
+		return &pb.CreateIntakeFormResponse{Message: "title is required", Success: false}, nil
+	}
+	if req.ServiceId != 0 {
+		if _, err := s.Repo.GetServiceByID(uint(req.ServiceId)); err != nil {
+			return &pb.CreateIntakeFormResponse{Message: "Service not found", Success: false}, err
+		}
+	}
+
+	fields := make([]models.IntakeField, len(req.Fields))
+	for i, field := range req.Fields {
+		fields[i] = models.IntakeField{
+			Key:      field.Key,
+			Label:    field.Label,
+			Type:     field.Type,
+			Required: field.Required,
+			Options:  field.Options,
+		}
+	}
+	if err := models.ValidateIntakeSchema(fields); err != nil {
+		return &pb.CreateIntakeFormResponse{Message: "Invalid form: " + err.Error(), Success: false}, nil
+	}
+
+	form := &models.IntakeForm{
+		ServiceID:      uint(req.ServiceId),
+		ProfessionalID: uint(req.ProfessionalId),
+		Title:          req.Title,
+	}
+	if err := form.SetFields(fields); err != nil {
+		return &pb.CreateIntakeFormResponse{Message: "Error creating intake form", Success: false}, err
+	}
+	if err := s.Repo.CreateIntakeForm(form); err != nil {
+		return &pb.CreateIntakeFormResponse{Message: "Error creating intake form", Success: false}, err
+	}
+
+	return &pb.CreateIntakeFormResponse{
+		Message: "Intake form created",
+		Success: true,
+		FormId:  uint32(form.ID),
+		Version: uint32(form.Version),
+	}, nil
+}
+
+func (s *ResourceServiceImpl) GetIntakeForm(req *pb.GetIntakeFormRequest) (*pb.GetIntakeFormResponse, error) {
+	form, err := intakeFormFor(s.Repo, uint(req.ServiceId), uint(req.ProfessionalId))
+	if err != nil {
+		return &pb.GetIntakeFormResponse{Success: false}, err
+	}
+	if form == nil {
+		return &pb.GetIntakeFormResponse{Success: true}, nil
+	}
+
+	pbForm, err := toPbIntakeForm(form)
+	if err != nil {
+		return &pb.GetIntakeFormResponse{Success: false}, err
+	}
+	return &pb.GetIntakeFormResponse{
+		Form:    pbForm,
+		Success: true,
+	}, nil
+}
+
+// intakeFormFor returns the latest form to fill when booking, the service's
+// form takes precedence over the professional's one. It's nil when neither
+// has a form.
+func intakeFormFor(repo repositories.ResourceRepository, serviceID, professionalID uint) (*models.IntakeForm, error) {
+	if serviceID != 0 {
+		form, err := repo.GetLatestIntakeForm(serviceID, 0)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return form, err
+		}
+	}
+	if professionalID != 0 {
+		form, err := repo.GetLatestIntakeForm(0, professionalID)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return form, err
+		}
+	}
+	return nil, nil
+}
+
+func toPbIntakeForm(form *models.IntakeForm) (*pb.IntakeForm, error) {
+	fields, err := form.Fields()
+	if err != nil {
+		return nil, err
+	}
+	pbFields := make([]*pb.IntakeField, len(fields))
+	for i, field := range fields {
+		pbFields[i] = &pb.IntakeField{
+			Key:      field.Key,
+			Label:    field.Label,
+			Type:     field.Type,
+			Required: field.Required,
+			Options:  field.Options,
+		}
+	}
+	return &pb.IntakeForm{
+		Id:             uint32(form.ID),
+		ServiceId:      uint32(form.ServiceID),
+		ProfessionalId: uint32(form.ProfessionalID),
+		Version:        uint32(form.Version),
+		Title:          form.Title,
+		Fields:         pbFields,
+	}, nil
+}
+
+func toPbService(service *models.Service) *pb.Service {
+	resourceIDs := make([]uint32, len(service.Resources))
+	for i, resource := range service.Resources {
+		resourceIDs[i] = uint32(resource.ID)
+	}
+	return &pb.Service{
+		Id:              uint32(service.ID),
+		Name:            service.Name,
+		DurationMinutes: uint32(service.DurationMinutes),
+		ResourceIds:     resourceIDs,
+		PriceCents:      uint32(service.PriceCents),
+		DepositCents:    uint32(service.DepositCents),
+		Currency:        service.Currency,
+		Modality:        service.Modality,
+		RequiresCredits: service.RequiresCredits,
+	}
+}
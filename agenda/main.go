@@ -3,45 +3,196 @@ package main
 import (
 	"log"
 	"net"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/blobs"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/config"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/handlers"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/jobs"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/meetings"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/payments"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
-	dsn = common.EnvString("AGENDA_DB", "host=localhost user=postgres password=yourpassword dbname=agenda_db port=5432 sslmode=disable")
+	// Debe coincidir con el del servicio de auth, firma también los tokens entre servicios
+	secretKey = common.EnvString("JWT_SECRET", rbac.DefaultSecret)
+	devMode   = common.EnvString("DEV_MODE", "false") == "true"
+	dsn       = common.EnvString("AGENDA_DB", "host=localhost user=postgres password=yourpassword dbname=agenda_db port=5432 sslmode=disable")
+	// Tiempo después del término de la cita en que se pide la reseña, ie: "2h", "24h"
+	reviewRequestDelay = common.EnvString("REVIEW_REQUEST_DELAY", "2h")
+	// Tiempo que tiene el cliente para pagar antes de liberar el slot
+	paymentTimeout = common.EnvString("PAYMENT_TIMEOUT", "15m")
+	// "stripe" o "fake", este último solo con DEV_MODE=true
+	paymentProvider      = common.EnvString("PAYMENT_PROVIDER", payments.ProviderFake)
+	paymentAPIKey        = common.EnvString("PAYMENT_API_KEY", "")
+	paymentWebhookSecret = common.EnvString("PAYMENT_WEBHOOK_SECRET", payments.DefaultWebhookSecret)
+	// Las cancelaciones hechas con al menos esta anticipación se reembolsan
+	cancellationRefundWindow = common.EnvString("CANCELLATION_REFUND_WINDOW", "24h")
+	// Plazo del profesional para aprobar una solicitud antes de que expire
+	approvalTimeout = common.EnvString("APPROVAL_TIMEOUT", "24h")
+	// Los adjuntos de las citas se guardan en este directorio
+	attachmentsDir      = common.EnvString("ATTACHMENTS_DIR", "./data/attachments")
+	attachmentMaxBytes  = common.EnvString("ATTACHMENT_MAX_BYTES", "5242880")
+	attachmentMimeTypes = common.EnvString("ATTACHMENT_MIME_TYPES", "application/pdf,image/jpeg,image/png,text/plain")
+	// Las salas de las citas remotas se crean bajo esta URL
+	meetingBaseURL = common.EnvString("MEETING_BASE_URL", "https://meet.jit.si")
+	// Cada cuánto se revisa que slots y citas coincidan, y qué se repara solo
+	// ie: "orphan_slot,free_booked_slot", vacío solo reporta
+	reconcileInterval = common.EnvString("RECONCILE_INTERVAL", "1h")
+	reconcileRepair   = common.EnvString("RECONCILE_REPAIR", "")
+	// "remote" le pregunta a auth y así respeta los tokens revocados, "local"
+	// solo revisa las llaves publicadas y acepta un token hasta que expira
+	tokenValidation = common.EnvString("TOKEN_VALIDATION", rbac.ValidationRemote)
+	// Cada cuánto se traen las llaves de auth, menos que SIGNING_KEY_PUBLISH_AHEAD
+	jwksSyncInterval = common.EnvString("JWKS_SYNC_INTERVAL", "5m")
 )
 
 func main() {
+	if err := rbac.CheckSecret(secretKey, devMode); err != nil {
+		log.Fatal(err)
+	}
+	if err := payments.CheckWebhookSecret(paymentWebhookSecret, devMode); err != nil {
+		log.Fatal(err)
+	}
+	provider, err := payments.NewProvider(paymentProvider, paymentAPIKey, paymentWebhookSecret, devMode)
+	if err != nil {
+		log.Fatalf("Invalid PAYMENT_PROVIDER: %v", err)
+	}
+
+	// Las llamadas a otros servicios van con un token de servicio
+	serviceCreds := rbac.NewServiceCredentials(secretKey, "agenda")
+	authConn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to auth server: %v", err)
+	}
+	defer authConn.Close()
+
 	dbConfig := config.NewDBConfig(dsn)
 	db, err := dbConfig.ConnectDB()
 	if err != nil {
 		log.Fatalf("Cannot connect to DB: %v", err)
 	}
 
-	notifConn, err := grpc.NewClient("localhost:50055", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	notifConn, err := grpc.NewClient("localhost:50055", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
 	if err != nil {
 		log.Fatalf("Cannot connect to notification server: %v", err)
 	}
 	defer notifConn.Close()
 
+	profConn, err := grpc.NewClient("localhost:50052", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to professional server: %v", err)
+	}
+	defer profConn.Close()
+
+	clientConn, err := grpc.NewClient("localhost:50053", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to client server: %v", err)
+	}
+	defer clientConn.Close()
+
 	repo := repositories.NewAgendaRepository(db)
-	svc := services.NewAgendaService(repo, notifConn)
+	resourceRepo := repositories.NewResourceRepository(db)
+	availabilityRepo := repositories.NewAvailabilityRepository(db)
+	refundWindow, err := time.ParseDuration(cancellationRefundWindow)
+	if err != nil {
+		log.Fatalf("Invalid CANCELLATION_REFUND_WINDOW: %v", err)
+	}
+	approvalWindow, err := time.ParseDuration(approvalTimeout)
+	if err != nil {
+		log.Fatalf("Invalid APPROVAL_TIMEOUT: %v", err)
+	}
+	meetingProvider := meetings.NewLinkProvider(meetingBaseURL)
+	svc := services.NewAgendaService(repo, resourceRepo, availabilityRepo, provider, meetingProvider,
+		services.BookingPolicy{RefundWindow: refundWindow, ApprovalTimeout: approvalWindow}, notifConn, profConn, clientConn)
+	paymentSvc := services.NewPaymentService(repo, provider, meetingProvider, notifConn)
 	handler := handlers.NewAgendaHandler(svc)
+	resourceHandler := handlers.NewResourceHandler(services.NewResourceService(resourceRepo))
+	availabilityHandler := handlers.NewAvailabilityHandler(services.NewAvailabilityService(availabilityRepo))
+
+	maxBytes, err := strconv.Atoi(attachmentMaxBytes)
+	if err != nil || maxBytes <= 0 {
+		log.Fatalf("Invalid ATTACHMENT_MAX_BYTES: %s", attachmentMaxBytes)
+	}
+	store, err := blobs.NewLocalStore(attachmentsDir)
+	if err != nil {
+		log.Fatalf("Cannot open attachments dir: %v", err)
+	}
+	noteSvc := services.NewNoteService(repositories.NewNoteRepository(db), repo, store,
+		services.AttachmentLimits{MaxBytes: maxBytes, AllowedTypes: strings.Split(attachmentMimeTypes, ",")})
+
+	reviewDelay, err := time.ParseDuration(reviewRequestDelay)
+	if err != nil {
+		log.Fatalf("Invalid REVIEW_REQUEST_DELAY: %v", err)
+	}
+	go jobs.Every("review requests", time.Minute, func(now time.Time) error {
+		return svc.SendReviewRequests(now.Add(-reviewDelay))
+	})
+
+	unpaidTimeout, err := time.ParseDuration(paymentTimeout)
+	if err != nil {
+		log.Fatalf("Invalid PAYMENT_TIMEOUT: %v", err)
+	}
+	go jobs.Every("payment expiry", time.Minute, func(now time.Time) error {
+		return paymentSvc.ReleaseUnpaid(now.Add(-unpaidTimeout))
+	})
+
+	go jobs.Every("approval expiry", time.Minute, svc.ExpireApprovalRequests)
+
+	// Cada profesional recibe el resumen a su hora local, el job solo revisa quién está pendiente
+	go jobs.Every("daily digest", time.Minute, svc.SendDailyDigests)
+
+	interval, err := time.ParseDuration(reconcileInterval)
+	if err != nil {
+		log.Fatalf("Invalid RECONCILE_INTERVAL: %v", err)
+	}
+	reconcilePolicy, err := services.ParseReconcilePolicy(reconcileRepair)
+	if err != nil {
+		log.Fatalf("Invalid RECONCILE_REPAIR: %v", err)
+	}
+	reconcileSvc := services.NewReconcileService(repositories.NewReconcileRepository(db), reconcilePolicy)
+	go jobs.Every("reconcile", interval, reconcileSvc.RunReconcile)
 
 	lis, err := net.Listen("tcp", ":50054")
 	if err != nil {
 		log.Fatalf("Error listening to port 50054: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	syncInterval, err := time.ParseDuration(jwksSyncInterval)
+	if err != nil {
+		log.Fatalf("Invalid JWKS_SYNC_INTERVAL: %v", err)
+	}
+	authClient := pb.NewAuthServiceClient(authConn)
+	keys := rbac.NewKeySet(secretKey, rbac.FetchJWKS(authClient))
+	go keys.Sync(syncInterval)
+	validator, err := rbac.NewValidator(tokenValidation, keys, authClient)
+	if err != nil {
+		log.Fatalf("Invalid TOKEN_VALIDATION: %v", err)
+	}
+	// Los adjuntos viajan en un solo mensaje, se deja margen sobre el tamaño máximo
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(maxBytes+1<<20),
+		grpc.UnaryInterceptor(rbac.UnaryServerInterceptor(validator, handlers.Permissions)),
+		grpc.StreamInterceptor(rbac.StreamServerInterceptor(validator, handlers.Permissions)))
 	pb.RegisterAgendaServiceServer(grpcServer, handler)
+	pb.RegisterResourceServiceServer(grpcServer, resourceHandler)
+	pb.RegisterAvailabilityServiceServer(grpcServer, availabilityHandler)
+	pb.RegisterPaymentServiceServer(grpcServer, handlers.NewPaymentHandler(paymentSvc))
+	pb.RegisterNoteServiceServer(grpcServer, handlers.NewNoteHandler(noteSvc))
+	pb.RegisterPackageServiceServer(grpcServer, handlers.NewPackageHandler(services.NewPackageService(repositories.NewPackageRepository(db))))
+	pb.RegisterReconcileServiceServer(grpcServer, handlers.NewReconcileHandler(reconcileSvc))
 
 	log.Println("Server runing on port :50054...")
 	if err := grpcServer.Serve(lis); err != nil {
@@ -39,8 +39,8 @@ func TestCreateSlotRepo(t *testing.T) {
 			slot: &models.Slot{ProfessionalID: 1, StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute), Available: true},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
-				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "slots" ("professional_id","start_time","end_time","available") VALUES ($1,$2,$3,$4) RETURNING "id"`)).
-					WithArgs(uint(1), sqlmock.AnyArg(), sqlmock.AnyArg(), true).
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "slots" ("professional_id","start_time","end_time","available","location_id","released") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+					WithArgs(uint(1), sqlmock.AnyArg(), sqlmock.AnyArg(), true, uint(0), false).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 				mock.ExpectCommit()
 			},
@@ -51,8 +51,8 @@ func TestCreateSlotRepo(t *testing.T) {
 			slot: &models.Slot{ProfessionalID: 1, StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute), Available: true},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
-				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "slots" ("professional_id","start_time","end_time","available") VALUES ($1,$2,$3,$4) RETURNING "id"`)).
-					WithArgs(uint(1), sqlmock.AnyArg(), sqlmock.AnyArg(), true).
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "slots" ("professional_id","start_time","end_time","available","location_id","released") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+					WithArgs(uint(1), sqlmock.AnyArg(), sqlmock.AnyArg(), true, uint(0), false).
 					WillReturnError(errors.New("db error"))
 				mock.ExpectRollback()
 			},
@@ -99,7 +99,7 @@ func TestListAvailableSlotsRepo(t *testing.T) {
 					AddRow(1, 1, startTime, endTime, true)
 				startOfDay := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
 				endOfDay := startOfDay.Add(24 * time.Hour)
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "slots" WHERE professional_id = $1 AND start_time >= $2 AND start_time < $3 AND available = $4`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "slots" WHERE professional_id = $1 AND (start_time >= $2 AND start_time < $3 AND available = $4)`)).
 					WithArgs(1, startOfDay, endOfDay, true).
 					WillReturnRows(rows)
 			},
@@ -116,7 +116,7 @@ func TestListAvailableSlotsRepo(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "professional_id", "start_time", "end_time", "available"})
 				startOfDay := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
 				endOfDay := startOfDay.Add(24 * time.Hour)
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "slots" WHERE professional_id = $1 AND start_time >= $2 AND start_time < $3 AND available = $4`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "slots" WHERE professional_id = $1 AND (start_time >= $2 AND start_time < $3 AND available = $4)`)).
 					WithArgs(1, startOfDay, endOfDay, true).
 					WillReturnRows(rows)
 			},
@@ -128,7 +128,7 @@ func TestListAvailableSlotsRepo(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup(mock)
-			slots, err := repo.ListAvailableSlots(tt.professionalID, tt.date)
+			slots, err := repo.ListAvailableSlots(tt.professionalID, 0, tt.date, tt.date.Add(24*time.Hour))
 			assert.Equal(t, tt.expectedSlots, slots)
 			assert.NoError(t, err)
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -151,8 +151,8 @@ func TestCreateAppointment(t *testing.T) {
 			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ProfessionalID: 2},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
-				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","slot_id","professional_id") VALUES ($1,$2,$3) RETURNING "id"`)).
-					WithArgs(uint(1), uint(1), uint(2)).
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","dependent_id","slot_id","professional_id","service_id","location_id","status","review_requested_at","approval_deadline","confirmed_at","cancelled_at","modality","meeting_id","meeting_url") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+					WithArgs(uint(1), uint(0), uint(1), uint(2), uint(0), uint(0), "booked", nil, nil, nil, nil, "in_person", "", "").
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 				mock.ExpectCommit()
 			},
@@ -163,8 +163,8 @@ func TestCreateAppointment(t *testing.T) {
 			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ProfessionalID: 2},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
-				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","slot_id","professional_id") VALUES ($1,$2,$3) RETURNING "id"`)).
-					WithArgs(uint(1), uint(1), uint(2)).
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","dependent_id","slot_id","professional_id","service_id","location_id","status","review_requested_at","approval_deadline","confirmed_at","cancelled_at","modality","meeting_id","meeting_url") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+					WithArgs(uint(1), uint(0), uint(1), uint(2), uint(0), uint(0), "booked", nil, nil, nil, nil, "in_person", "", "").
 					WillReturnError(errors.New("db error"))
 				mock.ExpectRollback()
 			},
@@ -341,3 +341,643 @@ func TestGetSlotByID(t *testing.T) {
 		})
 	}
 }
+
+func TestBookSlot(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	startTime := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(30 * time.Minute)
+	slotColumns := []string{"id", "professional_id", "start_time", "end_time", "available"}
+	lockSlot := regexp.QuoteMeta(`SELECT * FROM "slots" WHERE "slots"."id" = $1 ORDER BY "slots"."id" LIMIT $2 FOR UPDATE`)
+	lockCredits := regexp.QuoteMeta(`SELECT "client_packages"."id","client_packages"."client_id","client_packages"."package_id","client_packages"."credits","client_packages"."remaining","client_packages"."expires_at","client_packages"."created_at" FROM "client_packages" JOIN package_services ON package_services.package_id = client_packages.package_id WHERE client_packages.client_id = $1 AND package_services.service_id = $2 AND client_packages.remaining > 0 AND client_packages.expires_at > $3 ORDER BY client_packages.expires_at,"client_packages"."id" LIMIT $4 FOR UPDATE OF "client_packages"`)
+
+	tests := []struct {
+		name        string
+		appointment *models.Appointment
+		resourceIDs []uint
+		mockSetup   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name:        "SuccessWithResources",
+			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ServiceID: 3},
+			resourceIDs: []uint{4},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSlot).WithArgs(uint(1), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(1, 2, startTime, endTime, true))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "resources" WHERE id IN ($1) ORDER BY id FOR UPDATE`)).
+					WithArgs(uint(4)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type"}).AddRow(4, "Sala 1", "room"))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "resource_reservations" WHERE resource_id IN ($1) AND start_time < $2 AND end_time > $3`)).
+					WithArgs(uint(4), endTime, startTime).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","dependent_id","slot_id","professional_id","service_id","location_id","status","review_requested_at","approval_deadline","confirmed_at","cancelled_at","modality","meeting_id","meeting_url") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+					WithArgs(uint(1), uint(0), uint(1), uint(2), uint(3), uint(0), "booked", nil, nil, nil, nil, "in_person", "", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "resource_reservations" ("resource_id","appointment_id","start_time","end_time","reason") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+					WithArgs(uint(4), uint(7), startTime, endTime, "").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(false, uint(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			// Un recurso repetido se bloquea y reserva una sola vez
+			name:        "DuplicatedResource",
+			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ServiceID: 3},
+			resourceIDs: []uint{4, 4},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSlot).WithArgs(uint(1), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(1, 2, startTime, endTime, true))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "resources" WHERE id IN ($1) ORDER BY id FOR UPDATE`)).
+					WithArgs(uint(4)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type"}).AddRow(4, "Sala 1", "room"))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "resource_reservations" WHERE resource_id IN ($1) AND start_time < $2 AND end_time > $3`)).
+					WithArgs(uint(4), endTime, startTime).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","dependent_id","slot_id","professional_id","service_id","location_id","status","review_requested_at","approval_deadline","confirmed_at","cancelled_at","modality","meeting_id","meeting_url") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+					WithArgs(uint(1), uint(0), uint(1), uint(2), uint(3), uint(0), "booked", nil, nil, nil, nil, "in_person", "", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "resource_reservations" ("resource_id","appointment_id","start_time","end_time","reason") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+					WithArgs(uint(4), uint(7), startTime, endTime, "").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(false, uint(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "SuccessWithPayment",
+			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ServiceID: 3, Status: models.AppointmentPaymentPending,
+				Payment: &models.Payment{Provider: "fake", IntentID: "fake_pi_1", AmountCents: 2000, Currency: "USD", Status: models.PaymentPending}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSlot).WithArgs(uint(1), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(1, 2, startTime, endTime, true))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","dependent_id","slot_id","professional_id","service_id","location_id","status","review_requested_at","approval_deadline","confirmed_at","cancelled_at","modality","meeting_id","meeting_url") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+					WithArgs(uint(1), uint(0), uint(1), uint(2), uint(3), uint(0), "payment_pending", nil, nil, nil, nil, "in_person", "", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "payments" ("appointment_id","provider","intent_id","amount_cents","currency","status","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`)).
+					WithArgs(uint(7), "fake", "fake_pi_1", uint(2000), "USD", "pending", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(false, uint(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name:        "SuccessWithCredit",
+			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ServiceID: 3, Status: models.AppointmentBooked, Credit: &models.CreditTransaction{}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSlot).WithArgs(uint(1), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(1, 2, startTime, endTime, true))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","dependent_id","slot_id","professional_id","service_id","location_id","status","review_requested_at","approval_deadline","confirmed_at","cancelled_at","modality","meeting_id","meeting_url") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+					WithArgs(uint(1), uint(0), uint(1), uint(2), uint(3), uint(0), "booked", nil, nil, nil, nil, "in_person", "", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(lockCredits).WithArgs(uint(1), uint(3), startTime, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "package_id", "remaining"}).AddRow(5, 1, 2, 3))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "client_packages" SET "remaining"=remaining - 1 WHERE id = $1`)).
+					WithArgs(uint(5)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "credit_transactions" ("client_package_id","client_id","appointment_id","kind","delta","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+					WithArgs(uint(5), uint(1), uint(7), "use", -1, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(false, uint(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name:        "NoCreditsLeft",
+			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ServiceID: 3, Status: models.AppointmentBooked, Credit: &models.CreditTransaction{}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSlot).WithArgs(uint(1), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(1, 2, startTime, endTime, true))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(lockCredits).WithArgs(uint(1), uint(3), startTime, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrNoCredits,
+		},
+		{
+			name:        "SlotNotAvailable",
+			appointment: &models.Appointment{ClientID: 1, SlotID: 1},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSlot).WithArgs(uint(1), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(1, 2, startTime, endTime, false))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrSlotNotAvailable,
+		},
+		{
+			name:        "ResourceNotAvailable",
+			appointment: &models.Appointment{ClientID: 1, SlotID: 1, ServiceID: 3},
+			resourceIDs: []uint{4},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSlot).WithArgs(uint(1), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(1, 2, startTime, endTime, true))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "resources" WHERE id IN ($1) ORDER BY id FOR UPDATE`)).
+					WithArgs(uint(4)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type"}).AddRow(4, "Sala 1", "room"))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "resource_reservations" WHERE resource_id IN ($1) AND start_time < $2 AND end_time > $3`)).
+					WithArgs(uint(4), endTime, startTime).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrResourceNotAvailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			slot, err := repo.BookSlot(tt.appointment, tt.resourceIDs)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, slot)
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, slot.Available)
+				assert.Equal(t, uint(2), tt.appointment.ProfessionalID)
+				assert.Equal(t, uint(7), tt.appointment.ID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBookComputedSlot(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	startTime := time.Date(2030, 3, 11, 9, 30, 0, 0, time.UTC)
+	endTime := startTime.Add(30 * time.Minute)
+	settingsColumns := []string{"professional_id", "availability_mode", "timezone", "default_duration_minutes", "step_minutes", "buffer_minutes"}
+	lockSettings := regexp.QuoteMeta(`SELECT * FROM "professional_settings" WHERE professional_id = $1 ORDER BY "professional_settings"."professional_id" LIMIT $2 FOR UPDATE`)
+	countBooked := regexp.QuoteMeta(`SELECT count(*) FROM "slots" WHERE professional_id = $1 AND available = $2 AND released = $3 AND start_time < $4 AND end_time > $5`)
+	countTimeOff := regexp.QuoteMeta(`SELECT count(*) FROM "time_offs" WHERE professional_id = $1 AND start_time < $2 AND end_time > $3`)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSettings).WithArgs(uint(3), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns).AddRow(3, "computed", "UTC", 30, 0, 10))
+				mock.ExpectQuery(countBooked).
+					WithArgs(uint(3), false, false, endTime.Add(10*time.Minute), startTime.Add(-10*time.Minute)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(countTimeOff).WithArgs(uint(3), endTime, startTime).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "slots" ("professional_id","start_time","end_time","available","location_id","released") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+					WithArgs(uint(3), startTime, endTime, true, uint(2), false).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "appointments" ("client_id","dependent_id","slot_id","professional_id","service_id","location_id","status","review_requested_at","approval_deadline","confirmed_at","cancelled_at","modality","meeting_id","meeting_url") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+					WithArgs(uint(1), uint(0), uint(10), uint(3), uint(0), uint(2), "booked", nil, nil, nil, nil, "in_person", "", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(false, uint(10)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "BookedWithinBuffer",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSettings).WithArgs(uint(3), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns).AddRow(3, "computed", "UTC", 30, 0, 10))
+				mock.ExpectQuery(countBooked).
+					WithArgs(uint(3), false, false, endTime.Add(10*time.Minute), startTime.Add(-10*time.Minute)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrSlotNotAvailable,
+		},
+		{
+			name: "SwitchedToMaterialized",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockSettings).WithArgs(uint(3), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns).AddRow(3, "materialized", "UTC", 30, 0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrSlotNotAvailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			appointment := &models.Appointment{ClientID: 1}
+			slot := &models.Slot{ProfessionalID: 3, StartTime: startTime, EndTime: endTime, Available: true, LocationID: 2}
+			err := repo.BookComputedSlot(appointment, slot, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(10), appointment.SlotID)
+				assert.Equal(t, uint(7), appointment.ID)
+				assert.False(t, slot.Available)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListReviewRequestsDue(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	endedBefore := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "client_id", "slot_id", "professional_id", "status"}).
+		AddRow(1, 5, 3, 2, "completed")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "appointments"."id","appointments"."client_id","appointments"."dependent_id","appointments"."slot_id","appointments"."professional_id","appointments"."service_id","appointments"."location_id","appointments"."status","appointments"."review_requested_at","appointments"."approval_deadline","appointments"."confirmed_at","appointments"."cancelled_at","appointments"."modality","appointments"."meeting_id","appointments"."meeting_url" FROM "appointments" JOIN slots ON slots.id = appointments.slot_id WHERE appointments.status = $1 AND appointments.review_requested_at IS NULL AND slots.end_time <= $2`)).
+		WithArgs("completed", endedBefore).
+		WillReturnRows(rows)
+
+	appointments, err := repo.ListReviewRequestsDue(endedBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Appointment{{ID: 1, ClientID: 5, SlotID: 3, ProfessionalID: 2, Status: "completed"}}, appointments)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseAppointment(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	lockAppointment := regexp.QuoteMeta(`SELECT * FROM "appointments" WHERE "appointments"."id" = $1 ORDER BY "appointments"."id" LIMIT $2 FOR UPDATE`)
+	columns := []string{"id", "client_id", "slot_id", "professional_id", "status"}
+	getSettings := regexp.QuoteMeta(`SELECT * FROM "professional_settings" WHERE professional_id = $1 ORDER BY "professional_settings"."professional_id" LIMIT $2`)
+	settingsColumns := []string{"professional_id", "availability_mode"}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockAppointment).WithArgs(uint(7), 1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(7, 1, 3, 2, "payment_pending"))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "appointments" SET "status"=$1 WHERE id = $2`)).
+					WithArgs("expired", uint(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(getSettings).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(true, uint(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "resource_reservations" WHERE appointment_id = $1`)).
+					WithArgs(uint(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			// El slot se creó para la cita, no se ofrece a otros por su id
+			name: "ComputedSlotReleased",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockAppointment).WithArgs(uint(7), 1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(7, 1, 3, 2, "payment_pending"))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "appointments" SET "status"=$1 WHERE id = $2`)).
+					WithArgs("expired", uint(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(getSettings).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns).AddRow(2, "computed"))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "released"=$1 WHERE id = $2`)).
+					WithArgs(true, uint(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "resource_reservations" WHERE appointment_id = $1`)).
+					WithArgs(uint(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "AlreadyBooked",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockAppointment).WithArgs(uint(7), 1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(7, 1, 3, 2, "booked"))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrStatusChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			err := repo.ReleaseAppointment(7, []string{models.AppointmentPaymentPending}, models.AppointmentExpired)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUseActionLink(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	insertLink := regexp.QuoteMeta(`INSERT INTO "action_links" ("id","appointment_id","action","used_at") VALUES ($1,$2,$3,$4) ON CONFLICT DO NOTHING`)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "FirstUse",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertLink).WithArgs("abc", uint(7), "cancel", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "AlreadyUsed",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertLink).WithArgs("abc", uint(7), "cancel", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedErr: repositories.ErrLinkUsed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			err := repo.UseActionLink(&models.ActionLink{ID: "abc", AppointmentID: 7, Action: models.ActionCancel, UsedAt: time.Now()})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRescheduleAppointmentRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	lockAppointment := regexp.QuoteMeta(`SELECT * FROM "appointments" WHERE "appointments"."id" = $1 ORDER BY "appointments"."id" LIMIT $2 FOR UPDATE`)
+	lockSlot := regexp.QuoteMeta(`SELECT * FROM "slots" WHERE "slots"."id" = $1 ORDER BY "slots"."id" LIMIT $2 FOR UPDATE`)
+	appointmentColumns := []string{"id", "client_id", "slot_id", "professional_id", "status"}
+	slotColumns := []string{"id", "professional_id", "start_time", "end_time", "available"}
+	getSettings := regexp.QuoteMeta(`SELECT * FROM "professional_settings" WHERE professional_id = $1 ORDER BY "professional_settings"."professional_id" LIMIT $2`)
+	startTime := time.Date(2025, 3, 11, 10, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(30 * time.Minute)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockAppointment).WithArgs(uint(7), 1).
+					WillReturnRows(sqlmock.NewRows(appointmentColumns).AddRow(7, 1, 3, 2, "booked"))
+				mock.ExpectQuery(lockSlot).WithArgs(uint(4), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(4, 2, startTime, endTime, true))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "resource_reservations" WHERE appointment_id = $1`)).
+					WithArgs(uint(7)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "appointments" SET "location_id"=$1,"professional_id"=$2,"slot_id"=$3 WHERE id = $4`)).
+					WithArgs(uint(0), uint(2), uint(4), uint(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(false, uint(4)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(getSettings).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows([]string{"professional_id", "availability_mode"}).AddRow(2, "materialized"))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "slots" SET "available"=$1 WHERE id = $2`)).
+					WithArgs(true, uint(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "SlotOfAnotherProfessional",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockAppointment).WithArgs(uint(7), 1).
+					WillReturnRows(sqlmock.NewRows(appointmentColumns).AddRow(7, 1, 3, 2, "booked"))
+				mock.ExpectQuery(lockSlot).WithArgs(uint(4), 1).
+					WillReturnRows(sqlmock.NewRows(slotColumns).AddRow(4, 9, startTime, endTime, true))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrSlotNotAvailable,
+		},
+		{
+			name: "AlreadyCancelled",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockAppointment).WithArgs(uint(7), 1).
+					WillReturnRows(sqlmock.NewRows(appointmentColumns).AddRow(7, 1, 3, 2, "cancelled"))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrStatusChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			slot := &models.Slot{ID: 4}
+			err := repo.RescheduleAppointment(7, []string{models.AppointmentBooked}, slot, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, startTime, slot.StartTime)
+				assert.False(t, slot.Available)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestConfirmPayment(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	deadline := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "slot_id", "status", "approval_deadline"}
+
+	tests := []struct {
+		name           string
+		rows           *sqlmock.Rows
+		expectedStatus string
+	}{
+		{
+			name:           "Booked",
+			rows:           sqlmock.NewRows(columns).AddRow(7, 3, "payment_pending", nil),
+			expectedStatus: "booked",
+		},
+		{
+			// Si el profesional aprueba las reservas, la solicitud pasa a esperar su respuesta
+			name:           "WaitsForApproval",
+			rows:           sqlmock.NewRows(columns).AddRow(7, 3, "payment_pending", deadline),
+			expectedStatus: "pending_approval",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "appointments" WHERE "appointments"."id" = $1 ORDER BY "appointments"."id" LIMIT $2 FOR UPDATE`)).
+				WithArgs(uint(7), 1).
+				WillReturnRows(tt.rows)
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "appointments" SET "status"=$1 WHERE id = $2`)).
+				WithArgs(tt.expectedStatus, uint(7)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "payments" SET "status"=$1,"updated_at"=$2 WHERE id = $3`)).
+				WithArgs("captured", sqlmock.AnyArg(), uint(1)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			appointment, err := repo.ConfirmPayment(&models.Payment{ID: 1, AppointmentID: 7})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, appointment.Status)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListExpiredApprovals(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "appointments" WHERE status = $1 AND approval_deadline <= $2`)).
+		WithArgs("pending_approval", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "slot_id", "status"}).AddRow(7, 1, 3, "pending_approval"))
+
+	appointments, err := repo.ListExpiredApprovals(now)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Appointment{{ID: 7, ClientID: 1, SlotID: 3, Status: "pending_approval"}}, appointments)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListPendingPayments(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	createdBefore := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "payments" WHERE status = $1 AND created_at < $2`)).
+		WithArgs("pending", createdBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "appointment_id", "intent_id", "status"}).AddRow(1, 7, "fake_pi_1", "pending"))
+
+	payments, err := repo.ListPendingPayments(createdBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Payment{{ID: 1, AppointmentID: 7, IntentID: "fake_pi_1", Status: "pending"}}, payments)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefundCreditRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	findUse := regexp.QuoteMeta(`SELECT * FROM "credit_transactions" WHERE appointment_id = $1 AND kind = $2 ORDER BY "credit_transactions"."id" LIMIT $3`)
+	countRefunds := regexp.QuoteMeta(`SELECT count(*) FROM "credit_transactions" WHERE appointment_id = $1 AND kind = $2`)
+	useColumns := []string{"id", "client_package_id", "client_id", "appointment_id", "kind", "delta"}
+
+	tests := []struct {
+		name             string
+		mockSetup        func(sqlmock.Sqlmock)
+		expectedRefunded bool
+	}{
+		{
+			name: "Refunded",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(findUse).WithArgs(uint(7), "use", 1).
+					WillReturnRows(sqlmock.NewRows(useColumns).AddRow(1, 5, 1, 7, "use", -1))
+				mock.ExpectQuery(countRefunds).WithArgs(uint(7), "refund").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "client_packages" SET "remaining"=remaining + 1 WHERE id = $1`)).
+					WithArgs(uint(5)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "credit_transactions" ("client_package_id","client_id","appointment_id","kind","delta","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+					WithArgs(uint(5), uint(1), uint(7), "refund", 1, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectCommit()
+			},
+			expectedRefunded: true,
+		},
+		{
+			name: "AlreadyRefunded",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(findUse).WithArgs(uint(7), "use", 1).
+					WillReturnRows(sqlmock.NewRows(useColumns).AddRow(1, 5, 1, 7, "use", -1))
+				mock.ExpectQuery(countRefunds).WithArgs(uint(7), "refund").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			expectedRefunded: false,
+		},
+		{
+			name: "NotPaidWithCredits",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(findUse).WithArgs(uint(7), "use", 1).
+					WillReturnRows(sqlmock.NewRows(useColumns))
+				mock.ExpectCommit()
+			},
+			expectedRefunded: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			refunded, err := repo.RefundCredit(7)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRefunded, refunded)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
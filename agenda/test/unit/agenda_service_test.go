@@ -6,12 +6,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/meetings"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/payments"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
 	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 type MockAgendaRepository struct {
@@ -23,8 +28,8 @@ func (m *MockAgendaRepository) CreateSlot(slot *models.Slot) error {
 	return args.Error(0)
 }
 
-func (m *MockAgendaRepository) ListAvailableSlots(professionalID uint, date time.Time) ([]models.Slot, error) {
-	args := m.Called(professionalID, date)
+func (m *MockAgendaRepository) ListAvailableSlots(professionalID, locationID uint, from, to time.Time) ([]models.Slot, error) {
+	args := m.Called(professionalID, locationID, from, to)
 	return args.Get(0).([]models.Slot), args.Error(1)
 }
 
@@ -48,6 +53,139 @@ func (m *MockAgendaRepository) GetSlotByID(slotID uint) (*models.Slot, error) {
 	return args.Get(0).(*models.Slot), args.Error(1)
 }
 
+func (m *MockAgendaRepository) ListBookedSlots(professionalID uint, from, to time.Time) ([]models.Slot, error) {
+	args := m.Called(professionalID, from, to)
+	return args.Get(0).([]models.Slot), args.Error(1)
+}
+
+func (m *MockAgendaRepository) BookComputedSlot(appointment *models.Appointment, slot *models.Slot, resourceIDs []uint) error {
+	args := m.Called(appointment, slot, resourceIDs)
+	if args.Error(0) == nil {
+		appointment.ID = 1
+		appointment.SlotID = 10
+	}
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) GetAppointmentByID(id uint) (*models.Appointment, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Appointment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) UpdateAppointmentStatus(id uint, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ListReviewRequestsDue(endedBefore time.Time) ([]models.Appointment, error) {
+	args := m.Called(endedBefore)
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) MarkReviewRequested(id uint, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ReleaseAppointment(id uint, from []string, status string) error {
+	args := m.Called(id, from, status)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ConfirmPayment(payment *models.Payment) (*models.Appointment, error) {
+	args := m.Called(payment)
+	return args.Get(0).(*models.Appointment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) TransitionAppointment(id uint, from []string, status string) error {
+	args := m.Called(id, from, status)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ListExpiredApprovals(now time.Time) ([]models.Appointment, error) {
+	args := m.Called(now)
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) GetPaymentByIntent(intentID string) (*models.Payment, error) {
+	args := m.Called(intentID)
+	return args.Get(0).(*models.Payment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) GetPaymentByAppointment(appointmentID uint) (*models.Payment, error) {
+	args := m.Called(appointmentID)
+	return args.Get(0).(*models.Payment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) UpdatePaymentStatus(id uint, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ListPendingPayments(createdBefore time.Time) ([]models.Payment, error) {
+	args := m.Called(createdBefore)
+	return args.Get(0).([]models.Payment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) UseActionLink(link *models.ActionLink) error {
+	args := m.Called(link)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ReleaseActionLink(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ConfirmAppointment(id uint, from []string, at time.Time) error {
+	args := m.Called(id, from, at)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) RescheduleAppointment(id uint, from []string, slot *models.Slot, resourceIDs []uint) error {
+	args := m.Called(id, from, slot, resourceIDs)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) ListProfessionalAppointments(professionalID uint, statuses []string, from, to time.Time) ([]models.Appointment, error) {
+	args := m.Called(professionalID, statuses, from, to)
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) ListCancellations(professionalID uint, since time.Time) ([]models.Appointment, error) {
+	args := m.Called(professionalID, since)
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockAgendaRepository) ClearMeetingURL(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) RefundCredit(appointmentID uint) (bool, error) {
+	args := m.Called(appointmentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAgendaRepository) GetIntakeResponse(appointmentID uint) (*models.IntakeResponse, error) {
+	args := m.Called(appointmentID)
+	return args.Get(0).(*models.IntakeResponse), args.Error(1)
+}
+
+func (m *MockAgendaRepository) ReassignAppointments(from []string, moves []repositories.AppointmentMove) error {
+	args := m.Called(from, moves)
+	return args.Error(0)
+}
+
+func (m *MockAgendaRepository) BookSlot(appointment *models.Appointment, resourceIDs []uint) (*models.Slot, error) {
+	args := m.Called(appointment, resourceIDs)
+	if slot, ok := args.Get(0).(*models.Slot); ok && slot != nil {
+		appointment.ID = 1
+		appointment.ProfessionalID = slot.ProfessionalID
+	}
+	return args.Get(0).(*models.Slot), args.Error(1)
+}
+
 // Mock para NotificationServiceClient
 type MockNotificationServiceClient struct {
 	mock.Mock
@@ -58,11 +196,72 @@ func (m *MockNotificationServiceClient) SendAppointmentNotification(ctx context.
 	return args.Get(0).(*pb.SendAppointmentNotificationResponse), args.Error(1)
 }
 
+func (m *MockNotificationServiceClient) SendAppointmentUpdate(ctx context.Context, in *pb.SendAppointmentUpdateRequest, opts ...grpc.CallOption) (*pb.SendAppointmentUpdateResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.SendAppointmentUpdateResponse), args.Error(1)
+}
+
+func (m *MockNotificationServiceClient) SendDailyDigest(ctx context.Context, in *pb.SendDailyDigestRequest, opts ...grpc.CallOption) (*pb.SendDailyDigestResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.SendDailyDigestResponse), args.Error(1)
+}
+
+func (m *MockNotificationServiceClient) SendReviewRequest(ctx context.Context, in *pb.SendReviewRequestRequest, opts ...grpc.CallOption) (*pb.SendReviewRequestResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.SendReviewRequestResponse), args.Error(1)
+}
+
+func (m *MockNotificationServiceClient) SendPasswordReset(ctx context.Context, in *pb.SendPasswordResetRequest, opts ...grpc.CallOption) (*pb.SendPasswordResetResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.SendPasswordResetResponse), args.Error(1)
+}
+
+func (m *MockNotificationServiceClient) SendEmailVerification(ctx context.Context, in *pb.SendEmailVerificationRequest, opts ...grpc.CallOption) (*pb.SendEmailVerificationResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.SendEmailVerificationResponse), args.Error(1)
+}
+
+type MockClientServiceClient struct {
+	mock.Mock
+	pb.ClientServiceClient
+}
+
+func (m *MockClientServiceClient) GetDependent(ctx context.Context, in *pb.GetDependentRequest, opts ...grpc.CallOption) (*pb.GetDependentResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.GetDependentResponse), args.Error(1)
+}
+
+// Los clientes gRPC embeben la interfaz para sólo implementar los métodos usados
+type MockProfessionalServiceClient struct {
+	mock.Mock
+	pb.ProfessionalServiceClient
+}
+
+func (m *MockProfessionalServiceClient) GetProfessional(ctx context.Context, in *pb.GetProfessionalRequest, opts ...grpc.CallOption) (*pb.GetProfessionalResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.GetProfessionalResponse), args.Error(1)
+}
+
+func (m *MockProfessionalServiceClient) ListProfessionals(ctx context.Context, in *pb.ListProfessionalsRequest, opts ...grpc.CallOption) (*pb.ListProfessionalsResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.ListProfessionalsResponse), args.Error(1)
+}
+
+type MockLocationServiceClient struct {
+	mock.Mock
+	pb.LocationServiceClient
+}
+
+func (m *MockLocationServiceClient) GetLocation(ctx context.Context, in *pb.GetLocationRequest, opts ...grpc.CallOption) (*pb.GetLocationResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.GetLocationResponse), args.Error(1)
+}
+
 func TestCreateSlot(t *testing.T) {
 	mockRepo := new(MockAgendaRepository)
 	mockNotif := new(MockNotificationServiceClient)
 	// Creamos el servicio con un *grpc.ClientConn dummy (nil), y luego inyectamos el mock
-	srv := services.NewAgendaService(mockRepo, nil)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
 	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif // Inyectamos el mock después
 
 	tests := []struct {
@@ -102,7 +301,7 @@ func TestCreateSlot(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			resp, err := srv.CreateSlot(tt.req)
+			resp, err := srv.CreateSlot(context.Background(), tt.req)
 			assert.Equal(t, tt.expectedResp.Message, resp.Message)
 			assert.Equal(t, tt.expectedResp.Success, resp.Success)
 			/*if tt.expectedResp.Success {
@@ -119,10 +318,100 @@ func TestCreateSlot(t *testing.T) {
 	}
 }
 
+func TestCreateSlotWithLocation(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockProf := new(MockProfessionalServiceClient)
+	mockLocation := new(MockLocationServiceClient)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).ProfClient = mockProf
+	srv.(*services.AgendaServiceImpl).LocationClient = mockLocation
+
+	// Sucursal en Santiago (UTC-3 en marzo), abierta los lunes de 09:00 a 18:00
+	location := &pb.Location{Id: 2, Timezone: "America/Santiago",
+		OpeningHours: []*pb.OpeningHours{{Weekday: 1, Opens: "09:00", Closes: "18:00"}}}
+	assigned := &pb.Professional{Id: 1, Locations: []*pb.ProfessionalLocation{{LocationId: 2, Weekday: 1}}}
+
+	tests := []struct {
+		name         string
+		req          *pb.CreateSlotRequest
+		professional *pb.Professional
+		mockSetup    func()
+		expectedResp *pb.CreateSlotResponse
+	}{
+		{
+			name:         "Success",
+			req:          &pb.CreateSlotRequest{ProfessionalId: 1, StartTime: "2025-03-10T13:00:00Z", EndTime: "2025-03-10T13:30:00Z", LocationId: 2},
+			professional: assigned,
+			mockSetup: func() {
+				(mockRepo).On("CreateSlot", mock.MatchedBy(func(slot *models.Slot) bool { return slot.LocationID == 2 })).Return(nil).Once()
+			},
+			expectedResp: &pb.CreateSlotResponse{Message: "Slot created", Success: true},
+		},
+		{
+			name:         "NotAssignedThatDay",
+			req:          &pb.CreateSlotRequest{ProfessionalId: 1, StartTime: "2025-03-11T13:00:00Z", EndTime: "2025-03-11T13:30:00Z", LocationId: 2},
+			professional: assigned,
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateSlotResponse{Message: "Professional is not assigned to this location on that day", Success: false},
+		},
+		{
+			name:         "OutsideOpeningHours",
+			req:          &pb.CreateSlotRequest{ProfessionalId: 1, StartTime: "2025-03-10T11:00:00Z", EndTime: "2025-03-10T11:30:00Z", LocationId: 2},
+			professional: assigned,
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateSlotResponse{Message: "Slot is outside the location opening hours", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			(mockLocation).On("GetLocation", mock.Anything, &pb.GetLocationRequest{Id: 2}).
+				Return(&pb.GetLocationResponse{Location: location, Success: true}, nil).Once()
+			(mockProf).On("GetProfessional", mock.Anything, &pb.GetProfessionalRequest{Id: 1}).
+				Return(&pb.GetProfessionalResponse{Professional: tt.professional, Success: true}, nil).Once()
+			tt.mockSetup()
+			resp, err := srv.CreateSlot(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			(mockRepo).AssertExpectations(t)
+			(mockProf).AssertExpectations(t)
+			(mockLocation).AssertExpectations(t)
+		})
+	}
+}
+
+func TestListAvailableSlotsByLocation(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockLocation := new(MockLocationServiceClient)
+	mockAvailability := new(MockAvailabilityRepository)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), mockAvailability, nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).LocationClient = mockLocation
+
+	santiago, _ := time.LoadLocation("America/Santiago")
+	startOfDay := time.Date(2025, 3, 10, 0, 0, 0, 0, santiago)
+	(mockLocation).On("GetLocation", mock.Anything, &pb.GetLocationRequest{Id: 2}).
+		Return(&pb.GetLocationResponse{Location: &pb.Location{Id: 2, Timezone: "America/Santiago"}, Success: true}, nil).Once()
+	(mockRepo).On("ListAvailableSlots", uint(0), uint(2), startOfDay, startOfDay.AddDate(0, 0, 1)).Return([]models.Slot{
+		{ID: 1, ProfessionalID: 1, LocationID: 2, StartTime: time.Date(2025, 3, 10, 13, 0, 0, 0, time.UTC), EndTime: time.Date(2025, 3, 10, 13, 30, 0, 0, time.UTC), Available: true},
+	}, nil).Once()
+	(mockAvailability).On("ListComputedProfessionals", uint(2)).Return([]uint{}, nil).Once()
+
+	resp, err := srv.ListAvailableSlots(&pb.ListAvailableSlotsRequest{LocationId: 2, Date: "2025-03-10"})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Slots, 1)
+	assert.Equal(t, uint32(2), resp.Slots[0].LocationId)
+	(mockRepo).AssertExpectations(t)
+	(mockLocation).AssertExpectations(t)
+	(mockAvailability).AssertExpectations(t)
+}
+
 func TestListAvailableSlots(t *testing.T) {
 	mockRepo := new(MockAgendaRepository)
+	mockResourceRepo := new(MockResourceRepository)
+	mockAvailability := new(MockAvailabilityRepository)
 	mockNotif := new(MockNotificationServiceClient)
-	srv := services.NewAgendaService(mockRepo, nil)
+	srv := services.NewAgendaService(mockRepo, mockResourceRepo, mockAvailability, nil, nil, services.BookingPolicy{}, nil, nil, nil)
 	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
 
 	tests := []struct {
@@ -137,7 +426,8 @@ func TestListAvailableSlots(t *testing.T) {
 			req:  &pb.ListAvailableSlotsRequest{ProfessionalId: 1, Date: "2025-03-10"},
 			mockSetup: func() {
 				start, _ := time.Parse("2006-01-02", "2025-03-10")
-				(mockRepo).On("ListAvailableSlots", uint(1), start).Return([]models.Slot{
+				(mockAvailability).On("GetSettings", uint(1)).Return(materialized(1), nil).Once()
+				(mockRepo).On("ListAvailableSlots", uint(1), uint(0), start, start.Add(24*time.Hour)).Return([]models.Slot{
 					{ID: 1, ProfessionalID: 1, StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute), Available: true},
 				}, nil).Once()
 			},
@@ -149,6 +439,29 @@ func TestListAvailableSlots(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "FiltersBusyResources",
+			req:  &pb.ListAvailableSlotsRequest{ProfessionalId: 1, Date: "2025-03-10", ServiceId: 3},
+			mockSetup: func() {
+				start, _ := time.Parse("2006-01-02", "2025-03-10")
+				first := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+				second := first.Add(30 * time.Minute)
+				(mockAvailability).On("GetSettings", uint(1)).Return(materialized(1), nil).Once()
+				(mockRepo).On("ListAvailableSlots", uint(1), uint(0), start, start.Add(24*time.Hour)).Return([]models.Slot{
+					{ID: 1, ProfessionalID: 1, StartTime: first, EndTime: second, Available: true},
+					{ID: 2, ProfessionalID: 1, StartTime: second, EndTime: second.Add(30 * time.Minute), Available: true},
+				}, nil).Once()
+				(mockResourceRepo).On("GetServiceByID", uint(3)).
+					Return(&models.Service{ID: 3, Resources: []models.Resource{{ID: 4}}}, nil).Once()
+				(mockResourceRepo).On("ListReservations", []uint{4}, first, second.Add(30*time.Minute)).
+					Return([]models.ResourceReservation{{ResourceID: 4, StartTime: first, EndTime: second}}, nil).Once()
+			},
+			expectedResp: &pb.ListAvailableSlotsResponse{
+				Slots:   []*pb.Slot{{Id: 2, ProfessionalId: 1, Available: true}},
+				Success: true,
+			},
+			expectedErr: nil,
+		},
 		{
 			name:         "InvalidDate",
 			req:          &pb.ListAvailableSlotsRequest{ProfessionalId: 1, Date: "invalid"},
@@ -179,16 +492,84 @@ func TestListAvailableSlots(t *testing.T) {
 				assert.NoError(t, err)
 			}
 			(mockRepo).AssertExpectations(t)
+			(mockResourceRepo).AssertExpectations(t)
+			(mockAvailability).AssertExpectations(t)
 			(mockNotif).AssertExpectations(t)
 		})
 	}
 }
 
+func TestListAvailableSlotsComputed(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockAvailability := new(MockAvailabilityRepository)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), mockAvailability, nil, nil, services.BookingPolicy{}, nil, nil, nil)
+
+	// 2030-03-11 es lunes, jornada de 09:00 a 11:00 con citas de 30 minutos
+	day := time.Date(2030, 3, 11, 0, 0, 0, 0, time.UTC)
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+	rules := []models.AvailabilityRule{{ProfessionalID: 1, Weekday: 1, StartTime: "09:00", EndTime: "11:00", LocationID: 2}}
+
+	tests := []struct {
+		name          string
+		bufferMinutes uint
+		timeOff       []models.TimeOff
+		booked        []models.Slot
+		expected      []time.Time
+	}{
+		{
+			name:     "WholeDay",
+			expected: []time.Time{at(9, 0), at(9, 30), at(10, 0), at(10, 30)},
+		},
+		{
+			name:          "BufferAroundBooked",
+			bufferMinutes: 15,
+			booked:        []models.Slot{{ProfessionalID: 1, StartTime: at(9, 30), EndTime: at(10, 0)}},
+			expected:      []time.Time{at(10, 30)},
+		},
+		{
+			name:     "TimeOff",
+			timeOff:  []models.TimeOff{{ProfessionalID: 1, StartTime: at(9, 0), EndTime: at(10, 0)}},
+			expected: []time.Time{at(10, 0), at(10, 30)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			(mockAvailability).On("GetSettings", uint(1)).Return(&models.ProfessionalSettings{ProfessionalID: 1,
+				AvailabilityMode: models.AvailabilityComputed, Timezone: "UTC", DefaultDurationMinutes: 30, BufferMinutes: tt.bufferMinutes}, nil).Once()
+			(mockAvailability).On("ListRules", uint(1)).Return(rules, nil).Once()
+			(mockAvailability).On("ListTimeOff", uint(1), day, day.Add(24*time.Hour+30*time.Minute)).Return(tt.timeOff, nil).Once()
+			(mockRepo).On("ListBookedSlots", uint(1), mock.Anything, mock.Anything).Return(tt.booked, nil).Once()
+
+			resp, err := srv.ListAvailableSlots(&pb.ListAvailableSlotsRequest{ProfessionalId: 1, Date: "2030-03-11"})
+			assert.NoError(t, err)
+			assert.True(t, resp.Success)
+			starts := make([]time.Time, len(resp.Slots))
+			for i, slot := range resp.Slots {
+				starts[i], _ = time.Parse(time.RFC3339, slot.StartTime)
+				assert.Zero(t, slot.Id, "Los slots calculados no tienen ID")
+				assert.Equal(t, uint32(2), slot.LocationId)
+			}
+			assert.Equal(t, tt.expected, starts)
+			(mockRepo).AssertExpectations(t)
+			(mockAvailability).AssertExpectations(t)
+		})
+	}
+}
+
 func TestBookAppointment(t *testing.T) {
 	mockRepo := new(MockAgendaRepository)
+	mockResourceRepo := new(MockResourceRepository)
+	mockAvailability := new(MockAvailabilityRepository)
 	mockNotif := new(MockNotificationServiceClient)
-	srv := services.NewAgendaService(mockRepo, nil)
+	meetingProvider := meetings.NewFakeProvider()
+	srv := services.NewAgendaService(mockRepo, mockResourceRepo, mockAvailability, payments.NewFakeProvider("secret"), meetingProvider,
+		services.BookingPolicy{}, nil, nil, nil)
 	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif // Inyectamos el mock después
+	mockClients := new(MockClientServiceClient)
+	srv.(*services.AgendaServiceImpl).ClientClient = mockClients
 
 	tests := []struct {
 		name         string
@@ -201,128 +582,1295 @@ func TestBookAppointment(t *testing.T) {
 			name: "Success",
 			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1},
 			mockSetup: func() {
-				(mockRepo).On("GetSlotByID", uint(1)).Return(&models.Slot{ID: 1, ProfessionalID: 2, Available: true}, nil).Once()
-				(mockRepo).On("CreateAppointment", mock.AnythingOfType("*models.Appointment")).Return(nil).Once()
-				(mockRepo).On("UpdateSlotAvailability", uint(1), false).Return(nil).Once()
-				(mockNotif).On("SendAppointmentNotification", nil, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 0, 2)
+				(mockRepo).On("BookSlot", mock.AnythingOfType("*models.Appointment"), []uint(nil)).
+					Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
 					Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
 			},
-			expectedResp: &pb.BookAppointmentResponse{Message: "Cita reservada exitosamente", Success: true, AppointmentId: 1},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
 			expectedErr:  nil,
 		},
 		{
-			name: "SlotNotFound",
-			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 999},
+			name: "SuccessWithServiceResources",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 3},
 			mockSetup: func() {
-				(mockRepo).On("GetSlotByID", uint(999)).Return((*models.Slot)(nil), errors.New("not found")).Once()
+				(mockResourceRepo).On("GetServiceByID", uint(3)).
+					Return(&models.Service{ID: 3, Resources: []models.Resource{{ID: 4}, {ID: 5}}}, nil).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 3, 2)
+				(mockRepo).On("BookSlot", mock.AnythingOfType("*models.Appointment"), []uint{4, 5}).
+					Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
+					Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
 			},
-			expectedResp: &pb.BookAppointmentResponse{Message: "Slot no encontrado", Success: false},
-			expectedErr:  errors.New("not found"),
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
+			expectedErr:  nil,
 		},
 		{
-			name: "SlotNotAvailable",
-			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1},
+			name: "DepositRequired",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 6},
 			mockSetup: func() {
-				(mockRepo).On("GetSlotByID", uint(1)).Return(&models.Slot{ID: 1, ProfessionalID: 2, Available: false}, nil).Once()
+				(mockResourceRepo).On("GetServiceByID", uint(6)).
+					Return(&models.Service{ID: 6, PriceCents: 5000, DepositCents: 2000, Currency: "USD"}, nil).Once()
+				// La cita queda pendiente de pago y no se notifica hasta capturarlo
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 6, 2)
+				(mockRepo).On("BookSlot", mock.MatchedBy(func(a *models.Appointment) bool {
+					return a.Status == models.AppointmentPaymentPending && a.Payment != nil &&
+						a.Payment.AmountCents == 2000 && a.Payment.Status == models.PaymentPending
+				}), []uint{}).Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
 			},
-			expectedResp: &pb.BookAppointmentResponse{Message: "El slot no está disponible", Success: false},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment pending payment", Success: true, AppointmentId: 1},
 			expectedErr:  nil,
 		},
 		{
-			name: "NotificationError",
-			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1},
+			name: "ForDependent",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, DependentId: 3},
 			mockSetup: func() {
-				(mockRepo).On("GetSlotByID", uint(1)).Return(&models.Slot{ID: 1, ProfessionalID: 2, Available: true}, nil).Once()
-				(mockRepo).On("CreateAppointment", mock.AnythingOfType("*models.Appointment")).Return(nil).Once()
-				(mockRepo).On("UpdateSlotAvailability", uint(1), false).Return(nil).Once()
-				(mockNotif).On("SendAppointmentNotification", nil, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
-					Return(&pb.SendAppointmentNotificationResponse{Message: "Error", Success: false}, errors.New("notification failed")).Once()
+				(mockClients).On("GetDependent", mock.Anything, &pb.GetDependentRequest{Id: 3}).
+					Return(&pb.GetDependentResponse{Dependent: &pb.Dependent{Id: 3, ClientId: 1, Name: "Sofia Perez"}, Success: true}, nil).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 0, 2)
+				(mockRepo).On("BookSlot", mock.MatchedBy(func(a *models.Appointment) bool {
+					return a.ClientID == 1 && a.DependentID == 3
+				}), []uint(nil)).Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				// El aviso va al cliente, que es el apoderado del dependiente
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentNotificationRequest) bool {
+					return r.ClientId == 1 && r.DependentId == 3
+				})).Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
 			},
-			expectedResp: &pb.BookAppointmentResponse{Message: "Cita reservada exitosamente", Success: true, AppointmentId: 1},
-			expectedErr:  nil, // Error de notificación no afecta la reserva
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
+			expectedErr:  nil,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tt.mockSetup()
-			resp, err := srv.BookAppointment(tt.req)
-			assert.Equal(t, tt.expectedResp.Message, resp.Message)
-			assert.Equal(t, tt.expectedResp.Success, resp.Success)
-			if tt.expectedResp.Success {
-				assert.NotZero(t, resp.AppointmentId, "AppointmentId debería asignarse")
-			}
-			assert.Equal(t, tt.expectedErr, err)
-			(mockRepo).AssertExpectations(t)
-			(mockNotif).AssertExpectations(t)
-		})
-	}
-}
-
-func TestListAppointments(t *testing.T) {
-	mockRepo := new(MockAgendaRepository)
-	mockNotif := new(MockNotificationServiceClient)
-	srv := services.NewAgendaService(mockRepo, nil)
-	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
-
-	tests := []struct {
-		name         string
-		req          *pb.ListAppointmentsRequest
-		mockSetup    func()
-		expectedResp *pb.ListAppointmentsResponse
-		expectedErr  error
-	}{
 		{
-			name: "Success",
-			req:  &pb.ListAppointmentsRequest{ClientId: 1},
+			name: "DependentOfAnotherClient",
+			req:  &pb.BookAppointmentRequest{ClientId: 5, SlotId: 1, DependentId: 3},
 			mockSetup: func() {
-				(mockRepo).On("ListAppointments", uint(1), uint(0)).Return([]models.Appointment{
-					{ID: 1, ClientID: 1, SlotID: 1, ProfessionalID: 2},
-				}, nil).Once()
-				(mockRepo).On("GetSlotByID", uint(1)).Return(&models.Slot{ID: 1, StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute)}, nil).Once()
+				(mockClients).On("GetDependent", mock.Anything, &pb.GetDependentRequest{Id: 3}).
+					Return(&pb.GetDependentResponse{Dependent: &pb.Dependent{Id: 3, ClientId: 1, Name: "Sofia Perez"}, Success: true}, nil).Once()
 			},
-			expectedResp: &pb.ListAppointmentsResponse{
-				Appointments: []*pb.Appointment{
-					{Id: 1, ClientId: 1, SlotId: 1, ProfessionalId: 2, StartTime: time.Now().Format(time.RFC3339), EndTime: time.Now().Add(30 * time.Minute).Format(time.RFC3339)},
-				},
-				Success: true,
+			expectedResp: &pb.BookAppointmentResponse{Message: "Dependent belongs to another client", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "PaidWithCredits",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 6},
+			mockSetup: func() {
+				(mockResourceRepo).On("GetServiceByID", uint(6)).
+					Return(&models.Service{ID: 6, PriceCents: 5000, DepositCents: 5000, Currency: "USD", RequiresCredits: true}, nil).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 6, 2)
+				// El crédito reemplaza el pago, la cita queda reservada de inmediato
+				(mockRepo).On("BookSlot", mock.MatchedBy(func(a *models.Appointment) bool {
+					return a.Status == models.AppointmentBooked && a.Credit != nil && a.Payment == nil
+				}), []uint{}).Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
+					Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
 			},
-			expectedErr: nil,
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
+			expectedErr:  nil,
 		},
 		{
-			name: "EmptyList",
-			req:  &pb.ListAppointmentsRequest{ClientId: 1},
+			name: "NoCreditsLeft",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 3, UseCredits: true},
 			mockSetup: func() {
-				(mockRepo).On("ListAppointments", uint(1), uint(0)).Return([]models.Appointment{}, nil).Once()
+				(mockResourceRepo).On("GetServiceByID", uint(3)).Return(&models.Service{ID: 3}, nil).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 3, 2)
+				(mockRepo).On("BookSlot", mock.MatchedBy(func(a *models.Appointment) bool {
+					return a.Credit != nil
+				}), []uint{}).Return((*models.Slot)(nil), repositories.ErrNoCredits).Once()
 			},
-			expectedResp: &pb.ListAppointmentsResponse{Appointments: []*pb.Appointment{}, Success: true},
+			expectedResp: &pb.BookAppointmentResponse{Message: "No credits left for this service", Success: false},
 			expectedErr:  nil,
 		},
 		{
-			name: "DatabaseError",
-			req:  &pb.ListAppointmentsRequest{ClientId: 1},
+			name: "CreditsWithoutService",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, UseCredits: true},
 			mockSetup: func() {
-				(mockRepo).On("ListAppointments", uint(1), uint(0)).Return([]models.Appointment{}, errors.New("db error")).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 0, 2)
 			},
-			expectedResp: &pb.ListAppointmentsResponse{Success: false},
-			expectedErr:  errors.New("db error"),
+			expectedResp: &pb.BookAppointmentResponse{Message: "Credits can only be used to book a service", Success: false},
+			expectedErr:  nil,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tt.mockSetup()
-			resp, err := srv.ListAppointments(tt.req)
-			assert.Equal(t, tt.expectedResp.Success, resp.Success)
-			assert.Len(t, resp.Appointments, len(tt.expectedResp.Appointments))
-			for i, appt := range resp.Appointments {
-				assert.Equal(t, tt.expectedResp.Appointments[i].Id, appt.Id)
-				assert.Equal(t, tt.expectedResp.Appointments[i].ClientId, appt.ClientId)
-				assert.Equal(t, tt.expectedResp.Appointments[i].ProfessionalId, appt.ProfessionalId)
-			}
-			assert.Equal(t, tt.expectedErr, err)
-			(mockRepo).AssertExpectations(t)
-			(mockNotif).AssertExpectations(t)
+		{
+			name: "ApprovalRequired",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1},
+			mockSetup: func() {
+				bookableSlot(mockRepo, mockAvailability, true)
+				noIntakeForm(mockResourceRepo, 0, 2)
+				(mockRepo).On("BookSlot", mock.MatchedBy(func(a *models.Appointment) bool {
+					return a.Status == models.AppointmentPendingApproval && a.ApprovalDeadline != nil
+				}), []uint(nil)).Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				// Se avisa al cliente que la solicitud espera aprobación, no se confirma la cita
+				(mockNotif).On("SendAppointmentUpdate", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentUpdateRequest) bool {
+					return r.Event == "requested" && r.ClientId == 1
+				})).Return(&pb.SendAppointmentUpdateResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment request sent, waiting for approval", Success: true, AppointmentId: 1},
+			expectedErr:  nil,
+		},
+		{
+			name: "RemoteService",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 3},
+			mockSetup: func() {
+				(mockResourceRepo).On("GetServiceByID", uint(3)).
+					Return(&models.Service{ID: 3, Modality: models.ModalityRemote}, nil).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 3, 2)
+				(mockRepo).On("BookSlot", mock.MatchedBy(func(a *models.Appointment) bool {
+					return a.Modality == models.ModalityRemote && meetingProvider.Active(a.MeetingID) &&
+						a.MeetingURL == "https://meet.example.com/"+a.MeetingID
+				}), []uint{}).Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				// El enlace de la reunión va en la confirmación
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentNotificationRequest) bool {
+					return r.MeetingUrl == "https://meet.example.com/fake_room_1"
+				})).Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
+			expectedErr:  nil,
+		},
+		{
+			name: "InvalidModality",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, Modality: "phone"},
+			mockSetup: func() {
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 0, 2)
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "modality must be 'in_person' or 'remote'", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "WithIntakeAnswers",
+			req: &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 3,
+				IntakeAnswers: map[string]string{"allergies": "none", "consent": "true", "notes": ""}},
+			mockSetup: func() {
+				(mockResourceRepo).On("GetServiceByID", uint(3)).Return(&models.Service{ID: 3}, nil).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				(mockResourceRepo).On("GetLatestIntakeForm", uint(3), uint(0)).Return(intakeForm(), nil).Once()
+				// Las respuestas vacías de campos opcionales no se guardan
+				(mockRepo).On("BookSlot", mock.MatchedBy(func(a *models.Appointment) bool {
+					return a.Intake != nil && a.Intake.FormID == 8 && a.Intake.FormVersion == 2 &&
+						a.Intake.Answers == `{"allergies":"none","consent":"true"}`
+				}), []uint{}).Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
+					Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
+			expectedErr:  nil,
+		},
+		{
+			name: "ProfessionalIntakeFormMissingAnswer",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, IntakeAnswers: map[string]string{"allergies": "none"}},
+			mockSetup: func() {
+				bookableSlot(mockRepo, mockAvailability, false)
+				(mockResourceRepo).On("GetLatestIntakeForm", uint(0), uint(2)).Return(intakeForm(), nil).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Invalid intake answers: consent is required", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "InvalidIntakeChoice",
+			req: &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1,
+				IntakeAnswers: map[string]string{"allergies": "some", "consent": "true"}},
+			mockSetup: func() {
+				bookableSlot(mockRepo, mockAvailability, false)
+				(mockResourceRepo).On("GetLatestIntakeForm", uint(0), uint(2)).Return(intakeForm(), nil).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Invalid intake answers: allergies must be one of the options", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "AnswersWithoutIntakeForm",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, IntakeAnswers: map[string]string{"allergies": "none"}},
+			mockSetup: func() {
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 0, 2)
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "There is no intake form to answer", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "ServiceNotFound",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 99},
+			mockSetup: func() {
+				(mockResourceRepo).On("GetServiceByID", uint(99)).Return((*models.Service)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Service not found", Success: false},
+			expectedErr:  gorm.ErrRecordNotFound,
+		},
+		{
+			name: "SlotNotFound",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 999},
+			mockSetup: func() {
+				(mockRepo).On("GetSlotByID", uint(999)).Return((*models.Slot)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Slot not found", Success: false},
+			expectedErr:  gorm.ErrRecordNotFound,
+		},
+		{
+			name: "SlotNotAvailable",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1},
+			mockSetup: func() {
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 0, 2)
+				(mockRepo).On("BookSlot", mock.AnythingOfType("*models.Appointment"), []uint(nil)).
+					Return((*models.Slot)(nil), repositories.ErrSlotNotAvailable).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "This slot is not available", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "ResourceNotAvailable",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1, ServiceId: 3},
+			mockSetup: func() {
+				(mockResourceRepo).On("GetServiceByID", uint(3)).
+					Return(&models.Service{ID: 3, Resources: []models.Resource{{ID: 4}}}, nil).Once()
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 3, 2)
+				(mockRepo).On("BookSlot", mock.AnythingOfType("*models.Appointment"), []uint{4}).
+					Return((*models.Slot)(nil), repositories.ErrResourceNotAvailable).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "A required resource is not available", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "NotificationError",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, SlotId: 1},
+			mockSetup: func() {
+				bookableSlot(mockRepo, mockAvailability, false)
+				noIntakeForm(mockResourceRepo, 0, 2)
+				(mockRepo).On("BookSlot", mock.AnythingOfType("*models.Appointment"), []uint(nil)).
+					Return(&models.Slot{ID: 1, ProfessionalID: 2}, nil).Once()
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
+					Return(&pb.SendAppointmentNotificationResponse{Message: "Error", Success: false}, errors.New("notification failed")).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
+			expectedErr:  nil, // Error de notificación no afecta la reserva
+		},
+		{
+			name: "ComputedSuccess",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, ProfessionalId: 3, StartTime: "2030-03-11T09:30:00Z"},
+			mockSetup: func() {
+				computedMocks(mockAvailability, mockRepo)
+				start := time.Date(2030, 3, 11, 9, 30, 0, 0, time.UTC)
+				noIntakeForm(mockResourceRepo, 0, 3)
+				(mockRepo).On("BookComputedSlot", mock.AnythingOfType("*models.Appointment"), &models.Slot{
+					ProfessionalID: 3, StartTime: start, EndTime: start.Add(30 * time.Minute), Available: true,
+				}, []uint(nil)).Return(nil).Once()
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
+					Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Appointment successfully generated", Success: true, AppointmentId: 1},
+			expectedErr:  nil,
+		},
+		{
+			name: "ComputedOutsideRules",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, ProfessionalId: 3, StartTime: "2030-03-11T09:10:00Z"},
+			mockSetup: func() {
+				computedMocks(mockAvailability, mockRepo)
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "This slot is not available", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "ComputedTakenConcurrently",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, ProfessionalId: 3, StartTime: "2030-03-11T10:00:00Z"},
+			mockSetup: func() {
+				computedMocks(mockAvailability, mockRepo)
+				noIntakeForm(mockResourceRepo, 0, 3)
+				(mockRepo).On("BookComputedSlot", mock.AnythingOfType("*models.Appointment"), mock.AnythingOfType("*models.Slot"), []uint(nil)).
+					Return(repositories.ErrSlotNotAvailable).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "This slot is not available", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "ProfessionalNotComputed",
+			req:  &pb.BookAppointmentRequest{ClientId: 1, ProfessionalId: 4, StartTime: "2030-03-11T09:30:00Z"},
+			mockSetup: func() {
+				(mockAvailability).On("GetSettings", uint(4)).Return(materialized(4), nil).Once()
+			},
+			expectedResp: &pb.BookAppointmentResponse{Message: "Professional does not use computed availability", Success: false},
+			expectedErr:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.BookAppointment(context.Background(), tt.req)
+			assert.Equal(t, tt.expectedResp.Message, resp.Message)
+			assert.Equal(t, tt.expectedResp.Success, resp.Success)
+			if tt.expectedResp.Success {
+				assert.NotZero(t, resp.AppointmentId, "AppointmentId debería asignarse")
+			}
+			if tt.name == "DepositRequired" {
+				assert.NotEmpty(t, resp.PaymentIntentId)
+				assert.NotEmpty(t, resp.PaymentClientSecret)
+				assert.Equal(t, uint32(2000), resp.AmountCents)
+			}
+			assert.Equal(t, tt.expectedErr, err)
+			(mockRepo).AssertExpectations(t)
+			(mockResourceRepo).AssertExpectations(t)
+			(mockAvailability).AssertExpectations(t)
+			(mockNotif).AssertExpectations(t)
+		})
+	}
+}
+
+// bookableSlot configura el slot 1 de un profesional en modo materializado
+func bookableSlot(mockRepo *MockAgendaRepository, mockAvailability *MockAvailabilityRepository, requiresApproval bool) {
+	(mockRepo).On("GetSlotByID", uint(1)).Return(&models.Slot{ID: 1, ProfessionalID: 2,
+		StartTime: time.Now().Add(72 * time.Hour), EndTime: time.Now().Add(73 * time.Hour), Available: true}, nil).Once()
+	settings := materialized(2)
+	settings.RequiresApproval = requiresApproval
+	(mockAvailability).On("GetSettings", uint(2)).Return(settings, nil).Once()
+}
+
+// noIntakeForm configura un servicio y un profesional sin formulario de admisión
+func noIntakeForm(mockResourceRepo *MockResourceRepository, serviceID, professionalID uint) {
+	if serviceID != 0 {
+		(mockResourceRepo).On("GetLatestIntakeForm", serviceID, uint(0)).Return((*models.IntakeForm)(nil), gorm.ErrRecordNotFound).Once()
+	}
+	(mockResourceRepo).On("GetLatestIntakeForm", uint(0), professionalID).Return((*models.IntakeForm)(nil), gorm.ErrRecordNotFound).Once()
+}
+
+func intakeForm() *models.IntakeForm {
+	return &models.IntakeForm{ID: 8, ServiceID: 3, Version: 2, Title: "Antes de la consulta",
+		Schema: `[{"key":"allergies","label":"Alergias","type":"choice","options":["none","food","drugs"]},` +
+			`{"key":"notes","label":"Notas","type":"text"},` +
+			`{"key":"consent","label":"Acepto el tratamiento de mis datos","type":"consent","required":true}]`}
+}
+
+// computedMocks configura un profesional en modo calculado que atiende los lunes de 09:00 a 11:00
+func computedMocks(mockAvailability *MockAvailabilityRepository, mockRepo *MockAgendaRepository) {
+	(mockAvailability).On("GetSettings", uint(3)).Return(&models.ProfessionalSettings{ProfessionalID: 3,
+		AvailabilityMode: models.AvailabilityComputed, Timezone: "UTC", DefaultDurationMinutes: 30}, nil).Once()
+	(mockAvailability).On("ListRules", uint(3)).
+		Return([]models.AvailabilityRule{{ProfessionalID: 3, Weekday: 1, StartTime: "09:00", EndTime: "11:00"}}, nil).Once()
+	(mockAvailability).On("ListTimeOff", uint(3), mock.Anything, mock.Anything).Return([]models.TimeOff{}, nil).Once()
+	(mockRepo).On("ListBookedSlots", uint(3), mock.Anything, mock.Anything).Return([]models.Slot{}, nil).Once()
+}
+
+func TestListAppointments(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+
+	tests := []struct {
+		name         string
+		req          *pb.ListAppointmentsRequest
+		mockSetup    func()
+		expectedResp *pb.ListAppointmentsResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req:  &pb.ListAppointmentsRequest{ClientId: 1},
+			mockSetup: func() {
+				(mockRepo).On("ListAppointments", uint(1), uint(0)).Return([]models.Appointment{
+					{ID: 1, ClientID: 1, SlotID: 1, ProfessionalID: 2},
+				}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(1)).Return(&models.Slot{ID: 1, StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute)}, nil).Once()
+			},
+			expectedResp: &pb.ListAppointmentsResponse{
+				Appointments: []*pb.Appointment{
+					{Id: 1, ClientId: 1, SlotId: 1, ProfessionalId: 2, StartTime: time.Now().Format(time.RFC3339), EndTime: time.Now().Add(30 * time.Minute).Format(time.RFC3339)},
+				},
+				Success: true,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "EmptyList",
+			req:  &pb.ListAppointmentsRequest{ClientId: 1},
+			mockSetup: func() {
+				(mockRepo).On("ListAppointments", uint(1), uint(0)).Return([]models.Appointment{}, nil).Once()
+			},
+			expectedResp: &pb.ListAppointmentsResponse{Appointments: []*pb.Appointment{}, Success: true},
+			expectedErr:  nil,
+		},
+		{
+			name: "DatabaseError",
+			req:  &pb.ListAppointmentsRequest{ClientId: 1},
+			mockSetup: func() {
+				(mockRepo).On("ListAppointments", uint(1), uint(0)).Return([]models.Appointment{}, errors.New("db error")).Once()
+			},
+			expectedResp: &pb.ListAppointmentsResponse{Success: false},
+			expectedErr:  errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.ListAppointments(context.Background(), tt.req)
+			assert.Equal(t, tt.expectedResp.Success, resp.Success)
+			assert.Len(t, resp.Appointments, len(tt.expectedResp.Appointments))
+			for i, appt := range resp.Appointments {
+				assert.Equal(t, tt.expectedResp.Appointments[i].Id, appt.Id)
+				assert.Equal(t, tt.expectedResp.Appointments[i].ClientId, appt.ClientId)
+				assert.Equal(t, tt.expectedResp.Appointments[i].ProfessionalId, appt.ProfessionalId)
+			}
+			assert.Equal(t, tt.expectedErr, err)
+			(mockRepo).AssertExpectations(t)
+			(mockNotif).AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompleteAppointment(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+
+	tests := []struct {
+		name         string
+		req          *pb.CompleteAppointmentRequest
+		mockSetup    func()
+		expectedResp *pb.CompleteAppointmentResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req:  &pb.CompleteAppointmentRequest{AppointmentId: 1},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("UpdateAppointmentStatus", uint(1), models.AppointmentCompleted).Return(nil).Once()
+			},
+			expectedResp: &pb.CompleteAppointmentResponse{Message: "Appointment completed", Success: true},
+		},
+		{
+			name: "AlreadyCompleted",
+			req:  &pb.CompleteAppointmentRequest{AppointmentId: 1},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, Status: models.AppointmentCompleted}, nil).Once()
+			},
+			expectedResp: &pb.CompleteAppointmentResponse{Message: "Only booked appointments can be completed", Success: false},
+		},
+		{
+			name: "NotFound",
+			req:  &pb.CompleteAppointmentRequest{AppointmentId: 99},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(99)).Return((*models.Appointment)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.CompleteAppointmentResponse{Message: "Appointment not found", Success: false},
+			expectedErr:  gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CompleteAppointment(context.Background(), tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelAppointment(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	provider := payments.NewFakeProvider("secret")
+	meetingProvider := meetings.NewFakeProvider()
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), provider, meetingProvider,
+		services.BookingPolicy{RefundWindow: 24 * time.Hour}, nil, nil, nil)
+	room, _ := meetingProvider.CreateRoom("appointment 1")
+
+	active := []string{models.AppointmentBooked, models.AppointmentPaymentPending, models.AppointmentPendingApproval}
+	// capturedIntent simula un pago ya capturado en el proveedor
+	capturedIntent := func() string {
+		intent, _ := provider.CreateIntent(2000, "USD", "test")
+		provider.Authorize(intent.ID)
+		provider.Capture(intent.ID)
+		return intent.ID
+	}
+	slotAt := func(start time.Time) *models.Slot {
+		return &models.Slot{ID: 3, ProfessionalID: 2, StartTime: start, EndTime: start.Add(30 * time.Minute)}
+	}
+
+	tests := []struct {
+		name         string
+		mockSetup    func()
+		expectedResp *pb.CancelAppointmentResponse
+		expectedErr  error
+	}{
+		{
+			name: "RefundedWithinPolicy",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(48*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return(&models.Payment{ID: 5, AppointmentID: 1,
+					IntentID: capturedIntent(), AmountCents: 2000, Status: models.PaymentCaptured}, nil).Once()
+				(mockRepo).On("UpdatePaymentStatus", uint(5), models.PaymentRefunded).Return(nil).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true, RefundedCents: 2000},
+		},
+		{
+			name: "TooLateForRefund",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(2*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return(&models.Payment{ID: 5, AppointmentID: 1,
+					IntentID: capturedIntent(), AmountCents: 2000, Status: models.PaymentCaptured}, nil).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true},
+		},
+		{
+			name: "PendingPayment",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentPaymentPending}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(48*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return(&models.Payment{ID: 5, AppointmentID: 1,
+					IntentID: "fake_pi_99", AmountCents: 2000, Status: models.PaymentPending}, nil).Once()
+				(mockRepo).On("UpdatePaymentStatus", uint(5), models.PaymentCancelled).Return(nil).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true},
+		},
+		{
+			name: "WithoutPayment",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(48*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return((*models.Payment)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true},
+		},
+		{
+			name: "RefundsCredit",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, ServiceID: 6, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(48*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return((*models.Payment)(nil), gorm.ErrRecordNotFound).Once()
+				(mockRepo).On("RefundCredit", uint(1)).Return(true, nil).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true, RefundedCredits: 1},
+		},
+		{
+			name: "CreditKeptWhenTooLate",
+			mockSetup: func() {
+				// Fuera de la ventana de reembolso el crédito se pierde
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, ServiceID: 6, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(2*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return((*models.Payment)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true},
+		},
+		{
+			name: "RevokesMeeting",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentBooked,
+					Modality: models.ModalityRemote, MeetingID: room.ID, MeetingURL: room.URL}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(48*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return((*models.Payment)(nil), gorm.ErrRecordNotFound).Once()
+				(mockRepo).On("ClearMeetingURL", uint(1)).Return(nil).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true},
+		},
+		{
+			name: "AlreadyCompleted",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentCompleted}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(slotAt(time.Now().Add(-48*time.Hour)), nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(repositories.ErrStatusChanged).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Only booked appointments can be cancelled", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CancelAppointment(context.Background(), &pb.CancelAppointmentRequest{AppointmentId: 1})
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+	assert.False(t, meetingProvider.Active(room.ID))
+}
+
+func TestCancelAppointmentWithLink(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+
+	active := []string{models.AppointmentBooked, models.AppointmentPaymentPending, models.AppointmentPendingApproval}
+	isLink := mock.MatchedBy(func(link *models.ActionLink) bool {
+		return link.ID == "abc" && link.AppointmentID == 1 && link.Action == models.ActionCancel
+	})
+
+	tests := []struct {
+		name         string
+		mockSetup    func()
+		expectedResp *pb.CancelAppointmentResponse
+	}{
+		{
+			name: "Success",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, StartTime: time.Now().Add(48 * time.Hour)}, nil).Once()
+				(mockRepo).On("UseActionLink", isLink).Return(nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(nil).Once()
+				(mockRepo).On("GetPaymentByAppointment", uint(1)).Return((*models.Payment)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Appointment cancelled", Success: true},
+		},
+		{
+			name: "LinkAlreadyUsed",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, StartTime: time.Now().Add(48 * time.Hour)}, nil).Once()
+				(mockRepo).On("UseActionLink", isLink).Return(repositories.ErrLinkUsed).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "This link was already used", Success: false},
+		},
+		{
+			name: "NotCancellableReleasesLink",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, SlotID: 3, Status: models.AppointmentCompleted}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, StartTime: time.Now().Add(-48 * time.Hour)}, nil).Once()
+				(mockRepo).On("UseActionLink", isLink).Return(nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(1), active, models.AppointmentCancelled).Return(repositories.ErrStatusChanged).Once()
+				(mockRepo).On("ReleaseActionLink", "abc").Return(nil).Once()
+			},
+			expectedResp: &pb.CancelAppointmentResponse{Message: "Only booked appointments can be cancelled", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CancelAppointment(context.Background(), &pb.CancelAppointmentRequest{AppointmentId: 1, LinkId: "abc"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+}
+
+func TestConfirmAppointment(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+
+	confirmable := []string{models.AppointmentBooked, models.AppointmentPendingApproval}
+	confirmedAt := time.Now()
+
+	tests := []struct {
+		name         string
+		mockSetup    func()
+		expectedResp *pb.ConfirmAppointmentResponse
+	}{
+		{
+			name: "Success",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("UseActionLink", mock.AnythingOfType("*models.ActionLink")).Return(nil).Once()
+				(mockRepo).On("ConfirmAppointment", uint(1), confirmable, mock.AnythingOfType("time.Time")).Return(nil).Once()
+			},
+			expectedResp: &pb.ConfirmAppointmentResponse{Message: "Appointment confirmed", Success: true},
+		},
+		{
+			name: "AlreadyConfirmed",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).
+					Return(&models.Appointment{ID: 1, Status: models.AppointmentBooked, ConfirmedAt: &confirmedAt}, nil).Once()
+			},
+			expectedResp: &pb.ConfirmAppointmentResponse{Message: "Appointment already confirmed", Success: true},
+		},
+		{
+			name: "LinkAlreadyUsed",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("UseActionLink", mock.AnythingOfType("*models.ActionLink")).Return(repositories.ErrLinkUsed).Once()
+			},
+			expectedResp: &pb.ConfirmAppointmentResponse{Message: "This link was already used", Success: false},
+		},
+		{
+			name: "CancelledReleasesLink",
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, Status: models.AppointmentCancelled}, nil).Once()
+				(mockRepo).On("UseActionLink", mock.AnythingOfType("*models.ActionLink")).Return(nil).Once()
+				(mockRepo).On("ConfirmAppointment", uint(1), confirmable, mock.AnythingOfType("time.Time")).Return(repositories.ErrStatusChanged).Once()
+				(mockRepo).On("ReleaseActionLink", "abc").Return(nil).Once()
+			},
+			expectedResp: &pb.ConfirmAppointmentResponse{Message: "Only booked appointments can be confirmed", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.ConfirmAppointment(context.Background(), &pb.ConfirmAppointmentRequest{AppointmentId: 1, LinkId: "abc"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+}
+
+func TestRescheduleAppointment(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockAvailability := new(MockAvailabilityRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), mockAvailability, nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+
+	reschedulable := []string{models.AppointmentBooked, models.AppointmentPendingApproval}
+	start := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	current := &models.Slot{ID: 3, ProfessionalID: 2, StartTime: start, EndTime: start.Add(30 * time.Minute)}
+	appointment := &models.Appointment{ID: 1, ClientID: 5, SlotID: 3, ProfessionalID: 2, Status: models.AppointmentBooked}
+	// storedSlot simula que el repositorio completa el slot elegido
+	storedSlot := func(args mock.Arguments) {
+		slot := args.Get(2).(*models.Slot)
+		*slot = models.Slot{ID: 4, ProfessionalID: 2, StartTime: start.Add(24 * time.Hour), EndTime: start.Add(24*time.Hour + 30*time.Minute)}
+	}
+
+	tests := []struct {
+		name         string
+		req          *pb.RescheduleAppointmentRequest
+		mockSetup    func()
+		expectedResp *pb.RescheduleAppointmentResponse
+	}{
+		{
+			name: "ToAnotherSlot",
+			req:  &pb.RescheduleAppointmentRequest{AppointmentId: 1, SlotId: 4, LinkId: "abc"},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(current, nil).Once()
+				(mockRepo).On("UseActionLink", mock.AnythingOfType("*models.ActionLink")).Return(nil).Once()
+				(mockRepo).On("RescheduleAppointment", uint(1), reschedulable, &models.Slot{ID: 4}, []uint(nil)).
+					Run(storedSlot).Return(nil).Once()
+				(mockNotif).On("SendAppointmentUpdate", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentUpdateRequest) bool {
+					return r.Event == "rescheduled" && r.StartTime == "2025-03-11T10:00:00Z"
+				})).Return(&pb.SendAppointmentUpdateResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+			expectedResp: &pb.RescheduleAppointmentResponse{Message: "Appointment rescheduled", Success: true,
+				StartTime: "2025-03-11T10:00:00Z", EndTime: "2025-03-11T10:30:00Z"},
+		},
+		{
+			name: "SlotTakenReleasesLink",
+			req:  &pb.RescheduleAppointmentRequest{AppointmentId: 1, SlotId: 4, LinkId: "abc"},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(current, nil).Once()
+				(mockRepo).On("UseActionLink", mock.AnythingOfType("*models.ActionLink")).Return(nil).Once()
+				(mockRepo).On("RescheduleAppointment", uint(1), reschedulable, &models.Slot{ID: 4}, []uint(nil)).
+					Return(repositories.ErrSlotNotAvailable).Once()
+				(mockRepo).On("ReleaseActionLink", "abc").Return(nil).Once()
+			},
+			expectedResp: &pb.RescheduleAppointmentResponse{Message: "This slot is not available", Success: false},
+		},
+		{
+			name: "NotComputedWithoutSlot",
+			req:  &pb.RescheduleAppointmentRequest{AppointmentId: 1, StartTime: "2025-03-11T10:00:00Z"},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(current, nil).Once()
+				(mockAvailability).On("GetSettings", uint(2)).Return(&models.ProfessionalSettings{ProfessionalID: 2}, nil).Once()
+			},
+			expectedResp: &pb.RescheduleAppointmentResponse{Message: "Professional does not use computed availability", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.RescheduleAppointment(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			(mockRepo).AssertExpectations(t)
+			(mockNotif).AssertExpectations(t)
+		})
+	}
+}
+
+func TestApproveAppointment(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+
+	pending := []string{models.AppointmentPendingApproval}
+	request := func() *models.Appointment {
+		return &models.Appointment{ID: 1, ClientID: 5, SlotID: 3, ProfessionalID: 2, Status: models.AppointmentPendingApproval}
+	}
+
+	tests := []struct {
+		name         string
+		req          *pb.ApproveAppointmentRequest
+		mockSetup    func()
+		expectedResp *pb.ApproveAppointmentResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.ApproveAppointmentRequest{AppointmentId: 1, ProfessionalId: 2},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(request(), nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, ProfessionalID: 2}, nil).Once()
+				(mockRepo).On("TransitionAppointment", uint(1), pending, models.AppointmentBooked).Return(nil).Once()
+				(mockNotif).On("SendAppointmentUpdate", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentUpdateRequest) bool {
+					return r.Event == "approved" && r.ClientId == 5
+				})).Return(&pb.SendAppointmentUpdateResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+			expectedResp: &pb.ApproveAppointmentResponse{Message: "Appointment approved", Success: true},
+		},
+		{
+			name: "AnotherProfessional",
+			req:  &pb.ApproveAppointmentRequest{AppointmentId: 1, ProfessionalId: 9},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(request(), nil).Once()
+			},
+			expectedResp: &pb.ApproveAppointmentResponse{Message: "Appointment belongs to another professional", Success: false},
+		},
+		{
+			name: "NotPending",
+			req:  &pb.ApproveAppointmentRequest{AppointmentId: 1, ProfessionalId: 2},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).
+					Return(&models.Appointment{ID: 1, ProfessionalID: 2, Status: models.AppointmentBooked}, nil).Once()
+			},
+			expectedResp: &pb.ApproveAppointmentResponse{Message: "Appointment is not pending approval", Success: false},
+		},
+		{
+			name: "ExpiredConcurrently",
+			req:  &pb.ApproveAppointmentRequest{AppointmentId: 1, ProfessionalId: 2},
+			mockSetup: func() {
+				(mockRepo).On("GetAppointmentByID", uint(1)).Return(request(), nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, ProfessionalID: 2}, nil).Once()
+				(mockRepo).On("TransitionAppointment", uint(1), pending, models.AppointmentBooked).Return(repositories.ErrStatusChanged).Once()
+			},
+			expectedResp: &pb.ApproveAppointmentResponse{Message: "Only pending requests can be approved", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.ApproveAppointment(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			(mockRepo).AssertExpectations(t)
+			(mockNotif).AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeclineAppointment(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	provider := payments.NewFakeProvider("secret")
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), provider, nil,
+		services.BookingPolicy{RefundWindow: 24 * time.Hour}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+
+	intent, _ := provider.CreateIntent(2000, "USD", "test")
+	provider.Authorize(intent.ID)
+	provider.Capture(intent.ID)
+
+	(mockRepo).On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, ClientID: 5, SlotID: 3, ProfessionalID: 2,
+		Status: models.AppointmentPendingApproval}, nil).Once()
+	// Aunque la cita sea en una hora, al rechazarla se devuelve todo el pago
+	(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, ProfessionalID: 2, StartTime: time.Now().Add(time.Hour)}, nil).Once()
+	(mockRepo).On("ReleaseAppointment", uint(1), []string{models.AppointmentPendingApproval}, models.AppointmentDeclined).Return(nil).Once()
+	(mockRepo).On("GetPaymentByAppointment", uint(1)).Return(&models.Payment{ID: 4, AppointmentID: 1, IntentID: intent.ID,
+		AmountCents: 2000, Status: models.PaymentCaptured}, nil).Once()
+	(mockRepo).On("UpdatePaymentStatus", uint(4), models.PaymentRefunded).Return(nil).Once()
+	(mockNotif).On("SendAppointmentUpdate", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentUpdateRequest) bool {
+		return r.Event == "declined" && r.Reason == "Agenda completa"
+	})).Return(&pb.SendAppointmentUpdateResponse{Message: "Sent", Success: true}, nil).Once()
+
+	resp, err := srv.DeclineAppointment(context.Background(), &pb.DeclineAppointmentRequest{AppointmentId: 1, ProfessionalId: 2, Reason: "Agenda completa"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.DeclineAppointmentResponse{Message: "Appointment declined", Success: true, RefundedCents: 2000}, resp)
+	assert.Equal(t, payments.IntentRefunded, provider.Status(intent.ID))
+	(mockRepo).AssertExpectations(t)
+	(mockNotif).AssertExpectations(t)
+}
+
+func TestExpireApprovalRequests(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	pending := []string{models.AppointmentPendingApproval}
+	(mockRepo).On("ListExpiredApprovals", now).Return([]models.Appointment{
+		{ID: 1, ClientID: 5, SlotID: 3, ProfessionalID: 2, Status: models.AppointmentPendingApproval},
+		{ID: 2, ClientID: 6, SlotID: 4, ProfessionalID: 2, Status: models.AppointmentPendingApproval},
+	}, nil).Once()
+	(mockRepo).On("ReleaseAppointment", uint(1), pending, models.AppointmentExpired).Return(nil).Once()
+	(mockRepo).On("GetPaymentByAppointment", uint(1)).Return((*models.Payment)(nil), gorm.ErrRecordNotFound).Once()
+	(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, ProfessionalID: 2}, nil).Once()
+	(mockNotif).On("SendAppointmentUpdate", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentUpdateRequest) bool {
+		return r.Event == "expired" && r.AppointmentId == 1
+	})).Return(&pb.SendAppointmentUpdateResponse{Message: "Sent", Success: true}, nil).Once()
+	// La segunda fue aprobada justo antes de expirar
+	(mockRepo).On("ReleaseAppointment", uint(2), pending, models.AppointmentExpired).Return(repositories.ErrStatusChanged).Once()
+
+	err := srv.ExpireApprovalRequests(now)
+	assert.NoError(t, err)
+	(mockRepo).AssertExpectations(t)
+	(mockNotif).AssertExpectations(t)
+}
+
+func TestSendReviewRequests(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+
+	endedBefore := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	(mockRepo).On("ListReviewRequestsDue", endedBefore).Return([]models.Appointment{
+		{ID: 1, ClientID: 5, ProfessionalID: 2, Status: models.AppointmentCompleted},
+		{ID: 2, ClientID: 6, ProfessionalID: 2, Status: models.AppointmentCompleted},
+	}, nil).Once()
+	(mockNotif).On("SendReviewRequest", mock.Anything, &pb.SendReviewRequestRequest{ClientId: 5, ProfessionalId: 2, AppointmentId: 1}).
+		Return(&pb.SendReviewRequestResponse{Message: "Sent", Success: true}, nil).Once()
+	(mockRepo).On("MarkReviewRequested", uint(1), mock.AnythingOfType("time.Time")).Return(nil).Once()
+	// Si el envío falla no se marca, para reintentarlo en la siguiente ejecución
+	(mockNotif).On("SendReviewRequest", mock.Anything, &pb.SendReviewRequestRequest{ClientId: 6, ProfessionalId: 2, AppointmentId: 2}).
+		Return((*pb.SendReviewRequestResponse)(nil), errors.New("smtp down")).Once()
+
+	err := srv.SendReviewRequests(endedBefore)
+	assert.NoError(t, err)
+	(mockRepo).AssertExpectations(t)
+	(mockNotif).AssertExpectations(t)
+}
+
+func TestSendDailyDigests(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockAvailability := new(MockAvailabilityRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	mockProf := new(MockProfessionalServiceClient)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), mockAvailability, nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+	srv.(*services.AgendaServiceImpl).ProfClient = mockProf
+
+	santiago, _ := time.LoadLocation("America/Santiago")
+	// 07:30 en Santiago, con el resumen configurado a las 07:00
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, santiago)
+	dayStart := time.Date(2025, 3, 10, 0, 0, 0, 0, santiago)
+	previous := now.Add(-24 * time.Hour)
+	settings := &models.ProfessionalSettings{ProfessionalID: 2, Timezone: "America/Santiago"}
+	active := []string{models.AppointmentBooked, models.AppointmentPendingApproval}
+	slotStart := time.Date(2025, 3, 10, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+	}{
+		{
+			name: "Sent",
+			mockSetup: func() {
+				mockAvailability.On("GetDigestSettings", uint(2)).Return(&models.DigestSettings{ProfessionalID: 2, SendTime: "07:00", LastSentDate: "2025-03-09"}, nil).Once()
+				mockAvailability.On("GetSettings", uint(2)).Return(settings, nil).Once()
+				mockAvailability.On("ClaimDigest", uint(2), "2025-03-10", now).Return(&previous, nil).Once()
+				mockRepo.On("ListProfessionalAppointments", uint(2), active, dayStart, dayStart.AddDate(0, 0, 1)).
+					Return([]models.Appointment{{ID: 1, ClientID: 5, SlotID: 3, Status: models.AppointmentBooked}}, nil).Once()
+				mockRepo.On("ListCancellations", uint(2), previous).
+					Return([]models.Appointment{{ID: 4, ClientID: 6, SlotID: 8, Status: models.AppointmentCancelled}}, nil).Once()
+				mockRepo.On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, StartTime: slotStart, EndTime: slotStart.Add(30 * time.Minute)}, nil).Once()
+				mockRepo.On("GetSlotByID", uint(8)).Return(&models.Slot{ID: 8, StartTime: slotStart, EndTime: slotStart.Add(30 * time.Minute)}, nil).Once()
+				mockNotif.On("SendDailyDigest", mock.Anything, mock.MatchedBy(func(r *pb.SendDailyDigestRequest) bool {
+					return r.ProfessionalId == 2 && r.Date == "2025-03-10" && r.Timezone == "America/Santiago" &&
+						len(r.Appointments) == 1 && r.Appointments[0].ClientId == 5 &&
+						len(r.Cancellations) == 1 && r.Cancellations[0].AppointmentId == 4
+				})).Return(&pb.SendDailyDigestResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+		},
+		{
+			name: "BeforeSendTime",
+			mockSetup: func() {
+				mockAvailability.On("GetDigestSettings", uint(2)).Return(&models.DigestSettings{ProfessionalID: 2, SendTime: "08:00"}, nil).Once()
+				mockAvailability.On("GetSettings", uint(2)).Return(settings, nil).Once()
+			},
+		},
+		{
+			name: "AlreadySentToday",
+			mockSetup: func() {
+				mockAvailability.On("GetDigestSettings", uint(2)).Return(&models.DigestSettings{ProfessionalID: 2, SendTime: "07:00", LastSentDate: "2025-03-10"}, nil).Once()
+				mockAvailability.On("GetSettings", uint(2)).Return(settings, nil).Once()
+			},
+		},
+		{
+			name: "ClaimedByAnotherRun",
+			mockSetup: func() {
+				mockAvailability.On("GetDigestSettings", uint(2)).Return(&models.DigestSettings{ProfessionalID: 2, SendTime: "07:00"}, nil).Once()
+				mockAvailability.On("GetSettings", uint(2)).Return(settings, nil).Once()
+				mockAvailability.On("ClaimDigest", uint(2), "2025-03-10", now).Return((*time.Time)(nil), repositories.ErrDigestSent).Once()
+			},
+		},
+		{
+			name: "OptedOut",
+			mockSetup: func() {
+				mockAvailability.On("GetDigestSettings", uint(2)).Return(&models.DigestSettings{ProfessionalID: 2, SendTime: "07:00", OptOut: true}, nil).Once()
+			},
+		},
+		{
+			name: "FailedSendIsUnclaimed",
+			mockSetup: func() {
+				mockAvailability.On("GetDigestSettings", uint(2)).Return(&models.DigestSettings{ProfessionalID: 2, SendTime: "07:00", LastSentDate: "2025-03-09"}, nil).Once()
+				mockAvailability.On("GetSettings", uint(2)).Return(settings, nil).Once()
+				mockAvailability.On("ClaimDigest", uint(2), "2025-03-10", now).Return(&previous, nil).Once()
+				mockRepo.On("ListProfessionalAppointments", uint(2), active, dayStart, dayStart.AddDate(0, 0, 1)).Return([]models.Appointment{}, nil).Once()
+				mockRepo.On("ListCancellations", uint(2), previous).Return([]models.Appointment{}, nil).Once()
+				mockNotif.On("SendDailyDigest", mock.Anything, mock.Anything).Return((*pb.SendDailyDigestResponse)(nil), errors.New("smtp down")).Once()
+				mockAvailability.On("UnclaimDigest", uint(2), "2025-03-09", &previous).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProf.On("ListProfessionals", mock.Anything, &pb.ListProfessionalsRequest{}).
+				Return(&pb.ListProfessionalsResponse{Professionals: []*pb.Professional{{Id: 2}}, Success: true}, nil).Once()
+			tt.mockSetup()
+			assert.NoError(t, srv.SendDailyDigests(now))
+			mockRepo.AssertExpectations(t)
+			mockAvailability.AssertExpectations(t)
+			mockNotif.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReassignAppointments(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockAvailability := new(MockAvailabilityRepository)
+	mockResource := new(MockResourceRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	srv := services.NewAgendaService(mockRepo, mockResource, mockAvailability, nil, nil, services.BookingPolicy{}, nil, nil, nil)
+	srv.(*services.AgendaServiceImpl).NotifClient = mockNotif
+
+	active := []string{models.AppointmentBooked, models.AppointmentPendingApproval}
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	nine, ten := from.Add(9*time.Hour), from.Add(10*time.Hour)
+	req := &pb.ReassignAppointmentsRequest{SourceProfessionalId: 2, TargetProfessionalId: 3,
+		From: from.Format(time.RFC3339), To: to.Format(time.RFC3339)}
+	appointments := []models.Appointment{
+		{ID: 1, ClientID: 5, SlotID: 10, ProfessionalID: 2, ServiceID: 4, Status: models.AppointmentBooked},
+		{ID: 2, ClientID: 6, SlotID: 11, ProfessionalID: 2, Status: models.AppointmentBooked},
+	}
+	sourceSlots := func() {
+		mockRepo.On("GetSlotByID", uint(10)).Return(&models.Slot{ID: 10, ProfessionalID: 2, StartTime: nine, EndTime: nine.Add(30 * time.Minute)}, nil).Once()
+		mockRepo.On("GetSlotByID", uint(11)).Return(&models.Slot{ID: 11, ProfessionalID: 2, StartTime: ten, EndTime: ten.Add(30 * time.Minute)}, nil).Once()
+	}
+	// El profesional destino solo tiene libre el horario de las 9
+	targetFree := []models.Slot{
+		{ID: 20, ProfessionalID: 3, StartTime: nine, EndTime: nine.Add(30 * time.Minute), Available: true},
+		{ID: 21, ProfessionalID: 3, StartTime: ten, EndTime: ten.Add(time.Hour), Available: true},
+	}
+
+	tests := []struct {
+		name         string
+		req          *pb.ReassignAppointmentsRequest
+		mockSetup    func()
+		expectedResp *pb.ReassignAppointmentsResponse
+	}{
+		{
+			name: "PartiallyPlaced",
+			req:  req,
+			mockSetup: func() {
+				mockRepo.On("ListProfessionalAppointments", uint(2), active, from, to).Return(appointments, nil).Once()
+				mockAvailability.On("GetSettings", uint(3)).Return(materialized(3), nil).Once()
+				mockRepo.On("ListAvailableSlots", uint(3), uint(0), from, to).Return(targetFree, nil).Once()
+				sourceSlots()
+				mockResource.On("GetServiceByID", uint(4)).Return(&models.Service{ID: 4, Resources: []models.Resource{{ID: 7}}}, nil).Once()
+				mockRepo.On("ReassignAppointments", active, []repositories.AppointmentMove{
+					{AppointmentID: 1, Slot: &targetFree[0], ResourceIDs: []uint{7}},
+				}).Return(nil).Once()
+				mockNotif.On("SendAppointmentUpdate", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentUpdateRequest) bool {
+					return r.Event == "reassigned" && r.ClientId == 5 && r.ProfessionalId == 3
+				})).Return(&pb.SendAppointmentUpdateResponse{Message: "Sent", Success: true}, nil).Once()
+			},
+			expectedResp: &pb.ReassignAppointmentsResponse{
+				Message: "1 appointments reassigned, 1 could not be placed",
+				Success: true,
+				Moved: []*pb.ReassignedAppointment{{AppointmentId: 1, ClientId: 5, SlotId: 20,
+					StartTime: "2025-03-10T09:00:00Z", EndTime: "2025-03-10T09:30:00Z"}},
+				Unplaced: []*pb.ReassignedAppointment{{AppointmentId: 2, ClientId: 6,
+					StartTime: "2025-03-10T10:00:00Z", EndTime: "2025-03-10T10:30:00Z",
+					Reason: "No free slot of the target professional at that time"}},
+			},
+		},
+		{
+			name: "AgendaChanged",
+			req:  req,
+			mockSetup: func() {
+				mockRepo.On("ListProfessionalAppointments", uint(2), active, from, to).Return(appointments, nil).Once()
+				mockAvailability.On("GetSettings", uint(3)).Return(materialized(3), nil).Once()
+				mockRepo.On("ListAvailableSlots", uint(3), uint(0), from, to).Return(targetFree, nil).Once()
+				sourceSlots()
+				mockResource.On("GetServiceByID", uint(4)).Return(&models.Service{ID: 4}, nil).Once()
+				mockRepo.On("ReassignAppointments", active, mock.Anything).Return(repositories.ErrSlotNotAvailable).Once()
+			},
+			expectedResp: &pb.ReassignAppointmentsResponse{Message: "The agenda changed while reassigning, try again", Success: false},
+		},
+		{
+			name:         "SameProfessional",
+			req:          &pb.ReassignAppointmentsRequest{SourceProfessionalId: 2, TargetProfessionalId: 2, From: req.From, To: req.To},
+			mockSetup:    func() {},
+			expectedResp: &pb.ReassignAppointmentsResponse{Message: "Source and target professionals must be different", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.ReassignAppointments(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockNotif.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetIntakeAnswers(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockResource := new(MockResourceRepository)
+	srv := services.NewAgendaService(mockRepo, mockResource, new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+
+	tests := []struct {
+		name         string
+		req          *pb.GetIntakeAnswersRequest
+		mockSetup    func()
+		expectedResp *pb.GetIntakeAnswersResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.GetIntakeAnswersRequest{AppointmentId: 1, ProfessionalId: 2},
+			mockSetup: func() {
+				mockRepo.On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, ProfessionalID: 2}, nil).Once()
+				mockRepo.On("GetIntakeResponse", uint(1)).Return(&models.IntakeResponse{AppointmentID: 1, FormID: 8, FormVersion: 2,
+					Answers: `{"allergies":"none","consent":"true"}`}, nil).Once()
+				mockResource.On("GetIntakeFormByID", uint(8)).Return(intakeForm(), nil).Once()
+			},
+			// Las respuestas siguen el orden de los campos del formulario
+			expectedResp: &pb.GetIntakeAnswersResponse{Message: "Intake answers found", Success: true, FormId: 8, FormVersion: 2,
+				Title: "Antes de la consulta", Answers: []*pb.IntakeAnswer{
+					{Key: "allergies", Label: "Alergias", Type: "choice", Value: "none"},
+					{Key: "consent", Label: "Acepto el tratamiento de mis datos", Type: "consent", Value: "true"},
+				}},
+		},
+		{
+			name: "AnotherProfessional",
+			req:  &pb.GetIntakeAnswersRequest{AppointmentId: 1, ProfessionalId: 3},
+			mockSetup: func() {
+				mockRepo.On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, ProfessionalID: 2}, nil).Once()
+			},
+			expectedResp: &pb.GetIntakeAnswersResponse{Message: "Appointment belongs to another professional", Success: false},
+		},
+		{
+			name: "NoAnswers",
+			req:  &pb.GetIntakeAnswersRequest{AppointmentId: 1, ProfessionalId: 2},
+			mockSetup: func() {
+				mockRepo.On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, ProfessionalID: 2}, nil).Once()
+				mockRepo.On("GetIntakeResponse", uint(1)).Return((*models.IntakeResponse)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.GetIntakeAnswersResponse{Message: "The appointment has no intake answers", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.GetIntakeAnswers(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockResource.AssertExpectations(t)
 		})
 	}
 }
+
+func TestAppointmentOwnership(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+
+	client := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 1})
+	professional := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 2})
+	appointment := &models.Appointment{ID: 1, ClientID: 1, ProfessionalID: 2, SlotID: 3, Status: models.AppointmentCompleted}
+
+	// Un cliente solo ve sus citas, aunque filtre por profesional
+	mockRepo.On("ListAppointments", uint(1), uint(2)).Return([]models.Appointment{}, nil).Once()
+	listResp, err := srv.ListAppointments(client, &pb.ListAppointmentsRequest{ClientId: 1, ProfessionalId: 2})
+	assert.NoError(t, err)
+	assert.True(t, listResp.Success)
+	_, err = srv.ListAppointments(client, &pb.ListAppointmentsRequest{ProfessionalId: 2})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	_, err = srv.ListAppointments(client, &pb.ListAppointmentsRequest{})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+
+	bookResp, err := srv.BookAppointment(client, &pb.BookAppointmentRequest{ClientId: 5, SlotId: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.BookAppointmentResponse{Message: "Not allowed", Success: false}, bookResp)
+
+	slotResp, err := srv.CreateSlot(professional, &pb.CreateSlotRequest{ProfessionalId: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CreateSlotResponse{Message: "Not allowed", Success: false}, slotResp)
+
+	other := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 4})
+	mockRepo.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Twice()
+	cancelResp, err := srv.CancelAppointment(other, &pb.CancelAppointmentRequest{AppointmentId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CancelAppointmentResponse{Message: "Not allowed", Success: false}, cancelResp)
+	_, err = srv.GetAppointment(other, &pb.GetAppointmentRequest{Id: 1})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+
+	// El profesional de la cita sí puede consultarla
+	mockRepo.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+	mockRepo.On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, StartTime: time.Now(), EndTime: time.Now()}, nil).Once()
+	getResp, err := srv.GetAppointment(professional, &pb.GetAppointmentRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.True(t, getResp.Success)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAppointmentActionsOwnership(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	srv := services.NewAgendaService(mockRepo, new(MockResourceRepository), new(MockAvailabilityRepository), nil, nil, services.BookingPolicy{}, nil, nil, nil)
+
+	// Otro profesional no puede actuar sobre la cita aunque ponga su id en la petición
+	other := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 3})
+	otherClient := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 4})
+	appointment := &models.Appointment{ID: 1, ClientID: 1, ProfessionalID: 2, SlotID: 3, Status: models.AppointmentPendingApproval}
+	mockRepo.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Times(5)
+
+	completeResp, err := srv.CompleteAppointment(other, &pb.CompleteAppointmentRequest{AppointmentId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CompleteAppointmentResponse{Message: "Not allowed", Success: false}, completeResp)
+
+	approveResp, err := srv.ApproveAppointment(other, &pb.ApproveAppointmentRequest{AppointmentId: 1, ProfessionalId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.ApproveAppointmentResponse{Message: "Not allowed", Success: false}, approveResp)
+
+	declineResp, err := srv.DeclineAppointment(other, &pb.DeclineAppointmentRequest{AppointmentId: 1, ProfessionalId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.DeclineAppointmentResponse{Message: "Not allowed", Success: false}, declineResp)
+
+	_, err = srv.GetIntakeAnswers(other, &pb.GetIntakeAnswersRequest{AppointmentId: 1, ProfessionalId: 2})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+
+	confirmResp, err := srv.ConfirmAppointment(otherClient, &pb.ConfirmAppointmentRequest{AppointmentId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.ConfirmAppointmentResponse{Message: "Not allowed", Success: false}, confirmResp)
+
+	reassignResp, err := srv.ReassignAppointments(other, &pb.ReassignAppointmentsRequest{SourceProfessionalId: 2, TargetProfessionalId: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.ReassignAppointmentsResponse{Message: "Not allowed", Success: false}, reassignResp)
+	mockRepo.AssertExpectations(t)
+}
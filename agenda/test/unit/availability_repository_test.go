@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupAvailabilityMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.AvailabilityRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewAvailabilityRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestGetSettingsRepo(t *testing.T) {
+	sqlDB, mock, repo := setupAvailabilityMockDB(t)
+	defer sqlDB.Close()
+
+	query := regexp.QuoteMeta(`SELECT * FROM "professional_settings" WHERE "professional_settings"."professional_id" = $1 ORDER BY "professional_settings"."professional_id" LIMIT $2`)
+	columns := []string{"professional_id", "availability_mode", "timezone", "default_duration_minutes", "step_minutes", "buffer_minutes"}
+
+	t.Run("Saved", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(uint(1), 1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "computed", "America/Santiago", 45, 15, 10))
+
+		settings, err := repo.GetSettings(1)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ProfessionalSettings{ProfessionalID: 1, AvailabilityMode: "computed",
+			Timezone: "America/Santiago", DefaultDurationMinutes: 45, StepMinutes: 15, BufferMinutes: 10}, settings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(uint(2), 1).WillReturnRows(sqlmock.NewRows(columns))
+
+		settings, err := repo.GetSettings(2)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ProfessionalSettings{ProfessionalID: 2, AvailabilityMode: "materialized",
+			Timezone: "UTC", DefaultDurationMinutes: 30}, settings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReplaceRulesRepo(t *testing.T) {
+	sqlDB, mock, repo := setupAvailabilityMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "availability_rules" WHERE professional_id = $1`)).
+		WithArgs(uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "availability_rules" ("professional_id","weekday","start_time","end_time","location_id") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs(uint(1), uint(1), "09:00", "13:00", uint(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectCommit()
+
+	err := repo.ReplaceRules(1, []models.AvailabilityRule{
+		{ProfessionalID: 1, Weekday: 1, StartTime: "09:00", EndTime: "13:00", LocationID: 2},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimDigestRepo(t *testing.T) {
+	sqlDB, mock, repo := setupAvailabilityMockDB(t)
+	defer sqlDB.Close()
+
+	lockDigest := regexp.QuoteMeta(`SELECT * FROM "digest_settings" WHERE professional_id = $1 ORDER BY "digest_settings"."professional_id" LIMIT $2 FOR UPDATE`)
+	columns := []string{"professional_id", "send_time", "opt_out", "last_sent_date", "last_sent_at"}
+	now := time.Date(2025, 3, 10, 10, 30, 0, 0, time.UTC)
+	previous := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name             string
+		mockSetup        func(sqlmock.Sqlmock)
+		expectedPrevious *time.Time
+		expectedErr      error
+	}{
+		{
+			name: "NextDay",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockDigest).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "07:00", false, "2025-03-09", previous))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "digest_settings" SET "last_sent_at"=$1,"last_sent_date"=$2 WHERE professional_id = $3`)).
+					WithArgs(now, "2025-03-10", uint(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedPrevious: &previous,
+		},
+		{
+			name: "FirstDigest",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockDigest).WithArgs(uint(2), 1).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "digest_settings" ("professional_id","send_time","opt_out","last_sent_date","last_sent_at") VALUES ($1,$2,$3,$4,$5)`)).
+					WithArgs(uint(2), "07:00", false, "2025-03-10", now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedPrevious: nil,
+		},
+		{
+			name: "AlreadySent",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockDigest).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "07:00", false, "2025-03-10", now))
+				mock.ExpectRollback()
+			},
+			expectedErr: repositories.ErrDigestSent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			previous, err := repo.ClaimDigest(2, "2025-03-10", now)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrevious, previous)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAvailabilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockAvailabilityRepository) GetSettings(professionalID uint) (*models.ProfessionalSettings, error) {
+	args := m.Called(professionalID)
+	return args.Get(0).(*models.ProfessionalSettings), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) SaveSettings(settings *models.ProfessionalSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+func (m *MockAvailabilityRepository) ListComputedProfessionals(locationID uint) ([]uint, error) {
+	args := m.Called(locationID)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) ReplaceRules(professionalID uint, rules []models.AvailabilityRule) error {
+	args := m.Called(professionalID, rules)
+	return args.Error(0)
+}
+
+func (m *MockAvailabilityRepository) ListRules(professionalID uint) ([]models.AvailabilityRule, error) {
+	args := m.Called(professionalID)
+	return args.Get(0).([]models.AvailabilityRule), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) CreateTimeOff(timeOff *models.TimeOff) error {
+	args := m.Called(timeOff)
+	return args.Error(0)
+}
+
+func (m *MockAvailabilityRepository) ListTimeOff(professionalID uint, from, to time.Time) ([]models.TimeOff, error) {
+	args := m.Called(professionalID, from, to)
+	return args.Get(0).([]models.TimeOff), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) GetDigestSettings(professionalID uint) (*models.DigestSettings, error) {
+	args := m.Called(professionalID)
+	return args.Get(0).(*models.DigestSettings), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) SaveDigestPreferences(digest *models.DigestSettings) error {
+	args := m.Called(digest)
+	return args.Error(0)
+}
+
+func (m *MockAvailabilityRepository) ClaimDigest(professionalID uint, date string, at time.Time) (*time.Time, error) {
+	args := m.Called(professionalID, date, at)
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) UnclaimDigest(professionalID uint, date string, at *time.Time) error {
+	args := m.Called(professionalID, date, at)
+	return args.Error(0)
+}
+
+// materialized devuelve la configuración por defecto de un profesional
+func materialized(professionalID uint) *models.ProfessionalSettings {
+	return &models.ProfessionalSettings{ProfessionalID: professionalID, AvailabilityMode: models.AvailabilityMaterialized,
+		Timezone: "UTC", DefaultDurationMinutes: 30}
+}
+
+func TestUpdateAvailabilitySettings(t *testing.T) {
+	mockRepo := new(MockAvailabilityRepository)
+	srv := services.NewAvailabilityService(mockRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.UpdateAvailabilitySettingsRequest
+		mockSetup    func()
+		expectedResp *pb.UpdateAvailabilitySettingsResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req: &pb.UpdateAvailabilitySettingsRequest{Settings: &pb.AvailabilitySettings{
+				ProfessionalId: 1, Mode: "computed", Timezone: "America/Santiago", DefaultDurationMinutes: 45, BufferMinutes: 10}},
+			mockSetup: func() {
+				mockRepo.On("GetSettings", uint(1)).Return(materialized(1), nil).Once()
+				mockRepo.On("SaveSettings", mock.MatchedBy(func(s *models.ProfessionalSettings) bool {
+					return s.Computed() && s.Timezone == "America/Santiago" && s.DefaultDurationMinutes == 45 && s.BufferMinutes == 10
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.UpdateAvailabilitySettingsResponse{Message: "Settings updated", Success: true},
+		},
+		{
+			name: "InvalidMode",
+			req: &pb.UpdateAvailabilitySettingsRequest{Settings: &pb.AvailabilitySettings{
+				ProfessionalId: 1, Mode: "weekly", Timezone: "UTC", DefaultDurationMinutes: 30}},
+			mockSetup:    func() {},
+			expectedResp: &pb.UpdateAvailabilitySettingsResponse{Message: "mode must be 'materialized' or 'computed'", Success: false},
+		},
+		{
+			name: "InvalidTimezone",
+			req: &pb.UpdateAvailabilitySettingsRequest{Settings: &pb.AvailabilitySettings{
+				ProfessionalId: 1, Mode: "computed", Timezone: "Mars/Olympus", DefaultDurationMinutes: 30}},
+			mockSetup:    func() {},
+			expectedResp: &pb.UpdateAvailabilitySettingsResponse{Message: "Invalid timezone", Success: false},
+		},
+		{
+			name: "DatabaseError",
+			req: &pb.UpdateAvailabilitySettingsRequest{Settings: &pb.AvailabilitySettings{
+				ProfessionalId: 1, Mode: "computed", Timezone: "UTC", DefaultDurationMinutes: 30}},
+			mockSetup: func() {
+				mockRepo.On("GetSettings", uint(1)).Return(materialized(1), nil).Once()
+				mockRepo.On("SaveSettings", mock.AnythingOfType("*models.ProfessionalSettings")).Return(errors.New("db error")).Once()
+			},
+			expectedResp: &pb.UpdateAvailabilitySettingsResponse{Message: "Error updating settings", Success: false},
+			expectedErr:  errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.UpdateAvailabilitySettings(context.Background(), tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSetAvailabilityRules(t *testing.T) {
+	mockRepo := new(MockAvailabilityRepository)
+	srv := services.NewAvailabilityService(mockRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.SetAvailabilityRulesRequest
+		mockSetup    func()
+		expectedResp *pb.SetAvailabilityRulesResponse
+	}{
+		{
+			name: "Success",
+			req: &pb.SetAvailabilityRulesRequest{ProfessionalId: 1, Rules: []*pb.AvailabilityRule{
+				{Weekday: 1, StartTime: "09:00", EndTime: "13:00", LocationId: 2},
+				{Weekday: 1, StartTime: "14:00", EndTime: "18:00"},
+			}},
+			mockSetup: func() {
+				mockRepo.On("ReplaceRules", uint(1), []models.AvailabilityRule{
+					{ProfessionalID: 1, Weekday: 1, StartTime: "09:00", EndTime: "13:00", LocationID: 2},
+					{ProfessionalID: 1, Weekday: 1, StartTime: "14:00", EndTime: "18:00"},
+				}).Return(nil).Once()
+			},
+			expectedResp: &pb.SetAvailabilityRulesResponse{Message: "Rules saved", Success: true},
+		},
+		{
+			name: "EndBeforeStart",
+			req: &pb.SetAvailabilityRulesRequest{ProfessionalId: 1, Rules: []*pb.AvailabilityRule{
+				{Weekday: 1, StartTime: "13:00", EndTime: "09:00"},
+			}},
+			mockSetup:    func() {},
+			expectedResp: &pb.SetAvailabilityRulesResponse{Message: "Invalid availability rule", Success: false},
+		},
+		{
+			name: "InvalidWeekday",
+			req: &pb.SetAvailabilityRulesRequest{ProfessionalId: 1, Rules: []*pb.AvailabilityRule{
+				{Weekday: 7, StartTime: "09:00", EndTime: "13:00"},
+			}},
+			mockSetup:    func() {},
+			expectedResp: &pb.SetAvailabilityRulesResponse{Message: "Invalid availability rule", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.SetAvailabilityRules(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTimeOff(t *testing.T) {
+	mockRepo := new(MockAvailabilityRepository)
+	srv := services.NewAvailabilityService(mockRepo)
+
+	mockRepo.On("CreateTimeOff", mock.MatchedBy(func(off *models.TimeOff) bool {
+		return off.ProfessionalID == 1 && off.Reason == "Vacaciones"
+	})).Return(nil).Once()
+
+	resp, err := srv.CreateTimeOff(context.Background(), &pb.CreateTimeOffRequest{ProfessionalId: 1, StartTime: "2025-03-10T00:00:00Z",
+		EndTime: "2025-03-15T00:00:00Z", Reason: "Vacaciones"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CreateTimeOffResponse{Message: "Time off created", Success: true}, resp)
+
+	resp, err = srv.CreateTimeOff(context.Background(), &pb.CreateTimeOffRequest{ProfessionalId: 1, StartTime: "2025-03-15T00:00:00Z",
+		EndTime: "2025-03-10T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CreateTimeOffResponse{Message: "end_time must be after start_time", Success: false}, resp)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetApprovalMode(t *testing.T) {
+	mockRepo := new(MockAvailabilityRepository)
+	srv := services.NewAvailabilityService(mockRepo)
+
+	computed := materialized(1)
+	computed.AvailabilityMode = models.AvailabilityComputed
+	mockRepo.On("GetSettings", uint(1)).Return(computed, nil).Once()
+	// Solo cambia la aprobación, el resto de la configuración se conserva
+	mockRepo.On("SaveSettings", mock.MatchedBy(func(s *models.ProfessionalSettings) bool {
+		return s.RequiresApproval && s.Computed()
+	})).Return(nil).Once()
+
+	resp, err := srv.SetApprovalMode(context.Background(), &pb.SetApprovalModeRequest{ProfessionalId: 1, RequiresApproval: true})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.SetApprovalModeResponse{Message: "Approval mode updated", Success: true}, resp)
+
+	resp, err = srv.SetApprovalMode(context.Background(), &pb.SetApprovalModeRequest{RequiresApproval: true})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.SetApprovalModeResponse{Message: "professional_id is required", Success: false}, resp)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetDigestPreferences(t *testing.T) {
+	mockRepo := new(MockAvailabilityRepository)
+	srv := services.NewAvailabilityService(mockRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.SetDigestPreferencesRequest
+		mockSetup    func()
+		expectedResp *pb.SetDigestPreferencesResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.SetDigestPreferencesRequest{ProfessionalId: 1, SendTime: "7:30"},
+			mockSetup: func() {
+				// La hora se guarda con dos dígitos para compararla como texto
+				mockRepo.On("SaveDigestPreferences", &models.DigestSettings{ProfessionalID: 1, SendTime: "07:30"}).Return(nil).Once()
+			},
+			expectedResp: &pb.SetDigestPreferencesResponse{Message: "Digest preferences updated", Success: true},
+		},
+		{
+			name: "OptOut",
+			req:  &pb.SetDigestPreferencesRequest{ProfessionalId: 1, SendTime: "07:00", OptOut: true},
+			mockSetup: func() {
+				mockRepo.On("SaveDigestPreferences", &models.DigestSettings{ProfessionalID: 1, SendTime: "07:00", OptOut: true}).Return(nil).Once()
+			},
+			expectedResp: &pb.SetDigestPreferencesResponse{Message: "Digest preferences updated", Success: true},
+		},
+		{
+			name:         "InvalidTime",
+			req:          &pb.SetDigestPreferencesRequest{ProfessionalId: 1, SendTime: "25:00"},
+			mockSetup:    func() {},
+			expectedResp: &pb.SetDigestPreferencesResponse{Message: "send_time must be in HH:MM format", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.SetDigestPreferences(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAvailabilityOwnership(t *testing.T) {
+	mockRepo := new(MockAvailabilityRepository)
+	srv := services.NewAvailabilityService(mockRepo)
+
+	// Un profesional no puede cambiar la agenda de otro poniendo su id en la petición
+	other := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 3})
+
+	settingsResp, err := srv.UpdateAvailabilitySettings(other, &pb.UpdateAvailabilitySettingsRequest{Settings: &pb.AvailabilitySettings{
+		ProfessionalId: 1, Mode: "computed", Timezone: "UTC", DefaultDurationMinutes: 30}})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.UpdateAvailabilitySettingsResponse{Message: "Not allowed", Success: false}, settingsResp)
+
+	_, err = srv.GetAvailabilitySettings(other, &pb.GetAvailabilitySettingsRequest{ProfessionalId: 1})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+
+	rulesResp, err := srv.SetAvailabilityRules(other, &pb.SetAvailabilityRulesRequest{ProfessionalId: 1, Rules: []*pb.AvailabilityRule{
+		{Weekday: 1, StartTime: "09:00", EndTime: "13:00"}}})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.SetAvailabilityRulesResponse{Message: "Not allowed", Success: false}, rulesResp)
+
+	offResp, err := srv.CreateTimeOff(other, &pb.CreateTimeOffRequest{ProfessionalId: 1, StartTime: "2025-03-10T00:00:00Z",
+		EndTime: "2025-03-15T00:00:00Z"})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.CreateTimeOffResponse{Message: "Not allowed", Success: false}, offResp)
+
+	approvalResp, err := srv.SetApprovalMode(other, &pb.SetApprovalModeRequest{ProfessionalId: 1, RequiresApproval: true})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.SetApprovalModeResponse{Message: "Not allowed", Success: false}, approvalResp)
+
+	digestResp, err := srv.SetDigestPreferences(other, &pb.SetDigestPreferencesRequest{ProfessionalId: 1, SendTime: "07:00"})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.SetDigestPreferencesResponse{Message: "Not allowed", Success: false}, digestResp)
+
+	// Con su propio id sí puede
+	own := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 1})
+	mockRepo.On("SaveDigestPreferences", &models.DigestSettings{ProfessionalID: 1, SendTime: "07:00"}).Return(nil).Once()
+	digestResp, err = srv.SetDigestPreferences(own, &pb.SetDigestPreferencesRequest{ProfessionalId: 1, SendTime: "07:00"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.SetDigestPreferencesResponse{Message: "Digest preferences updated", Success: true}, digestResp)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupNoteMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.NoteRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewNoteRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestListNotesRepo(t *testing.T) {
+	sqlDB, mock, repo := setupNoteMockDB(t)
+	defer sqlDB.Close()
+
+	createdAt := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "appointment_id", "author_role", "author_id", "visibility", "body", "created_at"}).
+		AddRow(4, 1, "client", 5, "shared", "Hola", createdAt)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "appointment_notes" WHERE appointment_id = $1 AND visibility IN ($2) ORDER BY created_at`)).
+		WithArgs(uint(1), "shared").
+		WillReturnRows(rows)
+
+	notes, err := repo.ListNotes(1, []string{models.NoteShared})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AppointmentNote{
+		{ID: 4, AppointmentID: 1, AuthorRole: "client", AuthorID: 5, Visibility: "shared", Body: "Hola", CreatedAt: createdAt},
+	}, notes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAttachmentRepo(t *testing.T) {
+	sqlDB, mock, repo := setupNoteMockDB(t)
+	defer sqlDB.Close()
+
+	attachment := &models.Attachment{AppointmentID: 1, UploaderRole: "client", UploaderID: 5, Visibility: "shared",
+		FileName: "derivacion.pdf", ContentType: "application/pdf", SizeBytes: 120, BlobKey: "abc123"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "attachments" ("appointment_id","uploader_role","uploader_id","visibility","file_name","content_type","size_bytes","blob_key","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id"`)).
+		WithArgs(uint(1), "client", uint(5), "shared", "derivacion.pdf", "application/pdf", int64(120), "abc123", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	err := repo.CreateAttachment(attachment)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), attachment.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
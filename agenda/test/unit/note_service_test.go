@@ -0,0 +1,317 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/blobs"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockNoteRepository) CreateNote(note *models.AppointmentNote) error {
+	args := m.Called(note)
+	return args.Error(0)
+}
+
+func (m *MockNoteRepository) ListNotes(appointmentID uint, visibilities []string) ([]models.AppointmentNote, error) {
+	args := m.Called(appointmentID, visibilities)
+	return args.Get(0).([]models.AppointmentNote), args.Error(1)
+}
+
+func (m *MockNoteRepository) CreateAttachment(attachment *models.Attachment) error {
+	args := m.Called(attachment)
+	return args.Error(0)
+}
+
+func (m *MockNoteRepository) ListAttachments(appointmentID uint, visibilities []string) ([]models.Attachment, error) {
+	args := m.Called(appointmentID, visibilities)
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func (m *MockNoteRepository) GetAttachmentByID(id uint) (*models.Attachment, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+var pdfData = []byte("%PDF-1.4\n1 0 obj\n<< >>\nendobj\n")
+
+func newNoteService(mockRepo *MockNoteRepository, mockAgenda *MockAgendaRepository, store blobs.BlobStore) services.NoteService {
+	return services.NewNoteService(mockRepo, mockAgenda, store, services.AttachmentLimits{
+		MaxBytes: 64, AllowedTypes: []string{"application/pdf", "image/png"},
+	})
+}
+
+func TestAddNote(t *testing.T) {
+	mockRepo := new(MockNoteRepository)
+	mockAgenda := new(MockAgendaRepository)
+	srv := newNoteService(mockRepo, mockAgenda, blobs.NewMemoryStore())
+	appointment := &models.Appointment{ID: 1, ClientID: 5, ProfessionalID: 2}
+
+	tests := []struct {
+		name         string
+		req          *pb.AddNoteRequest
+		mockSetup    func()
+		expectedResp *pb.AddNoteResponse
+	}{
+		{
+			name: "PrivateByDefaultForProfessional",
+			req:  &pb.AddNoteRequest{AppointmentId: 1, ProfessionalId: 2, Body: " Revisar exámenes "},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+				mockRepo.On("CreateNote", &models.AppointmentNote{AppointmentID: 1, AuthorRole: models.AuthorProfessional,
+					AuthorID: 2, Visibility: models.NotePrivate, Body: "Revisar exámenes"}).
+					Run(func(args mock.Arguments) { args.Get(0).(*models.AppointmentNote).ID = 3 }).Return(nil).Once()
+			},
+			expectedResp: &pb.AddNoteResponse{Message: "Note added", Success: true, NoteId: 3},
+		},
+		{
+			name: "SharedByDefaultForClient",
+			req:  &pb.AddNoteRequest{AppointmentId: 1, ClientId: 5, Body: "Llego 5 minutos tarde"},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+				mockRepo.On("CreateNote", mock.MatchedBy(func(n *models.AppointmentNote) bool {
+					return n.AuthorRole == models.AuthorClient && n.AuthorID == 5 && n.Visibility == models.NoteShared
+				})).Run(func(args mock.Arguments) { args.Get(0).(*models.AppointmentNote).ID = 4 }).Return(nil).Once()
+			},
+			expectedResp: &pb.AddNoteResponse{Message: "Note added", Success: true, NoteId: 4},
+		},
+		{
+			name: "ClientPrivateNote",
+			req:  &pb.AddNoteRequest{AppointmentId: 1, ClientId: 5, Visibility: "private", Body: "Nota"},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+			},
+			expectedResp: &pb.AddNoteResponse{Message: "Clients can only share with the professional", Success: false},
+		},
+		{
+			name: "AnotherClient",
+			req:  &pb.AddNoteRequest{AppointmentId: 1, ClientId: 6, Body: "Nota"},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+			},
+			expectedResp: &pb.AddNoteResponse{Message: "Appointment belongs to another client", Success: false},
+		},
+		{
+			name:         "NoRequester",
+			req:          &pb.AddNoteRequest{AppointmentId: 1, Body: "Nota"},
+			mockSetup:    func() {},
+			expectedResp: &pb.AddNoteResponse{Message: "Either professional_id or client_id is required", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.AddNote(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockAgenda.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListNotesForClient(t *testing.T) {
+	mockRepo := new(MockNoteRepository)
+	mockAgenda := new(MockAgendaRepository)
+	srv := newNoteService(mockRepo, mockAgenda, blobs.NewMemoryStore())
+
+	// El cliente solo ve las notas compartidas
+	mockAgenda.On("GetAppointmentByID", uint(1)).Return(&models.Appointment{ID: 1, ClientID: 5, ProfessionalID: 2}, nil).Once()
+	mockRepo.On("ListNotes", uint(1), []string{models.NoteShared}).
+		Return([]models.AppointmentNote{{ID: 4, AppointmentID: 1, AuthorRole: "client", AuthorID: 5, Visibility: "shared", Body: "Hola"}}, nil).Once()
+
+	resp, err := srv.ListNotes(context.Background(), &pb.ListNotesRequest{AppointmentId: 1, ClientId: 5})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Notes, 1)
+	assert.Equal(t, "Hola", resp.Notes[0].Body)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUploadAttachment(t *testing.T) {
+	mockRepo := new(MockNoteRepository)
+	mockAgenda := new(MockAgendaRepository)
+	store := blobs.NewMemoryStore()
+	srv := newNoteService(mockRepo, mockAgenda, store)
+	appointment := &models.Appointment{ID: 1, ClientID: 5, ProfessionalID: 2}
+
+	tests := []struct {
+		name         string
+		req          *pb.UploadAttachmentRequest
+		mockSetup    func()
+		expectedResp *pb.UploadAttachmentResponse
+		expectedErr  bool
+		storedBlobs  int
+	}{
+		{
+			name: "Success",
+			req:  &pb.UploadAttachmentRequest{AppointmentId: 1, ClientId: 5, FileName: "../../derivacion.pdf", Data: pdfData},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+				mockRepo.On("CreateAttachment", mock.MatchedBy(func(a *models.Attachment) bool {
+					return a.FileName == "derivacion.pdf" && a.ContentType == "application/pdf" &&
+						a.Visibility == models.NoteShared && a.SizeBytes == int64(len(pdfData)) && a.BlobKey != ""
+				})).Run(func(args mock.Arguments) { args.Get(0).(*models.Attachment).ID = 7 }).Return(nil).Once()
+			},
+			expectedResp: &pb.UploadAttachmentResponse{Message: "Attachment uploaded", Success: true, AttachmentId: 7, ContentType: "application/pdf"},
+			storedBlobs:  1,
+		},
+		{
+			name: "TypeNotAllowed",
+			req:  &pb.UploadAttachmentRequest{AppointmentId: 1, ClientId: 5, FileName: "nota.pdf", Data: []byte("solo texto")},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+			},
+			expectedResp: &pb.UploadAttachmentResponse{Message: "File type not allowed", Success: false},
+			storedBlobs:  1,
+		},
+		{
+			name: "TooLarge",
+			req:  &pb.UploadAttachmentRequest{AppointmentId: 1, ProfessionalId: 2, FileName: "grande.pdf", Data: make([]byte, 65)},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+			},
+			expectedResp: &pb.UploadAttachmentResponse{Message: "The file can't be larger than 64 bytes", Success: false},
+			storedBlobs:  1,
+		},
+		{
+			name: "RecordFailsRemovesBlob",
+			req:  &pb.UploadAttachmentRequest{AppointmentId: 1, ProfessionalId: 2, FileName: "informe.pdf", Data: pdfData},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+				mockRepo.On("CreateAttachment", mock.AnythingOfType("*models.Attachment")).Return(errors.New("db error")).Once()
+			},
+			expectedResp: &pb.UploadAttachmentResponse{Message: "Error uploading attachment", Success: false},
+			expectedErr:  true,
+			storedBlobs:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.UploadAttachment(context.Background(), tt.req)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.storedBlobs, store.Len())
+			mockRepo.AssertExpectations(t)
+			mockAgenda.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	mockRepo := new(MockNoteRepository)
+	mockAgenda := new(MockAgendaRepository)
+	store := blobs.NewMemoryStore()
+	srv := newNoteService(mockRepo, mockAgenda, store)
+	assert.NoError(t, store.Put("abc123", pdfData))
+	appointment := &models.Appointment{ID: 1, ClientID: 5, ProfessionalID: 2}
+	private := &models.Attachment{ID: 7, AppointmentID: 1, Visibility: models.NotePrivate, FileName: "informe.pdf",
+		ContentType: "application/pdf", BlobKey: "abc123"}
+
+	tests := []struct {
+		name         string
+		req          *pb.DownloadAttachmentRequest
+		mockSetup    func()
+		expectedResp *pb.DownloadAttachmentResponse
+	}{
+		{
+			name: "Professional",
+			req:  &pb.DownloadAttachmentRequest{Id: 7, ProfessionalId: 2},
+			mockSetup: func() {
+				mockRepo.On("GetAttachmentByID", uint(7)).Return(private, nil).Once()
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+			},
+			expectedResp: &pb.DownloadAttachmentResponse{Message: "Attachment found", Success: true, FileName: "informe.pdf",
+				ContentType: "application/pdf", Data: pdfData},
+		},
+		{
+			name: "ClientPrivate",
+			req:  &pb.DownloadAttachmentRequest{Id: 7, ClientId: 5},
+			mockSetup: func() {
+				mockRepo.On("GetAttachmentByID", uint(7)).Return(private, nil).Once()
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+			},
+			expectedResp: &pb.DownloadAttachmentResponse{Message: "Attachment not found", Success: false},
+		},
+		{
+			name: "AnotherProfessional",
+			req:  &pb.DownloadAttachmentRequest{Id: 7, ProfessionalId: 3},
+			mockSetup: func() {
+				mockRepo.On("GetAttachmentByID", uint(7)).Return(private, nil).Once()
+				mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+			},
+			expectedResp: &pb.DownloadAttachmentResponse{Message: "Appointment belongs to another professional", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.DownloadAttachment(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockAgenda.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNoteRequesterFromClaims(t *testing.T) {
+	mockRepo := new(MockNoteRepository)
+	mockAgenda := new(MockAgendaRepository)
+	srv := newNoteService(mockRepo, mockAgenda, blobs.NewMemoryStore())
+	appointment := &models.Appointment{ID: 1, ClientID: 5, ProfessionalID: 2}
+	client := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 5})
+	other := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 3})
+
+	// El cliente no puede hacerse pasar por el profesional poniendo su id
+	mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+	addResp, err := srv.AddNote(client, &pb.AddNoteRequest{AppointmentId: 1, ProfessionalId: 2, Visibility: models.NotePrivate, Body: "Nota"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.AddNoteResponse{Message: "Clients can only share with the professional", Success: false}, addResp)
+
+	mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+	mockRepo.On("ListNotes", uint(1), []string{models.NoteShared}).Return([]models.AppointmentNote{}, nil).Once()
+	listResp, err := srv.ListNotes(client, &pb.ListNotesRequest{AppointmentId: 1, ProfessionalId: 2})
+	assert.NoError(t, err)
+	assert.True(t, listResp.Success)
+
+	mockAgenda.On("GetAppointmentByID", uint(1)).Return(appointment, nil).Once()
+	_, err = srv.ListAttachments(other, &pb.ListAttachmentsRequest{AppointmentId: 1, ProfessionalId: 2})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	mockRepo.AssertExpectations(t)
+	mockAgenda.AssertExpectations(t)
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := blobs.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Put("abc123", pdfData))
+	data, err := store.Get("abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, pdfData, data)
+
+	assert.NoError(t, store.Delete("abc123"))
+	_, err = store.Get("abc123")
+	assert.ErrorIs(t, err, blobs.ErrBlobNotFound)
+	// Las claves nunca pueden salir del directorio
+	assert.ErrorIs(t, store.Put("../fuera", pdfData), blobs.ErrInvalidKey)
+}
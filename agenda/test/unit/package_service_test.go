@@ -0,0 +1,245 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockPackageRepository struct {
+	mock.Mock
+}
+
+func (m *MockPackageRepository) CreatePackage(pkg *models.Package) error {
+	args := m.Called(pkg)
+	if args.Error(0) == nil {
+		pkg.ID = 1
+	}
+	return args.Error(0)
+}
+
+func (m *MockPackageRepository) GetPackageByID(id uint) (*models.Package, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Package), args.Error(1)
+}
+
+func (m *MockPackageRepository) ListPackages() ([]models.Package, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Package), args.Error(1)
+}
+
+func (m *MockPackageRepository) GetServicesByIDs(ids []uint) ([]models.Service, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]models.Service), args.Error(1)
+}
+
+func (m *MockPackageRepository) PurchasePackage(clientPackage *models.ClientPackage) error {
+	args := m.Called(clientPackage)
+	if args.Error(0) == nil {
+		clientPackage.ID = 3
+	}
+	return args.Error(0)
+}
+
+func (m *MockPackageRepository) ListActivePackages(clientID uint, at time.Time) ([]models.ClientPackage, error) {
+	args := m.Called(clientID, at)
+	return args.Get(0).([]models.ClientPackage), args.Error(1)
+}
+
+func (m *MockPackageRepository) ListCreditTransactions(clientID uint) ([]models.CreditTransaction, error) {
+	args := m.Called(clientID)
+	return args.Get(0).([]models.CreditTransaction), args.Error(1)
+}
+
+func TestCreatePackage(t *testing.T) {
+	mockRepo := new(MockPackageRepository)
+	srv := services.NewPackageService(mockRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.CreatePackageRequest
+		mockSetup    func()
+		expectedResp *pb.CreatePackageResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.CreatePackageRequest{Name: "10 sesiones", Credits: 10, ValidDays: 90, PriceCents: 40000, ServiceIds: []uint32{3, 6}},
+			mockSetup: func() {
+				(mockRepo).On("GetServicesByIDs", []uint{3, 6}).Return([]models.Service{{ID: 3}, {ID: 6}}, nil).Once()
+				(mockRepo).On("CreatePackage", mock.MatchedBy(func(p *models.Package) bool {
+					return p.Credits == 10 && p.ValidDays == 90 && p.Currency == "USD" && len(p.Services) == 2
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.CreatePackageResponse{Message: "Package created", Success: true, PackageId: 1},
+		},
+		{
+			name:         "WithoutCredits",
+			req:          &pb.CreatePackageRequest{Name: "Vacío", ValidDays: 90, ServiceIds: []uint32{3}},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreatePackageResponse{Message: "credits must be greater than 0", Success: false},
+		},
+		{
+			name:         "WithoutServices",
+			req:          &pb.CreatePackageRequest{Name: "10 sesiones", Credits: 10, ValidDays: 90},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreatePackageResponse{Message: "The package needs at least one service", Success: false},
+		},
+		{
+			name: "ServiceNotFound",
+			req:  &pb.CreatePackageRequest{Name: "10 sesiones", Credits: 10, ValidDays: 90, ServiceIds: []uint32{3, 99}},
+			mockSetup: func() {
+				(mockRepo).On("GetServicesByIDs", []uint{3, 99}).Return([]models.Service{{ID: 3}}, nil).Once()
+			},
+			expectedResp: &pb.CreatePackageResponse{Message: "Service not found", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CreatePackage(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+}
+
+func TestPurchasePackage(t *testing.T) {
+	mockRepo := new(MockPackageRepository)
+	srv := services.NewPackageService(mockRepo)
+	pkg := &models.Package{ID: 2, Name: "10 sesiones", Credits: 10, ValidDays: 30}
+	expiresAt := time.Now().Add(60 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	t.Run("ValidDays", func(t *testing.T) {
+		(mockRepo).On("GetPackageByID", uint(2)).Return(pkg, nil).Once()
+		(mockRepo).On("PurchasePackage", mock.MatchedBy(func(cp *models.ClientPackage) bool {
+			// Los créditos duran los días del paquete desde la compra
+			validFor := time.Until(cp.ExpiresAt)
+			return cp.ClientID == 1 && cp.Credits == 10 && cp.Remaining == 10 &&
+				validFor > 29*24*time.Hour && validFor <= 30*24*time.Hour
+		})).Return(nil).Once()
+
+		resp, err := srv.PurchasePackage(context.Background(), &pb.PurchasePackageRequest{ClientId: 1, PackageId: 2})
+		assert.NoError(t, err)
+		assert.True(t, resp.Success)
+		assert.Equal(t, uint32(3), resp.ClientPackageId)
+	})
+
+	t.Run("FixedExpiration", func(t *testing.T) {
+		(mockRepo).On("GetPackageByID", uint(2)).Return(pkg, nil).Once()
+		(mockRepo).On("PurchasePackage", mock.MatchedBy(func(cp *models.ClientPackage) bool {
+			return cp.ExpiresAt.Equal(expiresAt)
+		})).Return(nil).Once()
+
+		resp, err := srv.PurchasePackage(context.Background(), &pb.PurchasePackageRequest{ClientId: 1, PackageId: 2, ExpiresAt: expiresAt.Format(time.RFC3339)})
+		assert.NoError(t, err)
+		assert.Equal(t, &pb.PurchasePackageResponse{Message: "Package purchased", Success: true, ClientPackageId: 3,
+			ExpiresAt: expiresAt.Format(time.RFC3339)}, resp)
+	})
+
+	t.Run("ExpirationInThePast", func(t *testing.T) {
+		(mockRepo).On("GetPackageByID", uint(2)).Return(pkg, nil).Once()
+
+		resp, err := srv.PurchasePackage(context.Background(), &pb.PurchasePackageRequest{ClientId: 1, PackageId: 2, ExpiresAt: "2020-01-01T00:00:00Z"})
+		assert.NoError(t, err)
+		assert.Equal(t, &pb.PurchasePackageResponse{Message: "expires_at must be in the future", Success: false}, resp)
+	})
+
+	t.Run("PackageNotFound", func(t *testing.T) {
+		(mockRepo).On("GetPackageByID", uint(9)).Return((*models.Package)(nil), gorm.ErrRecordNotFound).Once()
+
+		resp, err := srv.PurchasePackage(context.Background(), &pb.PurchasePackageRequest{ClientId: 1, PackageId: 9})
+		assert.NoError(t, err)
+		assert.Equal(t, &pb.PurchasePackageResponse{Message: "Package not found", Success: false}, resp)
+	})
+
+	t.Run("AnotherClient", func(t *testing.T) {
+		ctx := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 4})
+
+		resp, err := srv.PurchasePackage(ctx, &pb.PurchasePackageRequest{ClientId: 1, PackageId: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, &pb.PurchasePackageResponse{Message: "Not allowed", Success: false}, resp)
+	})
+
+	(mockRepo).AssertExpectations(t)
+}
+
+func TestGetCreditBalance(t *testing.T) {
+	mockRepo := new(MockPackageRepository)
+	srv := services.NewPackageService(mockRepo)
+	expiresAt := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	active := []models.ClientPackage{
+		{ID: 3, PackageID: 2, Credits: 10, Remaining: 4, ExpiresAt: expiresAt,
+			Package: models.Package{ID: 2, Name: "10 sesiones", Services: []models.Service{{ID: 3}, {ID: 6}}}},
+		{ID: 4, PackageID: 5, Credits: 5, Remaining: 5, ExpiresAt: expiresAt.AddDate(0, 1, 0),
+			Package: models.Package{ID: 5, Name: "Masajes", Services: []models.Service{{ID: 7}}}},
+	}
+
+	tests := []struct {
+		name          string
+		serviceID     uint32
+		expectedTotal uint32
+		expectedIDs   []uint32
+	}{
+		{name: "AllPackages", expectedTotal: 9, expectedIDs: []uint32{3, 4}},
+		{name: "ByService", serviceID: 7, expectedTotal: 5, expectedIDs: []uint32{4}},
+		{name: "NoCreditsForService", serviceID: 8, expectedTotal: 0, expectedIDs: []uint32{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			(mockRepo).On("ListActivePackages", uint(1), mock.AnythingOfType("time.Time")).Return(active, nil).Once()
+
+			resp, err := srv.GetCreditBalance(context.Background(), &pb.GetCreditBalanceRequest{ClientId: 1, ServiceId: tt.serviceID})
+			assert.NoError(t, err)
+			assert.True(t, resp.Success)
+			assert.Equal(t, tt.expectedTotal, resp.TotalCredits)
+			ids := []uint32{}
+			for _, clientPackage := range resp.Packages {
+				ids = append(ids, clientPackage.Id)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+}
+
+func TestListCreditHistory(t *testing.T) {
+	mockRepo := new(MockPackageRepository)
+	srv := services.NewPackageService(mockRepo)
+	appointmentID := uint(7)
+	createdAt := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+
+	(mockRepo).On("ListCreditTransactions", uint(1)).Return([]models.CreditTransaction{
+		{ID: 2, ClientPackageID: 3, ClientID: 1, AppointmentID: &appointmentID, Kind: models.CreditUse, Delta: -1, CreatedAt: createdAt},
+		{ID: 1, ClientPackageID: 3, ClientID: 1, Kind: models.CreditPurchase, Delta: 10, CreatedAt: createdAt},
+	}, nil).Once()
+
+	resp, err := srv.ListCreditHistory(context.Background(), &pb.ListCreditHistoryRequest{ClientId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.ListCreditHistoryResponse{Message: "Credit history found", Success: true, Transactions: []*pb.CreditTransaction{
+		{Id: 2, ClientPackageId: 3, AppointmentId: 7, Kind: "use", Delta: -1, CreatedAt: "2025-03-10T10:00:00Z"},
+		{Id: 1, ClientPackageId: 3, Kind: "purchase", Delta: 10, CreatedAt: "2025-03-10T10:00:00Z"},
+	}}, resp)
+	(mockRepo).AssertExpectations(t)
+}
+
+func TestCreditsOwnership(t *testing.T) {
+	srv := services.NewPackageService(new(MockPackageRepository))
+	// Un cliente no puede ver los créditos de otro
+	ctx := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 4})
+
+	_, err := srv.GetCreditBalance(ctx, &pb.GetCreditBalanceRequest{ClientId: 1})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	_, err = srv.ListCreditHistory(ctx, &pb.ListCreditHistoryRequest{ClientId: 1})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+}
@@ -0,0 +1,308 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/meetings"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/payments"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFakeProvider(t *testing.T) {
+	provider := payments.NewFakeProvider("secret")
+
+	intent, err := provider.CreateIntent(2000, "USD", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, payments.IntentRequiresPayment, intent.Status)
+	// No se puede capturar antes de que el cliente pague
+	assert.ErrorIs(t, provider.Capture(intent.ID), payments.ErrInvalidState)
+
+	payload, signature, err := provider.Authorize(intent.ID)
+	assert.NoError(t, err)
+	event, err := provider.VerifyWebhook(payload, signature)
+	assert.NoError(t, err)
+	assert.Equal(t, &payments.Event{Type: payments.EventAuthorized, IntentID: intent.ID}, event)
+	_, err = provider.VerifyWebhook(payload, "forged")
+	assert.ErrorIs(t, err, payments.ErrInvalidSignature)
+
+	assert.NoError(t, provider.Capture(intent.ID))
+	assert.ErrorIs(t, provider.Refund(intent.ID, 3000), payments.ErrInvalidState)
+	assert.NoError(t, provider.Refund(intent.ID, 2000))
+	assert.Equal(t, payments.IntentRefunded, provider.Status(intent.ID))
+}
+
+func TestStripeProvider(t *testing.T) {
+	var requests []*http.Request
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		requests, forms = append(requests, r), append(forms, form)
+		switch r.URL.Path {
+		case "/v1/payment_intents":
+			fmt.Fprint(w, `{"id":"pi_1","client_secret":"pi_1_secret","amount":2000,"currency":"usd","status":"requires_payment_method"}`)
+		case "/v1/payment_intents/pi_1/capture":
+			fmt.Fprint(w, `{"id":"pi_1","status":"succeeded"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":"charge_already_refunded","message":"already refunded"}}`)
+		}
+	}))
+	defer server.Close()
+	provider := payments.NewStripeProvider("sk_test", "whsec")
+	provider.BaseURL = server.URL
+
+	intent, err := provider.CreateIntent(2000, "USD", "service 4 for client 1")
+	assert.NoError(t, err)
+	assert.Equal(t, &payments.Intent{ID: "pi_1", ClientSecret: "pi_1_secret", AmountCents: 2000, Currency: "USD",
+		Status: payments.IntentRequiresPayment}, intent)
+	user, _, _ := requests[0].BasicAuth()
+	assert.Equal(t, "sk_test", user)
+	// El cobro se retiene hasta que el webhook confirma la reserva
+	assert.Equal(t, "manual", forms[0].Get("capture_method"))
+
+	assert.NoError(t, provider.Capture("pi_1"))
+	assert.Equal(t, "capture-pi_1", requests[1].Header.Get("Idempotency-Key"))
+	assert.ErrorIs(t, provider.Refund("pi_1", 2000), payments.ErrInvalidState)
+	assert.Equal(t, "2000", forms[2].Get("amount"))
+
+	payload := []byte(`{"type":"payment_intent.amount_capturable_updated","data":{"object":{"id":"pi_1"}}}`)
+	now := time.Now().Unix()
+	event, err := provider.VerifyWebhook(payload, fmt.Sprintf("t=%d,v1=%s", now, provider.Sign(now, payload)))
+	assert.NoError(t, err)
+	assert.Equal(t, &payments.Event{Type: payments.EventAuthorized, IntentID: "pi_1"}, event)
+	_, err = provider.VerifyWebhook(payload, fmt.Sprintf("t=%d,v1=forged", now))
+	assert.ErrorIs(t, err, payments.ErrInvalidSignature)
+	// Un webhook viejo se toma como repetido aunque la firma sea válida
+	old := now - 3600
+	_, err = provider.VerifyWebhook(payload, fmt.Sprintf("t=%d,v1=%s", old, provider.Sign(old, payload)))
+	assert.ErrorIs(t, err, payments.ErrInvalidSignature)
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := payments.NewProvider(payments.ProviderStripe, "sk_test", "whsec", false)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.ProviderStripe, provider.Name())
+	_, err = payments.NewProvider(payments.ProviderStripe, "", "whsec", false)
+	assert.Error(t, err)
+
+	// El proveedor fake acepta cualquier pago, solo en desarrollo
+	_, err = payments.NewProvider(payments.ProviderFake, "", "whsec", false)
+	assert.Error(t, err)
+	provider, err = payments.NewProvider(payments.ProviderFake, "", "whsec", true)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.ProviderFake, provider.Name())
+	_, err = payments.NewProvider("paypal", "", "whsec", true)
+	assert.Error(t, err)
+
+	assert.Error(t, payments.CheckWebhookSecret("", true))
+	assert.Error(t, payments.CheckWebhookSecret(payments.DefaultWebhookSecret, false))
+	assert.NoError(t, payments.CheckWebhookSecret(payments.DefaultWebhookSecret, true))
+	assert.NoError(t, payments.CheckWebhookSecret("whsec", false))
+}
+
+func TestHandlePaymentWebhook(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	mockNotif := new(MockNotificationServiceClient)
+	provider := payments.NewFakeProvider("secret")
+	srv := &services.PaymentServiceImpl{Repo: mockRepo, Provider: provider, NotifClient: mockNotif}
+
+	tests := []struct {
+		name           string
+		mockSetup      func() *pb.PaymentWebhookRequest
+		expectedResp   *pb.PaymentWebhookResponse
+		expectedStatus string
+	}{
+		{
+			name: "Captured",
+			mockSetup: func() *pb.PaymentWebhookRequest {
+				intent, _ := provider.CreateIntent(2000, "USD", "test")
+				payload, signature, _ := provider.Authorize(intent.ID)
+				payment := &models.Payment{ID: 1, AppointmentID: 7, IntentID: intent.ID, AmountCents: 2000, Status: models.PaymentPending}
+				(mockRepo).On("GetPaymentByIntent", intent.ID).Return(payment, nil).Once()
+				(mockRepo).On("ConfirmPayment", payment).Return(&models.Appointment{ID: 7, ClientID: 1, SlotID: 3, Status: models.AppointmentBooked}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, ProfessionalID: 2, StartTime: time.Now(), EndTime: time.Now()}, nil).Once()
+				(mockNotif).On("SendAppointmentNotification", mock.Anything, mock.AnythingOfType("*pb.SendAppointmentNotificationRequest")).
+					Return(&pb.SendAppointmentNotificationResponse{Message: "Sent", Success: true}, nil).Once()
+				return &pb.PaymentWebhookRequest{Payload: payload, Signature: signature}
+			},
+			expectedResp:   &pb.PaymentWebhookResponse{Message: "Payment captured", Success: true},
+			expectedStatus: payments.IntentCaptured,
+		},
+		{
+			name: "CapturedAwaitingApproval",
+			mockSetup: func() *pb.PaymentWebhookRequest {
+				intent, _ := provider.CreateIntent(2000, "USD", "test")
+				payload, signature, _ := provider.Authorize(intent.ID)
+				payment := &models.Payment{ID: 1, AppointmentID: 7, IntentID: intent.ID, AmountCents: 2000, Status: models.PaymentPending}
+				(mockRepo).On("GetPaymentByIntent", intent.ID).Return(payment, nil).Once()
+				(mockRepo).On("ConfirmPayment", payment).
+					Return(&models.Appointment{ID: 7, ClientID: 1, SlotID: 3, Status: models.AppointmentPendingApproval}, nil).Once()
+				(mockRepo).On("GetSlotByID", uint(3)).Return(&models.Slot{ID: 3, ProfessionalID: 2, StartTime: time.Now(), EndTime: time.Now()}, nil).Once()
+				(mockNotif).On("SendAppointmentUpdate", mock.Anything, mock.MatchedBy(func(r *pb.SendAppointmentUpdateRequest) bool {
+					return r.Event == "requested"
+				})).Return(&pb.SendAppointmentUpdateResponse{Message: "Sent", Success: true}, nil).Once()
+				return &pb.PaymentWebhookRequest{Payload: payload, Signature: signature}
+			},
+			expectedResp:   &pb.PaymentWebhookResponse{Message: "Payment captured", Success: true},
+			expectedStatus: payments.IntentCaptured,
+		},
+		{
+			name: "ExpiredWhilePaying",
+			mockSetup: func() *pb.PaymentWebhookRequest {
+				intent, _ := provider.CreateIntent(2000, "USD", "test")
+				payload, signature, _ := provider.Authorize(intent.ID)
+				payment := &models.Payment{ID: 1, AppointmentID: 7, IntentID: intent.ID, AmountCents: 2000, Status: models.PaymentPending}
+				(mockRepo).On("GetPaymentByIntent", intent.ID).Return(payment, nil).Once()
+				(mockRepo).On("ConfirmPayment", payment).Return((*models.Appointment)(nil), repositories.ErrStatusChanged).Once()
+				(mockRepo).On("UpdatePaymentStatus", uint(1), models.PaymentRefunded).Return(nil).Once()
+				return &pb.PaymentWebhookRequest{Payload: payload, Signature: signature}
+			},
+			expectedResp:   &pb.PaymentWebhookResponse{Message: "Appointment no longer pending, payment refunded", Success: true},
+			expectedStatus: payments.IntentRefunded,
+		},
+		{
+			// Ya se capturó, si la cita no se puede confirmar no se cobra
+			name: "ConfirmFailsRefunds",
+			mockSetup: func() *pb.PaymentWebhookRequest {
+				intent, _ := provider.CreateIntent(2000, "USD", "test")
+				payload, signature, _ := provider.Authorize(intent.ID)
+				payment := &models.Payment{ID: 1, AppointmentID: 7, IntentID: intent.ID, AmountCents: 2000, Status: models.PaymentPending}
+				(mockRepo).On("GetPaymentByIntent", intent.ID).Return(payment, nil).Once()
+				(mockRepo).On("ConfirmPayment", payment).Return((*models.Appointment)(nil), errors.New("db error")).Once()
+				(mockRepo).On("UpdatePaymentStatus", uint(1), models.PaymentRefunded).Return(nil).Once()
+				(mockRepo).On("ReleaseAppointment", uint(7), []string{models.AppointmentPaymentPending}, models.AppointmentExpired).Return(nil).Once()
+				(mockRepo).On("GetAppointmentByID", uint(7)).Return(&models.Appointment{ID: 7, Status: models.AppointmentExpired}, nil).Once()
+				return &pb.PaymentWebhookRequest{Payload: payload, Signature: signature}
+			},
+			expectedResp:   &pb.PaymentWebhookResponse{Message: "Appointment could not be confirmed, payment refunded", Success: true},
+			expectedStatus: payments.IntentRefunded,
+		},
+		{
+			name: "AlreadyProcessed",
+			mockSetup: func() *pb.PaymentWebhookRequest {
+				intent, _ := provider.CreateIntent(2000, "USD", "test")
+				payload, signature, _ := provider.Authorize(intent.ID)
+				(mockRepo).On("GetPaymentByIntent", intent.ID).
+					Return(&models.Payment{ID: 1, IntentID: intent.ID, Status: models.PaymentCaptured}, nil).Once()
+				return &pb.PaymentWebhookRequest{Payload: payload, Signature: signature}
+			},
+			expectedResp:   &pb.PaymentWebhookResponse{Message: "Payment already processed", Success: true},
+			expectedStatus: payments.IntentAuthorized,
+		},
+		{
+			name: "InvalidSignature",
+			mockSetup: func() *pb.PaymentWebhookRequest {
+				intent, _ := provider.CreateIntent(2000, "USD", "test")
+				payload, _, _ := provider.Authorize(intent.ID)
+				return &pb.PaymentWebhookRequest{Payload: payload, Signature: "forged"}
+			},
+			expectedResp:   &pb.PaymentWebhookResponse{Message: "Invalid webhook", Success: false},
+			expectedStatus: payments.IntentAuthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.mockSetup()
+			resp, err := srv.HandlePaymentWebhook(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			event, _ := provider.VerifyWebhook(req.Payload, provider.Sign(req.Payload))
+			assert.Equal(t, tt.expectedStatus, provider.Status(event.IntentID))
+			(mockRepo).AssertExpectations(t)
+			(mockNotif).AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPayment(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	srv := services.NewPaymentService(mockRepo, payments.NewFakeProvider("secret"), nil, nil)
+
+	appointment := &models.Appointment{ID: 7, ClientID: 1, ProfessionalID: 2}
+	payment := &models.Payment{ID: 1, AppointmentID: 7, Provider: "fake", IntentID: "pi_1", AmountCents: 2000, Currency: "USD", Status: models.PaymentCaptured}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "Client",
+			ctx:  rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 1}),
+			mockSetup: func() {
+				mockRepo.On("GetAppointmentByID", uint(7)).Return(appointment, nil).Once()
+				mockRepo.On("GetPaymentByAppointment", uint(7)).Return(payment, nil).Once()
+			},
+		},
+		{
+			name: "Professional",
+			ctx:  rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 2}),
+			mockSetup: func() {
+				mockRepo.On("GetAppointmentByID", uint(7)).Return(appointment, nil).Once()
+				mockRepo.On("GetPaymentByAppointment", uint(7)).Return(payment, nil).Once()
+			},
+		},
+		{
+			name: "AnotherClient",
+			ctx:  rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 4}),
+			mockSetup: func() {
+				mockRepo.On("GetAppointmentByID", uint(7)).Return(appointment, nil).Once()
+			},
+			expectedErr: rbac.ErrNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.GetPayment(tt.ctx, &pb.GetPaymentRequest{AppointmentId: 7})
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedErr == nil, resp.Success)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReleaseUnpaid(t *testing.T) {
+	mockRepo := new(MockAgendaRepository)
+	meetingProvider := meetings.NewFakeProvider()
+	srv := services.NewPaymentService(mockRepo, payments.NewFakeProvider("secret"), meetingProvider, nil)
+	room, _ := meetingProvider.CreateRoom("appointment")
+
+	createdBefore := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	pending := []string{models.AppointmentPaymentPending}
+	(mockRepo).On("ListPendingPayments", createdBefore).Return([]models.Payment{
+		{ID: 1, AppointmentID: 7, Status: models.PaymentPending},
+		{ID: 2, AppointmentID: 8, Status: models.PaymentPending},
+	}, nil).Once()
+	(mockRepo).On("ReleaseAppointment", uint(7), pending, models.AppointmentExpired).Return(nil).Once()
+	(mockRepo).On("UpdatePaymentStatus", uint(1), models.PaymentExpired).Return(nil).Once()
+	(mockRepo).On("GetAppointmentByID", uint(7)).Return(&models.Appointment{
+		ID: 7, Status: models.AppointmentExpired, Modality: models.ModalityRemote, MeetingID: room.ID, MeetingURL: room.URL,
+	}, nil).Once()
+	(mockRepo).On("ClearMeetingURL", uint(7)).Return(nil).Once()
+	// La cita 8 se pagó justo antes de expirar, su pago no se toca
+	(mockRepo).On("ReleaseAppointment", uint(8), pending, models.AppointmentExpired).Return(repositories.ErrStatusChanged).Once()
+
+	err := srv.ReleaseUnpaid(createdBefore)
+	assert.NoError(t, err)
+	assert.False(t, meetingProvider.Active(room.ID))
+	(mockRepo).AssertExpectations(t)
+}
@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupReconcileMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.ReconcileRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewReconcileRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestListOrphanSlotsRepo(t *testing.T) {
+	sqlDB, mock, repo := setupReconcileMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "slots" WHERE available = $1 AND released = $2 AND NOT EXISTS (SELECT 1 FROM appointments WHERE appointments.slot_id = slots.id AND appointments.status IN ($3,$4,$5,$6)) ORDER BY id`)).
+		WithArgs(false, false, "payment_pending", "pending_approval", "booked", "completed").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "professional_id", "available"}).AddRow(3, 1, false))
+
+	slots, err := repo.ListOrphanSlots()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Slot{{ID: 3, ProfessionalID: 1}}, slots)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAppointmentsWithoutSlotRepo(t *testing.T) {
+	sqlDB, mock, repo := setupReconcileMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "appointments"."id","appointments"."client_id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slot_id", "status"}).AddRow(8, 9, "booked"))
+
+	appointments, err := repo.ListAppointmentsWithoutSlot()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Appointment{{ID: 8, SlotID: 9, Status: "booked"}}, appointments)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFreeOrphanSlotRepo(t *testing.T) {
+	getSettings := regexp.QuoteMeta(`SELECT * FROM "professional_settings" WHERE professional_id = $1 ORDER BY "professional_settings"."professional_id" LIMIT $2`)
+	settingsColumns := []string{"professional_id", "availability_mode"}
+	freeOrphan := func(column string) string {
+		return regexp.QuoteMeta(`UPDATE "slots" SET "` + column + `"=$1 WHERE id = $2 AND available = $3 AND released = $4 AND NOT EXISTS (SELECT 1 FROM appointments WHERE appointments.slot_id = slots.id AND appointments.status IN ($5,$6,$7,$8))`)
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedFreed bool
+	}{
+		{
+			name: "Freed",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(getSettings).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns))
+				mock.ExpectBegin()
+				mock.ExpectExec(freeOrphan("available")).
+					WithArgs(true, uint(3), false, false, "payment_pending", "pending_approval", "booked", "completed").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedFreed: true,
+		},
+		{
+			// Otra cita tomó el slot antes de la reparación
+			name: "TakenMeanwhile",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(getSettings).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns))
+				mock.ExpectBegin()
+				mock.ExpectExec(freeOrphan("available")).
+					WithArgs(true, uint(3), false, false, "payment_pending", "pending_approval", "booked", "completed").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedFreed: false,
+		},
+		{
+			// En modo calculado el slot se creó para la cita, no se ofrece a otros por su id
+			name: "ComputedSlotReleased",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(getSettings).WithArgs(uint(2), 1).
+					WillReturnRows(sqlmock.NewRows(settingsColumns).AddRow(2, models.AvailabilityComputed))
+				mock.ExpectBegin()
+				mock.ExpectExec(freeOrphan("released")).
+					WithArgs(true, uint(3), false, false, "payment_pending", "pending_approval", "booked", "completed").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedFreed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, repo := setupReconcileMockDB(t)
+			defer sqlDB.Close()
+			tt.mockSetup(mock)
+
+			freed, err := repo.FreeOrphanSlot(3, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFreed, freed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReconcileRepository struct {
+	mock.Mock
+}
+
+func (m *MockReconcileRepository) ListOrphanSlots() ([]models.Slot, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Slot), args.Error(1)
+}
+
+func (m *MockReconcileRepository) ListFreeBookedSlots() ([]models.Appointment, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockReconcileRepository) ListAppointmentsWithoutSlot() ([]models.Appointment, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockReconcileRepository) FreeOrphanSlot(slotID, professionalID uint) (bool, error) {
+	args := m.Called(slotID, professionalID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReconcileRepository) TakeBookedSlot(slotID uint) (bool, error) {
+	args := m.Called(slotID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestParseReconcilePolicy(t *testing.T) {
+	policy, err := services.ParseReconcilePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, services.ReconcilePolicy{}, policy)
+
+	policy, err = services.ParseReconcilePolicy("orphan_slot, free_booked_slot")
+	assert.NoError(t, err)
+	assert.Equal(t, services.ReconcilePolicy{RepairOrphanSlots: true, RepairFreeBookedSlots: true}, policy)
+
+	// Las citas sin slot no se pueden reparar solas
+	_, err = services.ParseReconcilePolicy("missing_slot")
+	assert.Error(t, err)
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       services.ReconcilePolicy
+		req          *pb.ReconcileRequest
+		mockSetup    func(*MockReconcileRepository)
+		expectedResp *pb.ReconcileResponse
+	}{
+		{
+			name:   "RepairsPerPolicy",
+			policy: services.ReconcilePolicy{RepairOrphanSlots: true},
+			req:    &pb.ReconcileRequest{},
+			mockSetup: func(repo *MockReconcileRepository) {
+				repo.On("ListOrphanSlots").Return([]models.Slot{{ID: 3, ProfessionalID: 2}, {ID: 4, ProfessionalID: 2}}, nil).Once()
+				repo.On("FreeOrphanSlot", uint(3), uint(2)).Return(true, nil).Once()
+				// El slot 4 se reservó entre la consulta y la reparación
+				repo.On("FreeOrphanSlot", uint(4), uint(2)).Return(false, nil).Once()
+				repo.On("ListFreeBookedSlots").Return([]models.Appointment{{ID: 7, SlotID: 5}}, nil).Once()
+				repo.On("ListAppointmentsWithoutSlot").Return([]models.Appointment{{ID: 8, SlotID: 9}}, nil).Once()
+			},
+			expectedResp: &pb.ReconcileResponse{Message: "4 issues found", Success: true, Issues: []*pb.ReconcileIssue{
+				{Kind: "orphan_slot", SlotId: 3, Repaired: true},
+				{Kind: "orphan_slot", SlotId: 4},
+				{Kind: "free_booked_slot", SlotId: 5, AppointmentId: 7},
+				{Kind: "missing_slot", SlotId: 9, AppointmentId: 8},
+			}},
+		},
+		{
+			name:   "DryRun",
+			policy: services.ReconcilePolicy{RepairOrphanSlots: true, RepairFreeBookedSlots: true},
+			req:    &pb.ReconcileRequest{DryRun: true},
+			mockSetup: func(repo *MockReconcileRepository) {
+				repo.On("ListOrphanSlots").Return([]models.Slot{{ID: 3}}, nil).Once()
+				repo.On("ListFreeBookedSlots").Return([]models.Appointment{{ID: 7, SlotID: 5}}, nil).Once()
+				repo.On("ListAppointmentsWithoutSlot").Return([]models.Appointment{}, nil).Once()
+			},
+			expectedResp: &pb.ReconcileResponse{Message: "2 issues found", Success: true, Issues: []*pb.ReconcileIssue{
+				{Kind: "orphan_slot", SlotId: 3},
+				{Kind: "free_booked_slot", SlotId: 5, AppointmentId: 7},
+			}},
+		},
+		{
+			name:   "TakesBookedSlot",
+			policy: services.ReconcilePolicy{RepairFreeBookedSlots: true},
+			req:    &pb.ReconcileRequest{},
+			mockSetup: func(repo *MockReconcileRepository) {
+				repo.On("ListOrphanSlots").Return([]models.Slot{}, nil).Once()
+				repo.On("ListFreeBookedSlots").Return([]models.Appointment{{ID: 7, SlotID: 5}}, nil).Once()
+				repo.On("TakeBookedSlot", uint(5)).Return(true, nil).Once()
+				repo.On("ListAppointmentsWithoutSlot").Return([]models.Appointment{}, nil).Once()
+			},
+			expectedResp: &pb.ReconcileResponse{Message: "1 issues found", Success: true, Issues: []*pb.ReconcileIssue{
+				{Kind: "free_booked_slot", SlotId: 5, AppointmentId: 7, Repaired: true},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReconcileRepository)
+			tt.mockSetup(mockRepo)
+			srv := services.NewReconcileService(mockRepo, tt.policy)
+
+			resp, err := srv.Reconcile(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
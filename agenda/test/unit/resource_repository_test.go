@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupResourceMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.ResourceRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewResourceRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestCreateResourceRepo(t *testing.T) {
+	sqlDB, mock, repo := setupResourceMockDB(t)
+	defer sqlDB.Close()
+
+	tests := []struct {
+		name      string
+		resource  *models.Resource
+		mockSetup func(sqlmock.Sqlmock)
+		expectErr bool
+	}{
+		{
+			name:     "Success",
+			resource: &models.Resource{Name: "Sala 1", Type: "room"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "resources" ("name","type") VALUES ($1,$2) RETURNING "id"`)).
+					WithArgs("Sala 1", "room").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			expectErr: false,
+		},
+		{
+			name:     "DatabaseError",
+			resource: &models.Resource{Name: "Sala 1", Type: "room"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "resources" ("name","type") VALUES ($1,$2) RETURNING "id"`)).
+					WithArgs("Sala 1", "room").
+					WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mock)
+			err := repo.CreateResource(tt.resource)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), tt.resource.ID, "El ID debería haberse asignado")
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListResourcesRepo(t *testing.T) {
+	sqlDB, mock, repo := setupResourceMockDB(t)
+	defer sqlDB.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "type"}).AddRow(2, "Laser", "equipment")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "resources" WHERE type = $1`)).
+		WithArgs("equipment").
+		WillReturnRows(rows)
+
+	resources, err := repo.ListResources("equipment")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Resource{{ID: 2, Name: "Laser", Type: "equipment"}}, resources)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListReservationsRepo(t *testing.T) {
+	sqlDB, mock, repo := setupResourceMockDB(t)
+	defer sqlDB.Close()
+
+	from := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "resource_id", "appointment_id", "start_time", "end_time", "reason"}).
+		AddRow(1, 4, 0, from, from.Add(30*time.Minute), "Mantenimiento")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "resource_reservations" WHERE resource_id IN ($1,$2) AND start_time < $3 AND end_time > $4`)).
+		WithArgs(uint(4), uint(5), to, from).
+		WillReturnRows(rows)
+
+	reservations, err := repo.ListReservations([]uint{4, 5}, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ResourceReservation{
+		{ID: 1, ResourceID: 4, StartTime: from, EndTime: from.Add(30 * time.Minute), Reason: "Mantenimiento"},
+	}, reservations)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateIntakeFormRepo(t *testing.T) {
+	sqlDB, mock, repo := setupResourceMockDB(t)
+	defer sqlDB.Close()
+
+	// El formulario se guarda como la versión siguiente a la vigente
+	form := &models.IntakeForm{ServiceID: 3, Title: "Antes de la consulta", Schema: `[]`}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(version), 0) FROM "intake_forms" WHERE service_id = $1 AND professional_id = $2`)).
+		WithArgs(uint(3), uint(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "intake_forms" ("service_id","professional_id","version","title","schema","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+		WithArgs(uint(3), uint(0), uint(3), "Antes de la consulta", `[]`, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectCommit()
+
+	err := repo.CreateIntakeForm(form)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), form.ID)
+	assert.Equal(t, uint(3), form.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
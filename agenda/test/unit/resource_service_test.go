@@ -0,0 +1,349 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/agenda/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockResourceRepository struct {
+	mock.Mock
+}
+
+func (m *MockResourceRepository) CreateResource(resource *models.Resource) error {
+	args := m.Called(resource)
+	return args.Error(0)
+}
+
+func (m *MockResourceRepository) ListResources(resourceType string) ([]models.Resource, error) {
+	args := m.Called(resourceType)
+	return args.Get(0).([]models.Resource), args.Error(1)
+}
+
+func (m *MockResourceRepository) GetResourcesByIDs(ids []uint) ([]models.Resource, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]models.Resource), args.Error(1)
+}
+
+func (m *MockResourceRepository) CreateReservation(reservation *models.ResourceReservation) error {
+	args := m.Called(reservation)
+	return args.Error(0)
+}
+
+func (m *MockResourceRepository) ListReservations(resourceIDs []uint, from, to time.Time) ([]models.ResourceReservation, error) {
+	args := m.Called(resourceIDs, from, to)
+	return args.Get(0).([]models.ResourceReservation), args.Error(1)
+}
+
+func (m *MockResourceRepository) CreateService(service *models.Service) error {
+	args := m.Called(service)
+	return args.Error(0)
+}
+
+func (m *MockResourceRepository) GetServiceByID(id uint) (*models.Service, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockResourceRepository) ListServices() ([]models.Service, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Service), args.Error(1)
+}
+
+func (m *MockResourceRepository) CreateIntakeForm(form *models.IntakeForm) error {
+	args := m.Called(form)
+	return args.Error(0)
+}
+
+func (m *MockResourceRepository) GetLatestIntakeForm(serviceID, professionalID uint) (*models.IntakeForm, error) {
+	args := m.Called(serviceID, professionalID)
+	return args.Get(0).(*models.IntakeForm), args.Error(1)
+}
+
+func (m *MockResourceRepository) GetIntakeFormByID(id uint) (*models.IntakeForm, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.IntakeForm), args.Error(1)
+}
+
+func TestCreateResource(t *testing.T) {
+	mockRepo := new(MockResourceRepository)
+	srv := services.NewResourceService(mockRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.CreateResourceRequest
+		mockSetup    func()
+		expectedResp *pb.CreateResourceResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req:  &pb.CreateResourceRequest{Name: "Sala 1", Type: "room"},
+			mockSetup: func() {
+				mockRepo.On("CreateResource", mock.AnythingOfType("*models.Resource")).Return(nil).Once()
+			},
+			expectedResp: &pb.CreateResourceResponse{Message: "Resource created", Success: true},
+			expectedErr:  nil,
+		},
+		{
+			name:         "InvalidType",
+			req:          &pb.CreateResourceRequest{Name: "Sala 1", Type: "desk"},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateResourceResponse{Message: "type must be 'room' or 'equipment'", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "DatabaseError",
+			req:  &pb.CreateResourceRequest{Name: "Laser", Type: "equipment"},
+			mockSetup: func() {
+				mockRepo.On("CreateResource", mock.AnythingOfType("*models.Resource")).Return(errors.New("db error")).Once()
+			},
+			expectedResp: &pb.CreateResourceResponse{Message: "Error creating resource", Success: false},
+			expectedErr:  errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CreateResource(tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlockResource(t *testing.T) {
+	mockRepo := new(MockResourceRepository)
+	srv := services.NewResourceService(mockRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.BlockResourceRequest
+		mockSetup    func()
+		expectedResp *pb.BlockResourceResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.BlockResourceRequest{ResourceId: 1, StartTime: "2025-03-10T10:00:00Z", EndTime: "2025-03-10T12:00:00Z", Reason: "Mantenimiento"},
+			mockSetup: func() {
+				mockRepo.On("CreateReservation", mock.MatchedBy(func(r *models.ResourceReservation) bool {
+					return r.ResourceID == 1 && r.AppointmentID == 0 && r.Reason == "Mantenimiento"
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.BlockResourceResponse{Message: "Resource blocked", Success: true},
+		},
+		{
+			name:         "EndBeforeStart",
+			req:          &pb.BlockResourceRequest{ResourceId: 1, StartTime: "2025-03-10T12:00:00Z", EndTime: "2025-03-10T10:00:00Z"},
+			mockSetup:    func() {},
+			expectedResp: &pb.BlockResourceResponse{Message: "end_time must be after start_time", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.BlockResource(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateService(t *testing.T) {
+	mockRepo := new(MockResourceRepository)
+	srv := services.NewResourceService(mockRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.CreateServiceRequest
+		mockSetup    func()
+		expectedResp *pb.CreateServiceResponse
+	}{
+		{
+			name: "SuccessWithResources",
+			req:  &pb.CreateServiceRequest{Name: "Depilación láser", DurationMinutes: 30, ResourceIds: []uint32{1, 2}},
+			mockSetup: func() {
+				mockRepo.On("GetResourcesByIDs", []uint{1, 2}).
+					Return([]models.Resource{{ID: 1, Type: "room"}, {ID: 2, Type: "equipment"}}, nil).Once()
+				mockRepo.On("CreateService", mock.MatchedBy(func(s *models.Service) bool {
+					return len(s.Resources) == 2 && s.DurationMinutes == 30
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.CreateServiceResponse{Message: "Service created", Success: true},
+		},
+		{
+			name: "UnknownResource",
+			req:  &pb.CreateServiceRequest{Name: "Depilación láser", DurationMinutes: 30, ResourceIds: []uint32{1, 9}},
+			mockSetup: func() {
+				mockRepo.On("GetResourcesByIDs", []uint{1, 9}).Return([]models.Resource{{ID: 1}}, nil).Once()
+			},
+			expectedResp: &pb.CreateServiceResponse{Message: "Resource not found", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CreateService(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListServices(t *testing.T) {
+	mockRepo := new(MockResourceRepository)
+	srv := services.NewResourceService(mockRepo)
+
+	mockRepo.On("ListServices").Return([]models.Service{
+		{ID: 1, Name: "Depilación láser", DurationMinutes: 30, Resources: []models.Resource{{ID: 1}, {ID: 2}}},
+	}, nil).Once()
+
+	resp, err := srv.ListServices(&pb.ListServicesRequest{})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Services, 1)
+	assert.Equal(t, []uint32{1, 2}, resp.Services[0].ResourceIds)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateIntakeForm(t *testing.T) {
+	mockRepo := new(MockResourceRepository)
+	srv := services.NewResourceService(mockRepo)
+
+	fields := []*pb.IntakeField{
+		{Key: "allergies", Label: "Alergias", Type: "choice", Options: []string{"none", "food"}},
+		{Key: "birth_date", Label: "Fecha de nacimiento", Type: "date", Required: true},
+		{Key: "consent", Label: "Acepto", Type: "consent", Required: true},
+	}
+
+	tests := []struct {
+		name         string
+		req          *pb.CreateIntakeFormRequest
+		mockSetup    func()
+		expectedResp *pb.CreateIntakeFormResponse
+	}{
+		{
+			name: "NewVersion",
+			req:  &pb.CreateIntakeFormRequest{ServiceId: 3, Title: "Antes de la consulta", Fields: fields},
+			mockSetup: func() {
+				mockRepo.On("GetServiceByID", uint(3)).Return(&models.Service{ID: 3}, nil).Once()
+				mockRepo.On("CreateIntakeForm", mock.MatchedBy(func(f *models.IntakeForm) bool {
+					return f.ServiceID == 3 && f.ProfessionalID == 0 && f.Title == "Antes de la consulta" &&
+						f.Schema == `[{"key":"allergies","label":"Alergias","type":"choice","options":["none","food"]},`+
+							`{"key":"birth_date","label":"Fecha de nacimiento","type":"date","required":true},`+
+							`{"key":"consent","label":"Acepto","type":"consent","required":true}]`
+				})).Run(func(args mock.Arguments) {
+					form := args.Get(0).(*models.IntakeForm)
+					form.ID, form.Version = 5, 2
+				}).Return(nil).Once()
+			},
+			expectedResp: &pb.CreateIntakeFormResponse{Message: "Intake form created", Success: true, FormId: 5, Version: 2},
+		},
+		{
+			name:         "ServiceAndProfessional",
+			req:          &pb.CreateIntakeFormRequest{ServiceId: 3, ProfessionalId: 2, Title: "Antes de la consulta", Fields: fields},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateIntakeFormResponse{Message: "Either service_id or professional_id is required", Success: false},
+		},
+		{
+			name: "ChoiceWithoutOptions",
+			req: &pb.CreateIntakeFormRequest{ProfessionalId: 2, Title: "Antes de la consulta",
+				Fields: []*pb.IntakeField{{Key: "allergies", Label: "Alergias", Type: "choice"}}},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateIntakeFormResponse{Message: "Invalid form: choice field allergies needs options", Success: false},
+		},
+		{
+			name: "DuplicatedKey",
+			req: &pb.CreateIntakeFormRequest{ProfessionalId: 2, Title: "Antes de la consulta",
+				Fields: []*pb.IntakeField{{Key: "notes", Label: "Notas", Type: "text"}, {Key: "notes", Label: "Otras", Type: "text"}}},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateIntakeFormResponse{Message: "Invalid form: field notes is duplicated", Success: false},
+		},
+		{
+			name: "UnknownType",
+			req: &pb.CreateIntakeFormRequest{ProfessionalId: 2, Title: "Antes de la consulta",
+				Fields: []*pb.IntakeField{{Key: "photo", Label: "Foto", Type: "file"}}},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateIntakeFormResponse{Message: `Invalid form: field photo has an unknown type "file"`, Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CreateIntakeForm(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResourceOwnership(t *testing.T) {
+	mockRepo := new(MockResourceRepository)
+	srv := services.NewResourceService(mockRepo)
+
+	professional := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 3})
+	fields := []*pb.IntakeField{{Key: "notes", Label: "Notas", Type: "text"}}
+
+	// Los recursos son de todos, un profesional no puede bloquearlos
+	blockResp, err := srv.BlockResource(professional, &pb.BlockResourceRequest{ResourceId: 1,
+		StartTime: "2025-03-10T10:00:00Z", EndTime: "2025-03-10T12:00:00Z"})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.BlockResourceResponse{Message: "Not allowed", Success: false}, blockResp)
+
+	// Ni crear el formulario de otro profesional o de un servicio
+	formResp, err := srv.CreateIntakeForm(professional, &pb.CreateIntakeFormRequest{ProfessionalId: 2, Title: "Antes de la consulta", Fields: fields})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.CreateIntakeFormResponse{Message: "Not allowed", Success: false}, formResp)
+
+	formResp, err = srv.CreateIntakeForm(professional, &pb.CreateIntakeFormRequest{ServiceId: 1, Title: "Antes de la consulta", Fields: fields})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	assert.Equal(t, &pb.CreateIntakeFormResponse{Message: "Not allowed", Success: false}, formResp)
+
+	// El suyo sí
+	mockRepo.On("CreateIntakeForm", mock.MatchedBy(func(f *models.IntakeForm) bool {
+		return f.ProfessionalID == 3
+	})).Run(func(args mock.Arguments) {
+		form := args.Get(0).(*models.IntakeForm)
+		form.ID, form.Version = 7, 1
+	}).Return(nil).Once()
+	formResp, err = srv.CreateIntakeForm(professional, &pb.CreateIntakeFormRequest{ProfessionalId: 3, Title: "Antes de la consulta", Fields: fields})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CreateIntakeFormResponse{Message: "Intake form created", Success: true, FormId: 7, Version: 1}, formResp)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetIntakeForm(t *testing.T) {
+	mockRepo := new(MockResourceRepository)
+	srv := services.NewResourceService(mockRepo)
+
+	// El servicio no tiene formulario, se usa el del profesional
+	mockRepo.On("GetLatestIntakeForm", uint(3), uint(0)).Return((*models.IntakeForm)(nil), gorm.ErrRecordNotFound).Once()
+	mockRepo.On("GetLatestIntakeForm", uint(0), uint(2)).Return(&models.IntakeForm{ID: 4, ProfessionalID: 2, Version: 1,
+		Title: "Primera visita", Schema: `[{"key":"notes","label":"Notas","type":"text"}]`}, nil).Once()
+
+	resp, err := srv.GetIntakeForm(&pb.GetIntakeFormRequest{ServiceId: 3, ProfessionalId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GetIntakeFormResponse{
+		Form: &pb.IntakeForm{Id: 4, ProfessionalId: 2, Version: 1, Title: "Primera visita",
+			Fields: []*pb.IntakeField{{Key: "notes", Label: "Notas", Type: "text"}}},
+		Success: true,
+	}, resp)
+	mockRepo.AssertExpectations(t)
+}
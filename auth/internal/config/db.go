@@ -23,7 +23,9 @@ func (c *DBConfig) ConnectDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.PasswordResetToken{},
+		&models.EmailVerificationToken{}, &models.SigningKey{}, &models.LoginAttempt{}, &models.LoginEvent{},
+		&models.TOTPEnrollment{}, &models.RecoveryCode{}, &models.MFAChallenge{}); err != nil {
 		log.Printf("Error migrating models to db %v", err)
 		return nil, err
 	}
@@ -10,10 +10,11 @@ import (
 type AuthHandler struct {
 	pb.UnimplementedAuthServiceServer
 	Service services.AuthService
+	Keys    services.KeyService
 }
 
-func NewAuthHandler(srv services.AuthService) *AuthHandler {
-	return &AuthHandler{Service: srv}
+func NewAuthHandler(srv services.AuthService, keys services.KeyService) *AuthHandler {
+	return &AuthHandler{Service: srv, Keys: keys}
 }
 
 func (h *AuthHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
@@ -23,3 +24,87 @@ func (h *AuthHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 func (h *AuthHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
 	return h.Service.Login(req)
 }
+
+func (h *AuthHandler) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	return h.Service.Refresh(req)
+}
+
+func (h *AuthHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	return h.Service.Logout(req)
+}
+
+func (h *AuthHandler) ListRevokedTokens(ctx context.Context, req *pb.ListRevokedTokensRequest) (*pb.ListRevokedTokensResponse, error) {
+	return h.Service.ListRevokedTokens(req)
+}
+
+func (h *AuthHandler) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	return h.Service.ValidateToken(req)
+}
+
+func (h *AuthHandler) Introspect(ctx context.Context, req *pb.IntrospectRequest) (*pb.IntrospectResponse, error) {
+	return h.Service.Introspect(req)
+}
+
+func (h *AuthHandler) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	return h.Keys.GetJWKS(req)
+}
+
+func (h *AuthHandler) SetRoles(ctx context.Context, req *pb.SetRolesRequest) (*pb.SetRolesResponse, error) {
+	return h.Service.SetRoles(req)
+}
+
+func (h *AuthHandler) LinkUser(ctx context.Context, req *pb.LinkUserRequest) (*pb.LinkUserResponse, error) {
+	return h.Service.LinkUser(req)
+}
+
+func (h *AuthHandler) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	return h.Service.RequestPasswordReset(req)
+}
+
+func (h *AuthHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	return h.Service.ResetPassword(req)
+}
+
+func (h *AuthHandler) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	return h.Service.VerifyEmail(req)
+}
+
+func (h *AuthHandler) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error) {
+	return h.Service.ResendVerification(req)
+}
+
+func (h *AuthHandler) UnlockLogin(ctx context.Context, req *pb.UnlockLoginRequest) (*pb.UnlockLoginResponse, error) {
+	return h.Service.UnlockLogin(ctx, req)
+}
+
+func (h *AuthHandler) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.LoginResponse, error) {
+	return h.Service.VerifyMFA(req)
+}
+
+func (h *AuthHandler) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	return h.Service.EnrollTOTP(ctx, req)
+}
+
+func (h *AuthHandler) ActivateTOTP(ctx context.Context, req *pb.ActivateTOTPRequest) (*pb.ActivateTOTPResponse, error) {
+	return h.Service.ActivateTOTP(ctx, req)
+}
+
+func (h *AuthHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	return h.Service.ChangePassword(ctx, req)
+}
+
+func (h *AuthHandler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	return h.Service.ListUsers(req)
+}
+
+func (h *AuthHandler) DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error) {
+	return h.Service.DisableUser(ctx, req)
+}
+
+func (h *AuthHandler) EnableUser(ctx context.Context, req *pb.EnableUserRequest) (*pb.EnableUserResponse, error) {
+	return h.Service.EnableUser(req)
+}
+
+func (h *AuthHandler) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	return h.Service.DeleteUser(ctx, req)
+}
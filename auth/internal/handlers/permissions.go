@@ -0,0 +1,32 @@
+package handlers
+
+import "github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+
+// Permissions lists the roles allowed to call each method, admins and the
+// other services can call all of them.
+var Permissions = rbac.Permissions{
+	"/pb.AuthService/CreateUser":           {rbac.Public},
+	"/pb.AuthService/Login":                {rbac.Public},
+	"/pb.AuthService/Refresh":              {rbac.Public},
+	"/pb.AuthService/Logout":               {rbac.Public},
+	"/pb.AuthService/ListRevokedTokens":    {rbac.RoleService},
+	"/pb.AuthService/SetRoles":             {rbac.RoleAdmin},
+	"/pb.AuthService/LinkUser":             {rbac.RoleAdmin},
+	"/pb.AuthService/ValidateToken":        {rbac.RoleService},
+	"/pb.AuthService/Introspect":           {rbac.RoleService},
+	"/pb.AuthService/GetJWKS":              {rbac.Public},
+	"/pb.AuthService/RequestPasswordReset": {rbac.Public},
+	"/pb.AuthService/ResetPassword":        {rbac.Public},
+	"/pb.AuthService/VerifyEmail":          {rbac.Public},
+	"/pb.AuthService/ResendVerification":   {rbac.Public},
+	"/pb.AuthService/UnlockLogin":          {rbac.RoleAdmin},
+	"/pb.AuthService/ChangePassword":       {rbac.Authenticated},
+	"/pb.AuthService/ListUsers":            {rbac.RoleAdmin},
+	"/pb.AuthService/DisableUser":          {rbac.RoleAdmin},
+	"/pb.AuthService/EnableUser":           {rbac.RoleAdmin},
+	"/pb.AuthService/DeleteUser":           {rbac.RoleAdmin},
+	// Se llaman con el token del primer paso del login o con el access token
+	"/pb.AuthService/VerifyMFA":    {rbac.Public},
+	"/pb.AuthService/EnrollTOTP":   {rbac.Public},
+	"/pb.AuthService/ActivateTOTP": {rbac.Public},
+}
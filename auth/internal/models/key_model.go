@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// SigningKey is an Ed25519 key the access tokens are signed with. A new key is
+// published a while before it starts signing, and an old one until the last
+// token it signed has expired.
+type SigningKey struct {
+	ID  uint   `gorm:"primaryKey"`
+	Kid string `gorm:"unique;not null"`
+	// Seed is the private key, whoever reads it can sign tokens
+	Seed        []byte    `gorm:"not null"`
+	ActivatesAt time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+}
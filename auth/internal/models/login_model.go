@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Kinds of LoginEvent.
+const (
+	LoginEventLockout = "lockout"
+	LoginEventUnlock  = "unlock"
+)
+
+// LoginAttempt counts the recent failed logins of an account or an address,
+// Key is "user:<username>" or "ip:<address>". Unknown usernames are counted
+// too, so a lockout doesn't tell which accounts exist.
+type LoginAttempt struct {
+	Key           string    `gorm:"primaryKey"`
+	Failures      int       `gorm:"not null"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
+
+// LoginEvent records when a key was locked out and when an admin unlocked it.
+type LoginEvent struct {
+	ID   uint   `gorm:"primaryKey"`
+	Kind string `gorm:"not null"`
+	Key  string `gorm:"not null;index"`
+	// ActorID is the admin that unlocked it, 0 for the lockouts
+	ActorID   uint
+	CreatedAt time.Time
+}
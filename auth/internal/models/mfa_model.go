@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// TOTPEnrollment is the TOTP secret of a user, it's asked for at login once
+// activated with a valid code. The secret is stored as is, like the signing
+// keys, since the codes are checked with it.
+type TOTPEnrollment struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"unique;not null"`
+	Secret      string `gorm:"not null"`
+	ActivatedAt *time.Time
+	// LastStep is the time step of the last code accepted, a code works once
+	LastStep  int64 `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// RecoveryCode replaces a TOTP code once, for when the authenticator is lost.
+// It's stored by its hash.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// MFAChallenge is issued when the password of a login that needs MFA was
+// right, its token finishes the login along with a code. It's stored by hash
+// like the other tokens and works once.
+type MFAChallenge struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"unique;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	// Failures counts the wrong codes, too many and the login starts over
+	Failures  int `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// RefreshToken is stored by the hash of its value. Every refresh rotates it,
+// the new token joins the same family and the used one can't be refreshed
+// again, so a reused token means it leaked and the whole family is revoked.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	FamilyID  string    `gorm:"not null;index"`
+	TokenHash string    `gorm:"unique;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	// AccessJTI is the ID of the access token issued along with this one, it's
+	// rejected by the gateway once the family is revoked
+	AccessJTI       string    `gorm:"not null;index"`
+	AccessExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt          *time.Time
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
+
+// PasswordResetToken is emailed to reset a forgotten password, it's stored by
+// the hash of its value like the refresh tokens and works only once.
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"unique;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// EmailVerificationToken is emailed to confirm the address of an account, it
+// works once like the password reset ones.
+type EmailVerificationToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"unique;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
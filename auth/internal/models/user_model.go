@@ -1,6 +1,9 @@
 package models
 
 import (
+	"strings"
+	"time"
+
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -9,15 +12,50 @@ type User struct {
 	ID       uint   `gorm:"primaryKey"`
 	Username string `gorm:"unique;not null"`
 	Password string `gorm:"not null"`
+	// Roles are stored comma separated, ie: "staff,professional"
+	Roles string `gorm:"not null;default:client"`
+	// ClientID and ProfessionalID link the account to its records in the
+	// client and professional services, 0 when it isn't linked
+	ClientID       uint
+	ProfessionalID uint
+	// Email is optional, nil when the user didn't give one. Until it's
+	// verified the account is pending
+	Email           *string `gorm:"unique"`
+	EmailVerifiedAt *time.Time
+	// DisabledAt is set while an admin keeps the user from logging in
+	DisabledAt *time.Time
+}
+
+// PendingVerification reports whether the user gave an email and hasn't
+// verified it yet.
+func (u *User) PendingVerification() bool {
+	return u.Email != nil && u.EmailVerifiedAt == nil
+}
+
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+func (u *User) RoleList() []string {
+	if u.Roles == "" {
+		return nil
+	}
+	return strings.Split(u.Roles, ",")
 }
 
 func (u *User) BeforeSave(tx *gorm.DB) error {
 	if u.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+		hashedPassword, err := HashPassword(u.Password)
 		if err != nil {
 			return err
 		}
-		u.Password = string(hashedPassword)
+		u.Password = hashedPassword
 	}
 	return nil
 }
+
+// HashPassword is what gets stored of a password.
+func HashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hashedPassword), err
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"gorm.io/gorm"
+)
+
+type KeyRepository interface {
+	CreateKey(key *models.SigningKey) error
+	ListKeys() ([]models.SigningKey, error)
+	DeleteKey(id uint) error
+}
+
+type keyRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewKeyRepository(db *gorm.DB) KeyRepository {
+	return &keyRepositoryImpl{DB: db}
+}
+
+func (r *keyRepositoryImpl) CreateKey(key *models.SigningKey) error {
+	return r.DB.Create(key).Error
+}
+
+// ListKeys returns the keys from the oldest to the newest.
+func (r *keyRepositoryImpl) ListKeys() ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.DB.Order("activates_at").Find(&keys).Error
+	return keys, err
+}
+
+func (r *keyRepositoryImpl) DeleteKey(id uint) error {
+	return r.DB.Delete(&models.SigningKey{}, id).Error
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginRepository interface {
+	FindAttempts(keys []string) ([]models.LoginAttempt, error)
+	RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	Lock(key string, until time.Time, maxFailures int) (bool, error)
+	ClearAttempts(keys []string) (int64, error)
+	CreateEvent(event *models.LoginEvent) error
+}
+
+type loginRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewLoginRepository(db *gorm.DB) LoginRepository {
+	return &loginRepositoryImpl{DB: db}
+}
+
+func (r *loginRepositoryImpl) FindAttempts(keys []string) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	err := r.DB.Where("key IN ?", keys).Find(&attempts).Error
+	return attempts, err
+}
+
+// RecordFailure counts a failed login in a single statement, so the logins
+// racing on other instances aren't lost. The failures older than the window
+// are forgotten.
+func (r *loginRepositoryImpl) RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	attempt := models.LoginAttempt{Key: key, Failures: 1, LastFailureAt: now}
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", now.Add(-window)),
+			"last_failure_at": now,
+		}),
+	}, clause.Returning{}).Create(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Lock locks the key out until the given time and starts counting its failures
+// again. It reports false when the key no longer has maxFailures, another
+// instance locked it first.
+func (r *loginRepositoryImpl) Lock(key string, until time.Time, maxFailures int) (bool, error) {
+	result := r.DB.Model(&models.LoginAttempt{}).
+		Where("key = ? AND failures >= ?", key, maxFailures).
+		Updates(map[string]interface{}{"locked_until": until, "failures": 0})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClearAttempts forgets the failures of the keys, it returns how many had any.
+func (r *loginRepositoryImpl) ClearAttempts(keys []string) (int64, error) {
+	result := r.DB.Where("key IN ?", keys).Delete(&models.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *loginRepositoryImpl) CreateEvent(event *models.LoginEvent) error {
+	return r.DB.Create(event).Error
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTOTPStepUsed        = errors.New("totp_step_used")
+	ErrRecoveryCodeInvalid = errors.New("recovery_code_invalid")
+	ErrChallengeUsed       = errors.New("mfa_challenge_used")
+)
+
+type MFARepository interface {
+	FindEnrollment(userID uint) (*models.TOTPEnrollment, error)
+	SaveEnrollment(enrollment *models.TOTPEnrollment) error
+	ActivateEnrollment(userID uint, recoveryHashes []string) error
+	UseTOTPStep(userID uint, step int64) error
+	UseRecoveryCode(userID uint, codeHash string) error
+	CreateChallenge(challenge *models.MFAChallenge) error
+	FindChallenge(tokenHash string) (*models.MFAChallenge, error)
+	FailChallenge(id uint) error
+	UseChallenge(id uint) error
+}
+
+type mfaRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepositoryImpl{DB: db}
+}
+
+func (r *mfaRepositoryImpl) FindEnrollment(userID uint) (*models.TOTPEnrollment, error) {
+	var enrollment models.TOTPEnrollment
+	err := r.DB.Where("user_id = ?", userID).First(&enrollment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// SaveEnrollment stores the enrollment, replacing the one of the user that
+// wasn't activated.
+func (r *mfaRepositoryImpl) SaveEnrollment(enrollment *models.TOTPEnrollment) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "created_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "totp_enrollments.activated_at IS NULL"}}},
+	}).Create(enrollment).Error
+}
+
+// ActivateEnrollment activates the enrollment of the user and replaces its
+// recovery codes. It gives gorm.ErrRecordNotFound when there's no enrollment
+// waiting to be activated.
+func (r *mfaRepositoryImpl) ActivateEnrollment(userID uint, recoveryHashes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TOTPEnrollment{}).
+			Where("user_id = ? AND activated_at IS NULL", userID).
+			Update("activated_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(recoveryHashes))
+		for i, hash := range recoveryHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseTOTPStep records the step of an accepted code. It fails with
+// ErrTOTPStepUsed when a code of that step or a later one was already used.
+func (r *mfaRepositoryImpl) UseTOTPStep(userID uint, step int64) error {
+	result := r.DB.Model(&models.TOTPEnrollment{}).
+		Where("user_id = ? AND last_step < ?", userID, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTOTPStepUsed
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the code as used, ErrRecoveryCodeInvalid when the
+// user has no such code left.
+func (r *mfaRepositoryImpl) UseRecoveryCode(userID uint, codeHash string) error {
+	result := r.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecoveryCodeInvalid
+	}
+	return nil
+}
+
+func (r *mfaRepositoryImpl) CreateChallenge(challenge *models.MFAChallenge) error {
+	return r.DB.Create(challenge).Error
+}
+
+func (r *mfaRepositoryImpl) FindChallenge(tokenHash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *mfaRepositoryImpl) FailChallenge(id uint) error {
+	return r.DB.Model(&models.MFAChallenge{}).
+		Where("id = ?", id).
+		Update("failures", gorm.Expr("failures + 1")).Error
+}
+
+// UseChallenge marks the challenge as used, ErrChallengeUsed when it already
+// was, ie: two logins racing with the same token.
+func (r *mfaRepositoryImpl) UseChallenge(id uint) error {
+	result := r.DB.Model(&models.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChallengeUsed
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTokenReused     = errors.New("refresh_token_reused")
+	ErrResetTokenUsed  = errors.New("reset_token_used")
+	ErrVerifyTokenUsed = errors.New("verification_token_used")
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) error
+	FindRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(used *models.RefreshToken, next *models.RefreshToken) error
+	RevokeFamily(familyID string) error
+	ListRevokedAccessTokens(now time.Time) ([]string, error)
+	AccessTokenRevoked(jti string) (bool, error)
+	RevokeUserTokens(userID uint) error
+	CreateResetToken(token *models.PasswordResetToken) error
+	FindResetToken(tokenHash string) (*models.PasswordResetToken, error)
+	UseResetToken(id uint) error
+	CreateVerificationToken(token *models.EmailVerificationToken) error
+	FindVerificationToken(tokenHash string) (*models.EmailVerificationToken, error)
+	UseVerificationToken(id uint) error
+	LastVerificationSentAt(userID uint) (time.Time, error)
+}
+
+type tokenRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepositoryImpl{DB: db}
+}
+
+func (r *tokenRepositoryImpl) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *tokenRepositoryImpl) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken marks the token as used and stores the next one of its
+// family. It fails with ErrTokenReused when the token was used or revoked in
+// the meantime, ie: two refreshes racing with the same token.
+func (r *tokenRepositoryImpl) RotateRefreshToken(used *models.RefreshToken, next *models.RefreshToken) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", used.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenReused
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// RevokeFamily revokes every token of the family, the access tokens issued
+// with them included.
+func (r *tokenRepositoryImpl) RevokeFamily(familyID string) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ListRevokedAccessTokens returns the IDs of the revoked access tokens that
+// haven't expired yet, the expired ones are rejected anyway.
+func (r *tokenRepositoryImpl) ListRevokedAccessTokens(now time.Time) ([]string, error) {
+	var jtis []string
+	err := r.DB.Model(&models.RefreshToken{}).
+		Where("revoked_at IS NOT NULL AND access_expires_at > ?", now).
+		Pluck("access_jti", &jtis).Error
+	return jtis, err
+}
+
+func (r *tokenRepositoryImpl) AccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.RefreshToken{}).
+		Where("access_jti = ? AND revoked_at IS NOT NULL", jti).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RevokeUserTokens revokes every session of the user, ie: after the password
+// changes.
+func (r *tokenRepositoryImpl) RevokeUserTokens(userID uint) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *tokenRepositoryImpl) CreateResetToken(token *models.PasswordResetToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *tokenRepositoryImpl) FindResetToken(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UseResetToken marks the token as used, it fails with ErrResetTokenUsed when
+// it was used in the meantime.
+func (r *tokenRepositoryImpl) UseResetToken(id uint) error {
+	result := r.DB.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrResetTokenUsed
+	}
+	return nil
+}
+
+func (r *tokenRepositoryImpl) CreateVerificationToken(token *models.EmailVerificationToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *tokenRepositoryImpl) FindVerificationToken(tokenHash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UseVerificationToken marks the token as used, it fails with
+// ErrVerifyTokenUsed when it was used in the meantime.
+func (r *tokenRepositoryImpl) UseVerificationToken(id uint) error {
+	result := r.DB.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVerifyTokenUsed
+	}
+	return nil
+}
+
+// LastVerificationSentAt returns when the newest verification token of the
+// user was created, the zero time when there's none.
+func (r *tokenRepositoryImpl) LastVerificationSentAt(userID uint) (time.Time, error) {
+	var token models.EmailVerificationToken
+	err := r.DB.Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	return token.CreatedAt, err
+}
@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"strings"
+	"time"
+
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
 	"gorm.io/gorm"
 )
@@ -8,8 +11,20 @@ import (
 type UserRepository interface {
 	CreateUser(user *models.User) error
 	FindByUsername(username string) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	FindByID(id uint) (*models.User, error)
+	UpdateRoles(id uint, roles []string) error
+	UpdateLinks(id, clientID, professionalID uint) error
+	UpdatePassword(id uint, password string) error
+	MarkEmailVerified(id uint) error
+	ListUsers(search string, offset, limit int) ([]models.User, int64, error)
+	SetDisabled(id uint, disabled bool) error
+	DeleteUser(id uint) error
 }
 
+// likeEscaper escapes the LIKE wildcards, a search matches them literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type userRepositoryImpl struct {
 	DB *gorm.DB
 }
@@ -30,3 +45,117 @@ func (u *userRepositoryImpl) FindByUsername(username string) (*models.User, erro
 	}
 	return &user, nil
 }
+
+func (u *userRepositoryImpl) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	err := u.DB.Where("email = ?", email).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (u *userRepositoryImpl) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	err := u.DB.First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (u *userRepositoryImpl) UpdateRoles(id uint, roles []string) error {
+	result := u.DB.Model(&models.User{}).Where("id = ?", id).Update("roles", strings.Join(roles, ","))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (u *userRepositoryImpl) UpdateLinks(id, clientID, professionalID uint) error {
+	result := u.DB.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"client_id":       clientID,
+		"professional_id": professionalID,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (u *userRepositoryImpl) UpdatePassword(id uint, password string) error {
+	hashedPassword, err := models.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	result := u.DB.Model(&models.User{}).Where("id = ?", id).Update("password", hashedPassword)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (u *userRepositoryImpl) MarkEmailVerified(id uint) error {
+	result := u.DB.Model(&models.User{}).Where("id = ?", id).Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListUsers returns a page of the users by ID, and how many match the search
+// in total.
+func (u *userRepositoryImpl) ListUsers(search string, offset, limit int) ([]models.User, int64, error) {
+	query := u.DB.Model(&models.User{})
+	if search != "" {
+		pattern := "%" + likeEscaper.Replace(search) + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []models.User
+	err := query.Order("id").Offset(offset).Limit(limit).Find(&users).Error
+	return users, total, err
+}
+
+func (u *userRepositoryImpl) SetDisabled(id uint, disabled bool) error {
+	var disabledAt *time.Time
+	if disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+	result := u.DB.Model(&models.User{}).Where("id = ?", id).Update("disabled_at", disabledAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (u *userRepositoryImpl) DeleteUser(id uint) error {
+	result := u.DB.Delete(&models.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
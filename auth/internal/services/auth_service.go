@@ -1,29 +1,124 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"log"
+	"math"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+const (
+	tokenTypeAccess  = "access_token"
+	tokenTypeRefresh = "refresh_token"
+)
+
+// resetRequested and verificationResent are the answers to every password
+// reset and verification request, they don't tell whether the account exists.
+const (
+	resetRequested     = "If the account exists, a reset link was sent"
+	verificationResent = "If the account is pending verification, a new link was sent"
+)
+
+// ListUsers page sizes.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// What happens when a user whose email isn't verified logs in.
+const (
+	UnverifiedLoginDeny = "deny"
+	// UnverifiedLoginLimited issues a token without roles, it only opens the
+	// endpoints any authenticated caller can use
+	UnverifiedLoginLimited = "limited"
+)
+
+// TokenPolicy holds how long the issued tokens and emailed links last.
+type TokenPolicy struct {
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	ResetTTL   time.Duration
+	// ResetURL is the page the password reset emails link to, the token goes
+	// in its query
+	ResetURL string
+	// VerifyTTL and VerifyURL are the same for the email verification links,
+	// which can be resent once every ResendInterval
+	VerifyTTL       time.Duration
+	VerifyURL       string
+	ResendInterval  time.Duration
+	UnverifiedLogin string
+}
+
 type AuthService interface {
 	CreateUser(req *pb.CreateUserRequest) (*pb.CreateUserResponse, error)
 	Login(req *pb.LoginRequest) (*pb.LoginResponse, error)
+	Refresh(req *pb.RefreshRequest) (*pb.RefreshResponse, error)
+	Logout(req *pb.LogoutRequest) (*pb.LogoutResponse, error)
+	ListRevokedTokens(req *pb.ListRevokedTokensRequest) (*pb.ListRevokedTokensResponse, error)
+	SetRoles(req *pb.SetRolesRequest) (*pb.SetRolesResponse, error)
+	LinkUser(req *pb.LinkUserRequest) (*pb.LinkUserResponse, error)
+	ValidateToken(req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error)
+	Introspect(req *pb.IntrospectRequest) (*pb.IntrospectResponse, error)
+	RequestPasswordReset(req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error)
+	ResetPassword(req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error)
+	VerifyEmail(req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error)
+	ResendVerification(req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error)
+	UnlockLogin(ctx context.Context, req *pb.UnlockLoginRequest) (*pb.UnlockLoginResponse, error)
+	VerifyMFA(req *pb.VerifyMFARequest) (*pb.LoginResponse, error)
+	EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error)
+	ActivateTOTP(ctx context.Context, req *pb.ActivateTOTPRequest) (*pb.ActivateTOTPResponse, error)
+	ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error)
+	ListUsers(req *pb.ListUsersRequest) (*pb.ListUsersResponse, error)
+	DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error)
+	EnableUser(req *pb.EnableUserRequest) (*pb.EnableUserResponse, error)
+	DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error)
+	EnsureAdmin(username, password string) error
 }
 
 type authServiceImpl struct {
 	Repo      repositories.UserRepository
-	SecretKey []byte
+	TokenRepo repositories.TokenRepository
+	LoginRepo repositories.LoginRepository
+	MFARepo   repositories.MFARepository
+	// Keys signs the access tokens, Verifier checks them and the service tokens
+	Keys        KeyService
+	Verifier    *rbac.KeySet
+	Policy      TokenPolicy
+	LoginPolicy LoginPolicy
+	NotifClient pb.NotificationServiceClient
 }
 
-func NewAuthService(repo repositories.UserRepository, secretKey string) AuthService {
-	return &authServiceImpl{Repo: repo, SecretKey: []byte(secretKey)}
+func NewAuthService(repo repositories.UserRepository, tokenRepo repositories.TokenRepository,
+	loginRepo repositories.LoginRepository, mfaRepo repositories.MFARepository, keys KeyService, verifier *rbac.KeySet, policy TokenPolicy,
+	loginPolicy LoginPolicy, notifClient pb.NotificationServiceClient) AuthService {
+	return &authServiceImpl{
+		Repo:        repo,
+		TokenRepo:   tokenRepo,
+		LoginRepo:   loginRepo,
+		MFARepo:     mfaRepo,
+		Keys:        keys,
+		Verifier:    verifier,
+		Policy:      policy,
+		LoginPolicy: loginPolicy,
+		NotifClient: notifClient,
+	}
 }
 
 func (s *authServiceImpl) CreateUser(req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
@@ -35,15 +130,38 @@ func (s *authServiceImpl) CreateUser(req *pb.CreateUserRequest) (*pb.CreateUserR
 		}, nil
 	}
 
+	var email *string
+	if req.Email != "" {
+		if address, err := mail.ParseAddress(req.Email); err != nil || address.Address != req.Email {
+			return &pb.CreateUserResponse{Message: "Invalid email", Success: false}, nil
+		}
+		if _, err := s.Repo.FindByEmail(req.Email); err == nil {
+			return &pb.CreateUserResponse{Message: "Email is not available", Success: false}, nil
+		}
+		email = &req.Email
+	}
+
+	// Quien se registra es un cliente, los demás roles los asigna un admin
 	user := &models.User{
 		Username: req.Username,
 		Password: req.Password,
+		Roles:    rbac.RoleClient,
+		Email:    email,
 	}
 
 	if err := s.Repo.CreateUser(user); err != nil {
 		return nil, err
 	}
 
+	if user.PendingVerification() {
+		// Puede pedir otro enlace con ResendVerification
+		if err := s.startVerification(user); err != nil {
+			log.Printf("Error starting email verification of user %d: %v", user.ID, err)
+			return &pb.CreateUserResponse{Message: "User created, error sending the verification email", Success: true}, nil
+		}
+		return &pb.CreateUserResponse{Message: "User created, verify the email to activate it", Success: true}, nil
+	}
+
 	return &pb.CreateUserResponse{
 		Message: "User created",
 		Success: true,
@@ -51,31 +169,74 @@ func (s *authServiceImpl) CreateUser(req *pb.CreateUserRequest) (*pb.CreateUserR
 }
 
 func (s *authServiceImpl) Login(req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	user, err := s.Repo.FindByUsername(req.Username)
+	now := time.Now()
+	keys := loginKeys(req.Username, req.ClientIp)
+	wait, err := s.loginRetryAfter(keys, now)
+	if err != nil {
+		log.Printf("Error checking failed logins: %v", err)
+		return &pb.LoginResponse{
+			Token:   "",
+			Success: false,
+		}, errors.New("error_checking_login")
+	}
+	if wait > 0 {
+		return &pb.LoginResponse{
+			Token:      "",
+			Success:    false,
+			RetryAfter: int64(math.Ceil(wait.Seconds())),
+		}, nil
+	}
 
+	user, err := s.Repo.FindByUsername(req.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Tarda lo mismo que una contraseña incorrecta
+		bcrypt.CompareHashAndPassword(unknownUserHash, []byte(req.Password))
+		return s.failLogin(keys, now)
+	}
 	if err != nil {
 		return &pb.LoginResponse{
 			Token:   "",
 			Success: false,
-		}, errors.New("user_not_found")
+		}, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return s.failLogin(keys, now)
+	}
+	if user.Disabled() {
+		// Igual que una contraseña incorrecta, pero no cuenta como falla
 		return &pb.LoginResponse{
 			Token:   "",
 			Success: false,
-		}, errors.New("incorrect_password")
+		}, errInvalidCredentials
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,                               // ID del usuario en el cuerpo
-		"exp":     time.Now().Add(24 * time.Hour).Unix(), // Expira en 24 horas
-		"iat":     time.Now().Unix(),                     // Issued At: tiempo de emisión
-	})
+	if user.PendingVerification() && s.Policy.UnverifiedLogin != UnverifiedLoginLimited {
+		return &pb.LoginResponse{
+			Token:   "",
+			Success: false,
+		}, errors.New("email_not_verified")
+	}
+
+	enrolled, err := s.mfaEnrolled(user.ID)
+	if err != nil {
+		return &pb.LoginResponse{
+			Token:   "",
+			Success: false,
+		}, err
+	}
+	// Con MFA las fallas se olvidan recién con un código válido, si no cada
+	// login con la contraseña robada daría más intentos para el código
+	if !enrolled {
+		s.clearLoginFailures(keys[0])
+	}
+	if enrolled || s.mfaRequired(user) {
+		return s.challengeLogin(user, enrolled)
+	}
 
-	tokenString, err := token.SignedString(s.SecretKey)
+	accessToken, refreshToken, err := s.issueTokens(user, "", nil)
 	if err != nil {
-		log.Printf("Error singning token: %v", err)
+		log.Printf("Error issuing tokens: %v", err)
 		return &pb.LoginResponse{
 			Token:   "",
 			Success: false,
@@ -83,7 +244,601 @@ func (s *authServiceImpl) Login(req *pb.LoginRequest) (*pb.LoginResponse, error)
 	}
 
 	return &pb.LoginResponse{
-		Token:   tokenString,
-		Success: true,
+		Token:               accessToken,
+		Success:             true,
+		RefreshToken:        refreshToken,
+		ExpiresIn:           int64(s.Policy.AccessTTL.Seconds()),
+		PendingVerification: user.PendingVerification(),
+	}, nil
+}
+
+func (s *authServiceImpl) Refresh(req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	stored, err := s.TokenRepo.FindRefreshToken(hashToken(req.RefreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.RefreshResponse{Message: "Invalid refresh token", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.RefreshResponse{Message: "Error refreshing token", Success: false}, err
+	}
+	if stored.RevokedAt != nil || !time.Now().Before(stored.ExpiresAt) {
+		return &pb.RefreshResponse{Message: "Invalid refresh token", Success: false}, nil
+	}
+	if stored.UsedAt != nil {
+		return s.revokeReused(stored)
+	}
+	// Los roles pudieron cambiar desde el login, se leen de nuevo
+	user, err := s.Repo.FindByID(stored.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.RefreshResponse{Message: "Invalid refresh token", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.RefreshResponse{Message: "Error refreshing token", Success: false}, err
+	}
+	if user.Disabled() {
+		return &pb.RefreshResponse{Message: "Invalid refresh token", Success: false}, nil
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(user, stored.FamilyID, stored)
+	if errors.Is(err, repositories.ErrTokenReused) {
+		return s.revokeReused(stored)
+	}
+	if err != nil {
+		return &pb.RefreshResponse{Message: "Error refreshing token", Success: false}, err
+	}
+
+	return &pb.RefreshResponse{
+		Message:      "Token refreshed",
+		Success:      true,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.Policy.AccessTTL.Seconds()),
+	}, nil
+}
+
+func (s *authServiceImpl) Logout(req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	stored, err := s.TokenRepo.FindRefreshToken(hashToken(req.RefreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.LogoutResponse{Message: "Invalid refresh token", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.LogoutResponse{Message: "Error logging out", Success: false}, err
+	}
+
+	if err := s.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+		return &pb.LogoutResponse{Message: "Error logging out", Success: false}, err
+	}
+	return &pb.LogoutResponse{Message: "Logged out", Success: true}, nil
+}
+
+func (s *authServiceImpl) ListRevokedTokens(req *pb.ListRevokedTokensRequest) (*pb.ListRevokedTokensResponse, error) {
+	jtis, err := s.TokenRepo.ListRevokedAccessTokens(time.Now())
+	if err != nil {
+		return &pb.ListRevokedTokensResponse{Success: false}, err
+	}
+	return &pb.ListRevokedTokensResponse{Jtis: jtis, Success: true}, nil
+}
+
+func (s *authServiceImpl) SetRoles(req *pb.SetRolesRequest) (*pb.SetRolesResponse, error) {
+	if len(req.Roles) == 0 {
+		return &pb.SetRolesResponse{Message: "At least one role is required", Success: false}, nil
+	}
+	for _, role := range req.Roles {
+		if !slices.Contains(rbac.UserRoles, role) {
+			return &pb.SetRolesResponse{Message: "Unknown role " + role, Success: false}, nil
+		}
+	}
+
+	user, err := s.Repo.FindByID(uint(req.UserId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.SetRolesResponse{Message: "User not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.SetRolesResponse{Message: "Error updating roles", Success: false}, err
+	}
+
+	err = s.Repo.UpdateRoles(user.ID, req.Roles)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.SetRolesResponse{Message: "User not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.SetRolesResponse{Message: "Error updating roles", Success: false}, err
+	}
+
+	// Los tokens emitidos aún llevan el rol quitado, se cierran sus sesiones
+	for _, role := range user.RoleList() {
+		if slices.Contains(req.Roles, role) {
+			continue
+		}
+		if err := s.TokenRepo.RevokeUserTokens(user.ID); err != nil {
+			return &pb.SetRolesResponse{Message: "Error revoking sessions", Success: false}, err
+		}
+		break
+	}
+	return &pb.SetRolesResponse{Message: "Roles updated", Success: true}, nil
+}
+
+func (s *authServiceImpl) LinkUser(req *pb.LinkUserRequest) (*pb.LinkUserResponse, error) {
+	err := s.Repo.UpdateLinks(uint(req.UserId), uint(req.ClientId), uint(req.ProfessionalId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.LinkUserResponse{Message: "User not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.LinkUserResponse{Message: "Error linking user", Success: false}, err
+	}
+	return &pb.LinkUserResponse{Message: "User linked", Success: true}, nil
+}
+
+func (s *authServiceImpl) ListUsers(req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	pageSize := int(req.PageSize)
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+	page := max(int(req.Page), 1)
+
+	users, total, err := s.Repo.ListUsers(strings.TrimSpace(req.Search), (page-1)*pageSize, pageSize)
+	if err != nil {
+		return &pb.ListUsersResponse{Success: false}, err
+	}
+
+	resp := &pb.ListUsersResponse{Users: make([]*pb.UserInfo, len(users)), Total: total, Success: true}
+	for i, user := range users {
+		resp.Users[i] = &pb.UserInfo{
+			Id:             uint32(user.ID),
+			Username:       user.Username,
+			Roles:          user.RoleList(),
+			ClientId:       uint32(user.ClientID),
+			ProfessionalId: uint32(user.ProfessionalID),
+			EmailVerified:  user.EmailVerifiedAt != nil,
+			Disabled:       user.Disabled(),
+		}
+		if user.Email != nil {
+			resp.Users[i].Email = *user.Email
+		}
+	}
+	return resp, nil
+}
+
+// DisableUser keeps the user from logging in and revokes its tokens, the
+// access tokens are rejected from then on like the ones of a logout.
+func (s *authServiceImpl) DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error) {
+	if claims := rbac.FromContext(ctx); claims != nil && claims.UserID == uint(req.UserId) {
+		return &pb.DisableUserResponse{Message: "You can't disable your own account", Success: false}, nil
+	}
+
+	err := s.Repo.SetDisabled(uint(req.UserId), true)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.DisableUserResponse{Message: "User not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.DisableUserResponse{Message: "Error disabling user", Success: false}, err
+	}
+	if err := s.TokenRepo.RevokeUserTokens(uint(req.UserId)); err != nil {
+		return &pb.DisableUserResponse{Message: "Error revoking sessions", Success: false}, err
+	}
+	log.Printf("User %d disabled", req.UserId)
+	return &pb.DisableUserResponse{Message: "User disabled", Success: true}, nil
+}
+
+func (s *authServiceImpl) EnableUser(req *pb.EnableUserRequest) (*pb.EnableUserResponse, error) {
+	err := s.Repo.SetDisabled(uint(req.UserId), false)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.EnableUserResponse{Message: "User not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.EnableUserResponse{Message: "Error enabling user", Success: false}, err
+	}
+	log.Printf("User %d enabled", req.UserId)
+	return &pb.EnableUserResponse{Message: "User enabled", Success: true}, nil
+}
+
+// DeleteUser revokes the user's tokens before deleting it, the revoked ones
+// stay until they expire so the access tokens keep being rejected.
+func (s *authServiceImpl) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if claims := rbac.FromContext(ctx); claims != nil && claims.UserID == uint(req.UserId) {
+		return &pb.DeleteUserResponse{Message: "You can't delete your own account", Success: false}, nil
+	}
+
+	if err := s.TokenRepo.RevokeUserTokens(uint(req.UserId)); err != nil {
+		return &pb.DeleteUserResponse{Message: "Error revoking sessions", Success: false}, err
+	}
+	err := s.Repo.DeleteUser(uint(req.UserId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.DeleteUserResponse{Message: "User not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.DeleteUserResponse{Message: "Error deleting user", Success: false}, err
+	}
+	log.Printf("User %d deleted", req.UserId)
+	return &pb.DeleteUserResponse{Message: "User deleted", Success: true}, nil
+}
+
+// ValidateToken checks an access token for the other services. Unlike their
+// local check it knows about the revoked tokens.
+func (s *authServiceImpl) ValidateToken(req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	claims, err := s.accessClaims(req.Token)
+	if err != nil {
+		return &pb.ValidateTokenResponse{Valid: false}, err
+	}
+	if claims == nil {
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	}
+	return &pb.ValidateTokenResponse{Valid: true, Claims: rbac.ClaimsToPb(claims)}, nil
+}
+
+// Introspect tells whether a token is an active access or refresh token. The
+// hint only skips the access token check, it's tried first otherwise.
+func (s *authServiceImpl) Introspect(req *pb.IntrospectRequest) (*pb.IntrospectResponse, error) {
+	if req.TokenTypeHint != tokenTypeRefresh {
+		claims, err := s.accessClaims(req.Token)
+		if err != nil {
+			return &pb.IntrospectResponse{Active: false}, err
+		}
+		if claims != nil {
+			return &pb.IntrospectResponse{Active: true, TokenType: tokenTypeAccess, Claims: rbac.ClaimsToPb(claims)}, nil
+		}
+	}
+
+	stored, err := s.TokenRepo.FindRefreshToken(hashToken(req.Token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.IntrospectResponse{Active: false}, nil
+	}
+	if err != nil {
+		return &pb.IntrospectResponse{Active: false}, err
+	}
+	if stored.UsedAt != nil || stored.RevokedAt != nil || !time.Now().Before(stored.ExpiresAt) {
+		return &pb.IntrospectResponse{Active: false}, nil
+	}
+	return &pb.IntrospectResponse{
+		Active:    true,
+		TokenType: tokenTypeRefresh,
+		Claims: &pb.TokenClaims{
+			UserId:    uint32(stored.UserID),
+			IssuedAt:  stored.CreatedAt.Unix(),
+			ExpiresAt: stored.ExpiresAt.Unix(),
+		},
 	}, nil
 }
+
+// RequestPasswordReset emails the user a one-time link to reset the password.
+// It answers the same whether the account exists or not, and without waiting
+// for the email, so it can't be used to find out which accounts exist.
+func (s *authServiceImpl) RequestPasswordReset(req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	user, err := s.findAccount(req.UsernameOrEmail)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.RequestPasswordResetResponse{Message: resetRequested, Success: true}, nil
+	}
+	if err != nil {
+		return &pb.RequestPasswordResetResponse{Message: "Error requesting password reset", Success: false}, err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return &pb.RequestPasswordResetResponse{Message: "Error requesting password reset", Success: false}, err
+	}
+	expiresAt := time.Now().Add(s.Policy.ResetTTL)
+	err = s.TokenRepo.CreateResetToken(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return &pb.RequestPasswordResetResponse{Message: "Error requesting password reset", Success: false}, err
+	}
+
+	// El correo se envía aparte, esperarlo haría notar que la cuenta existe
+	go s.sendPasswordReset(user, token, expiresAt)
+	return &pb.RequestPasswordResetResponse{Message: resetRequested, Success: true}, nil
+}
+
+// ResetPassword sets the new password with a reset token and revokes every
+// session of the user, whoever took over the account is logged out.
+func (s *authServiceImpl) ResetPassword(req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if req.NewPassword == "" {
+		return &pb.ResetPasswordResponse{Message: "Password is required", Success: false}, nil
+	}
+	stored, err := s.TokenRepo.FindResetToken(hashToken(req.Token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.ResetPasswordResponse{Message: "Invalid reset token", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ResetPasswordResponse{Message: "Error resetting password", Success: false}, err
+	}
+	if stored.UsedAt != nil || !time.Now().Before(stored.ExpiresAt) {
+		return &pb.ResetPasswordResponse{Message: "Invalid reset token", Success: false}, nil
+	}
+
+	err = s.TokenRepo.UseResetToken(stored.ID)
+	if errors.Is(err, repositories.ErrResetTokenUsed) {
+		return &pb.ResetPasswordResponse{Message: "Invalid reset token", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ResetPasswordResponse{Message: "Error resetting password", Success: false}, err
+	}
+	if err := s.Repo.UpdatePassword(stored.UserID, req.NewPassword); err != nil {
+		return &pb.ResetPasswordResponse{Message: "Error resetting password", Success: false}, err
+	}
+	if err := s.TokenRepo.RevokeUserTokens(stored.UserID); err != nil {
+		return &pb.ResetPasswordResponse{Message: "Error revoking sessions", Success: false}, err
+	}
+	return &pb.ResetPasswordResponse{Message: "Password updated", Success: true}, nil
+}
+
+// ChangePassword checks the old password like a login, its failures count
+// towards the account's lockout.
+func (s *authServiceImpl) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	claims := rbac.FromContext(ctx)
+	if claims == nil || claims.UserID == 0 {
+		return &pb.ChangePasswordResponse{Message: "Login required", Success: false}, nil
+	}
+	if req.NewPassword == "" {
+		return &pb.ChangePasswordResponse{Message: "Password is required", Success: false}, nil
+	}
+	user, err := s.Repo.FindByID(claims.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.ChangePasswordResponse{Message: "User not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ChangePasswordResponse{Message: "Error changing password", Success: false}, err
+	}
+
+	now := time.Now()
+	keys := []string{accountKey(user.Username)}
+	wait, err := s.loginRetryAfter(keys, now)
+	if err != nil {
+		return &pb.ChangePasswordResponse{Message: "Error changing password", Success: false}, err
+	}
+	if wait > 0 {
+		return &pb.ChangePasswordResponse{Message: "Too many failed attempts, try again later", Success: false}, nil
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+		s.recordLoginFailure(keys, now)
+		return &pb.ChangePasswordResponse{Message: "Incorrect password", Success: false}, nil
+	}
+
+	if err := s.Repo.UpdatePassword(user.ID, req.NewPassword); err != nil {
+		return &pb.ChangePasswordResponse{Message: "Error changing password", Success: false}, err
+	}
+	if err := s.TokenRepo.RevokeUserTokens(user.ID); err != nil {
+		return &pb.ChangePasswordResponse{Message: "Error revoking sessions", Success: false}, err
+	}
+	return &pb.ChangePasswordResponse{Message: "Password updated, log in again", Success: true}, nil
+}
+
+// VerifyEmail confirms the email of the account the token was sent for, which
+// stops being pending.
+func (s *authServiceImpl) VerifyEmail(req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	stored, err := s.TokenRepo.FindVerificationToken(hashToken(req.Token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.VerifyEmailResponse{Message: "Invalid verification token", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.VerifyEmailResponse{Message: "Error verifying email", Success: false}, err
+	}
+	if stored.UsedAt != nil || !time.Now().Before(stored.ExpiresAt) {
+		return &pb.VerifyEmailResponse{Message: "Invalid verification token", Success: false}, nil
+	}
+
+	err = s.TokenRepo.UseVerificationToken(stored.ID)
+	if errors.Is(err, repositories.ErrVerifyTokenUsed) {
+		return &pb.VerifyEmailResponse{Message: "Invalid verification token", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.VerifyEmailResponse{Message: "Error verifying email", Success: false}, err
+	}
+	if err := s.Repo.MarkEmailVerified(stored.UserID); err != nil {
+		return &pb.VerifyEmailResponse{Message: "Error verifying email", Success: false}, err
+	}
+	return &pb.VerifyEmailResponse{Message: "Email verified", Success: true}, nil
+}
+
+// ResendVerification emails a new verification link to a pending account,
+// unless the last one was sent less than ResendInterval ago. It answers the
+// same in every case, like RequestPasswordReset.
+func (s *authServiceImpl) ResendVerification(req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error) {
+	user, err := s.findAccount(req.UsernameOrEmail)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.ResendVerificationResponse{Message: verificationResent, Success: true}, nil
+	}
+	if err != nil {
+		return &pb.ResendVerificationResponse{Message: "Error resending verification", Success: false}, err
+	}
+	if !user.PendingVerification() {
+		return &pb.ResendVerificationResponse{Message: verificationResent, Success: true}, nil
+	}
+
+	lastSent, err := s.TokenRepo.LastVerificationSentAt(user.ID)
+	if err != nil {
+		return &pb.ResendVerificationResponse{Message: "Error resending verification", Success: false}, err
+	}
+	if time.Since(lastSent) < s.Policy.ResendInterval {
+		log.Printf("Verification resend of user %d rate limited", user.ID)
+		return &pb.ResendVerificationResponse{Message: verificationResent, Success: true}, nil
+	}
+	if err := s.startVerification(user); err != nil {
+		return &pb.ResendVerificationResponse{Message: "Error resending verification", Success: false}, err
+	}
+	return &pb.ResendVerificationResponse{Message: verificationResent, Success: true}, nil
+}
+
+// accessClaims returns the claims of a valid access token, nil when it's
+// invalid or was revoked.
+func (s *authServiceImpl) accessClaims(token string) (*rbac.Claims, error) {
+	claims, err := s.Verifier.ParseToken(token)
+	if err != nil {
+		return nil, nil
+	}
+	// Los tokens de servicio no llevan jti, no se revocan
+	if claims.ID == "" {
+		if claims.HasRole(rbac.RoleService) {
+			return claims, nil
+		}
+		return nil, nil
+	}
+	revoked, err := s.TokenRepo.AccessTokenRevoked(claims.ID)
+	if err != nil || revoked {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// EnsureAdmin creates the admin user when it doesn't exist yet, it's the
+// only way to get the first admin.
+func (s *authServiceImpl) EnsureAdmin(username, password string) error {
+	_, err := s.Repo.FindByUsername(username)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return s.Repo.CreateUser(&models.User{Username: username, Password: password, Roles: rbac.RoleAdmin})
+}
+
+// findAccount looks the user up by username and then by email.
+func (s *authServiceImpl) findAccount(usernameOrEmail string) (*models.User, error) {
+	user, err := s.Repo.FindByUsername(usernameOrEmail)
+	if errors.Is(err, gorm.ErrRecordNotFound) && strings.Contains(usernameOrEmail, "@") {
+		return s.Repo.FindByEmail(usernameOrEmail)
+	}
+	return user, err
+}
+
+// startVerification stores a new verification token for the user and emails
+// it in the background.
+func (s *authServiceImpl) startVerification(user *models.User) error {
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.Policy.VerifyTTL)
+	err = s.TokenRepo.CreateVerificationToken(&models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		resp, err := s.NotifClient.SendEmailVerification(ctx, &pb.SendEmailVerificationRequest{
+			Email:     *user.Email,
+			VerifyUrl: s.Policy.VerifyURL + "?token=" + url.QueryEscape(token),
+			ExpiresAt: expiresAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			log.Printf("Error sending email verification to user %d: %v", user.ID, err)
+		} else if !resp.Success {
+			log.Printf("Email verification not sent to user %d: %s", user.ID, resp.Message)
+		}
+	}()
+	return nil
+}
+
+// sendPasswordReset asks the notification service to email the reset link.
+// Users without an email get it at their username, when it's one, or at their
+// linked record's email.
+func (s *authServiceImpl) sendPasswordReset(user *models.User, token string, expiresAt time.Time) {
+	email := ""
+	if user.Email != nil {
+		email = *user.Email
+	} else if _, err := mail.ParseAddress(user.Username); err == nil {
+		email = user.Username
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := s.NotifClient.SendPasswordReset(ctx, &pb.SendPasswordResetRequest{
+		Email:          email,
+		ClientId:       uint32(user.ClientID),
+		ProfessionalId: uint32(user.ProfessionalID),
+		ResetUrl:       s.Policy.ResetURL + "?token=" + url.QueryEscape(token),
+		ExpiresAt:      expiresAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Printf("Error sending password reset to user %d: %v", user.ID, err)
+	} else if !resp.Success {
+		log.Printf("Password reset not sent to user %d: %s", user.ID, resp.Message)
+	}
+}
+
+// revokeReused revokes the family of a refresh token presented after it was
+// already rotated, whoever holds the family can't be trusted anymore.
+func (s *authServiceImpl) revokeReused(token *models.RefreshToken) (*pb.RefreshResponse, error) {
+	log.Printf("Refresh token reuse detected for user %d, revoking family %s", token.UserID, token.FamilyID)
+	if err := s.TokenRepo.RevokeFamily(token.FamilyID); err != nil {
+		return &pb.RefreshResponse{Message: "Error refreshing token", Success: false}, err
+	}
+	return &pb.RefreshResponse{Message: "Invalid refresh token", Success: false}, nil
+}
+
+// issueTokens signs a new access token and stores its refresh token. Login
+// starts a family, a refresh rotates the used token into its family.
+func (s *authServiceImpl) issueTokens(user *models.User, familyID string, used *models.RefreshToken) (string, string, error) {
+	now := time.Now()
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	// Sin verificar el correo solo entra donde basta cualquier token
+	roles := user.RoleList()
+	if user.PendingVerification() {
+		roles = []string{}
+	}
+	accessToken, err := s.Keys.Sign(jwt.MapClaims{
+		"user_id":         user.ID,                            // ID del usuario en el cuerpo
+		"roles":           roles,                              // Roles revisados por el gateway y los servicios
+		"client_id":       user.ClientID,                      // Registros del usuario, los servicios
+		"professional_id": user.ProfessionalID,                // revisan que solo toque los suyos
+		"exp":             now.Add(s.Policy.AccessTTL).Unix(), // Expira pronto, se renueva con el refresh token
+		"iat":             now.Unix(),                         // Issued At: tiempo de emisión
+		"jti":             jti,                                // Permite revocarlo antes de que expire
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	if familyID == "" {
+		if familyID, err = randomToken(16); err != nil {
+			return "", "", err
+		}
+	}
+	stored := &models.RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       hashToken(refreshToken),
+		ExpiresAt:       now.Add(s.Policy.RefreshTTL),
+		AccessJTI:       jti,
+		AccessExpiresAt: now.Add(s.Policy.AccessTTL),
+	}
+	if used != nil {
+		err = s.TokenRepo.RotateRefreshToken(used, stored)
+	} else {
+		err = s.TokenRepo.CreateRefreshToken(stored)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is what gets stored of a refresh token, a leaked table can't be
+// used to refresh. They're random enough to not need a slow hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+)
+
+var ErrNoSigningKey = errors.New("no_signing_key")
+
+// KeyPolicy holds how the signing keys rotate.
+type KeyPolicy struct {
+	// Rotation is how long a key signs before the next one takes over
+	Rotation time.Duration
+	// PublishAhead is how long the next key is published before it signs, it
+	// must be longer than the JWKS refresh interval of the gateway and services
+	PublishAhead time.Duration
+	// TokenTTL keeps a replaced key published until the tokens it signed expire
+	TokenTTL time.Duration
+}
+
+type KeyService interface {
+	GetJWKS(req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error)
+	JWKS(ctx context.Context) (*rbac.JWKS, error)
+	Sign(claims jwt.Claims) (string, error)
+	RotateKeys(now time.Time) error
+}
+
+// keyServiceImpl keeps the keys in memory, RotateKeys reloads them so the
+// ones created by another instance are picked up.
+type keyServiceImpl struct {
+	Repo   repositories.KeyRepository
+	Policy KeyPolicy
+
+	mu   sync.RWMutex
+	keys []models.SigningKey
+}
+
+func NewKeyService(repo repositories.KeyRepository, policy KeyPolicy) KeyService {
+	return &keyServiceImpl{Repo: repo, Policy: policy}
+}
+
+func (s *keyServiceImpl) GetJWKS(req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	jwks, _ := s.JWKS(context.Background())
+	resp := &pb.GetJWKSResponse{Keys: make([]*pb.JWK, len(jwks.Keys))}
+	for i, key := range jwks.Keys {
+		resp.Keys[i] = &pb.JWK{Kty: key.Kty, Crv: key.Crv, X: key.X, Kid: key.Kid, Use: key.Use, Alg: key.Alg}
+	}
+	return resp, nil
+}
+
+// JWKS returns the public keys, including the next one that doesn't sign yet.
+func (s *keyServiceImpl) JWKS(ctx context.Context) (*rbac.JWKS, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jwks := &rbac.JWKS{Keys: make([]rbac.JWK, len(s.keys))}
+	for i, key := range s.keys {
+		public := ed25519.NewKeyFromSeed(key.Seed).Public().(ed25519.PublicKey)
+		jwks.Keys[i] = rbac.NewJWK(key.Kid, public)
+	}
+	return jwks, nil
+}
+
+// Sign signs the claims with the newest active key, its kid goes in the
+// header.
+func (s *keyServiceImpl) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if s.keys[i].ActivatesAt.After(now) {
+			continue
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = s.keys[i].Kid
+		return token.SignedString(ed25519.NewKeyFromSeed(s.keys[i].Seed))
+	}
+	return "", ErrNoSigningKey
+}
+
+// RotateKeys creates the next key when the current one is close to the end of
+// its rotation, and deletes the replaced keys whose tokens have all expired.
+// The first run creates a key that signs right away.
+func (s *keyServiceImpl) RotateKeys(now time.Time) error {
+	keys, err := s.Repo.ListKeys()
+	if err != nil {
+		return err
+	}
+
+	kept := make([]models.SigningKey, 0, len(keys))
+	for i, key := range keys {
+		// Una llave reemplazada se publica hasta que expiran los tokens que firmó
+		if i+1 < len(keys) && now.After(keys[i+1].ActivatesAt.Add(s.Policy.TokenTTL)) {
+			if err := s.Repo.DeleteKey(key.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		kept = append(kept, key)
+	}
+
+	var activatesAt time.Time
+	switch {
+	case len(kept) == 0:
+		activatesAt = now
+	case !now.Before(kept[len(kept)-1].ActivatesAt.Add(s.Policy.Rotation - s.Policy.PublishAhead)):
+		activatesAt = kept[len(kept)-1].ActivatesAt.Add(s.Policy.Rotation)
+		if earliest := now.Add(s.Policy.PublishAhead); activatesAt.Before(earliest) {
+			activatesAt = earliest
+		}
+	}
+	if !activatesAt.IsZero() {
+		key, err := newSigningKey(activatesAt)
+		if err != nil {
+			return err
+		}
+		if err := s.Repo.CreateKey(key); err != nil {
+			return err
+		}
+		log.Printf("Signing key %s created, signs from %s", key.Kid, activatesAt.Format(time.RFC3339))
+		kept = append(kept, *key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = kept
+	return nil
+}
+
+func newSigningKey(activatesAt time.Time) (*models.SigningKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := randomToken(12)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SigningKey{Kid: kid, Seed: private.Seed(), ActivatesAt: activatesAt}, nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// errInvalidCredentials is the only answer to a failed login, whether the
+// username exists or not.
+var errInvalidCredentials = errors.New("invalid_credentials")
+
+// unknownUserHash is compared with the password of the unknown usernames, so
+// they take as long to fail as a wrong password.
+var unknownUserHash, _ = bcrypt.GenerateFromPassword([]byte("unknown-user"), bcrypt.DefaultCost)
+
+// LoginPolicy holds how the failed logins are slowed down and locked out.
+type LoginPolicy struct {
+	// After FreeAttempts failures an account waits Delay before the next try,
+	// doubling with every failure up to MaxDelay
+	FreeAttempts int
+	Delay        time.Duration
+	MaxDelay     time.Duration
+	// MaxFailures locks an account out for Lockout, IPMaxFailures an address,
+	// which isn't delayed since many users can share it. The failures are
+	// forgotten after Lockout without any
+	MaxFailures   int
+	IPMaxFailures int
+	Lockout       time.Duration
+	// MFARoles must log in with a TOTP code too, the others only once they
+	// enroll. The code is asked for with a challenge that lasts MFAChallengeTTL
+	MFARoles        []string
+	MFAChallengeTTL time.Duration
+	// MFAIssuer names the service in the authenticator apps
+	MFAIssuer string
+}
+
+func accountKey(username string) string {
+	return "user:" + username
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginKeys are the keys a login is counted by, the address only when the
+// gateway sent it.
+func loginKeys(username, ip string) []string {
+	keys := []string{accountKey(username)}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
+
+// loginRetryAfter returns how long the login must wait because of its keys'
+// failures, 0 when it can go ahead.
+func (s *authServiceImpl) loginRetryAfter(keys []string, now time.Time) (time.Duration, error) {
+	attempts, err := s.LoginRepo.FindAttempts(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var wait time.Duration
+	for _, attempt := range attempts {
+		until := time.Time{}
+		if attempt.LockedUntil != nil {
+			until = *attempt.LockedUntil
+		}
+		if attempt.Key == keys[0] && attempt.Failures >= s.LoginPolicy.FreeAttempts &&
+			now.Sub(attempt.LastFailureAt) < s.LoginPolicy.Lockout {
+			if delayed := attempt.LastFailureAt.Add(s.loginDelay(attempt.Failures)); delayed.After(until) {
+				until = delayed
+			}
+		}
+		if until.Sub(now) > wait {
+			wait = until.Sub(now)
+		}
+	}
+	return wait, nil
+}
+
+func (s *authServiceImpl) loginDelay(failures int) time.Duration {
+	delay := s.LoginPolicy.Delay
+	for i := s.LoginPolicy.FreeAttempts; i < failures && delay < s.LoginPolicy.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, s.LoginPolicy.MaxDelay)
+}
+
+// failLogin answers a failed login once counted.
+func (s *authServiceImpl) failLogin(keys []string, now time.Time) (*pb.LoginResponse, error) {
+	s.recordLoginFailure(keys, now)
+	return &pb.LoginResponse{Token: "", Success: false}, errInvalidCredentials
+}
+
+// clearLoginFailures forgets the failures of the account once it logged in.
+// The address' ones expire by themselves, with an account of its own an
+// attacker could otherwise keep trying others.
+func (s *authServiceImpl) clearLoginFailures(key string) {
+	if _, err := s.LoginRepo.ClearAttempts([]string{key}); err != nil {
+		log.Printf("Error clearing failed logins of %s: %v", key, err)
+	}
+}
+
+// recordLoginFailure counts the failure on every key and locks out the ones
+// that reached their limit.
+func (s *authServiceImpl) recordLoginFailure(keys []string, now time.Time) {
+	for i, key := range keys {
+		attempt, err := s.LoginRepo.RecordFailure(key, now, s.LoginPolicy.Lockout)
+		if err != nil {
+			log.Printf("Error recording failed login of %s: %v", key, err)
+			continue
+		}
+		maxFailures := s.LoginPolicy.MaxFailures
+		if i > 0 {
+			maxFailures = s.LoginPolicy.IPMaxFailures
+		}
+		if attempt.Failures < maxFailures {
+			continue
+		}
+
+		until := now.Add(s.LoginPolicy.Lockout)
+		locked, err := s.LoginRepo.Lock(key, until, maxFailures)
+		if err != nil {
+			log.Printf("Error locking out %s: %v", key, err)
+			continue
+		}
+		if !locked {
+			continue
+		}
+		log.Printf("Login of %s locked out until %s after %d failures", key, until.Format(time.RFC3339), attempt.Failures)
+		if err := s.LoginRepo.CreateEvent(&models.LoginEvent{Kind: models.LoginEventLockout, Key: key}); err != nil {
+			log.Printf("Error recording lockout of %s: %v", key, err)
+		}
+	}
+}
+
+// UnlockLogin lets an admin clear the failed logins of an account or an
+// address before their lockout ends.
+func (s *authServiceImpl) UnlockLogin(ctx context.Context, req *pb.UnlockLoginRequest) (*pb.UnlockLoginResponse, error) {
+	var keys []string
+	if req.Username != "" {
+		keys = append(keys, accountKey(req.Username))
+	}
+	if req.ClientIp != "" {
+		keys = append(keys, ipKey(req.ClientIp))
+	}
+	if len(keys) == 0 {
+		return &pb.UnlockLoginResponse{Message: "Username or client IP is required", Success: false}, nil
+	}
+
+	cleared, err := s.LoginRepo.ClearAttempts(keys)
+	if err != nil {
+		return &pb.UnlockLoginResponse{Message: "Error unlocking login", Success: false}, err
+	}
+	if cleared == 0 {
+		return &pb.UnlockLoginResponse{Message: "No failed logins to clear", Success: false}, nil
+	}
+
+	var actorID uint
+	if claims := rbac.FromContext(ctx); claims != nil {
+		actorID = claims.UserID
+	}
+	for _, key := range keys {
+		log.Printf("Login of %s unlocked by user %d", key, actorID)
+		if err := s.LoginRepo.CreateEvent(&models.LoginEvent{Kind: models.LoginEventUnlock, Key: key, ActorID: actorID}); err != nil {
+			log.Printf("Error recording unlock of %s: %v", key, err)
+		}
+	}
+	return &pb.UnlockLoginResponse{Message: "Login unlocked", Success: true}, nil
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"gorm.io/gorm"
+)
+
+// errInvalidMFAToken is the answer to an unknown, used or expired MFA token.
+var errInvalidMFAToken = errors.New("invalid_mfa_token")
+
+const (
+	// maxMFAFailures wrong codes and the login has to start over with the
+	// password, which is throttled
+	maxMFAFailures = 5
+	recoveryCodes  = 10
+)
+
+// mfaEnrolled reports whether the user activated TOTP.
+func (s *authServiceImpl) mfaEnrolled(userID uint) (bool, error) {
+	enrollment, err := s.MFARepo.FindEnrollment(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return enrollment.ActivatedAt != nil, nil
+}
+
+// mfaRequired reports whether any of the user's roles must use MFA.
+func (s *authServiceImpl) mfaRequired(user *models.User) bool {
+	for _, role := range user.RoleList() {
+		if slices.Contains(s.LoginPolicy.MFARoles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// challengeLogin answers the first step of a login that needs MFA with the
+// token for the second one.
+func (s *authServiceImpl) challengeLogin(user *models.User, enrolled bool) (*pb.LoginResponse, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return &pb.LoginResponse{Token: "", Success: false}, err
+	}
+	err = s.MFARepo.CreateChallenge(&models.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.LoginPolicy.MFAChallengeTTL),
+	})
+	if err != nil {
+		return &pb.LoginResponse{Token: "", Success: false}, err
+	}
+
+	return &pb.LoginResponse{
+		Token:                 "",
+		Success:               false,
+		MfaRequired:           enrolled,
+		MfaEnrollmentRequired: !enrolled,
+		MfaToken:              token,
+	}, nil
+}
+
+// findChallenge returns the challenge of the token while it can still be
+// used, errInvalidMFAToken otherwise.
+func (s *authServiceImpl) findChallenge(token string) (*models.MFAChallenge, error) {
+	challenge, err := s.MFARepo.FindChallenge(hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errInvalidMFAToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if challenge.UsedAt != nil || !time.Now().Before(challenge.ExpiresAt) || challenge.Failures >= maxMFAFailures {
+		return nil, errInvalidMFAToken
+	}
+	return challenge, nil
+}
+
+// VerifyMFA finishes a login with a TOTP or recovery code. The wrong codes
+// count as failed logins, so guessing them is throttled like the password.
+func (s *authServiceImpl) VerifyMFA(req *pb.VerifyMFARequest) (*pb.LoginResponse, error) {
+	challenge, err := s.findChallenge(req.MfaToken)
+	if err != nil {
+		return &pb.LoginResponse{Token: "", Success: false}, err
+	}
+	user, err := s.Repo.FindByID(challenge.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && user.Disabled()) {
+		return &pb.LoginResponse{Token: "", Success: false}, errInvalidMFAToken
+	}
+	if err != nil {
+		return &pb.LoginResponse{Token: "", Success: false}, err
+	}
+
+	now := time.Now()
+	keys := loginKeys(user.Username, req.ClientIp)
+	wait, err := s.loginRetryAfter(keys, now)
+	if err != nil {
+		log.Printf("Error checking failed logins: %v", err)
+		return &pb.LoginResponse{Token: "", Success: false}, errors.New("error_checking_login")
+	}
+	if wait > 0 {
+		return &pb.LoginResponse{
+			Token:      "",
+			Success:    false,
+			RetryAfter: int64(math.Ceil(wait.Seconds())),
+		}, nil
+	}
+
+	enrollment, err := s.MFARepo.FindEnrollment(challenge.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && enrollment.ActivatedAt == nil) {
+		// Todavía tiene que activar TOTP
+		return &pb.LoginResponse{Token: "", Success: false}, errInvalidMFAToken
+	}
+	if err != nil {
+		return &pb.LoginResponse{Token: "", Success: false}, err
+	}
+
+	if err := s.checkMFACode(enrollment, req.Code); err != nil {
+		if errors.Is(err, errInvalidCredentials) {
+			if err := s.MFARepo.FailChallenge(challenge.ID); err != nil {
+				log.Printf("Error counting failed MFA code of user %d: %v", challenge.UserID, err)
+			}
+			s.recordLoginFailure(keys, now)
+		}
+		return &pb.LoginResponse{Token: "", Success: false}, err
+	}
+
+	err = s.MFARepo.UseChallenge(challenge.ID)
+	if errors.Is(err, repositories.ErrChallengeUsed) {
+		return &pb.LoginResponse{Token: "", Success: false}, errInvalidMFAToken
+	}
+	if err != nil {
+		return &pb.LoginResponse{Token: "", Success: false}, err
+	}
+	s.clearLoginFailures(keys[0])
+
+	accessToken, refreshToken, err := s.issueTokens(user, "", nil)
+	if err != nil {
+		log.Printf("Error issuing tokens: %v", err)
+		return &pb.LoginResponse{Token: "", Success: false}, errors.New("error_generating_token")
+	}
+	return &pb.LoginResponse{
+		Token:               accessToken,
+		Success:             true,
+		RefreshToken:        refreshToken,
+		ExpiresIn:           int64(s.Policy.AccessTTL.Seconds()),
+		PendingVerification: user.PendingVerification(),
+	}, nil
+}
+
+// checkMFACode accepts a TOTP code not used before or an unused recovery code,
+// errInvalidCredentials otherwise.
+func (s *authServiceImpl) checkMFACode(enrollment *models.TOTPEnrollment, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := matchTOTP(enrollment.Secret, code, time.Now()); ok {
+		err := s.MFARepo.UseTOTPStep(enrollment.UserID, step)
+		if errors.Is(err, repositories.ErrTOTPStepUsed) {
+			return errInvalidCredentials
+		}
+		return err
+	}
+	if len(code) == totpDigits {
+		return errInvalidCredentials
+	}
+
+	err := s.MFARepo.UseRecoveryCode(enrollment.UserID, hashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, repositories.ErrRecoveryCodeInvalid) {
+		return errInvalidCredentials
+	}
+	if err == nil {
+		log.Printf("User %d logged in with a recovery code", enrollment.UserID)
+	}
+	return err
+}
+
+// mfaUser is who enrolls: the user of the MFA token when logging in, the
+// caller otherwise.
+func (s *authServiceImpl) mfaUser(ctx context.Context, mfaToken string) (*models.User, error) {
+	var userID uint
+	if mfaToken != "" {
+		challenge, err := s.findChallenge(mfaToken)
+		if err != nil {
+			return nil, err
+		}
+		userID = challenge.UserID
+	} else if claims := rbac.FromContext(ctx); claims != nil && claims.UserID != 0 {
+		userID = claims.UserID
+	} else {
+		return nil, errInvalidMFAToken
+	}
+
+	user, err := s.Repo.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && user.Disabled()) {
+		return nil, errInvalidMFAToken
+	}
+	return user, err
+}
+
+// EnrollTOTP creates a new TOTP secret for the user, it's used once
+// ActivateTOTP checks a code of it.
+func (s *authServiceImpl) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	user, err := s.mfaUser(ctx, req.MfaToken)
+	if errors.Is(err, errInvalidMFAToken) {
+		return &pb.EnrollTOTPResponse{Message: "Login required", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.EnrollTOTPResponse{Message: "Error enrolling TOTP", Success: false}, err
+	}
+	enrolled, err := s.mfaEnrolled(user.ID)
+	if err != nil {
+		return &pb.EnrollTOTPResponse{Message: "Error enrolling TOTP", Success: false}, err
+	}
+	if enrolled {
+		return &pb.EnrollTOTPResponse{Message: "TOTP is already active", Success: false}, nil
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return &pb.EnrollTOTPResponse{Message: "Error enrolling TOTP", Success: false}, err
+	}
+	if err := s.MFARepo.SaveEnrollment(&models.TOTPEnrollment{UserID: user.ID, Secret: secret}); err != nil {
+		return &pb.EnrollTOTPResponse{Message: "Error enrolling TOTP", Success: false}, err
+	}
+	return &pb.EnrollTOTPResponse{
+		Message:         "Add it to the authenticator app and activate it with a code",
+		Success:         true,
+		Secret:          secret,
+		ProvisioningUri: provisioningURI(s.LoginPolicy.MFAIssuer, user.Username, secret),
+	}, nil
+}
+
+// ActivateTOTP activates the enrolled secret with one of its codes and gives
+// the recovery codes. The code isn't spent, when logging in it can also
+// finish the login.
+func (s *authServiceImpl) ActivateTOTP(ctx context.Context, req *pb.ActivateTOTPRequest) (*pb.ActivateTOTPResponse, error) {
+	user, err := s.mfaUser(ctx, req.MfaToken)
+	if errors.Is(err, errInvalidMFAToken) {
+		return &pb.ActivateTOTPResponse{Message: "Login required", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ActivateTOTPResponse{Message: "Error activating TOTP", Success: false}, err
+	}
+	enrollment, err := s.MFARepo.FindEnrollment(user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.ActivateTOTPResponse{Message: "Enroll TOTP first", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ActivateTOTPResponse{Message: "Error activating TOTP", Success: false}, err
+	}
+	if enrollment.ActivatedAt != nil {
+		return &pb.ActivateTOTPResponse{Message: "TOTP is already active", Success: false}, nil
+	}
+	if _, ok := matchTOTP(enrollment.Secret, strings.TrimSpace(req.Code), time.Now()); !ok {
+		return &pb.ActivateTOTPResponse{Message: "Invalid code", Success: false}, nil
+	}
+
+	codes := make([]string, recoveryCodes)
+	hashes := make([]string, recoveryCodes)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return &pb.ActivateTOTPResponse{Message: "Error activating TOTP", Success: false}, err
+		}
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+	err = s.MFARepo.ActivateEnrollment(user.ID, hashes)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.ActivateTOTPResponse{Message: "TOTP is already active", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.ActivateTOTPResponse{Message: "Error activating TOTP", Success: false}, err
+	}
+	log.Printf("TOTP activated for user %d", user.ID)
+	return &pb.ActivateTOTPResponse{Message: "TOTP activated", Success: true, RecoveryCodes: codes}, nil
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/config"
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/handlers"
@@ -10,31 +15,188 @@ import (
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/services"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
-	secretKey = common.EnvString("JWT_SECRET", "please-dont-use-this-key-12345")
+	// Firma solo los tokens entre servicios, los de los usuarios se firman con las llaves rotativas
+	secretKey = common.EnvString("JWT_SECRET", rbac.DefaultSecret)
+	devMode   = common.EnvString("DEV_MODE", "false") == "true"
 	dsn       = common.EnvString("AUTH_DB", "host=localhost user=postgres password=postgres dbname=Auth port=5432 sslmode=disable")
+	// Los access tokens duran poco, se renuevan con el refresh token
+	accessTokenTTL  = common.EnvString("ACCESS_TOKEN_TTL", "15m")
+	refreshTokenTTL = common.EnvString("REFRESH_TOKEN_TTL", "720h")
+	// Si se definen, se crea este admin al partir cuando aún no existe
+	adminUsername = common.EnvString("ADMIN_USERNAME", "")
+	adminPassword = common.EnvString("ADMIN_PASSWORD", "")
+	// Cada cuánto cambia la llave que firma, la siguiente se publica antes de usarse
+	keyRotation     = common.EnvString("SIGNING_KEY_ROTATION", "720h")
+	keyPublishAhead = common.EnvString("SIGNING_KEY_PUBLISH_AHEAD", "1h")
+	// Los correos para restablecer la contraseña enlazan a esta página del
+	// frontend, que pide la nueva y llama a /api/reset-password con el token
+	passwordResetURL = common.EnvString("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+	passwordResetTTL = common.EnvString("PASSWORD_RESET_TTL", "1h")
+	// Lo mismo para confirmar el correo de las cuentas nuevas, el enlace se
+	// reenvía a lo más una vez cada EMAIL_VERIFICATION_RESEND_INTERVAL
+	emailVerificationURL            = common.EnvString("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email")
+	emailVerificationTTL            = common.EnvString("EMAIL_VERIFICATION_TTL", "24h")
+	emailVerificationResendInterval = common.EnvString("EMAIL_VERIFICATION_RESEND_INTERVAL", "1m")
+	// "deny" rechaza el login sin el correo verificado, "limited" entrega un
+	// token sin roles
+	unverifiedLogin = common.EnvString("UNVERIFIED_LOGIN", services.UnverifiedLoginDeny)
+	// Tras LOGIN_MAX_FAILURES logins fallidos la cuenta se bloquea por
+	// LOGIN_LOCKOUT, la dirección tras LOGIN_IP_MAX_FAILURES
+	loginMaxFailures   = common.EnvString("LOGIN_MAX_FAILURES", "10")
+	loginIPMaxFailures = common.EnvString("LOGIN_IP_MAX_FAILURES", "100")
+	loginLockout       = common.EnvString("LOGIN_LOCKOUT", "15m")
+	// Estos roles entran con un código TOTP además de la contraseña, separados
+	// por comas; vacío para que sea opcional en todos
+	mfaRequiredRoles = common.EnvString("MFA_REQUIRED_ROLES", "admin,staff")
+	mfaIssuer        = common.EnvString("MFA_ISSUER", "Appointment Booking")
 )
 
 func main() {
+	if err := rbac.CheckSecret(secretKey, devMode); err != nil {
+		log.Fatal(err)
+	}
+
 	dbConfig := config.NewDBConfig(dsn)
 	db, err := dbConfig.ConnectDB()
 	if err != nil {
 		log.Fatalf("DB connection error: %v", err)
 	}
 
+	accessTTL, err := time.ParseDuration(accessTokenTTL)
+	if err != nil {
+		log.Fatalf("Invalid ACCESS_TOKEN_TTL: %v", err)
+	}
+	refreshTTL, err := time.ParseDuration(refreshTokenTTL)
+	if err != nil {
+		log.Fatalf("Invalid REFRESH_TOKEN_TTL: %v", err)
+	}
+
+	resetTTL, err := time.ParseDuration(passwordResetTTL)
+	if err != nil {
+		log.Fatalf("Invalid PASSWORD_RESET_TTL: %v", err)
+	}
+
+	verifyTTL, err := time.ParseDuration(emailVerificationTTL)
+	if err != nil {
+		log.Fatalf("Invalid EMAIL_VERIFICATION_TTL: %v", err)
+	}
+	resendInterval, err := time.ParseDuration(emailVerificationResendInterval)
+	if err != nil {
+		log.Fatalf("Invalid EMAIL_VERIFICATION_RESEND_INTERVAL: %v", err)
+	}
+	if unverifiedLogin != services.UnverifiedLoginDeny && unverifiedLogin != services.UnverifiedLoginLimited {
+		log.Fatalf("Invalid UNVERIFIED_LOGIN: %s", unverifiedLogin)
+	}
+
+	maxFailures, err := strconv.Atoi(loginMaxFailures)
+	if err != nil || maxFailures < 1 {
+		log.Fatalf("Invalid LOGIN_MAX_FAILURES: %s", loginMaxFailures)
+	}
+	ipMaxFailures, err := strconv.Atoi(loginIPMaxFailures)
+	if err != nil || ipMaxFailures < 1 {
+		log.Fatalf("Invalid LOGIN_IP_MAX_FAILURES: %s", loginIPMaxFailures)
+	}
+	lockout, err := time.ParseDuration(loginLockout)
+	if err != nil {
+		log.Fatalf("Invalid LOGIN_LOCKOUT: %v", err)
+	}
+
+	var mfaRoles []string
+	for _, role := range strings.Split(mfaRequiredRoles, ",") {
+		if role = strings.TrimSpace(role); role == "" {
+			continue
+		}
+		if !slices.Contains(rbac.UserRoles, role) {
+			log.Fatalf("Invalid MFA_REQUIRED_ROLES: unknown role %s", role)
+		}
+		mfaRoles = append(mfaRoles, role)
+	}
+
+	rotation, err := time.ParseDuration(keyRotation)
+	if err != nil {
+		log.Fatalf("Invalid SIGNING_KEY_ROTATION: %v", err)
+	}
+	publishAhead, err := time.ParseDuration(keyPublishAhead)
+	if err != nil || publishAhead >= rotation {
+		log.Fatalf("Invalid SIGNING_KEY_PUBLISH_AHEAD: %s", keyPublishAhead)
+	}
+
+	keySvc := services.NewKeyService(repositories.NewKeyRepository(db),
+		services.KeyPolicy{Rotation: rotation, PublishAhead: publishAhead, TokenTTL: accessTTL})
+	if err := keySvc.RotateKeys(time.Now()); err != nil {
+		log.Fatalf("Error loading signing keys: %v", err)
+	}
+	keys := rbac.NewKeySet(secretKey, keySvc.JWKS)
+	if err := keys.Refresh(context.Background()); err != nil {
+		log.Fatalf("Error loading signing keys: %v", err)
+	}
+	// Cada minuto se revisa si toca rotar y se cargan las llaves creadas por otra instancia
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			if err := keySvc.RotateKeys(now); err != nil {
+				log.Printf("Error rotating signing keys: %v", err)
+				continue
+			}
+			keys.Refresh(context.Background())
+		}
+	}()
+
+	// Las llamadas a otros servicios van con un token de servicio
+	serviceCreds := rbac.NewServiceCredentials(secretKey, "auth")
+	notifConn, err := grpc.NewClient("localhost:50055", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to notification server: %v", err)
+	}
+	defer notifConn.Close()
+
 	repo := repositories.NewUserRepository(db)
-	srv := services.NewAuthService(repo, secretKey)
-	handler := handlers.NewAuthHandler(srv)
+	srv := services.NewAuthService(repo, repositories.NewTokenRepository(db), repositories.NewLoginRepository(db),
+		repositories.NewMFARepository(db), keySvc, keys,
+		services.TokenPolicy{
+			AccessTTL:       accessTTL,
+			RefreshTTL:      refreshTTL,
+			ResetTTL:        resetTTL,
+			ResetURL:        passwordResetURL,
+			VerifyTTL:       verifyTTL,
+			VerifyURL:       emailVerificationURL,
+			ResendInterval:  resendInterval,
+			UnverifiedLogin: unverifiedLogin,
+		},
+		services.LoginPolicy{
+			FreeAttempts:    3,
+			Delay:           time.Second,
+			MaxDelay:        time.Minute,
+			MaxFailures:     maxFailures,
+			IPMaxFailures:   ipMaxFailures,
+			Lockout:         lockout,
+			MFARoles:        mfaRoles,
+			MFAChallengeTTL: 5 * time.Minute,
+			MFAIssuer:       mfaIssuer,
+		},
+		pb.NewNotificationServiceClient(notifConn))
+	handler := handlers.NewAuthHandler(srv, keySvc)
+	if adminUsername != "" && adminPassword != "" {
+		if err := srv.EnsureAdmin(adminUsername, adminPassword); err != nil {
+			log.Fatalf("Error creating admin user: %v", err)
+		}
+	}
 
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		log.Fatalf("Error opening port 50051: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	// Auth firma los tokens, los revisa localmente
+	validator := rbac.NewLocalValidator(keys)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(rbac.UnaryServerInterceptor(validator, handlers.Permissions)),
+		grpc.StreamInterceptor(rbac.StreamServerInterceptor(validator, handlers.Permissions)))
 	pb.RegisterAuthServiceServer(grpcServer, handler)
 
 	log.Println("Auth server runing in port :50051...")
@@ -1,16 +1,25 @@
 package unit
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/services"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 type MockUserRepository struct {
@@ -27,10 +36,328 @@ func (m *MockUserRepository) FindByUsername(username string) (*models.User, erro
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateRoles(id uint, roles []string) error {
+	args := m.Called(id, roles)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateLinks(id, clientID, professionalID uint) error {
+	args := m.Called(id, clientID, professionalID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(id uint, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListUsers(search string, offset, limit int) ([]models.User, int64, error) {
+	args := m.Called(search, offset, limit)
+	return args.Get(0).([]models.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) SetDisabled(id uint, disabled bool) error {
+	args := m.Called(id, disabled)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteUser(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) RotateRefreshToken(used *models.RefreshToken, next *models.RefreshToken) error {
+	args := m.Called(used, next)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeFamily(familyID string) error {
+	args := m.Called(familyID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) ListRevokedAccessTokens(now time.Time) ([]string, error) {
+	args := m.Called(now)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTokenRepository) AccessTokenRevoked(jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeUserTokens(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) CreateResetToken(token *models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) FindResetToken(tokenHash string) (*models.PasswordResetToken, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*models.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) UseResetToken(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) CreateVerificationToken(token *models.EmailVerificationToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) FindVerificationToken(tokenHash string) (*models.EmailVerificationToken, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*models.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) UseVerificationToken(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) LastVerificationSentAt(userID uint) (time.Time, error) {
+	args := m.Called(userID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+type MockLoginRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginRepository) FindAttempts(keys []string) ([]models.LoginAttempt, error) {
+	args := m.Called(keys)
+	return args.Get(0).([]models.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginRepository) RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	args := m.Called(key, now, window)
+	return args.Get(0).(*models.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginRepository) Lock(key string, until time.Time, maxFailures int) (bool, error) {
+	args := m.Called(key, until, maxFailures)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLoginRepository) ClearAttempts(keys []string) (int64, error) {
+	args := m.Called(keys)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockLoginRepository) CreateEvent(event *models.LoginEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) FindEnrollment(userID uint) (*models.TOTPEnrollment, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*models.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockMFARepository) SaveEnrollment(enrollment *models.TOTPEnrollment) error {
+	args := m.Called(enrollment)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) ActivateEnrollment(userID uint, recoveryHashes []string) error {
+	args := m.Called(userID, recoveryHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseTOTPStep(userID uint, step int64) error {
+	args := m.Called(userID, step)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseRecoveryCode(userID uint, codeHash string) error {
+	args := m.Called(userID, codeHash)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) CreateChallenge(challenge *models.MFAChallenge) error {
+	args := m.Called(challenge)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) FindChallenge(tokenHash string) (*models.MFAChallenge, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*models.MFAChallenge), args.Error(1)
+}
+
+func (m *MockMFARepository) FailChallenge(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseChallenge(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockNotificationServiceClient only implements the account emails, auth
+// doesn't send the other notifications. They're sent in the background, sent
+// gets a value once one was.
+type MockNotificationServiceClient struct {
+	pb.NotificationServiceClient
+	mock.Mock
+	sent chan struct{}
+}
+
+func newMockNotificationServiceClient() *MockNotificationServiceClient {
+	return &MockNotificationServiceClient{sent: make(chan struct{}, 1)}
+}
+
+func (m *MockNotificationServiceClient) SendPasswordReset(ctx context.Context, in *pb.SendPasswordResetRequest, opts ...grpc.CallOption) (*pb.SendPasswordResetResponse, error) {
+	defer func() { m.sent <- struct{}{} }()
+	args := m.Called(in)
+	return args.Get(0).(*pb.SendPasswordResetResponse), args.Error(1)
+}
+
+func (m *MockNotificationServiceClient) SendEmailVerification(ctx context.Context, in *pb.SendEmailVerificationRequest, opts ...grpc.CallOption) (*pb.SendEmailVerificationResponse, error) {
+	defer func() { m.sent <- struct{}{} }()
+	args := m.Called(in)
+	return args.Get(0).(*pb.SendEmailVerificationResponse), args.Error(1)
+}
+
+// waitSent fails the test when the notification isn't sent within a second.
+func (m *MockNotificationServiceClient) waitSent(t *testing.T) {
+	select {
+	case <-m.sent:
+	case <-time.After(time.Second):
+		t.Fatal("notification not sent")
+	}
+}
+
+type MockKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockKeyRepository) CreateKey(key *models.SigningKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockKeyRepository) ListKeys() ([]models.SigningKey, error) {
+	args := m.Called()
+	return args.Get(0).([]models.SigningKey), args.Error(1)
+}
+
+func (m *MockKeyRepository) DeleteKey(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// newTestKeys returns a key service with a single key that signs right away.
+func newTestKeys() services.KeyService {
+	repo := new(MockKeyRepository)
+	repo.On("ListKeys").Return([]models.SigningKey{}, nil)
+	repo.On("CreateKey", mock.Anything).Return(nil)
+	keys := services.NewKeyService(repo, testKeyPolicy)
+	if err := keys.RotateKeys(time.Now()); err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+var (
+	testKeyPolicy = services.KeyPolicy{Rotation: 720 * time.Hour, PublishAhead: time.Hour, TokenTTL: 15 * time.Minute}
+	testKeys      = newTestKeys()
+	// testVerifier trusts testKeys and "test-secret-key" for service tokens
+	testVerifier = rbac.NewKeySet("test-secret-key", testKeys.JWKS)
+)
+
+func init() {
+	if err := testVerifier.Refresh(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+var testTokenPolicy = services.TokenPolicy{
+	AccessTTL:  15 * time.Minute,
+	RefreshTTL: 24 * time.Hour,
+	ResetTTL:   time.Hour,
+	ResetURL:   "http://localhost:3000/reset-password",
+	VerifyTTL:  24 * time.Hour,
+	VerifyURL:  "http://localhost:3000/verify-email",
+	// Sin UnverifiedLogin se rechaza el login de las cuentas pendientes
+	ResendInterval: time.Minute,
+}
+
+var testLoginPolicy = services.LoginPolicy{
+	FreeAttempts:  3,
+	Delay:         time.Second,
+	MaxDelay:      time.Minute,
+	MaxFailures:   10,
+	IPMaxFailures: 100,
+	Lockout:       15 * time.Minute,
+	// Los tests de login usan staff, el MFA obligatorio se prueba con admin
+	MFARoles:        []string{"admin"},
+	MFAChallengeTTL: 5 * time.Minute,
+	MFAIssuer:       "Appointments",
+}
+
+// newUnenrolledMFARepository has no TOTP enrolled for any user.
+func newUnenrolledMFARepository() *MockMFARepository {
+	mfa := new(MockMFARepository)
+	mfa.On("FindEnrollment", mock.Anything).Return((*models.TOTPEnrollment)(nil), gorm.ErrRecordNotFound)
+	return mfa
+}
+
+// newCleanLoginRepository has no failed logins for the username.
+func newCleanLoginRepository(username string) *MockLoginRepository {
+	logins := new(MockLoginRepository)
+	logins.On("FindAttempts", []string{"user:" + username}).Return([]models.LoginAttempt{}, nil)
+	logins.On("ClearAttempts", []string{"user:" + username}).Return(int64(0), nil)
+	return logins
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
 func TestCreateUser(t *testing.T) {
-	secretKey := "test-secret-key"
 	mockRepo := new(MockUserRepository)
-	srv := services.NewAuthService(mockRepo, secretKey)
+	srv := services.NewAuthService(mockRepo, new(MockTokenRepository), nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
 
 	tests := []struct {
 		name         string
@@ -44,7 +371,9 @@ func TestCreateUser(t *testing.T) {
 			req:  &pb.CreateUserRequest{Username: "testuser", Password: "testpass"},
 			mockSetup: func() {
 				mockRepo.On("FindByUsername", "testuser").Return((*models.User)(nil), errors.New("not found")).Once()
-				mockRepo.On("CreateUser", mock.AnythingOfType("*models.User")).Return(nil).Once()
+				mockRepo.On("CreateUser", mock.MatchedBy(func(user *models.User) bool {
+					return user.Roles == "client"
+				})).Return(nil).Once()
 			},
 			expectedResp: &pb.CreateUserResponse{Message: "User created", Success: true},
 			expectedErr:  nil,
@@ -82,13 +411,14 @@ func TestCreateUser(t *testing.T) {
 }
 
 func TestLogin(t *testing.T) {
-	secretKey := "test-secret-key"
 	mockRepo := new(MockUserRepository)
-	srv := services.NewAuthService(mockRepo, secretKey)
+	mockTokens := new(MockTokenRepository)
+	mockLogins := new(MockLoginRepository)
+	srv := services.NewAuthService(mockRepo, mockTokens, mockLogins, newUnenrolledMFARepository(), testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
 
 	// Mock de usuario con contraseña encriptada
 	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.DefaultCost)
-	user := &models.User{ID: 1, Username: "testuser", Password: string(hashedPass)}
+	user := &models.User{ID: 1, Username: "testuser", Password: string(hashedPass), Roles: "staff,professional", ProfessionalID: 3}
 
 	tests := []struct {
 		name         string
@@ -99,30 +429,56 @@ func TestLogin(t *testing.T) {
 	}{
 		{
 			name: "Success",
-			req:  &pb.LoginRequest{Username: "testuser", Password: "testpass"},
+			req:  &pb.LoginRequest{Username: "testuser", Password: "testpass", ClientIp: "10.0.0.1"},
 			mockSetup: func() {
+				mockLogins.On("FindAttempts", []string{"user:testuser", "ip:10.0.0.1"}).Return([]models.LoginAttempt{}, nil).Once()
 				mockRepo.On("FindByUsername", "testuser").Return(user, nil).Once()
+				mockLogins.On("ClearAttempts", []string{"user:testuser"}).Return(int64(1), nil).Once()
+				mockTokens.On("CreateRefreshToken", mock.MatchedBy(func(token *models.RefreshToken) bool {
+					return token.UserID == 1 && token.FamilyID != "" && token.AccessJTI != ""
+				})).Return(nil).Once()
 			},
 			expectedResp: &pb.LoginResponse{Success: true},
 			expectedErr:  nil,
 		},
 		{
 			name: "UserNotFound",
-			req:  &pb.LoginRequest{Username: "unknown", Password: "testpass"},
+			req:  &pb.LoginRequest{Username: "unknown", Password: "testpass", ClientIp: "10.0.0.1"},
 			mockSetup: func() {
-				mockRepo.On("FindByUsername", "unknown").Return((*models.User)(nil), errors.New("not found")).Once()
+				mockLogins.On("FindAttempts", []string{"user:unknown", "ip:10.0.0.1"}).Return([]models.LoginAttempt{}, nil).Once()
+				mockRepo.On("FindByUsername", "unknown").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+				mockLogins.On("RecordFailure", "user:unknown", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "user:unknown", Failures: 1}, nil).Once()
+				mockLogins.On("RecordFailure", "ip:10.0.0.1", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "ip:10.0.0.1", Failures: 1}, nil).Once()
 			},
 			expectedResp: &pb.LoginResponse{Token: "", Success: false},
-			expectedErr:  errors.New("user_not_found"),
+			expectedErr:  errors.New("invalid_credentials"),
 		},
 		{
 			name: "WrongPassword",
 			req:  &pb.LoginRequest{Username: "testuser", Password: "wrongpass"},
 			mockSetup: func() {
+				mockLogins.On("FindAttempts", []string{"user:testuser"}).Return([]models.LoginAttempt{}, nil).Once()
 				mockRepo.On("FindByUsername", "testuser").Return(user, nil).Once()
+				mockLogins.On("RecordFailure", "user:testuser", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "user:testuser", Failures: 2}, nil).Once()
+			},
+			expectedResp: &pb.LoginResponse{Token: "", Success: false},
+			expectedErr:  errors.New("invalid_credentials"),
+		},
+		{
+			name: "Disabled",
+			req:  &pb.LoginRequest{Username: "testuser", Password: "testpass"},
+			mockSetup: func() {
+				disabledAt := time.Now().Add(-time.Hour)
+				disabled := *user
+				disabled.DisabledAt = &disabledAt
+				mockLogins.On("FindAttempts", []string{"user:testuser"}).Return([]models.LoginAttempt{}, nil).Once()
+				mockRepo.On("FindByUsername", "testuser").Return(&disabled, nil).Once()
 			},
 			expectedResp: &pb.LoginResponse{Token: "", Success: false},
-			expectedErr:  errors.New("incorrect_password"),
+			expectedErr:  errors.New("invalid_credentials"),
 		},
 	}
 
@@ -133,13 +489,1598 @@ func TestLogin(t *testing.T) {
 			assert.Equal(t, tt.expectedResp.Success, resp.Success)
 			if tt.expectedResp.Success {
 				assert.NotEmpty(t, resp.Token)
-				token, _ := jwt.Parse(resp.Token, func(token *jwt.Token) (interface{}, error) {
-					return []byte(secretKey), nil
-				})
-				assert.True(t, token.Valid)
+				claims, err := testVerifier.ParseToken(resp.Token)
+				assert.NoError(t, err)
+				assert.NotEmpty(t, claims.ID)
+				assert.Equal(t, []string{"staff", "professional"}, claims.Roles)
+				assert.Equal(t, uint(3), claims.ProfessionalID)
+				assert.NotEmpty(t, resp.RefreshToken)
+				assert.Equal(t, int64(900), resp.ExpiresIn)
+			}
+			assert.Equal(t, tt.expectedErr, err)
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+			mockLogins.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.DefaultCost)
+	user := &models.User{ID: 1, Username: "testuser", Password: string(hashedPass), Roles: "client"}
+	keys := []string{"user:testuser", "ip:10.0.0.1"}
+
+	tests := []struct {
+		name       string
+		password   string
+		mockSetup  func(*MockUserRepository, *MockLoginRepository)
+		retryAfter int64
+		// Sin retryAfter se revisó la contraseña y falló
+		expectedErr error
+	}{
+		{
+			name:     "Delayed",
+			password: "testpass",
+			mockSetup: func(repo *MockUserRepository, logins *MockLoginRepository) {
+				// La quinta falla espera 4s
+				logins.On("FindAttempts", keys).Return([]models.LoginAttempt{
+					{Key: "user:testuser", Failures: 5, LastFailureAt: time.Now().Add(-time.Second)},
+				}, nil).Once()
+			},
+			retryAfter: 3,
+		},
+		{
+			name:     "DelayOver",
+			password: "wrongpass",
+			mockSetup: func(repo *MockUserRepository, logins *MockLoginRepository) {
+				logins.On("FindAttempts", keys).Return([]models.LoginAttempt{
+					{Key: "user:testuser", Failures: 5, LastFailureAt: time.Now().Add(-5 * time.Second)},
+				}, nil).Once()
+				repo.On("FindByUsername", "testuser").Return(user, nil).Once()
+				logins.On("RecordFailure", "user:testuser", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "user:testuser", Failures: 6}, nil).Once()
+				logins.On("RecordFailure", "ip:10.0.0.1", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "ip:10.0.0.1", Failures: 6}, nil).Once()
+			},
+			expectedErr: errors.New("invalid_credentials"),
+		},
+		{
+			name:     "AddressNotDelayed",
+			password: "testpass",
+			mockSetup: func(repo *MockUserRepository, logins *MockLoginRepository) {
+				logins.On("FindAttempts", keys).Return([]models.LoginAttempt{
+					{Key: "ip:10.0.0.1", Failures: 50, LastFailureAt: time.Now()},
+				}, nil).Once()
+				repo.On("FindByUsername", "testuser").Return(user, nil).Once()
+				logins.On("ClearAttempts", keys[:1]).Return(int64(0), nil).Once()
+			},
+		},
+		{
+			name:     "AddressLocked",
+			password: "testpass",
+			mockSetup: func(repo *MockUserRepository, logins *MockLoginRepository) {
+				until := time.Now().Add(10 * time.Minute)
+				logins.On("FindAttempts", keys).Return([]models.LoginAttempt{
+					{Key: "ip:10.0.0.1", LastFailureAt: time.Now(), LockedUntil: &until},
+				}, nil).Once()
+			},
+			retryAfter: 600,
+		},
+		{
+			name:     "LocksOut",
+			password: "wrongpass",
+			mockSetup: func(repo *MockUserRepository, logins *MockLoginRepository) {
+				logins.On("FindAttempts", keys).Return([]models.LoginAttempt{
+					{Key: "user:testuser", Failures: 9, LastFailureAt: time.Now().Add(-2 * time.Minute)},
+				}, nil).Once()
+				repo.On("FindByUsername", "testuser").Return(user, nil).Once()
+				logins.On("RecordFailure", "user:testuser", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "user:testuser", Failures: 10}, nil).Once()
+				logins.On("Lock", "user:testuser", mock.MatchedBy(func(until time.Time) bool {
+					return time.Until(until) > 14*time.Minute
+				}), 10).Return(true, nil).Once()
+				logins.On("CreateEvent", &models.LoginEvent{Kind: models.LoginEventLockout, Key: "user:testuser"}).Return(nil).Once()
+				logins.On("RecordFailure", "ip:10.0.0.1", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "ip:10.0.0.1", Failures: 10}, nil).Once()
+			},
+			expectedErr: errors.New("invalid_credentials"),
+		},
+		{
+			name:     "LockedByAnotherInstance",
+			password: "wrongpass",
+			mockSetup: func(repo *MockUserRepository, logins *MockLoginRepository) {
+				logins.On("FindAttempts", keys).Return([]models.LoginAttempt{}, nil).Once()
+				repo.On("FindByUsername", "testuser").Return(user, nil).Once()
+				logins.On("RecordFailure", "user:testuser", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "user:testuser", Failures: 1}, nil).Once()
+				logins.On("RecordFailure", "ip:10.0.0.1", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "ip:10.0.0.1", Failures: 100}, nil).Once()
+				logins.On("Lock", "ip:10.0.0.1", mock.Anything, 100).Return(false, nil).Once()
+			},
+			expectedErr: errors.New("invalid_credentials"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			mockLogins := new(MockLoginRepository)
+			tt.mockSetup(mockRepo, mockLogins)
+			mockTokens.On("CreateRefreshToken", mock.AnythingOfType("*models.RefreshToken")).Return(nil).Maybe()
+			srv := services.NewAuthService(mockRepo, mockTokens, mockLogins, newUnenrolledMFARepository(), testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.Login(&pb.LoginRequest{Username: "testuser", Password: tt.password, ClientIp: "10.0.0.1"})
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.retryAfter > 0 {
+				assert.False(t, resp.Success)
+				assert.InDelta(t, tt.retryAfter, resp.RetryAfter, 1)
+			} else {
+				assert.Zero(t, resp.RetryAfter)
+				assert.Equal(t, tt.expectedErr == nil, resp.Success)
 			}
+			mockRepo.AssertExpectations(t)
+			mockLogins.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnlockLogin(t *testing.T) {
+	admin := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 1, Roles: []string{rbac.RoleAdmin}})
+
+	tests := []struct {
+		name         string
+		req          *pb.UnlockLoginRequest
+		mockSetup    func(*MockLoginRepository)
+		expectedResp *pb.UnlockLoginResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.UnlockLoginRequest{Username: "testuser", ClientIp: "10.0.0.1"},
+			mockSetup: func(logins *MockLoginRepository) {
+				logins.On("ClearAttempts", []string{"user:testuser", "ip:10.0.0.1"}).Return(int64(1), nil).Once()
+				logins.On("CreateEvent", &models.LoginEvent{Kind: models.LoginEventUnlock, Key: "user:testuser", ActorID: 1}).Return(nil).Once()
+				logins.On("CreateEvent", &models.LoginEvent{Kind: models.LoginEventUnlock, Key: "ip:10.0.0.1", ActorID: 1}).Return(nil).Once()
+			},
+			expectedResp: &pb.UnlockLoginResponse{Message: "Login unlocked", Success: true},
+		},
+		{
+			name: "NothingToClear",
+			req:  &pb.UnlockLoginRequest{Username: "testuser"},
+			mockSetup: func(logins *MockLoginRepository) {
+				logins.On("ClearAttempts", []string{"user:testuser"}).Return(int64(0), nil).Once()
+			},
+			expectedResp: &pb.UnlockLoginResponse{Message: "No failed logins to clear", Success: false},
+		},
+		{
+			name:         "Empty",
+			req:          &pb.UnlockLoginRequest{},
+			mockSetup:    func(logins *MockLoginRepository) {},
+			expectedResp: &pb.UnlockLoginResponse{Message: "Username or client IP is required", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogins := new(MockLoginRepository)
+			tt.mockSetup(mockLogins)
+			srv := services.NewAuthService(new(MockUserRepository), new(MockTokenRepository), mockLogins, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.UnlockLogin(admin, tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockLogins.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	used := time.Now().Add(-time.Minute)
+	stored := func() *models.RefreshToken {
+		return &models.RefreshToken{ID: 4, UserID: 1, FamilyID: "family", TokenHash: sha256Hex("refresh"), ExpiresAt: future}
+	}
+
+	tests := []struct {
+		name         string
+		mockSetup    func(*MockTokenRepository, *MockUserRepository)
+		expectedResp *pb.RefreshResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			mockSetup: func(tokens *MockTokenRepository, users *MockUserRepository) {
+				tokens.On("FindRefreshToken", sha256Hex("refresh")).Return(stored(), nil).Once()
+				users.On("FindByID", uint(1)).Return(&models.User{ID: 1, Roles: "client"}, nil).Once()
+				tokens.On("RotateRefreshToken", stored(), mock.MatchedBy(func(next *models.RefreshToken) bool {
+					return next.UserID == 1 && next.FamilyID == "family" && next.TokenHash != sha256Hex("refresh")
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.RefreshResponse{Message: "Token refreshed", Success: true},
+		},
+		{
+			name: "Reused",
+			mockSetup: func(tokens *MockTokenRepository, users *MockUserRepository) {
+				token := stored()
+				token.UsedAt = &used
+				tokens.On("FindRefreshToken", sha256Hex("refresh")).Return(token, nil).Once()
+				tokens.On("RevokeFamily", "family").Return(nil).Once()
+			},
+			expectedResp: &pb.RefreshResponse{Message: "Invalid refresh token", Success: false},
+		},
+		{
+			name: "ReusedConcurrently",
+			mockSetup: func(tokens *MockTokenRepository, users *MockUserRepository) {
+				tokens.On("FindRefreshToken", sha256Hex("refresh")).Return(stored(), nil).Once()
+				users.On("FindByID", uint(1)).Return(&models.User{ID: 1, Roles: "client"}, nil).Once()
+				tokens.On("RotateRefreshToken", stored(), mock.AnythingOfType("*models.RefreshToken")).
+					Return(repositories.ErrTokenReused).Once()
+				tokens.On("RevokeFamily", "family").Return(nil).Once()
+			},
+			expectedResp: &pb.RefreshResponse{Message: "Invalid refresh token", Success: false},
+		},
+		{
+			name: "Revoked",
+			mockSetup: func(tokens *MockTokenRepository, users *MockUserRepository) {
+				token := stored()
+				token.RevokedAt = &used
+				tokens.On("FindRefreshToken", sha256Hex("refresh")).Return(token, nil).Once()
+			},
+			expectedResp: &pb.RefreshResponse{Message: "Invalid refresh token", Success: false},
+		},
+		{
+			name: "Expired",
+			mockSetup: func(tokens *MockTokenRepository, users *MockUserRepository) {
+				token := stored()
+				token.ExpiresAt = used
+				tokens.On("FindRefreshToken", sha256Hex("refresh")).Return(token, nil).Once()
+			},
+			expectedResp: &pb.RefreshResponse{Message: "Invalid refresh token", Success: false},
+		},
+		{
+			name: "Unknown",
+			mockSetup: func(tokens *MockTokenRepository, users *MockUserRepository) {
+				tokens.On("FindRefreshToken", sha256Hex("refresh")).Return((*models.RefreshToken)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.RefreshResponse{Message: "Invalid refresh token", Success: false},
+		},
+		{
+			name: "UserDisabled",
+			mockSetup: func(tokens *MockTokenRepository, users *MockUserRepository) {
+				tokens.On("FindRefreshToken", sha256Hex("refresh")).Return(stored(), nil).Once()
+				users.On("FindByID", uint(1)).Return(&models.User{ID: 1, Roles: "client", DisabledAt: &used}, nil).Once()
+			},
+			expectedResp: &pb.RefreshResponse{Message: "Invalid refresh token", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokens := new(MockTokenRepository)
+			mockRepo := new(MockUserRepository)
+			tt.mockSetup(mockTokens, mockRepo)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.Refresh(&pb.RefreshRequest{RefreshToken: "refresh"})
 			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedResp.Message, resp.Message)
+			assert.Equal(t, tt.expectedResp.Success, resp.Success)
+			if tt.expectedResp.Success {
+				assert.NotEmpty(t, resp.Token)
+				assert.NotEqual(t, "refresh", resp.RefreshToken)
+			}
+			mockTokens.AssertExpectations(t)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	mockTokens := new(MockTokenRepository)
+	srv := services.NewAuthService(new(MockUserRepository), mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+	mockTokens.On("FindRefreshToken", sha256Hex("refresh")).Return(&models.RefreshToken{ID: 4, FamilyID: "family"}, nil).Once()
+	mockTokens.On("RevokeFamily", "family").Return(nil).Once()
+	resp, err := srv.Logout(&pb.LogoutRequest{RefreshToken: "refresh"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.LogoutResponse{Message: "Logged out", Success: true}, resp)
+
+	mockTokens.On("FindRefreshToken", sha256Hex("other")).Return((*models.RefreshToken)(nil), gorm.ErrRecordNotFound).Once()
+	resp, err = srv.Logout(&pb.LogoutRequest{RefreshToken: "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.LogoutResponse{Message: "Invalid refresh token", Success: false}, resp)
+	mockTokens.AssertExpectations(t)
+}
+
+func TestSetRoles(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          *pb.SetRolesRequest
+		mockSetup    func(*MockUserRepository, *MockTokenRepository)
+		expectedResp *pb.SetRolesResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.SetRolesRequest{UserId: 2, Roles: []string{"staff", "professional"}},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository) {
+				users.On("FindByID", uint(2)).Return(&models.User{ID: 2, Roles: "professional"}, nil).Once()
+				users.On("UpdateRoles", uint(2), []string{"staff", "professional"}).Return(nil).Once()
+			},
+			expectedResp: &pb.SetRolesResponse{Message: "Roles updated", Success: true},
+		},
+		{
+			name: "RoleRemoved",
+			req:  &pb.SetRolesRequest{UserId: 2, Roles: []string{"client"}},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository) {
+				users.On("FindByID", uint(2)).Return(&models.User{ID: 2, Roles: "staff,client"}, nil).Once()
+				users.On("UpdateRoles", uint(2), []string{"client"}).Return(nil).Once()
+				tokens.On("RevokeUserTokens", uint(2)).Return(nil).Once()
+			},
+			expectedResp: &pb.SetRolesResponse{Message: "Roles updated", Success: true},
+		},
+		{
+			name:         "UnknownRole",
+			req:          &pb.SetRolesRequest{UserId: 2, Roles: []string{"service"}},
+			mockSetup:    func(users *MockUserRepository, tokens *MockTokenRepository) {},
+			expectedResp: &pb.SetRolesResponse{Message: "Unknown role service", Success: false},
+		},
+		{
+			name:         "NoRoles",
+			req:          &pb.SetRolesRequest{UserId: 2},
+			mockSetup:    func(users *MockUserRepository, tokens *MockTokenRepository) {},
+			expectedResp: &pb.SetRolesResponse{Message: "At least one role is required", Success: false},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.SetRolesRequest{UserId: 9, Roles: []string{"client"}},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository) {
+				users.On("FindByID", uint(9)).Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.SetRolesResponse{Message: "User not found", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			tt.mockSetup(mockRepo, mockTokens)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.SetRoles(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLinkUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	srv := services.NewAuthService(mockRepo, new(MockTokenRepository), nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+	mockRepo.On("UpdateLinks", uint(2), uint(4), uint(0)).Return(nil).Once()
+	resp, err := srv.LinkUser(&pb.LinkUserRequest{UserId: 2, ClientId: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.LinkUserResponse{Message: "User linked", Success: true}, resp)
+
+	mockRepo.On("UpdateLinks", uint(9), uint(4), uint(0)).Return(gorm.ErrRecordNotFound).Once()
+	resp, err = srv.LinkUser(&pb.LinkUserRequest{UserId: 9, ClientId: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.LinkUserResponse{Message: "User not found", Success: false}, resp)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListUsers(t *testing.T) {
+	email := "ana@example.com"
+	now := time.Now()
+	users := []models.User{
+		{ID: 2, Username: "ana", Roles: "staff,professional", ProfessionalID: 3, Email: &email, EmailVerifiedAt: &now},
+		{ID: 5, Username: "bob", Roles: "client", ClientID: 4, DisabledAt: &now},
+	}
+
+	tests := []struct {
+		name          string
+		req           *pb.ListUsersRequest
+		offset, limit int
+	}{
+		{name: "Defaults", req: &pb.ListUsersRequest{}, offset: 0, limit: 20},
+		{name: "Page", req: &pb.ListUsersRequest{Search: " an ", Page: 3, PageSize: 10}, offset: 20, limit: 10},
+		{name: "MaxPageSize", req: &pb.ListUsersRequest{Page: 2, PageSize: 500}, offset: 100, limit: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockRepo.On("ListUsers", strings.TrimSpace(tt.req.Search), tt.offset, tt.limit).Return(users, int64(42), nil).Once()
+			srv := services.NewAuthService(mockRepo, new(MockTokenRepository), nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.ListUsers(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, &pb.ListUsersResponse{
+				Users: []*pb.UserInfo{
+					{Id: 2, Username: "ana", Email: email, Roles: []string{"staff", "professional"}, ProfessionalId: 3, EmailVerified: true},
+					{Id: 5, Username: "bob", Roles: []string{"client"}, ClientId: 4, Disabled: true},
+				},
+				Total:   42,
+				Success: true,
+			}, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDisableUser(t *testing.T) {
+	admin := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 1, Roles: []string{rbac.RoleAdmin}})
+
+	tests := []struct {
+		name         string
+		req          *pb.DisableUserRequest
+		mockSetup    func(*MockUserRepository, *MockTokenRepository)
+		expectedResp *pb.DisableUserResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.DisableUserRequest{UserId: 2},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository) {
+				users.On("SetDisabled", uint(2), true).Return(nil).Once()
+				tokens.On("RevokeUserTokens", uint(2)).Return(nil).Once()
+			},
+			expectedResp: &pb.DisableUserResponse{Message: "User disabled", Success: true},
+		},
+		{
+			name:         "Self",
+			req:          &pb.DisableUserRequest{UserId: 1},
+			mockSetup:    func(users *MockUserRepository, tokens *MockTokenRepository) {},
+			expectedResp: &pb.DisableUserResponse{Message: "You can't disable your own account", Success: false},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.DisableUserRequest{UserId: 9},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository) {
+				users.On("SetDisabled", uint(9), true).Return(gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.DisableUserResponse{Message: "User not found", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			tt.mockSetup(mockRepo, mockTokens)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.DisableUser(admin, tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEnableUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	srv := services.NewAuthService(mockRepo, new(MockTokenRepository), nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+	mockRepo.On("SetDisabled", uint(2), false).Return(nil).Once()
+	resp, err := srv.EnableUser(&pb.EnableUserRequest{UserId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.EnableUserResponse{Message: "User enabled", Success: true}, resp)
+
+	mockRepo.On("SetDisabled", uint(9), false).Return(gorm.ErrRecordNotFound).Once()
+	resp, err = srv.EnableUser(&pb.EnableUserRequest{UserId: 9})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.EnableUserResponse{Message: "User not found", Success: false}, resp)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteUser(t *testing.T) {
+	admin := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 1, Roles: []string{rbac.RoleAdmin}})
+
+	tests := []struct {
+		name         string
+		req          *pb.DeleteUserRequest
+		mockSetup    func(*MockUserRepository, *MockTokenRepository)
+		expectedResp *pb.DeleteUserResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.DeleteUserRequest{UserId: 2},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("RevokeUserTokens", uint(2)).Return(nil).Once()
+				users.On("DeleteUser", uint(2)).Return(nil).Once()
+			},
+			expectedResp: &pb.DeleteUserResponse{Message: "User deleted", Success: true},
+		},
+		{
+			name:         "Self",
+			req:          &pb.DeleteUserRequest{UserId: 1},
+			mockSetup:    func(users *MockUserRepository, tokens *MockTokenRepository) {},
+			expectedResp: &pb.DeleteUserResponse{Message: "You can't delete your own account", Success: false},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.DeleteUserRequest{UserId: 9},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("RevokeUserTokens", uint(9)).Return(nil).Once()
+				users.On("DeleteUser", uint(9)).Return(gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.DeleteUserResponse{Message: "User not found", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			tt.mockSetup(mockRepo, mockTokens)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.DeleteUser(admin, tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+		})
+	}
+}
+
+func signAccessToken(t *testing.T, keys services.KeyService, jti string, expiresAt time.Time) string {
+	token, err := keys.Sign(rbac.Claims{
+		UserID:   1,
+		Roles:    []string{rbac.RoleClient},
+		ClientID: 4,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(expiresAt.Add(-15 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	assert.NoError(t, err)
+	return token
+}
+
+func TestValidateToken(t *testing.T) {
+	expiresAt := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	valid := signAccessToken(t, testKeys, "jti-1", expiresAt)
+	serviceToken, err := rbac.NewServiceCredentials("test-secret-key", "agenda").Token()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		token        string
+		mockSetup    func(*MockTokenRepository)
+		expectedResp *pb.ValidateTokenResponse
+		expectedErr  error
+	}{
+		{
+			name:  "Valid",
+			token: valid,
+			mockSetup: func(tokens *MockTokenRepository) {
+				tokens.On("AccessTokenRevoked", "jti-1").Return(false, nil).Once()
+			},
+			expectedResp: &pb.ValidateTokenResponse{Valid: true, Claims: &pb.TokenClaims{
+				UserId: 1, Roles: []string{"client"}, ClientId: 4, Jti: "jti-1",
+				IssuedAt: expiresAt.Add(-15 * time.Minute).Unix(), ExpiresAt: expiresAt.Unix(),
+			}},
+		},
+		{
+			name:  "Revoked",
+			token: valid,
+			mockSetup: func(tokens *MockTokenRepository) {
+				tokens.On("AccessTokenRevoked", "jti-1").Return(true, nil).Once()
+			},
+			expectedResp: &pb.ValidateTokenResponse{Valid: false},
+		},
+		{
+			name:         "Expired",
+			token:        signAccessToken(t, testKeys, "jti-2", time.Now().Add(-time.Minute)),
+			mockSetup:    func(tokens *MockTokenRepository) {},
+			expectedResp: &pb.ValidateTokenResponse{Valid: false},
+		},
+		{
+			name:         "Forged",
+			token:        signAccessToken(t, newTestKeys(), "jti-1", expiresAt),
+			mockSetup:    func(tokens *MockTokenRepository) {},
+			expectedResp: &pb.ValidateTokenResponse{Valid: false},
+		},
+		{
+			name:      "ServiceToken",
+			token:     serviceToken,
+			mockSetup: func(tokens *MockTokenRepository) {},
+		},
+		{
+			name:  "DatabaseError",
+			token: valid,
+			mockSetup: func(tokens *MockTokenRepository) {
+				tokens.On("AccessTokenRevoked", "jti-1").Return(false, errors.New("db error")).Once()
+			},
+			expectedResp: &pb.ValidateTokenResponse{Valid: false},
+			expectedErr:  errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokens := new(MockTokenRepository)
+			tt.mockSetup(mockTokens)
+			srv := services.NewAuthService(new(MockUserRepository), mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.ValidateToken(&pb.ValidateTokenRequest{Token: tt.token})
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedResp == nil {
+				assert.True(t, resp.Valid)
+				assert.Equal(t, "agenda", resp.Claims.Subject)
+				assert.Equal(t, []string{rbac.RoleService}, resp.Claims.Roles)
+			} else {
+				assert.Equal(t, tt.expectedResp, resp)
+			}
+			mockTokens.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	usedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		req          *pb.IntrospectRequest
+		mockSetup    func(*MockTokenRepository)
+		expectedResp *pb.IntrospectResponse
+	}{
+		{
+			name: "AccessToken",
+			req:  &pb.IntrospectRequest{Token: signAccessToken(t, testKeys, "jti-1", expiresAt)},
+			mockSetup: func(tokens *MockTokenRepository) {
+				tokens.On("AccessTokenRevoked", "jti-1").Return(false, nil).Once()
+			},
+			expectedResp: &pb.IntrospectResponse{Active: true, TokenType: "access_token", Claims: &pb.TokenClaims{
+				UserId: 1, Roles: []string{"client"}, ClientId: 4, Jti: "jti-1",
+				IssuedAt: expiresAt.Add(-15 * time.Minute).Unix(), ExpiresAt: expiresAt.Unix(),
+			}},
+		},
+		{
+			name: "RefreshToken",
+			req:  &pb.IntrospectRequest{Token: "refresh-1", TokenTypeHint: "refresh_token"},
+			mockSetup: func(tokens *MockTokenRepository) {
+				tokens.On("FindRefreshToken", sha256Hex("refresh-1")).Return(&models.RefreshToken{ID: 5, UserID: 1,
+					CreatedAt: createdAt, ExpiresAt: expiresAt}, nil).Once()
+			},
+			expectedResp: &pb.IntrospectResponse{Active: true, TokenType: "refresh_token", Claims: &pb.TokenClaims{
+				UserId: 1, IssuedAt: createdAt.Unix(), ExpiresAt: expiresAt.Unix(),
+			}},
+		},
+		{
+			name: "UsedRefreshToken",
+			req:  &pb.IntrospectRequest{Token: "refresh-1"},
+			mockSetup: func(tokens *MockTokenRepository) {
+				tokens.On("FindRefreshToken", sha256Hex("refresh-1")).Return(&models.RefreshToken{ID: 5, UserID: 1,
+					CreatedAt: createdAt, ExpiresAt: expiresAt, UsedAt: &usedAt}, nil).Once()
+			},
+			expectedResp: &pb.IntrospectResponse{Active: false},
+		},
+		{
+			name: "Unknown",
+			req:  &pb.IntrospectRequest{Token: "garbage"},
+			mockSetup: func(tokens *MockTokenRepository) {
+				tokens.On("FindRefreshToken", sha256Hex("garbage")).Return((*models.RefreshToken)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.IntrospectResponse{Active: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokens := new(MockTokenRepository)
+			tt.mockSetup(mockTokens)
+			srv := services.NewAuthService(new(MockUserRepository), mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.Introspect(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockTokens.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	sent := &pb.SendPasswordResetResponse{Message: "Password reset send success", Success: true}
+	expected := &pb.RequestPasswordResetResponse{Message: "If the account exists, a reset link was sent", Success: true}
+
+	tests := []struct {
+		name      string
+		req       *pb.RequestPasswordResetRequest
+		mockSetup func(*MockUserRepository, *MockTokenRepository, *MockNotificationServiceClient)
+		sends     bool
+	}{
+		{
+			name: "EmailUsername",
+			req:  &pb.RequestPasswordResetRequest{UsernameOrEmail: "maria@email.com"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				var hash string
+				repo.On("FindByUsername", "maria@email.com").Return(&models.User{ID: 3, Username: "maria@email.com"}, nil).Once()
+				tokens.On("CreateResetToken", mock.MatchedBy(func(token *models.PasswordResetToken) bool {
+					hash = token.TokenHash
+					return token.UserID == 3 && time.Until(token.ExpiresAt) > 59*time.Minute
+				})).Return(nil).Once()
+				notif.On("SendPasswordReset", mock.MatchedBy(func(req *pb.SendPasswordResetRequest) bool {
+					// El enlace lleva el token, se guarda solo su hash
+					token, ok := strings.CutPrefix(req.ResetUrl, "http://localhost:3000/reset-password?token=")
+					return ok && sha256Hex(token) == hash && req.Email == "maria@email.com"
+				})).Return(sent, nil).Once()
+			},
+			sends: true,
+		},
+		{
+			name: "LinkedRecordsEmail",
+			req:  &pb.RequestPasswordResetRequest{UsernameOrEmail: "maria"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return(&models.User{ID: 3, Username: "maria", ClientID: 4}, nil).Once()
+				tokens.On("CreateResetToken", mock.Anything).Return(nil).Once()
+				notif.On("SendPasswordReset", mock.MatchedBy(func(req *pb.SendPasswordResetRequest) bool {
+					return req.Email == "" && req.ClientId == 4
+				})).Return(sent, nil).Once()
+			},
+			sends: true,
+		},
+		{
+			name: "ByEmail",
+			req:  &pb.RequestPasswordResetRequest{UsernameOrEmail: "maria@email.com"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				email := "maria@email.com"
+				repo.On("FindByUsername", "maria@email.com").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+				repo.On("FindByEmail", "maria@email.com").Return(&models.User{ID: 3, Username: "maria", Email: &email}, nil).Once()
+				tokens.On("CreateResetToken", mock.Anything).Return(nil).Once()
+				notif.On("SendPasswordReset", mock.MatchedBy(func(req *pb.SendPasswordResetRequest) bool {
+					return req.Email == "maria@email.com"
+				})).Return(sent, nil).Once()
+			},
+			sends: true,
+		},
+		{
+			name: "UnknownAccount",
+			req:  &pb.RequestPasswordResetRequest{UsernameOrEmail: "nobody"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "nobody").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+			},
+		},
+		{
+			name: "NotificationFails",
+			req:  &pb.RequestPasswordResetRequest{UsernameOrEmail: "maria"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return(&models.User{ID: 3, Username: "maria"}, nil).Once()
+				tokens.On("CreateResetToken", mock.Anything).Return(nil).Once()
+				notif.On("SendPasswordReset", mock.Anything).Return((*pb.SendPasswordResetResponse)(nil), errors.New("unavailable")).Once()
+			},
+			sends: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			mockNotif := newMockNotificationServiceClient()
+			tt.mockSetup(mockRepo, mockTokens, mockNotif)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, mockNotif)
+
+			// La respuesta no delata si la cuenta existe
+			resp, err := srv.RequestPasswordReset(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, resp)
+			if tt.sends {
+				mockNotif.waitSent(t)
+			}
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+			mockNotif.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	valid := &models.PasswordResetToken{ID: 6, UserID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name         string
+		req          *pb.ResetPasswordRequest
+		mockSetup    func(*MockUserRepository, *MockTokenRepository)
+		expectedResp *pb.ResetPasswordResponse
+	}{
+		{
+			name: "Success",
+			req:  &pb.ResetPasswordRequest{Token: "reset", NewPassword: "newpass"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindResetToken", sha256Hex("reset")).Return(valid, nil).Once()
+				tokens.On("UseResetToken", uint(6)).Return(nil).Once()
+				repo.On("UpdatePassword", uint(3), "newpass").Return(nil).Once()
+				tokens.On("RevokeUserTokens", uint(3)).Return(nil).Once()
+			},
+			expectedResp: &pb.ResetPasswordResponse{Message: "Password updated", Success: true},
+		},
+		{
+			name:         "EmptyPassword",
+			req:          &pb.ResetPasswordRequest{Token: "reset"},
+			mockSetup:    func(repo *MockUserRepository, tokens *MockTokenRepository) {},
+			expectedResp: &pb.ResetPasswordResponse{Message: "Password is required", Success: false},
+		},
+		{
+			name: "UnknownToken",
+			req:  &pb.ResetPasswordRequest{Token: "other", NewPassword: "newpass"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindResetToken", sha256Hex("other")).Return((*models.PasswordResetToken)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.ResetPasswordResponse{Message: "Invalid reset token", Success: false},
+		},
+		{
+			name: "Expired",
+			req:  &pb.ResetPasswordRequest{Token: "reset", NewPassword: "newpass"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindResetToken", sha256Hex("reset")).
+					Return(&models.PasswordResetToken{ID: 6, UserID: 3, ExpiresAt: time.Now().Add(-time.Minute)}, nil).Once()
+			},
+			expectedResp: &pb.ResetPasswordResponse{Message: "Invalid reset token", Success: false},
+		},
+		{
+			name: "AlreadyUsed",
+			req:  &pb.ResetPasswordRequest{Token: "reset", NewPassword: "newpass"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindResetToken", sha256Hex("reset")).
+					Return(&models.PasswordResetToken{ID: 6, UserID: 3, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil).Once()
+			},
+			expectedResp: &pb.ResetPasswordResponse{Message: "Invalid reset token", Success: false},
+		},
+		{
+			name: "UsedConcurrently",
+			req:  &pb.ResetPasswordRequest{Token: "reset", NewPassword: "newpass"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindResetToken", sha256Hex("reset")).Return(valid, nil).Once()
+				tokens.On("UseResetToken", uint(6)).Return(repositories.ErrResetTokenUsed).Once()
+			},
+			expectedResp: &pb.ResetPasswordResponse{Message: "Invalid reset token", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			tt.mockSetup(mockRepo, mockTokens)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.ResetPassword(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	caller := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 3, Roles: []string{rbac.RoleClient}})
+	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("oldpass"), bcrypt.DefaultCost)
+	user := &models.User{ID: 3, Username: "testuser", Password: string(hashedPass), Roles: "client"}
+	lockedUntil := time.Now().Add(10 * time.Minute)
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		req          *pb.ChangePasswordRequest
+		mockSetup    func(*MockUserRepository, *MockTokenRepository, *MockLoginRepository)
+		expectedResp *pb.ChangePasswordResponse
+	}{
+		{
+			name: "Success",
+			ctx:  caller,
+			req:  &pb.ChangePasswordRequest{OldPassword: "oldpass", NewPassword: "newpass"},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository, logins *MockLoginRepository) {
+				users.On("FindByID", uint(3)).Return(user, nil).Once()
+				logins.On("FindAttempts", []string{"user:testuser"}).Return([]models.LoginAttempt{}, nil).Once()
+				users.On("UpdatePassword", uint(3), "newpass").Return(nil).Once()
+				tokens.On("RevokeUserTokens", uint(3)).Return(nil).Once()
+			},
+			expectedResp: &pb.ChangePasswordResponse{Message: "Password updated, log in again", Success: true},
+		},
+		{
+			name: "WrongPassword",
+			ctx:  caller,
+			req:  &pb.ChangePasswordRequest{OldPassword: "wrongpass", NewPassword: "newpass"},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository, logins *MockLoginRepository) {
+				users.On("FindByID", uint(3)).Return(user, nil).Once()
+				logins.On("FindAttempts", []string{"user:testuser"}).Return([]models.LoginAttempt{}, nil).Once()
+				logins.On("RecordFailure", "user:testuser", mock.Anything, 15*time.Minute).
+					Return(&models.LoginAttempt{Key: "user:testuser", Failures: 1}, nil).Once()
+			},
+			expectedResp: &pb.ChangePasswordResponse{Message: "Incorrect password", Success: false},
+		},
+		{
+			name: "LockedOut",
+			ctx:  caller,
+			req:  &pb.ChangePasswordRequest{OldPassword: "oldpass", NewPassword: "newpass"},
+			mockSetup: func(users *MockUserRepository, tokens *MockTokenRepository, logins *MockLoginRepository) {
+				users.On("FindByID", uint(3)).Return(user, nil).Once()
+				logins.On("FindAttempts", []string{"user:testuser"}).
+					Return([]models.LoginAttempt{{Key: "user:testuser", LockedUntil: &lockedUntil}}, nil).Once()
+			},
+			expectedResp: &pb.ChangePasswordResponse{Message: "Too many failed attempts, try again later", Success: false},
+		},
+		{
+			name:         "EmptyPassword",
+			ctx:          caller,
+			req:          &pb.ChangePasswordRequest{OldPassword: "oldpass"},
+			mockSetup:    func(users *MockUserRepository, tokens *MockTokenRepository, logins *MockLoginRepository) {},
+			expectedResp: &pb.ChangePasswordResponse{Message: "Password is required", Success: false},
+		},
+		{
+			name:         "NoCaller",
+			ctx:          context.Background(),
+			req:          &pb.ChangePasswordRequest{OldPassword: "oldpass", NewPassword: "newpass"},
+			mockSetup:    func(users *MockUserRepository, tokens *MockTokenRepository, logins *MockLoginRepository) {},
+			expectedResp: &pb.ChangePasswordResponse{Message: "Login required", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			mockLogins := new(MockLoginRepository)
+			tt.mockSetup(mockRepo, mockTokens, mockLogins)
+			srv := services.NewAuthService(mockRepo, mockTokens, mockLogins, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.ChangePassword(tt.ctx, tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+			mockLogins.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateUserWithEmail(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          *pb.CreateUserRequest
+		mockSetup    func(*MockUserRepository, *MockTokenRepository, *MockNotificationServiceClient)
+		expectedResp *pb.CreateUserResponse
+		sends        bool
+	}{
+		{
+			name: "PendingVerification",
+			req:  &pb.CreateUserRequest{Username: "maria", Password: "testpass", Email: "maria@email.com"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				var hash string
+				repo.On("FindByUsername", "maria").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+				repo.On("FindByEmail", "maria@email.com").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+				repo.On("CreateUser", mock.MatchedBy(func(user *models.User) bool {
+					return user.Email != nil && *user.Email == "maria@email.com" && user.EmailVerifiedAt == nil
+				})).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.User).ID = 3
+				}).Return(nil).Once()
+				tokens.On("CreateVerificationToken", mock.MatchedBy(func(token *models.EmailVerificationToken) bool {
+					hash = token.TokenHash
+					return token.UserID == 3 && time.Until(token.ExpiresAt) > 23*time.Hour
+				})).Return(nil).Once()
+				notif.On("SendEmailVerification", mock.MatchedBy(func(req *pb.SendEmailVerificationRequest) bool {
+					token, ok := strings.CutPrefix(req.VerifyUrl, "http://localhost:3000/verify-email?token=")
+					return ok && sha256Hex(token) == hash && req.Email == "maria@email.com"
+				})).Return(&pb.SendEmailVerificationResponse{Success: true}, nil).Once()
+			},
+			expectedResp: &pb.CreateUserResponse{Message: "User created, verify the email to activate it", Success: true},
+			sends:        true,
+		},
+		{
+			name: "InvalidEmail",
+			req:  &pb.CreateUserRequest{Username: "maria", Password: "testpass", Email: "Maria <maria@email.com>"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.CreateUserResponse{Message: "Invalid email", Success: false},
+		},
+		{
+			name: "EmailTaken",
+			req:  &pb.CreateUserRequest{Username: "maria", Password: "testpass", Email: "maria@email.com"},
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+				repo.On("FindByEmail", "maria@email.com").Return(&models.User{ID: 5}, nil).Once()
+			},
+			expectedResp: &pb.CreateUserResponse{Message: "Email is not available", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			mockNotif := newMockNotificationServiceClient()
+			tt.mockSetup(mockRepo, mockTokens, mockNotif)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, mockNotif)
+
+			resp, err := srv.CreateUser(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			if tt.sends {
+				mockNotif.waitSent(t)
+			}
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+			mockNotif.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoginPendingVerification(t *testing.T) {
+	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.DefaultCost)
+	email := "maria@email.com"
+	user := &models.User{ID: 3, Username: "maria", Password: string(hashedPass), Roles: "client", Email: &email}
+
+	// Por defecto la cuenta pendiente no entra
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("FindByUsername", "maria").Return(user, nil).Once()
+	srv := services.NewAuthService(mockRepo, new(MockTokenRepository), newCleanLoginRepository("maria"), nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+	resp, err := srv.Login(&pb.LoginRequest{Username: "maria", Password: "testpass"})
+	assert.Equal(t, errors.New("email_not_verified"), err)
+	assert.False(t, resp.Success)
+	mockRepo.AssertExpectations(t)
+
+	// Con "limited" entra con un token sin roles
+	policy := testTokenPolicy
+	policy.UnverifiedLogin = services.UnverifiedLoginLimited
+	mockRepo = new(MockUserRepository)
+	mockTokens := new(MockTokenRepository)
+	mockRepo.On("FindByUsername", "maria").Return(user, nil).Once()
+	mockTokens.On("CreateRefreshToken", mock.AnythingOfType("*models.RefreshToken")).Return(nil).Once()
+	srv = services.NewAuthService(mockRepo, mockTokens, newCleanLoginRepository("maria"), newUnenrolledMFARepository(), testKeys, testVerifier, policy, testLoginPolicy, nil)
+	resp, err = srv.Login(&pb.LoginRequest{Username: "maria", Password: "testpass"})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.True(t, resp.PendingVerification)
+	claims, err := testVerifier.ParseToken(resp.Token)
+	assert.NoError(t, err)
+	assert.Empty(t, claims.Roles)
+	mockRepo.AssertExpectations(t)
+	mockTokens.AssertExpectations(t)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	valid := &models.EmailVerificationToken{ID: 8, UserID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name         string
+		mockSetup    func(*MockUserRepository, *MockTokenRepository)
+		expectedResp *pb.VerifyEmailResponse
+	}{
+		{
+			name: "Success",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindVerificationToken", sha256Hex("verify")).Return(valid, nil).Once()
+				tokens.On("UseVerificationToken", uint(8)).Return(nil).Once()
+				repo.On("MarkEmailVerified", uint(3)).Return(nil).Once()
+			},
+			expectedResp: &pb.VerifyEmailResponse{Message: "Email verified", Success: true},
+		},
+		{
+			name: "UnknownToken",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindVerificationToken", sha256Hex("verify")).Return((*models.EmailVerificationToken)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.VerifyEmailResponse{Message: "Invalid verification token", Success: false},
+		},
+		{
+			name: "Expired",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindVerificationToken", sha256Hex("verify")).
+					Return(&models.EmailVerificationToken{ID: 8, UserID: 3, ExpiresAt: time.Now().Add(-time.Minute)}, nil).Once()
+			},
+			expectedResp: &pb.VerifyEmailResponse{Message: "Invalid verification token", Success: false},
+		},
+		{
+			name: "AlreadyUsed",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindVerificationToken", sha256Hex("verify")).
+					Return(&models.EmailVerificationToken{ID: 8, UserID: 3, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil).Once()
+			},
+			expectedResp: &pb.VerifyEmailResponse{Message: "Invalid verification token", Success: false},
+		},
+		{
+			name: "UsedConcurrently",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository) {
+				tokens.On("FindVerificationToken", sha256Hex("verify")).Return(valid, nil).Once()
+				tokens.On("UseVerificationToken", uint(8)).Return(repositories.ErrVerifyTokenUsed).Once()
+			},
+			expectedResp: &pb.VerifyEmailResponse{Message: "Invalid verification token", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			tt.mockSetup(mockRepo, mockTokens)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.VerifyEmail(&pb.VerifyEmailRequest{Token: "verify"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	email := "maria@email.com"
+	verifiedAt := time.Now().Add(-time.Hour)
+	pending := &models.User{ID: 3, Username: "maria", Email: &email}
+	expected := &pb.ResendVerificationResponse{Message: "If the account is pending verification, a new link was sent", Success: true}
+
+	tests := []struct {
+		name      string
+		mockSetup func(*MockUserRepository, *MockTokenRepository, *MockNotificationServiceClient)
+		sends     bool
+	}{
+		{
+			name: "Sends",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return(pending, nil).Once()
+				tokens.On("LastVerificationSentAt", uint(3)).Return(time.Now().Add(-2*time.Minute), nil).Once()
+				tokens.On("CreateVerificationToken", mock.AnythingOfType("*models.EmailVerificationToken")).Return(nil).Once()
+				notif.On("SendEmailVerification", mock.Anything).Return(&pb.SendEmailVerificationResponse{Success: true}, nil).Once()
+			},
+			sends: true,
+		},
+		{
+			name: "RateLimited",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return(pending, nil).Once()
+				tokens.On("LastVerificationSentAt", uint(3)).Return(time.Now().Add(-10*time.Second), nil).Once()
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return(&models.User{ID: 3, Email: &email, EmailVerifiedAt: &verifiedAt}, nil).Once()
+			},
+		},
+		{
+			name: "UnknownAccount",
+			mockSetup: func(repo *MockUserRepository, tokens *MockTokenRepository, notif *MockNotificationServiceClient) {
+				repo.On("FindByUsername", "maria").Return((*models.User)(nil), gorm.ErrRecordNotFound).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			mockNotif := newMockNotificationServiceClient()
+			tt.mockSetup(mockRepo, mockTokens, mockNotif)
+			srv := services.NewAuthService(mockRepo, mockTokens, nil, nil, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, mockNotif)
+
+			resp, err := srv.ResendVerification(&pb.ResendVerificationRequest{UsernameOrEmail: "maria"})
+			assert.NoError(t, err)
+			assert.Equal(t, expected, resp)
+			if tt.sends {
+				mockNotif.waitSent(t)
+			}
+			mockRepo.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+			mockNotif.AssertExpectations(t)
+		})
+	}
+}
+
+// testTOTPSecret is the key of the RFC 6238 test vectors, "12345678901234567890".
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// Los vectores de RFC 6238 para SHA1, con los últimos 6 dígitos
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		code, err := services.TOTPCode(testTOTPSecret, time.Unix(tt.unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.code, code)
+	}
+}
+
+func TestLoginMFA(t *testing.T) {
+	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.DefaultCost)
+	activatedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name               string
+		user               *models.User
+		enrollment         *models.TOTPEnrollment
+		enrollmentRequired bool
+	}{
+		{
+			name:       "Enrolled",
+			user:       &models.User{ID: 1, Username: "testuser", Password: string(hashedPass), Roles: "client"},
+			enrollment: &models.TOTPEnrollment{UserID: 1, Secret: testTOTPSecret, ActivatedAt: &activatedAt},
+		},
+		{
+			name:               "RequiredForRole",
+			user:               &models.User{ID: 1, Username: "testuser", Password: string(hashedPass), Roles: "staff,admin"},
+			enrollmentRequired: true,
+		},
+		{
+			name:               "RequiredNotActivated",
+			user:               &models.User{ID: 1, Username: "testuser", Password: string(hashedPass), Roles: "admin"},
+			enrollment:         &models.TOTPEnrollment{UserID: 1, Secret: testTOTPSecret},
+			enrollmentRequired: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockMFA := new(MockMFARepository)
+			mockRepo.On("FindByUsername", "testuser").Return(tt.user, nil).Once()
+			if tt.enrollment != nil {
+				mockMFA.On("FindEnrollment", uint(1)).Return(tt.enrollment, nil).Once()
+			} else {
+				mockMFA.On("FindEnrollment", uint(1)).Return((*models.TOTPEnrollment)(nil), gorm.ErrRecordNotFound).Once()
+			}
+			var hash string
+			mockMFA.On("CreateChallenge", mock.MatchedBy(func(challenge *models.MFAChallenge) bool {
+				hash = challenge.TokenHash
+				return challenge.UserID == 1 && time.Until(challenge.ExpiresAt) > 4*time.Minute
+			})).Return(nil).Once()
+			mockLogins := newCleanLoginRepository("testuser")
+			srv := services.NewAuthService(mockRepo, new(MockTokenRepository), mockLogins, mockMFA,
+				testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.Login(&pb.LoginRequest{Username: "testuser", Password: "testpass"})
+			assert.NoError(t, err)
+			assert.False(t, resp.Success)
+			assert.Empty(t, resp.Token)
+			assert.Equal(t, !tt.enrollmentRequired, resp.MfaRequired)
+			assert.Equal(t, tt.enrollmentRequired, resp.MfaEnrollmentRequired)
+			assert.Equal(t, hash, sha256Hex(resp.MfaToken))
+			if !tt.enrollmentRequired {
+				// Las fallas se olvidan recién con el código
+				mockLogins.AssertNotCalled(t, "ClearAttempts", mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+			mockMFA.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyMFA(t *testing.T) {
+	activatedAt := time.Now().Add(-time.Hour)
+	used := time.Now().Add(-time.Minute)
+	enrollment := &models.TOTPEnrollment{UserID: 1, Secret: testTOTPSecret, ActivatedAt: &activatedAt}
+	challenge := &models.MFAChallenge{ID: 6, UserID: 1, TokenHash: sha256Hex("mfa"), ExpiresAt: time.Now().Add(time.Minute)}
+	code, _ := services.TOTPCode(testTOTPSecret, time.Now())
+	user := &models.User{ID: 1, Username: "testuser", Roles: "admin"}
+	keys := []string{"user:testuser", "ip:10.0.0.1"}
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	clean := func(logins *MockLoginRepository) {
+		logins.On("FindAttempts", keys).Return([]models.LoginAttempt{}, nil).Once()
+	}
+	failed := func(logins *MockLoginRepository) {
+		clean(logins)
+		for _, key := range keys {
+			logins.On("RecordFailure", key, mock.Anything, 15*time.Minute).
+				Return(&models.LoginAttempt{Key: key, Failures: 1}, nil).Once()
+		}
+	}
+
+	tests := []struct {
+		name        string
+		code        string
+		mockSetup   func(*MockUserRepository, *MockMFARepository, *MockLoginRepository)
+		retryAfter  bool
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				clean(logins)
+				mfa.On("FindEnrollment", uint(1)).Return(enrollment, nil).Once()
+				mfa.On("UseTOTPStep", uint(1), mock.AnythingOfType("int64")).Return(nil).Once()
+				mfa.On("UseChallenge", uint(6)).Return(nil).Once()
+				logins.On("ClearAttempts", []string{"user:testuser"}).Return(int64(1), nil).Once()
+			},
+		},
+		{
+			name: "RecoveryCode",
+			code: "ABCD-efgh-ijkl-mnop",
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				clean(logins)
+				mfa.On("FindEnrollment", uint(1)).Return(enrollment, nil).Once()
+				mfa.On("UseRecoveryCode", uint(1), sha256Hex("abcdefghijklmnop")).Return(nil).Once()
+				mfa.On("UseChallenge", uint(6)).Return(nil).Once()
+				logins.On("ClearAttempts", []string{"user:testuser"}).Return(int64(1), nil).Once()
+			},
+		},
+		{
+			name: "CodeReused",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				failed(logins)
+				mfa.On("FindEnrollment", uint(1)).Return(enrollment, nil).Once()
+				mfa.On("UseTOTPStep", uint(1), mock.AnythingOfType("int64")).Return(repositories.ErrTOTPStepUsed).Once()
+				mfa.On("FailChallenge", uint(6)).Return(nil).Once()
+			},
+			expectedErr: errors.New("invalid_credentials"),
+		},
+		{
+			name: "WrongCode",
+			code: "000000",
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				failed(logins)
+				mfa.On("FindEnrollment", uint(1)).Return(enrollment, nil).Once()
+				mfa.On("FailChallenge", uint(6)).Return(nil).Once()
+			},
+			expectedErr: errors.New("invalid_credentials"),
+		},
+		{
+			name: "UsedRecoveryCode",
+			code: "abcd-efgh-ijkl-mnop",
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				failed(logins)
+				mfa.On("FindEnrollment", uint(1)).Return(enrollment, nil).Once()
+				mfa.On("UseRecoveryCode", uint(1), sha256Hex("abcdefghijklmnop")).Return(repositories.ErrRecoveryCodeInvalid).Once()
+				mfa.On("FailChallenge", uint(6)).Return(nil).Once()
+			},
+			expectedErr: errors.New("invalid_credentials"),
+		},
+		{
+			name: "LockedOut",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				logins.On("FindAttempts", keys).
+					Return([]models.LoginAttempt{{Key: "user:testuser", Failures: 5, LockedUntil: &lockedUntil}}, nil).Once()
+			},
+			retryAfter: true,
+		},
+		{
+			name: "UserDisabled",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Username: "testuser", DisabledAt: &used}, nil).Once()
+			},
+			expectedErr: errors.New("invalid_mfa_token"),
+		},
+		{
+			name: "TooManyFailures",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).
+					Return(&models.MFAChallenge{ID: 6, UserID: 1, ExpiresAt: time.Now().Add(time.Minute), Failures: 5}, nil).Once()
+			},
+			expectedErr: errors.New("invalid_mfa_token"),
+		},
+		{
+			name: "ChallengeUsed",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).
+					Return(&models.MFAChallenge{ID: 6, UserID: 1, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &used}, nil).Once()
+			},
+			expectedErr: errors.New("invalid_mfa_token"),
+		},
+		{
+			name: "ChallengeExpired",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).
+					Return(&models.MFAChallenge{ID: 6, UserID: 1, ExpiresAt: time.Now().Add(-time.Second)}, nil).Once()
+			},
+			expectedErr: errors.New("invalid_mfa_token"),
+		},
+		{
+			name: "NotActivated",
+			code: code,
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository, logins *MockLoginRepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(challenge, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				clean(logins)
+				mfa.On("FindEnrollment", uint(1)).Return(&models.TOTPEnrollment{UserID: 1, Secret: testTOTPSecret}, nil).Once()
+			},
+			expectedErr: errors.New("invalid_mfa_token"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockTokens := new(MockTokenRepository)
+			mockMFA := new(MockMFARepository)
+			mockLogins := new(MockLoginRepository)
+			tt.mockSetup(mockRepo, mockMFA, mockLogins)
+			mockTokens.On("CreateRefreshToken", mock.AnythingOfType("*models.RefreshToken")).Return(nil).Maybe()
+			srv := services.NewAuthService(mockRepo, mockTokens, mockLogins, mockMFA, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.VerifyMFA(&pb.VerifyMFARequest{MfaToken: "mfa", Code: tt.code, ClientIp: "10.0.0.1"})
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedErr == nil && !tt.retryAfter, resp.Success)
+			assert.Equal(t, tt.retryAfter, resp.RetryAfter > 0)
+			if tt.expectedErr == nil && !tt.retryAfter {
+				claims, err := testVerifier.ParseToken(resp.Token)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"admin"}, claims.Roles)
+				assert.NotEmpty(t, resp.RefreshToken)
+			}
+			mockRepo.AssertExpectations(t)
+			mockMFA.AssertExpectations(t)
+			mockLogins.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	caller := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 1, Roles: []string{rbac.RoleClient}})
+	activatedAt := time.Now().Add(-time.Hour)
+	user := &models.User{ID: 1, Username: "testuser", Roles: "client"}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		req          *pb.EnrollTOTPRequest
+		mockSetup    func(*MockUserRepository, *MockMFARepository)
+		expectedResp *pb.EnrollTOTPResponse
+	}{
+		{
+			name: "Caller",
+			ctx:  caller,
+			req:  &pb.EnrollTOTPRequest{},
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository) {
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				mfa.On("FindEnrollment", uint(1)).Return((*models.TOTPEnrollment)(nil), gorm.ErrRecordNotFound).Once()
+				mfa.On("SaveEnrollment", mock.MatchedBy(func(enrollment *models.TOTPEnrollment) bool {
+					return enrollment.UserID == 1 && len(enrollment.Secret) == 32 && enrollment.ActivatedAt == nil
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.EnrollTOTPResponse{Message: "Add it to the authenticator app and activate it with a code", Success: true},
+		},
+		{
+			name: "LoggingIn",
+			ctx:  context.Background(),
+			req:  &pb.EnrollTOTPRequest{MfaToken: "mfa"},
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository) {
+				mfa.On("FindChallenge", sha256Hex("mfa")).Return(&models.MFAChallenge{ID: 6, UserID: 1, ExpiresAt: time.Now().Add(time.Minute)}, nil).Once()
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				mfa.On("FindEnrollment", uint(1)).Return(&models.TOTPEnrollment{UserID: 1, Secret: testTOTPSecret}, nil).Once()
+				mfa.On("SaveEnrollment", mock.AnythingOfType("*models.TOTPEnrollment")).Return(nil).Once()
+			},
+			expectedResp: &pb.EnrollTOTPResponse{Message: "Add it to the authenticator app and activate it with a code", Success: true},
+		},
+		{
+			name: "AlreadyActive",
+			ctx:  caller,
+			req:  &pb.EnrollTOTPRequest{},
+			mockSetup: func(repo *MockUserRepository, mfa *MockMFARepository) {
+				repo.On("FindByID", uint(1)).Return(user, nil).Once()
+				mfa.On("FindEnrollment", uint(1)).Return(&models.TOTPEnrollment{UserID: 1, ActivatedAt: &activatedAt}, nil).Once()
+			},
+			expectedResp: &pb.EnrollTOTPResponse{Message: "TOTP is already active", Success: false},
+		},
+		{
+			name:         "Anonymous",
+			ctx:          context.Background(),
+			req:          &pb.EnrollTOTPRequest{},
+			mockSetup:    func(repo *MockUserRepository, mfa *MockMFARepository) {},
+			expectedResp: &pb.EnrollTOTPResponse{Message: "Login required", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockMFA := new(MockMFARepository)
+			tt.mockSetup(mockRepo, mockMFA)
+			srv := services.NewAuthService(mockRepo, new(MockTokenRepository), nil, mockMFA, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.EnrollTOTP(tt.ctx, tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp.Message, resp.Message)
+			assert.Equal(t, tt.expectedResp.Success, resp.Success)
+			if tt.expectedResp.Success {
+				assert.Equal(t, "otpauth://totp/Appointments:testuser?algorithm=SHA1&digits=6&issuer=Appointments&period=30&secret="+resp.Secret,
+					resp.ProvisioningUri)
+			}
+			mockRepo.AssertExpectations(t)
+			mockMFA.AssertExpectations(t)
+		})
+	}
+}
+
+func TestActivateTOTP(t *testing.T) {
+	caller := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 1, Roles: []string{rbac.RoleAdmin}})
+	user := &models.User{ID: 1, Username: "testuser", Roles: "admin"}
+	pending := &models.TOTPEnrollment{UserID: 1, Secret: testTOTPSecret}
+	code, _ := services.TOTPCode(testTOTPSecret, time.Now())
+
+	tests := []struct {
+		name         string
+		code         string
+		mockSetup    func(*MockMFARepository)
+		expectedResp *pb.ActivateTOTPResponse
+	}{
+		{
+			name: "Success",
+			code: code,
+			mockSetup: func(mfa *MockMFARepository) {
+				mfa.On("FindEnrollment", uint(1)).Return(pending, nil).Once()
+				mfa.On("ActivateEnrollment", uint(1), mock.MatchedBy(func(hashes []string) bool {
+					return len(hashes) == 10
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.ActivateTOTPResponse{Message: "TOTP activated", Success: true},
+		},
+		{
+			name: "InvalidCode",
+			code: "000000",
+			mockSetup: func(mfa *MockMFARepository) {
+				mfa.On("FindEnrollment", uint(1)).Return(pending, nil).Once()
+			},
+			expectedResp: &pb.ActivateTOTPResponse{Message: "Invalid code", Success: false},
+		},
+		{
+			name: "NotEnrolled",
+			code: code,
+			mockSetup: func(mfa *MockMFARepository) {
+				mfa.On("FindEnrollment", uint(1)).Return((*models.TOTPEnrollment)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.ActivateTOTPResponse{Message: "Enroll TOTP first", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockMFA := new(MockMFARepository)
+			mockRepo.On("FindByID", uint(1)).Return(user, nil).Once()
+			tt.mockSetup(mockMFA)
+			srv := services.NewAuthService(mockRepo, new(MockTokenRepository), nil, mockMFA, testKeys, testVerifier, testTokenPolicy, testLoginPolicy, nil)
+
+			resp, err := srv.ActivateTOTP(caller, &pb.ActivateTOTPRequest{Code: tt.code})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResp.Message, resp.Message)
+			assert.Equal(t, tt.expectedResp.Success, resp.Success)
+			if tt.expectedResp.Success {
+				assert.Len(t, resp.RecoveryCodes, 10)
+				assert.Regexp(t, `^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`, resp.RecoveryCodes[0])
+			}
 			mockRepo.AssertExpectations(t)
+			mockMFA.AssertExpectations(t)
 		})
 	}
 }
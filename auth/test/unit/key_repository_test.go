@@ -0,0 +1,33 @@
+package unit
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestListKeysRepo(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer sqlDB.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewKeyRepository(gormDB)
+
+	activatesAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "signing_keys" ORDER BY activates_at`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kid", "seed", "activates_at"}).
+			AddRow(1, "a", []byte("seed"), activatesAt))
+
+	keys, err := repo.ListKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SigningKey{{ID: 1, Kid: "a", Seed: []byte("seed"), ActivatesAt: activatesAt}}, keys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
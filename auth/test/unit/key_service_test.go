@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRotateKeys(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	seed := make([]byte, 32)
+	activatesAt := func(at time.Time) interface{} {
+		return mock.MatchedBy(func(key *models.SigningKey) bool {
+			return key.Kid != "" && len(key.Seed) == 32 && key.ActivatesAt.Equal(at)
+		})
+	}
+
+	tests := []struct {
+		name         string
+		mockSetup    func(*MockKeyRepository)
+		expectedKids []string
+	}{
+		{
+			name: "FirstKeySignsNow",
+			mockSetup: func(repo *MockKeyRepository) {
+				repo.On("ListKeys").Return([]models.SigningKey{}, nil).Once()
+				repo.On("CreateKey", activatesAt(now)).Return(nil).Once()
+			},
+		},
+		{
+			name: "NotDue",
+			mockSetup: func(repo *MockKeyRepository) {
+				repo.On("ListKeys").Return([]models.SigningKey{
+					{ID: 1, Kid: "a", Seed: seed, ActivatesAt: now.Add(-100 * time.Hour)},
+				}, nil).Once()
+			},
+			expectedKids: []string{"a"},
+		},
+		{
+			name: "PublishesNextAhead",
+			mockSetup: func(repo *MockKeyRepository) {
+				repo.On("ListKeys").Return([]models.SigningKey{
+					{ID: 1, Kid: "a", Seed: seed, ActivatesAt: now.Add(-719 * time.Hour)},
+				}, nil).Once()
+				repo.On("CreateKey", activatesAt(now.Add(time.Hour))).Return(nil).Once()
+			},
+		},
+		{
+			name: "OverdueSignsAfterPublishing",
+			mockSetup: func(repo *MockKeyRepository) {
+				repo.On("ListKeys").Return([]models.SigningKey{
+					{ID: 1, Kid: "a", Seed: seed, ActivatesAt: now.Add(-1000 * time.Hour)},
+				}, nil).Once()
+				repo.On("CreateKey", activatesAt(now.Add(time.Hour))).Return(nil).Once()
+			},
+		},
+		{
+			name: "DeletesReplacedKeys",
+			mockSetup: func(repo *MockKeyRepository) {
+				repo.On("ListKeys").Return([]models.SigningKey{
+					{ID: 1, Kid: "a", Seed: seed, ActivatesAt: now.Add(-800 * time.Hour)},
+					// Los tokens firmados con b aún pueden estar vigentes
+					{ID: 2, Kid: "b", Seed: seed, ActivatesAt: now.Add(-80 * time.Hour)},
+					{ID: 3, Kid: "c", Seed: seed, ActivatesAt: now.Add(-10 * time.Minute)},
+				}, nil).Once()
+				repo.On("DeleteKey", uint(1)).Return(nil).Once()
+			},
+			expectedKids: []string{"b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockKeyRepository)
+			tt.mockSetup(mockRepo)
+			srv := services.NewKeyService(mockRepo, testKeyPolicy)
+
+			assert.NoError(t, srv.RotateKeys(now))
+			if tt.expectedKids != nil {
+				resp, err := srv.GetJWKS(&pb.GetJWKSRequest{})
+				assert.NoError(t, err)
+				kids := []string{}
+				for _, key := range resp.Keys {
+					kids = append(kids, key.Kid)
+				}
+				assert.Equal(t, tt.expectedKids, kids)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSignWithActiveKey(t *testing.T) {
+	mockRepo := new(MockKeyRepository)
+	srv := services.NewKeyService(mockRepo, testKeyPolicy)
+
+	// La llave siguiente ya se publica pero aún no firma
+	now := time.Now()
+	mockRepo.On("ListKeys").Return([]models.SigningKey{
+		{ID: 1, Kid: "current", Seed: make([]byte, 32), ActivatesAt: now.Add(-time.Hour)},
+		{ID: 2, Kid: "next", Seed: []byte("0123456789abcdef0123456789abcdef"), ActivatesAt: now.Add(time.Hour)},
+	}, nil).Once()
+	assert.NoError(t, srv.RotateKeys(now))
+
+	token, err := srv.Sign(rbac.Claims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}})
+	assert.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &rbac.Claims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "current", parsed.Header["kid"])
+
+	verifier := rbac.NewKeySet("test-secret-key", srv.JWKS)
+	claims, err := verifier.ParseToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupLoginMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.LoginRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewLoginRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestRecordFailureRepo(t *testing.T) {
+	sqlDB, mock, repo := setupLoginMockDB(t)
+	defer sqlDB.Close()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "login_attempts" ("key","failures","last_failure_at","locked_until") VALUES ($1,$2,$3,$4) `+
+		`ON CONFLICT ("key") DO UPDATE SET "failures"=CASE WHEN login_attempts.last_failure_at < $5 THEN 1 ELSE login_attempts.failures + 1 END,"last_failure_at"=$6 RETURNING *`)).
+		WithArgs("user:maria", 1, now, nil, now.Add(-15*time.Minute), now).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "failures", "last_failure_at", "locked_until"}).AddRow("user:maria", 4, now, nil))
+	mock.ExpectCommit()
+
+	attempt, err := repo.RecordFailure("user:maria", now, 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, attempt.Failures)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLockRepo(t *testing.T) {
+	sqlDB, mock, repo := setupLoginMockDB(t)
+	defer sqlDB.Close()
+	until := time.Date(2025, 3, 10, 12, 15, 0, 0, time.UTC)
+	lock := regexp.QuoteMeta(`UPDATE "login_attempts" SET "failures"=$1,"locked_until"=$2 WHERE key = $3 AND failures >= $4`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(lock).WithArgs(0, until, "user:maria", 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	locked, err := repo.Lock("user:maria", until, 10)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	// Otra instancia la bloqueó primero
+	mock.ExpectBegin()
+	mock.ExpectExec(lock).WithArgs(0, until, "user:maria", 10).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	locked, err = repo.Lock("user:maria", until, 10)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClearAttemptsRepo(t *testing.T) {
+	sqlDB, mock, repo := setupLoginMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_attempts" WHERE key IN ($1,$2)`)).
+		WithArgs("user:maria", "ip:10.0.0.1").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	cleared, err := repo.ClearAttempts([]string{"user:maria", "ip:10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cleared)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
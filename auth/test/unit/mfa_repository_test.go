@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupMFAMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.MFARepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewMFARepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestUseTOTPStepRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMFAMockDB(t)
+	defer sqlDB.Close()
+	use := regexp.QuoteMeta(`UPDATE "totp_enrollments" SET "last_step"=$1 WHERE user_id = $2 AND last_step < $3`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(use).WithArgs(int64(100), uint(1), int64(100)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.UseTOTPStep(1, 100))
+
+	// El código de ese periodo ya se usó
+	mock.ExpectBegin()
+	mock.ExpectExec(use).WithArgs(int64(100), uint(1), int64(100)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.Equal(t, repositories.ErrTOTPStepUsed, repo.UseTOTPStep(1, 100))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestActivateEnrollmentRepo(t *testing.T) {
+	activate := regexp.QuoteMeta(`UPDATE "totp_enrollments" SET "activated_at"=$1 WHERE user_id = $2 AND activated_at IS NULL`)
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(activate).WithArgs(sqlmock.AnyArg(), uint(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "recovery_codes" WHERE user_id = $1`)).
+					WithArgs(uint(1)).WillReturnResult(sqlmock.NewResult(0, 10))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "recovery_codes" ("user_id","code_hash","used_at","created_at") VALUES ($1,$2,$3,$4),($5,$6,$7,$8) RETURNING "id"`)).
+					WithArgs(uint(1), "a", nil, sqlmock.AnyArg(), uint(1), "b", nil, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "AlreadyActive",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(activate).WithArgs(sqlmock.AnyArg(), uint(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, repo := setupMFAMockDB(t)
+			defer sqlDB.Close()
+			tt.mockSetup(mock)
+
+			err := repo.ActivateEnrollment(1, []string{"a", "b"})
+			assert.Equal(t, tt.expectedErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
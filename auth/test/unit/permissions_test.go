@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/handlers"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/services"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func signTestToken(t *testing.T, keys services.KeyService, roles ...string) string {
+	token, err := keys.Sign(rbac.Claims{
+		UserID: 1,
+		Roles:  roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	assert.NoError(t, err)
+	return token
+}
+
+func TestPermissionsInterceptor(t *testing.T) {
+	interceptor := rbac.UnaryServerInterceptor(rbac.NewLocalValidator(testVerifier), handlers.Permissions)
+	serviceToken, err := rbac.NewServiceCredentials("test-secret-key", "gateway").Token()
+	assert.NoError(t, err)
+	// El secreto compartido solo firma tokens de servicio
+	userTokenWithSecret, err := jwt.NewWithClaims(jwt.SigningMethodHS256, rbac.Claims{
+		UserID: 1,
+		Roles:  []string{"admin"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte("test-secret-key"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		method       string
+		token        string
+		expectedCode codes.Code
+	}{
+		{name: "PublicWithoutToken", method: "/pb.AuthService/Login", expectedCode: codes.OK},
+		{name: "AdminOnlyWithoutToken", method: "/pb.AuthService/SetRoles", expectedCode: codes.Unauthenticated},
+		{name: "AdminOnlyAsClient", method: "/pb.AuthService/SetRoles", token: signTestToken(t, testKeys, "client"), expectedCode: codes.PermissionDenied},
+		{name: "AdminOnlyAsAdmin", method: "/pb.AuthService/SetRoles", token: signTestToken(t, testKeys, "admin"), expectedCode: codes.OK},
+		{name: "ServiceOnlyAsStaff", method: "/pb.AuthService/ListRevokedTokens", token: signTestToken(t, testKeys, "staff"), expectedCode: codes.PermissionDenied},
+		{name: "ServiceOnlyAsService", method: "/pb.AuthService/ListRevokedTokens", token: serviceToken, expectedCode: codes.OK},
+		{name: "ValidateTokenAsClient", method: "/pb.AuthService/ValidateToken", token: signTestToken(t, testKeys, "client"), expectedCode: codes.PermissionDenied},
+		{name: "ForgedToken", method: "/pb.AuthService/SetRoles", token: signTestToken(t, newTestKeys(), "admin"), expectedCode: codes.Unauthenticated},
+		{name: "UserTokenWithSecret", method: "/pb.AuthService/SetRoles", token: userTokenWithSecret, expectedCode: codes.Unauthenticated},
+		{name: "UnknownMethod", method: "/pb.AuthService/DropUsers", token: signTestToken(t, testKeys, "staff"), expectedCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupTokenMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.TokenRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewTokenRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestRotateRefreshTokenRepo(t *testing.T) {
+	expiresAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	rotate := regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "used_at"=$1 WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL`)
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		expectErr error
+	}{
+		{
+			name: "Success",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(rotate).WithArgs(sqlmock.AnyArg(), uint(4)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "refresh_tokens" ("user_id","family_id","token_hash","expires_at","access_jti","access_expires_at","used_at","revoked_at","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id"`)).
+					WithArgs(uint(1), "family", "hash", expiresAt, "jti", expiresAt, nil, nil, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "AlreadyUsed",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(rotate).WithArgs(sqlmock.AnyArg(), uint(4)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectErr: repositories.ErrTokenReused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, repo := setupTokenMockDB(t)
+			defer sqlDB.Close()
+			tt.mockSetup(mock)
+
+			next := &models.RefreshToken{UserID: 1, FamilyID: "family", TokenHash: "hash", ExpiresAt: expiresAt, AccessJTI: "jti", AccessExpiresAt: expiresAt}
+			err := repo.RotateRefreshToken(&models.RefreshToken{ID: 4}, next)
+			assert.Equal(t, tt.expectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListRevokedAccessTokensRepo(t *testing.T) {
+	sqlDB, mock, repo := setupTokenMockDB(t)
+	defer sqlDB.Close()
+
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "access_jti" FROM "refresh_tokens" WHERE revoked_at IS NOT NULL AND access_expires_at > $1`)).
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows([]string{"access_jti"}).AddRow("jti-1").AddRow("jti-2"))
+
+	jtis, err := repo.ListRevokedAccessTokens(now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"jti-1", "jti-2"}, jtis)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccessTokenRevokedRepo(t *testing.T) {
+	sqlDB, mock, repo := setupTokenMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "refresh_tokens" WHERE access_jti = $1 AND revoked_at IS NOT NULL`)).
+		WithArgs("jti-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	revoked, err := repo.AccessTokenRevoked("jti-1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseResetTokenRepo(t *testing.T) {
+	sqlDB, mock, repo := setupTokenMockDB(t)
+	defer sqlDB.Close()
+	use := regexp.QuoteMeta(`UPDATE "password_reset_tokens" SET "used_at"=$1 WHERE id = $2 AND used_at IS NULL`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(use).WithArgs(sqlmock.AnyArg(), uint(6)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.UseResetToken(6))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(use).WithArgs(sqlmock.AnyArg(), uint(6)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.Equal(t, repositories.ErrResetTokenUsed, repo.UseResetToken(6))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeUserTokensRepo(t *testing.T) {
+	sqlDB, mock, repo := setupTokenMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked_at"=$1 WHERE user_id = $2 AND revoked_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), uint(3)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.RevokeUserTokens(3))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLastVerificationSentAtRepo(t *testing.T) {
+	sqlDB, mock, repo := setupTokenMockDB(t)
+	defer sqlDB.Close()
+	last := regexp.QuoteMeta(`SELECT * FROM "email_verification_tokens" WHERE user_id = $1 ORDER BY created_at DESC,"email_verification_tokens"."id" LIMIT $2`)
+
+	sentAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(last).WithArgs(uint(3), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).AddRow(8, 3, sentAt))
+	got, err := repo.LastVerificationSentAt(3)
+	assert.NoError(t, err)
+	assert.Equal(t, sentAt, got)
+
+	// Sin enlaces enviados no hay límite
+	mock.ExpectQuery(last).WithArgs(uint(4), 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	got, err = repo.LastVerificationSentAt(4)
+	assert.NoError(t, err)
+	assert.True(t, got.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
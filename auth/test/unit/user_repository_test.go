@@ -2,6 +2,7 @@ package unit
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/models"
 	"github.com/lpsaldana/go-appointment-booking-microservices/auth/internal/repositories"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -35,11 +37,11 @@ func TestCreateUserRepo(t *testing.T) {
 	}{
 		{
 			name: "Success",
-			user: &models.User{Username: "testuser", Password: "testpass"},
+			user: &models.User{Username: "testuser", Password: "testpass", Roles: "client"},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
-				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("username","password") VALUES ($1,$2) RETURNING "id"`)).
-					WithArgs("testuser", sqlmock.AnyArg()).
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("username","password","roles","client_id","professional_id","email","email_verified_at","disabled_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`)).
+					WithArgs("testuser", sqlmock.AnyArg(), "client", 0, 0, nil, nil, nil).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 				mock.ExpectCommit()
 			},
@@ -47,11 +49,11 @@ func TestCreateUserRepo(t *testing.T) {
 		},
 		{
 			name: "DatabaseError",
-			user: &models.User{Username: "testuser", Password: "testpass"},
+			user: &models.User{Username: "testuser", Password: "testpass", Roles: "client"},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
-				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("username","password") VALUES ($1,$2) RETURNING "id"`)).
-					WithArgs("testuser", sqlmock.AnyArg()).
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("username","password","roles","client_id","professional_id","email","email_verified_at","disabled_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`)).
+					WithArgs("testuser", sqlmock.AnyArg(), "client", 0, 0, nil, nil, nil).
 					WillReturnError(errors.New("db error"))
 				mock.ExpectRollback()
 			},
@@ -123,3 +125,110 @@ func TestFindByUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateRolesRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "roles"=$1 WHERE id = $2`)).
+		WithArgs("staff,professional", uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.UpdateRoles(2, []string{"staff", "professional"}))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "roles"=$1 WHERE id = $2`)).
+		WithArgs("client", uint(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.ErrorIs(t, repo.UpdateRoles(9, []string{"client"}), gorm.ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateLinksRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "client_id"=$1,"professional_id"=$2 WHERE id = $3`)).
+		WithArgs(uint(4), uint(0), uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.UpdateLinks(2, 4, 0))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePasswordRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "password"=$1 WHERE id = $2`)).
+		WithArgs(bcryptOf("newpass"), uint(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.UpdatePassword(3, "newpass"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// bcryptOf matches the stored hash of the password, never the password itself.
+type bcryptOf string
+
+func (p bcryptOf) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	return ok && bcrypt.CompareHashAndPassword([]byte(hash), []byte(p)) == nil
+}
+
+func TestListUsersRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE username ILIKE $1 OR email ILIKE $2`)).
+		WithArgs(`%ana\_%`, `%ana\_%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE username ILIKE $1 OR email ILIKE $2 ORDER BY id LIMIT $3 OFFSET $4`)).
+		WithArgs(`%ana\_%`, `%ana\_%`, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "roles"}).AddRow(5, "ana_b", "client"))
+
+	users, total, err := repo.ListUsers("ana_", 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []models.User{{ID: 5, Username: "ana_b", Roles: "client"}}, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetDisabledRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "disabled_at"=$1 WHERE id = $2`)).
+		WithArgs(sqlmock.AnyArg(), uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SetDisabled(2, true))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "disabled_at"=$1 WHERE id = $2`)).
+		WithArgs(nil, uint(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.ErrorIs(t, repo.SetDisabled(9, false), gorm.ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteUserRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "users" WHERE "users"."id" = $1`)).
+		WithArgs(uint(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.ErrorIs(t, repo.DeleteUser(9), gorm.ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -23,7 +23,7 @@ func (c *DBConfig) ConnectDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&models.Client{}); err != nil {
+	if err := db.AutoMigrate(&models.Client{}, &models.Dependent{}); err != nil {
 		log.Printf("Error creating DB models: %v", err)
 		return nil, err
 	}
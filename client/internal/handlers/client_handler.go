@@ -17,13 +17,25 @@ func NewClientHandler(svc services.ClientService) *ClientHandler {
 }
 
 func (h *ClientHandler) CreateClient(ctx context.Context, req *pb.CreateClientRequest) (*pb.CreateClientResponse, error) {
-	return h.Service.CreateClient(req)
+	return h.Service.CreateClient(ctx, req)
 }
 
 func (h *ClientHandler) GetClient(ctx context.Context, req *pb.GetClientRequest) (*pb.GetClientResponse, error) {
-	return h.Service.GetClient(req)
+	return h.Service.GetClient(ctx, req)
 }
 
 func (h *ClientHandler) ListClients(ctx context.Context, req *pb.ListClientsRequest) (*pb.ListClientsResponse, error) {
 	return h.Service.ListClients(req)
 }
+
+func (h *ClientHandler) AddDependent(ctx context.Context, req *pb.AddDependentRequest) (*pb.AddDependentResponse, error) {
+	return h.Service.AddDependent(ctx, req)
+}
+
+func (h *ClientHandler) GetDependent(ctx context.Context, req *pb.GetDependentRequest) (*pb.GetDependentResponse, error) {
+	return h.Service.GetDependent(ctx, req)
+}
+
+func (h *ClientHandler) ListDependents(ctx context.Context, req *pb.ListDependentsRequest) (*pb.ListDependentsResponse, error) {
+	return h.Service.ListDependents(ctx, req)
+}
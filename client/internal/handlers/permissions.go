@@ -0,0 +1,14 @@
+package handlers
+
+import "github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+
+// Permissions lists the roles allowed to call each method, admins and the
+// other services can call all of them.
+var Permissions = rbac.Permissions{
+	"/pb.ClientService/CreateClient":   {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.ClientService/GetClient":      {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.ClientService/ListClients":    {rbac.RoleStaff},
+	"/pb.ClientService/AddDependent":   {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.ClientService/GetDependent":   {rbac.RoleStaff, rbac.RoleClient},
+	"/pb.ClientService/ListDependents": {rbac.RoleStaff, rbac.RoleClient},
+}
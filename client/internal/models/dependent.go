@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DateOfBirthLayout is the format of the dates of birth in requests
+const DateOfBirthLayout = "2006-01-02"
+
+// Dependent is a family member a client books appointments for, ie: a child.
+// It has no email of its own, the client is their guardian and gets the
+// notifications of their appointments.
+type Dependent struct {
+	ID          uint      `gorm:"primaryKey"`
+	ClientID    uint      `gorm:"not null;index"`
+	Name        string    `gorm:"not null"`
+	DateOfBirth time.Time `gorm:"type:date;not null"`
+	CreatedAt   time.Time
+}
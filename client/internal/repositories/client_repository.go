@@ -9,6 +9,9 @@ type ClientRepository interface {
 	CreateClient(client *models.Client) error
 	GetClientByID(id uint) (*models.Client, error)
 	ListClients() ([]models.Client, error)
+	CreateDependent(dependent *models.Dependent) error
+	GetDependentByID(id uint) (*models.Dependent, error)
+	ListDependents(clientID uint) ([]models.Dependent, error)
 }
 
 type ClientRepositoryImpl struct {
@@ -37,3 +40,22 @@ func (r *ClientRepositoryImpl) ListClients() ([]models.Client, error) {
 	err := r.DB.Find(&clients).Error
 	return clients, err
 }
+
+func (r *ClientRepositoryImpl) CreateDependent(dependent *models.Dependent) error {
+	return r.DB.Create(dependent).Error
+}
+
+func (r *ClientRepositoryImpl) GetDependentByID(id uint) (*models.Dependent, error) {
+	var dependent models.Dependent
+	err := r.DB.First(&dependent, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dependent, nil
+}
+
+func (r *ClientRepositoryImpl) ListDependents(clientID uint) ([]models.Dependent, error) {
+	var dependents []models.Dependent
+	err := r.DB.Where("client_id = ?", clientID).Order("name").Find(&dependents).Error
+	return dependents, err
+}
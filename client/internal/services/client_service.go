@@ -1,26 +1,46 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/models"
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/repositories"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 type ClientService interface {
-	CreateClient(req *pb.CreateClientRequest) (*pb.CreateClientResponse, error)
-	GetClient(req *pb.GetClientRequest) (*pb.GetClientResponse, error)
+	CreateClient(ctx context.Context, req *pb.CreateClientRequest) (*pb.CreateClientResponse, error)
+	GetClient(ctx context.Context, req *pb.GetClientRequest) (*pb.GetClientResponse, error)
 	ListClients(req *pb.ListClientsRequest) (*pb.ListClientsResponse, error)
+	AddDependent(ctx context.Context, req *pb.AddDependentRequest) (*pb.AddDependentResponse, error)
+	GetDependent(ctx context.Context, req *pb.GetDependentRequest) (*pb.GetDependentResponse, error)
+	ListDependents(ctx context.Context, req *pb.ListDependentsRequest) (*pb.ListDependentsResponse, error)
 }
 
 type ClientServiceImpl struct {
-	Repo repositories.ClientRepository
+	Repo       repositories.ClientRepository
+	AuthClient pb.AuthServiceClient
 }
 
-func NewClientService(repo repositories.ClientRepository) ClientService {
-	return &ClientServiceImpl{Repo: repo}
+func NewClientService(repo repositories.ClientRepository, authConn *grpc.ClientConn) ClientService {
+	return &ClientServiceImpl{Repo: repo, AuthClient: pb.NewAuthServiceClient(authConn)}
 }
 
-func (s *ClientServiceImpl) CreateClient(req *pb.CreateClientRequest) (*pb.CreateClientResponse, error) {
+// CreateClient registers a client. When a client user registers themselves
+// the record is linked to their account, the IDs are in the tokens issued
+// from then on.
+func (s *ClientServiceImpl) CreateClient(ctx context.Context, req *pb.CreateClientRequest) (*pb.CreateClientResponse, error) {
+	claims := rbac.FromContext(ctx)
+	selfSignup := claims != nil && !claims.ActsForAnyone()
+	if selfSignup && claims.ClientID != 0 {
+		return &pb.CreateClientResponse{Message: "Account already linked to a client", Success: false}, nil
+	}
+
 	client := &models.Client{
 		Name:  req.Name,
 		Email: req.Email,
@@ -33,6 +53,26 @@ func (s *ClientServiceImpl) CreateClient(req *pb.CreateClientRequest) (*pb.Creat
 		}, err
 	}
 
+	if selfSignup {
+		linkCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		linkResp, err := s.AuthClient.LinkUser(linkCtx, &pb.LinkUserRequest{
+			UserId:         uint32(claims.UserID),
+			ClientId:       uint32(client.ID),
+			ProfessionalId: uint32(claims.ProfessionalID),
+		})
+		if err == nil && !linkResp.Success {
+			err = errors.New(linkResp.Message)
+		}
+		if err != nil {
+			return &pb.CreateClientResponse{
+				Message:  "Client created, error linking account",
+				Success:  false,
+				ClientId: uint32(client.ID),
+			}, err
+		}
+	}
+
 	return &pb.CreateClientResponse{
 		Message:  "Client created",
 		Success:  true,
@@ -40,7 +80,11 @@ func (s *ClientServiceImpl) CreateClient(req *pb.CreateClientRequest) (*pb.Creat
 	}, nil
 }
 
-func (s *ClientServiceImpl) GetClient(req *pb.GetClientRequest) (*pb.GetClientResponse, error) {
+// GetClient returns a client to themselves, staff or the other services.
+func (s *ClientServiceImpl) GetClient(ctx context.Context, req *pb.GetClientRequest) (*pb.GetClientResponse, error) {
+	if !callerOwns(ctx, uint(req.Id)) {
+		return &pb.GetClientResponse{Success: false}, rbac.ErrNotAllowed
+	}
 	client, err := s.Repo.GetClientByID(uint(req.Id))
 	if err != nil {
 		return &pb.GetClientResponse{
@@ -82,3 +126,102 @@ func (s *ClientServiceImpl) ListClients(req *pb.ListClientsRequest) (*pb.ListCli
 		Success: true,
 	}, nil
 }
+
+func (s *ClientServiceImpl) AddDependent(ctx context.Context, req *pb.AddDependentRequest) (*pb.AddDependentResponse, error) {
+	if !callerOwns(ctx, uint(req.ClientId)) {
+		return &pb.AddDependentResponse{Message: "Not allowed", Success: false}, nil
+	}
+	if req.Name == "" {
+		return &pb.AddDependentResponse{Message: "name is required", Success: false}, nil
+	}
+	dateOfBirth, err := time.Parse(models.DateOfBirthLayout, req.DateOfBirth)
+	if err != nil {
+		return &pb.AddDependentResponse{Message: "date_of_birth must be in YYYY-MM-DD format", Success: false}, nil
+	}
+	if dateOfBirth.After(time.Now()) {
+		return &pb.AddDependentResponse{Message: "date_of_birth can't be in the future", Success: false}, nil
+	}
+
+	_, err = s.Repo.GetClientByID(uint(req.ClientId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &pb.AddDependentResponse{Message: "Client not found", Success: false}, nil
+	}
+	if err != nil {
+		return &pb.AddDependentResponse{Message: "Error creating dependent", Success: false}, err
+	}
+
+	dependent := &models.Dependent{
+		ClientID:    uint(req.ClientId),
+		Name:        req.Name,
+		DateOfBirth: dateOfBirth,
+	}
+	if err := s.Repo.CreateDependent(dependent); err != nil {
+		return &pb.AddDependentResponse{Message: "Error creating dependent", Success: false}, err
+	}
+
+	return &pb.AddDependentResponse{
+		Message:     "Dependent created",
+		Success:     true,
+		DependentId: uint32(dependent.ID),
+	}, nil
+}
+
+// GetDependent returns a dependent to their guardian, staff or the other
+// services.
+func (s *ClientServiceImpl) GetDependent(ctx context.Context, req *pb.GetDependentRequest) (*pb.GetDependentResponse, error) {
+	dependent, err := s.Repo.GetDependentByID(uint(req.Id))
+	if err != nil {
+		return &pb.GetDependentResponse{
+			Success: false,
+		}, err
+	}
+	if !callerOwns(ctx, dependent.ClientID) {
+		return &pb.GetDependentResponse{Success: false}, rbac.ErrNotAllowed
+	}
+
+	return &pb.GetDependentResponse{
+		Dependent: toPbDependent(dependent),
+		Success:   true,
+	}, nil
+}
+
+// ListDependents returns the household of a client, the family members they
+// can book for.
+func (s *ClientServiceImpl) ListDependents(ctx context.Context, req *pb.ListDependentsRequest) (*pb.ListDependentsResponse, error) {
+	if !callerOwns(ctx, uint(req.ClientId)) {
+		return &pb.ListDependentsResponse{Success: false}, rbac.ErrNotAllowed
+	}
+	dependents, err := s.Repo.ListDependents(uint(req.ClientId))
+	if err != nil {
+		return &pb.ListDependentsResponse{
+			Success: false,
+		}, err
+	}
+
+	pbDependents := make([]*pb.Dependent, len(dependents))
+	for i := range dependents {
+		pbDependents[i] = toPbDependent(&dependents[i])
+	}
+
+	return &pb.ListDependentsResponse{
+		Dependents: pbDependents,
+		Success:    true,
+	}, nil
+}
+
+func toPbDependent(dependent *models.Dependent) *pb.Dependent {
+	return &pb.Dependent{
+		Id:          uint32(dependent.ID),
+		ClientId:    uint32(dependent.ClientID),
+		Name:        dependent.Name,
+		DateOfBirth: dependent.DateOfBirth.Format(models.DateOfBirthLayout),
+	}
+}
+
+// callerOwns reports whether the caller can act on the client's records. The
+// interceptor turns away anonymous callers, so no claims means a call from
+// inside the service.
+func callerOwns(ctx context.Context, clientID uint) bool {
+	claims := rbac.FromContext(ctx)
+	return claims == nil || claims.OwnsClient(clientID)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"net"
+	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/config"
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/handlers"
@@ -10,22 +11,45 @@ import (
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/services"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
-	dsn = common.EnvString("CLIENT_DB", "host=localhost user=postgres password=postgres dbname=Clients port=5432 sslmode=disable")
+	// Debe coincidir con el del servicio de auth, firma y valida los tokens entre servicios
+	secretKey = common.EnvString("JWT_SECRET", rbac.DefaultSecret)
+	devMode   = common.EnvString("DEV_MODE", "false") == "true"
+	dsn       = common.EnvString("CLIENT_DB", "host=localhost user=postgres password=postgres dbname=Clients port=5432 sslmode=disable")
+	// "remote" le pregunta a auth y así respeta los tokens revocados, "local"
+	// solo revisa las llaves publicadas y acepta un token hasta que expira
+	tokenValidation = common.EnvString("TOKEN_VALIDATION", rbac.ValidationRemote)
+	// Cada cuánto se traen las llaves de auth, menos que SIGNING_KEY_PUBLISH_AHEAD
+	jwksSyncInterval = common.EnvString("JWKS_SYNC_INTERVAL", "5m")
 )
 
 func main() {
+	if err := rbac.CheckSecret(secretKey, devMode); err != nil {
+		log.Fatal(err)
+	}
+
 	dbConfig := config.NewDBConfig(dsn)
 	db, err := dbConfig.ConnectDB()
 	if err != nil {
 		log.Fatalf("Cannot connect to DB: %v", err)
 	}
 
+	// Las llamadas a otros servicios van con un token de servicio
+	serviceCreds := rbac.NewServiceCredentials(secretKey, "client")
+	authConn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to auth server: %v", err)
+	}
+	defer authConn.Close()
+
 	repo := repositories.NewClientRepository(db)
-	svc := services.NewClientService(repo)
+	svc := services.NewClientService(repo, authConn)
 	handler := handlers.NewClientHandler(svc)
 
 	lis, err := net.Listen("tcp", ":50053")
@@ -33,7 +57,19 @@ func main() {
 		log.Fatalf("Error listening to port 50053: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	syncInterval, err := time.ParseDuration(jwksSyncInterval)
+	if err != nil {
+		log.Fatalf("Invalid JWKS_SYNC_INTERVAL: %v", err)
+	}
+	authClient := pb.NewAuthServiceClient(authConn)
+	keys := rbac.NewKeySet(secretKey, rbac.FetchJWKS(authClient))
+	go keys.Sync(syncInterval)
+	validator, err := rbac.NewValidator(tokenValidation, keys, authClient)
+	if err != nil {
+		log.Fatalf("Invalid TOKEN_VALIDATION: %v", err)
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(rbac.UnaryServerInterceptor(validator, handlers.Permissions)),
+		grpc.StreamInterceptor(rbac.StreamServerInterceptor(validator, handlers.Permissions)))
 	pb.RegisterClientServiceServer(grpcServer, handler)
 
 	log.Println("Server runing on port :50053...")
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/models"
@@ -178,3 +179,37 @@ func TestListClientsRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateDependentRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	dateOfBirth := time.Date(2018, 5, 20, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "dependents" ("client_id","name","date_of_birth","created_at") VALUES ($1,$2,$3,$4) RETURNING "id"`)).
+		WithArgs(uint(1), "Sofia Perez", dateOfBirth, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectCommit()
+
+	dependent := &models.Dependent{ClientID: 1, Name: "Sofia Perez", DateOfBirth: dateOfBirth}
+	err := repo.CreateDependent(dependent)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), dependent.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListDependentsRepo(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "dependents" WHERE client_id = $1 ORDER BY name`)).
+		WithArgs(uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "name", "date_of_birth"}).
+			AddRow(3, 1, "Sofia Perez", time.Date(2018, 5, 20, 0, 0, 0, 0, time.UTC)))
+
+	dependents, err := repo.ListDependents(1)
+	assert.NoError(t, err)
+	assert.Len(t, dependents, 1)
+	assert.Equal(t, "Sofia Perez", dependents[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
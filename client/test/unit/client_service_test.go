@@ -1,14 +1,19 @@
 package unit
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/models"
 	"github.com/lpsaldana/go-appointment-booking-microservices/client/internal/services"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 type MockClientRepository struct {
@@ -30,9 +35,36 @@ func (m *MockClientRepository) ListClients() ([]models.Client, error) {
 	return args.Get(0).([]models.Client), args.Error(1)
 }
 
+func (m *MockClientRepository) CreateDependent(dependent *models.Dependent) error {
+	args := m.Called(dependent)
+	return args.Error(0)
+}
+
+func (m *MockClientRepository) GetDependentByID(id uint) (*models.Dependent, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Dependent), args.Error(1)
+}
+
+func (m *MockClientRepository) ListDependents(clientID uint) ([]models.Dependent, error) {
+	args := m.Called(clientID)
+	return args.Get(0).([]models.Dependent), args.Error(1)
+}
+
+// MockAuthServiceClient only implements LinkUser, the client service doesn't
+// call the other methods.
+type MockAuthServiceClient struct {
+	pb.AuthServiceClient
+	mock.Mock
+}
+
+func (m *MockAuthServiceClient) LinkUser(ctx context.Context, in *pb.LinkUserRequest, opts ...grpc.CallOption) (*pb.LinkUserResponse, error) {
+	args := m.Called(in)
+	return args.Get(0).(*pb.LinkUserResponse), args.Error(1)
+}
+
 func TestCreateClient(t *testing.T) {
 	mockRepo := new(MockClientRepository)
-	srv := services.NewClientService(mockRepo)
+	srv := services.NewClientService(mockRepo, nil)
 
 	tests := []struct {
 		name         string
@@ -64,7 +96,7 @@ func TestCreateClient(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			resp, err := srv.CreateClient(tt.req)
+			resp, err := srv.CreateClient(context.Background(), tt.req)
 			assert.Equal(t, tt.expectedResp.Message, resp.Message)
 			assert.Equal(t, tt.expectedResp.Success, resp.Success)
 			/*if tt.expectedResp.Success {
@@ -78,7 +110,7 @@ func TestCreateClient(t *testing.T) {
 
 func TestGetClient(t *testing.T) {
 	mockRepo := new(MockClientRepository)
-	srv := services.NewClientService(mockRepo)
+	srv := services.NewClientService(mockRepo, nil)
 
 	tests := []struct {
 		name         string
@@ -113,7 +145,7 @@ func TestGetClient(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			resp, err := srv.GetClient(tt.req)
+			resp, err := srv.GetClient(context.Background(), tt.req)
 			assert.Equal(t, tt.expectedResp, resp)
 			assert.Equal(t, tt.expectedErr, err)
 			(mockRepo).AssertExpectations(t)
@@ -123,7 +155,7 @@ func TestGetClient(t *testing.T) {
 
 func TestListClients(t *testing.T) {
 	mockRepo := new(MockClientRepository)
-	srv := services.NewClientService(mockRepo)
+	srv := services.NewClientService(mockRepo, nil)
 
 	tests := []struct {
 		name         string
@@ -180,3 +212,159 @@ func TestListClients(t *testing.T) {
 		})
 	}
 }
+
+func TestAddDependent(t *testing.T) {
+	mockRepo := new(MockClientRepository)
+	srv := services.NewClientService(mockRepo, nil)
+
+	tests := []struct {
+		name         string
+		req          *pb.AddDependentRequest
+		mockSetup    func()
+		expectedResp *pb.AddDependentResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req:  &pb.AddDependentRequest{ClientId: 1, Name: "Sofia Perez", DateOfBirth: "2018-05-20"},
+			mockSetup: func() {
+				(mockRepo).On("GetClientByID", uint(1)).Return(&models.Client{ID: 1, Name: "Maria Perez"}, nil).Once()
+				(mockRepo).On("CreateDependent", mock.MatchedBy(func(d *models.Dependent) bool {
+					return d.ClientID == 1 && d.Name == "Sofia Perez" && d.DateOfBirth.Equal(time.Date(2018, 5, 20, 0, 0, 0, 0, time.UTC))
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.AddDependentResponse{Message: "Dependent created", Success: true},
+			expectedErr:  nil,
+		},
+		{
+			name:         "InvalidDateOfBirth",
+			req:          &pb.AddDependentRequest{ClientId: 1, Name: "Sofia Perez", DateOfBirth: "20/05/2018"},
+			mockSetup:    func() {},
+			expectedResp: &pb.AddDependentResponse{Message: "date_of_birth must be in YYYY-MM-DD format", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name:         "BornInTheFuture",
+			req:          &pb.AddDependentRequest{ClientId: 1, Name: "Sofia Perez", DateOfBirth: time.Now().AddDate(1, 0, 0).Format("2006-01-02")},
+			mockSetup:    func() {},
+			expectedResp: &pb.AddDependentResponse{Message: "date_of_birth can't be in the future", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "ClientNotFound",
+			req:  &pb.AddDependentRequest{ClientId: 999, Name: "Sofia Perez", DateOfBirth: "2018-05-20"},
+			mockSetup: func() {
+				(mockRepo).On("GetClientByID", uint(999)).Return((*models.Client)(nil), gorm.ErrRecordNotFound).Once()
+			},
+			expectedResp: &pb.AddDependentResponse{Message: "Client not found", Success: false},
+			expectedErr:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.AddDependent(context.Background(), tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+}
+
+func TestListDependents(t *testing.T) {
+	mockRepo := new(MockClientRepository)
+	srv := services.NewClientService(mockRepo, nil)
+
+	(mockRepo).On("ListDependents", uint(1)).Return([]models.Dependent{
+		{ID: 3, ClientID: 1, Name: "Sofia Perez", DateOfBirth: time.Date(2018, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{ID: 4, ClientID: 1, Name: "Tomas Perez", DateOfBirth: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}, nil).Once()
+
+	resp, err := srv.ListDependents(context.Background(), &pb.ListDependentsRequest{ClientId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.ListDependentsResponse{
+		Dependents: []*pb.Dependent{
+			{Id: 3, ClientId: 1, Name: "Sofia Perez", DateOfBirth: "2018-05-20"},
+			{Id: 4, ClientId: 1, Name: "Tomas Perez", DateOfBirth: "2020-01-02"},
+		},
+		Success: true,
+	}, resp)
+	(mockRepo).AssertExpectations(t)
+}
+
+func TestCreateClientSelfSignup(t *testing.T) {
+	mockRepo := new(MockClientRepository)
+	mockAuth := new(MockAuthServiceClient)
+	srv := services.NewClientService(mockRepo, nil)
+	srv.(*services.ClientServiceImpl).AuthClient = mockAuth
+
+	req := &pb.CreateClientRequest{Name: "Maria Perez", Email: "maria@email.com", Phone: "123456789"}
+	user := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 8, Roles: []string{rbac.RoleClient}})
+
+	mockRepo.On("CreateClient", mock.AnythingOfType("*models.Client")).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Client).ID = 4
+	}).Return(nil).Once()
+	mockAuth.On("LinkUser", &pb.LinkUserRequest{UserId: 8, ClientId: 4}).Return(&pb.LinkUserResponse{Message: "User linked", Success: true}, nil).Once()
+	resp, err := srv.CreateClient(user, req)
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CreateClientResponse{Message: "Client created", Success: true, ClientId: 4}, resp)
+
+	// Una cuenta ya vinculada no puede crear otra ficha
+	linked := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 8, Roles: []string{rbac.RoleClient}, ClientID: 4})
+	resp, err = srv.CreateClient(linked, req)
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CreateClientResponse{Message: "Account already linked to a client", Success: false}, resp)
+
+	// El staff registra clientes sin vincularse a ellos
+	staff := rbac.NewContext(context.Background(), &rbac.Claims{UserID: 2, Roles: []string{rbac.RoleStaff}})
+	mockRepo.On("CreateClient", mock.AnythingOfType("*models.Client")).Return(nil).Once()
+	resp, err = srv.CreateClient(staff, req)
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	mockRepo.AssertExpectations(t)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestDependentsOwnership(t *testing.T) {
+	mockRepo := new(MockClientRepository)
+	srv := services.NewClientService(mockRepo, nil)
+	other := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 2})
+
+	addResp, err := srv.AddDependent(other, &pb.AddDependentRequest{ClientId: 1, Name: "Sofia Perez", DateOfBirth: "2018-05-20"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.AddDependentResponse{Message: "Not allowed", Success: false}, addResp)
+
+	_, err = srv.ListDependents(other, &pb.ListDependentsRequest{ClientId: 1})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestClientReadsOwnership(t *testing.T) {
+	mockRepo := new(MockClientRepository)
+	srv := services.NewClientService(mockRepo, nil)
+	guardian := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 1})
+	other := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 2})
+	professional := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 1})
+
+	// Los datos de contacto solo los ve el propio cliente
+	_, err := srv.GetClient(other, &pb.GetClientRequest{Id: 1})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+	_, err = srv.GetClient(professional, &pb.GetClientRequest{Id: 1})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+
+	// Un dependiente lo ve quien lo tiene a cargo
+	dependent := &models.Dependent{ID: 3, ClientID: 1, Name: "Sofia Perez", DateOfBirth: time.Date(2018, 5, 20, 0, 0, 0, 0, time.UTC)}
+	mockRepo.On("GetDependentByID", uint(3)).Return(dependent, nil).Twice()
+	_, err = srv.GetDependent(other, &pb.GetDependentRequest{Id: 3})
+	assert.Equal(t, rbac.ErrNotAllowed, err)
+
+	resp, err := srv.GetDependent(guardian, &pb.GetDependentRequest{Id: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GetDependentResponse{
+		Dependent: &pb.Dependent{Id: 3, ClientId: 1, Name: "Sofia Perez", DateOfBirth: "2018-05-20"},
+		Success:   true,
+	}, resp)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+// Package actionlink signs and verifies the self-service links sent to clients
+// so they can manage a booking without an account. Links are HS256 JWTs bound
+// to one appointment and one action; the agenda records their ID when they're
+// used so each link works only once.
+package actionlink
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ActionConfirm    = "confirm"
+	ActionCancel     = "cancel"
+	ActionReschedule = "reschedule"
+)
+
+// DefaultSecret is the development fallback of ACTION_LINK_SECRET, it's in
+// the repo so it must never sign a link that's sent out.
+const DefaultSecret = "please-dont-use-this-link-key"
+
+var ErrInvalidLink = errors.New("invalid_action_link")
+
+// CheckSecret refuses an empty secret, and the default one unless running in
+// development mode.
+func CheckSecret(secret string, devMode bool) error {
+	if secret == "" {
+		return errors.New("ACTION_LINK_SECRET is empty")
+	}
+	if secret == DefaultSecret && !devMode {
+		return errors.New("ACTION_LINK_SECRET is the default one, set it or DEV_MODE=true")
+	}
+	return nil
+}
+
+// Actions lists every action a link can carry.
+var Actions = []string{ActionConfirm, ActionCancel, ActionReschedule}
+
+type Claims struct {
+	AppointmentID uint32 `json:"appointment_id"`
+	Action        string `json:"action"`
+	jwt.RegisteredClaims
+}
+
+// Sign issues a link token for the action on the appointment, valid until
+// expiresAt.
+func Sign(secret []byte, appointmentID uint32, action string, expiresAt time.Time) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		AppointmentID: appointmentID,
+		Action:        action,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	return token.SignedString(secret)
+}
+
+// Verify checks the signature and expiry of a link token and that it was
+// issued for the expected action.
+func Verify(secret []byte, tokenString, action string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.Action != action || claims.ID == "" || claims.AppointmentID == 0 {
+		return nil, ErrInvalidLink
+	}
+	return &claims, nil
+}
@@ -19,10 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AgendaService_CreateSlot_FullMethodName         = "/pb.AgendaService/CreateSlot"
-	AgendaService_ListAvailableSlots_FullMethodName = "/pb.AgendaService/ListAvailableSlots"
-	AgendaService_BookAppointment_FullMethodName    = "/pb.AgendaService/BookAppointment"
-	AgendaService_ListAppointments_FullMethodName   = "/pb.AgendaService/ListAppointments"
+	AgendaService_CreateSlot_FullMethodName            = "/pb.AgendaService/CreateSlot"
+	AgendaService_ListAvailableSlots_FullMethodName    = "/pb.AgendaService/ListAvailableSlots"
+	AgendaService_BookAppointment_FullMethodName       = "/pb.AgendaService/BookAppointment"
+	AgendaService_ListAppointments_FullMethodName      = "/pb.AgendaService/ListAppointments"
+	AgendaService_GetAppointment_FullMethodName        = "/pb.AgendaService/GetAppointment"
+	AgendaService_CompleteAppointment_FullMethodName   = "/pb.AgendaService/CompleteAppointment"
+	AgendaService_CancelAppointment_FullMethodName     = "/pb.AgendaService/CancelAppointment"
+	AgendaService_ApproveAppointment_FullMethodName    = "/pb.AgendaService/ApproveAppointment"
+	AgendaService_DeclineAppointment_FullMethodName    = "/pb.AgendaService/DeclineAppointment"
+	AgendaService_ConfirmAppointment_FullMethodName    = "/pb.AgendaService/ConfirmAppointment"
+	AgendaService_RescheduleAppointment_FullMethodName = "/pb.AgendaService/RescheduleAppointment"
+	AgendaService_ReassignAppointments_FullMethodName  = "/pb.AgendaService/ReassignAppointments"
+	AgendaService_GetIntakeAnswers_FullMethodName      = "/pb.AgendaService/GetIntakeAnswers"
 )
 
 // AgendaServiceClient is the client API for AgendaService service.
@@ -33,6 +42,15 @@ type AgendaServiceClient interface {
 	ListAvailableSlots(ctx context.Context, in *ListAvailableSlotsRequest, opts ...grpc.CallOption) (*ListAvailableSlotsResponse, error)
 	BookAppointment(ctx context.Context, in *BookAppointmentRequest, opts ...grpc.CallOption) (*BookAppointmentResponse, error)
 	ListAppointments(ctx context.Context, in *ListAppointmentsRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error)
+	GetAppointment(ctx context.Context, in *GetAppointmentRequest, opts ...grpc.CallOption) (*GetAppointmentResponse, error)
+	CompleteAppointment(ctx context.Context, in *CompleteAppointmentRequest, opts ...grpc.CallOption) (*CompleteAppointmentResponse, error)
+	CancelAppointment(ctx context.Context, in *CancelAppointmentRequest, opts ...grpc.CallOption) (*CancelAppointmentResponse, error)
+	ApproveAppointment(ctx context.Context, in *ApproveAppointmentRequest, opts ...grpc.CallOption) (*ApproveAppointmentResponse, error)
+	DeclineAppointment(ctx context.Context, in *DeclineAppointmentRequest, opts ...grpc.CallOption) (*DeclineAppointmentResponse, error)
+	ConfirmAppointment(ctx context.Context, in *ConfirmAppointmentRequest, opts ...grpc.CallOption) (*ConfirmAppointmentResponse, error)
+	RescheduleAppointment(ctx context.Context, in *RescheduleAppointmentRequest, opts ...grpc.CallOption) (*RescheduleAppointmentResponse, error)
+	ReassignAppointments(ctx context.Context, in *ReassignAppointmentsRequest, opts ...grpc.CallOption) (*ReassignAppointmentsResponse, error)
+	GetIntakeAnswers(ctx context.Context, in *GetIntakeAnswersRequest, opts ...grpc.CallOption) (*GetIntakeAnswersResponse, error)
 }
 
 type agendaServiceClient struct {
@@ -83,6 +101,96 @@ func (c *agendaServiceClient) ListAppointments(ctx context.Context, in *ListAppo
 	return out, nil
 }
 
+func (c *agendaServiceClient) GetAppointment(ctx context.Context, in *GetAppointmentRequest, opts ...grpc.CallOption) (*GetAppointmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAppointmentResponse)
+	err := c.cc.Invoke(ctx, AgendaService_GetAppointment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) CompleteAppointment(ctx context.Context, in *CompleteAppointmentRequest, opts ...grpc.CallOption) (*CompleteAppointmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteAppointmentResponse)
+	err := c.cc.Invoke(ctx, AgendaService_CompleteAppointment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) CancelAppointment(ctx context.Context, in *CancelAppointmentRequest, opts ...grpc.CallOption) (*CancelAppointmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelAppointmentResponse)
+	err := c.cc.Invoke(ctx, AgendaService_CancelAppointment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) ApproveAppointment(ctx context.Context, in *ApproveAppointmentRequest, opts ...grpc.CallOption) (*ApproveAppointmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApproveAppointmentResponse)
+	err := c.cc.Invoke(ctx, AgendaService_ApproveAppointment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) DeclineAppointment(ctx context.Context, in *DeclineAppointmentRequest, opts ...grpc.CallOption) (*DeclineAppointmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeclineAppointmentResponse)
+	err := c.cc.Invoke(ctx, AgendaService_DeclineAppointment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) ConfirmAppointment(ctx context.Context, in *ConfirmAppointmentRequest, opts ...grpc.CallOption) (*ConfirmAppointmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmAppointmentResponse)
+	err := c.cc.Invoke(ctx, AgendaService_ConfirmAppointment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) RescheduleAppointment(ctx context.Context, in *RescheduleAppointmentRequest, opts ...grpc.CallOption) (*RescheduleAppointmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RescheduleAppointmentResponse)
+	err := c.cc.Invoke(ctx, AgendaService_RescheduleAppointment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) ReassignAppointments(ctx context.Context, in *ReassignAppointmentsRequest, opts ...grpc.CallOption) (*ReassignAppointmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReassignAppointmentsResponse)
+	err := c.cc.Invoke(ctx, AgendaService_ReassignAppointments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agendaServiceClient) GetIntakeAnswers(ctx context.Context, in *GetIntakeAnswersRequest, opts ...grpc.CallOption) (*GetIntakeAnswersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetIntakeAnswersResponse)
+	err := c.cc.Invoke(ctx, AgendaService_GetIntakeAnswers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgendaServiceServer is the server API for AgendaService service.
 // All implementations must embed UnimplementedAgendaServiceServer
 // for forward compatibility.
@@ -91,6 +199,15 @@ type AgendaServiceServer interface {
 	ListAvailableSlots(context.Context, *ListAvailableSlotsRequest) (*ListAvailableSlotsResponse, error)
 	BookAppointment(context.Context, *BookAppointmentRequest) (*BookAppointmentResponse, error)
 	ListAppointments(context.Context, *ListAppointmentsRequest) (*ListAppointmentsResponse, error)
+	GetAppointment(context.Context, *GetAppointmentRequest) (*GetAppointmentResponse, error)
+	CompleteAppointment(context.Context, *CompleteAppointmentRequest) (*CompleteAppointmentResponse, error)
+	CancelAppointment(context.Context, *CancelAppointmentRequest) (*CancelAppointmentResponse, error)
+	ApproveAppointment(context.Context, *ApproveAppointmentRequest) (*ApproveAppointmentResponse, error)
+	DeclineAppointment(context.Context, *DeclineAppointmentRequest) (*DeclineAppointmentResponse, error)
+	ConfirmAppointment(context.Context, *ConfirmAppointmentRequest) (*ConfirmAppointmentResponse, error)
+	RescheduleAppointment(context.Context, *RescheduleAppointmentRequest) (*RescheduleAppointmentResponse, error)
+	ReassignAppointments(context.Context, *ReassignAppointmentsRequest) (*ReassignAppointmentsResponse, error)
+	GetIntakeAnswers(context.Context, *GetIntakeAnswersRequest) (*GetIntakeAnswersResponse, error)
 	mustEmbedUnimplementedAgendaServiceServer()
 }
 
@@ -113,6 +230,33 @@ func (UnimplementedAgendaServiceServer) BookAppointment(context.Context, *BookAp
 func (UnimplementedAgendaServiceServer) ListAppointments(context.Context, *ListAppointmentsRequest) (*ListAppointmentsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListAppointments not implemented")
 }
+func (UnimplementedAgendaServiceServer) GetAppointment(context.Context, *GetAppointmentRequest) (*GetAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAppointment not implemented")
+}
+func (UnimplementedAgendaServiceServer) CompleteAppointment(context.Context, *CompleteAppointmentRequest) (*CompleteAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteAppointment not implemented")
+}
+func (UnimplementedAgendaServiceServer) CancelAppointment(context.Context, *CancelAppointmentRequest) (*CancelAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelAppointment not implemented")
+}
+func (UnimplementedAgendaServiceServer) ApproveAppointment(context.Context, *ApproveAppointmentRequest) (*ApproveAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveAppointment not implemented")
+}
+func (UnimplementedAgendaServiceServer) DeclineAppointment(context.Context, *DeclineAppointmentRequest) (*DeclineAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeclineAppointment not implemented")
+}
+func (UnimplementedAgendaServiceServer) ConfirmAppointment(context.Context, *ConfirmAppointmentRequest) (*ConfirmAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmAppointment not implemented")
+}
+func (UnimplementedAgendaServiceServer) RescheduleAppointment(context.Context, *RescheduleAppointmentRequest) (*RescheduleAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RescheduleAppointment not implemented")
+}
+func (UnimplementedAgendaServiceServer) ReassignAppointments(context.Context, *ReassignAppointmentsRequest) (*ReassignAppointmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReassignAppointments not implemented")
+}
+func (UnimplementedAgendaServiceServer) GetIntakeAnswers(context.Context, *GetIntakeAnswersRequest) (*GetIntakeAnswersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIntakeAnswers not implemented")
+}
 func (UnimplementedAgendaServiceServer) mustEmbedUnimplementedAgendaServiceServer() {}
 func (UnimplementedAgendaServiceServer) testEmbeddedByValue()                       {}
 
@@ -206,6 +350,168 @@ func _AgendaService_ListAppointments_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgendaService_GetAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).GetAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_GetAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).GetAppointment(ctx, req.(*GetAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_CompleteAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).CompleteAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_CompleteAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).CompleteAppointment(ctx, req.(*CompleteAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_CancelAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).CancelAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_CancelAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).CancelAppointment(ctx, req.(*CancelAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_ApproveAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).ApproveAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_ApproveAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).ApproveAppointment(ctx, req.(*ApproveAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_DeclineAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeclineAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).DeclineAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_DeclineAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).DeclineAppointment(ctx, req.(*DeclineAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_ConfirmAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).ConfirmAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_ConfirmAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).ConfirmAppointment(ctx, req.(*ConfirmAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_RescheduleAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RescheduleAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).RescheduleAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_RescheduleAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).RescheduleAppointment(ctx, req.(*RescheduleAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_ReassignAppointments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReassignAppointmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).ReassignAppointments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_ReassignAppointments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).ReassignAppointments(ctx, req.(*ReassignAppointmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgendaService_GetIntakeAnswers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIntakeAnswersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgendaServiceServer).GetIntakeAnswers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgendaService_GetIntakeAnswers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgendaServiceServer).GetIntakeAnswers(ctx, req.(*GetIntakeAnswersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgendaService_ServiceDesc is the grpc.ServiceDesc for AgendaService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +535,42 @@ var AgendaService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListAppointments",
 			Handler:    _AgendaService_ListAppointments_Handler,
 		},
+		{
+			MethodName: "GetAppointment",
+			Handler:    _AgendaService_GetAppointment_Handler,
+		},
+		{
+			MethodName: "CompleteAppointment",
+			Handler:    _AgendaService_CompleteAppointment_Handler,
+		},
+		{
+			MethodName: "CancelAppointment",
+			Handler:    _AgendaService_CancelAppointment_Handler,
+		},
+		{
+			MethodName: "ApproveAppointment",
+			Handler:    _AgendaService_ApproveAppointment_Handler,
+		},
+		{
+			MethodName: "DeclineAppointment",
+			Handler:    _AgendaService_DeclineAppointment_Handler,
+		},
+		{
+			MethodName: "ConfirmAppointment",
+			Handler:    _AgendaService_ConfirmAppointment_Handler,
+		},
+		{
+			MethodName: "RescheduleAppointment",
+			Handler:    _AgendaService_RescheduleAppointment_Handler,
+		},
+		{
+			MethodName: "ReassignAppointments",
+			Handler:    _AgendaService_ReassignAppointments_Handler,
+		},
+		{
+			MethodName: "GetIntakeAnswers",
+			Handler:    _AgendaService_GetIntakeAnswers_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pb/agenda.proto",
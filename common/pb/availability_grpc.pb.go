@@ -0,0 +1,311 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: pb/availability.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AvailabilityService_UpdateAvailabilitySettings_FullMethodName = "/pb.AvailabilityService/UpdateAvailabilitySettings"
+	AvailabilityService_GetAvailabilitySettings_FullMethodName    = "/pb.AvailabilityService/GetAvailabilitySettings"
+	AvailabilityService_SetAvailabilityRules_FullMethodName       = "/pb.AvailabilityService/SetAvailabilityRules"
+	AvailabilityService_CreateTimeOff_FullMethodName              = "/pb.AvailabilityService/CreateTimeOff"
+	AvailabilityService_SetApprovalMode_FullMethodName            = "/pb.AvailabilityService/SetApprovalMode"
+	AvailabilityService_SetDigestPreferences_FullMethodName       = "/pb.AvailabilityService/SetDigestPreferences"
+)
+
+// AvailabilityServiceClient is the client API for AvailabilityService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AvailabilityServiceClient interface {
+	UpdateAvailabilitySettings(ctx context.Context, in *UpdateAvailabilitySettingsRequest, opts ...grpc.CallOption) (*UpdateAvailabilitySettingsResponse, error)
+	GetAvailabilitySettings(ctx context.Context, in *GetAvailabilitySettingsRequest, opts ...grpc.CallOption) (*GetAvailabilitySettingsResponse, error)
+	SetAvailabilityRules(ctx context.Context, in *SetAvailabilityRulesRequest, opts ...grpc.CallOption) (*SetAvailabilityRulesResponse, error)
+	CreateTimeOff(ctx context.Context, in *CreateTimeOffRequest, opts ...grpc.CallOption) (*CreateTimeOffResponse, error)
+	SetApprovalMode(ctx context.Context, in *SetApprovalModeRequest, opts ...grpc.CallOption) (*SetApprovalModeResponse, error)
+	SetDigestPreferences(ctx context.Context, in *SetDigestPreferencesRequest, opts ...grpc.CallOption) (*SetDigestPreferencesResponse, error)
+}
+
+type availabilityServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAvailabilityServiceClient(cc grpc.ClientConnInterface) AvailabilityServiceClient {
+	return &availabilityServiceClient{cc}
+}
+
+func (c *availabilityServiceClient) UpdateAvailabilitySettings(ctx context.Context, in *UpdateAvailabilitySettingsRequest, opts ...grpc.CallOption) (*UpdateAvailabilitySettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAvailabilitySettingsResponse)
+	err := c.cc.Invoke(ctx, AvailabilityService_UpdateAvailabilitySettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *availabilityServiceClient) GetAvailabilitySettings(ctx context.Context, in *GetAvailabilitySettingsRequest, opts ...grpc.CallOption) (*GetAvailabilitySettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAvailabilitySettingsResponse)
+	err := c.cc.Invoke(ctx, AvailabilityService_GetAvailabilitySettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *availabilityServiceClient) SetAvailabilityRules(ctx context.Context, in *SetAvailabilityRulesRequest, opts ...grpc.CallOption) (*SetAvailabilityRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAvailabilityRulesResponse)
+	err := c.cc.Invoke(ctx, AvailabilityService_SetAvailabilityRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *availabilityServiceClient) CreateTimeOff(ctx context.Context, in *CreateTimeOffRequest, opts ...grpc.CallOption) (*CreateTimeOffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTimeOffResponse)
+	err := c.cc.Invoke(ctx, AvailabilityService_CreateTimeOff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *availabilityServiceClient) SetApprovalMode(ctx context.Context, in *SetApprovalModeRequest, opts ...grpc.CallOption) (*SetApprovalModeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetApprovalModeResponse)
+	err := c.cc.Invoke(ctx, AvailabilityService_SetApprovalMode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *availabilityServiceClient) SetDigestPreferences(ctx context.Context, in *SetDigestPreferencesRequest, opts ...grpc.CallOption) (*SetDigestPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDigestPreferencesResponse)
+	err := c.cc.Invoke(ctx, AvailabilityService_SetDigestPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AvailabilityServiceServer is the server API for AvailabilityService service.
+// All implementations must embed UnimplementedAvailabilityServiceServer
+// for forward compatibility.
+type AvailabilityServiceServer interface {
+	UpdateAvailabilitySettings(context.Context, *UpdateAvailabilitySettingsRequest) (*UpdateAvailabilitySettingsResponse, error)
+	GetAvailabilitySettings(context.Context, *GetAvailabilitySettingsRequest) (*GetAvailabilitySettingsResponse, error)
+	SetAvailabilityRules(context.Context, *SetAvailabilityRulesRequest) (*SetAvailabilityRulesResponse, error)
+	CreateTimeOff(context.Context, *CreateTimeOffRequest) (*CreateTimeOffResponse, error)
+	SetApprovalMode(context.Context, *SetApprovalModeRequest) (*SetApprovalModeResponse, error)
+	SetDigestPreferences(context.Context, *SetDigestPreferencesRequest) (*SetDigestPreferencesResponse, error)
+	mustEmbedUnimplementedAvailabilityServiceServer()
+}
+
+// UnimplementedAvailabilityServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAvailabilityServiceServer struct{}
+
+func (UnimplementedAvailabilityServiceServer) UpdateAvailabilitySettings(context.Context, *UpdateAvailabilitySettingsRequest) (*UpdateAvailabilitySettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAvailabilitySettings not implemented")
+}
+func (UnimplementedAvailabilityServiceServer) GetAvailabilitySettings(context.Context, *GetAvailabilitySettingsRequest) (*GetAvailabilitySettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailabilitySettings not implemented")
+}
+func (UnimplementedAvailabilityServiceServer) SetAvailabilityRules(context.Context, *SetAvailabilityRulesRequest) (*SetAvailabilityRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAvailabilityRules not implemented")
+}
+func (UnimplementedAvailabilityServiceServer) CreateTimeOff(context.Context, *CreateTimeOffRequest) (*CreateTimeOffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTimeOff not implemented")
+}
+func (UnimplementedAvailabilityServiceServer) SetApprovalMode(context.Context, *SetApprovalModeRequest) (*SetApprovalModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetApprovalMode not implemented")
+}
+func (UnimplementedAvailabilityServiceServer) SetDigestPreferences(context.Context, *SetDigestPreferencesRequest) (*SetDigestPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDigestPreferences not implemented")
+}
+func (UnimplementedAvailabilityServiceServer) mustEmbedUnimplementedAvailabilityServiceServer() {}
+func (UnimplementedAvailabilityServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeAvailabilityServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AvailabilityServiceServer will
+// result in compilation errors.
+type UnsafeAvailabilityServiceServer interface {
+	mustEmbedUnimplementedAvailabilityServiceServer()
+}
+
+func RegisterAvailabilityServiceServer(s grpc.ServiceRegistrar, srv AvailabilityServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAvailabilityServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AvailabilityService_ServiceDesc, srv)
+}
+
+func _AvailabilityService_UpdateAvailabilitySettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAvailabilitySettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvailabilityServiceServer).UpdateAvailabilitySettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvailabilityService_UpdateAvailabilitySettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvailabilityServiceServer).UpdateAvailabilitySettings(ctx, req.(*UpdateAvailabilitySettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvailabilityService_GetAvailabilitySettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAvailabilitySettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvailabilityServiceServer).GetAvailabilitySettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvailabilityService_GetAvailabilitySettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvailabilityServiceServer).GetAvailabilitySettings(ctx, req.(*GetAvailabilitySettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvailabilityService_SetAvailabilityRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAvailabilityRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvailabilityServiceServer).SetAvailabilityRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvailabilityService_SetAvailabilityRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvailabilityServiceServer).SetAvailabilityRules(ctx, req.(*SetAvailabilityRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvailabilityService_CreateTimeOff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTimeOffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvailabilityServiceServer).CreateTimeOff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvailabilityService_CreateTimeOff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvailabilityServiceServer).CreateTimeOff(ctx, req.(*CreateTimeOffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvailabilityService_SetApprovalMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetApprovalModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvailabilityServiceServer).SetApprovalMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvailabilityService_SetApprovalMode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvailabilityServiceServer).SetApprovalMode(ctx, req.(*SetApprovalModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvailabilityService_SetDigestPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDigestPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvailabilityServiceServer).SetDigestPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvailabilityService_SetDigestPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvailabilityServiceServer).SetDigestPreferences(ctx, req.(*SetDigestPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AvailabilityService_ServiceDesc is the grpc.ServiceDesc for AvailabilityService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AvailabilityService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.AvailabilityService",
+	HandlerType: (*AvailabilityServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UpdateAvailabilitySettings",
+			Handler:    _AvailabilityService_UpdateAvailabilitySettings_Handler,
+		},
+		{
+			MethodName: "GetAvailabilitySettings",
+			Handler:    _AvailabilityService_GetAvailabilitySettings_Handler,
+		},
+		{
+			MethodName: "SetAvailabilityRules",
+			Handler:    _AvailabilityService_SetAvailabilityRules_Handler,
+		},
+		{
+			MethodName: "CreateTimeOff",
+			Handler:    _AvailabilityService_CreateTimeOff_Handler,
+		},
+		{
+			MethodName: "SetApprovalMode",
+			Handler:    _AvailabilityService_SetApprovalMode_Handler,
+		},
+		{
+			MethodName: "SetDigestPreferences",
+			Handler:    _AvailabilityService_SetDigestPreferences_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/availability.proto",
+}
@@ -19,9 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ClientService_CreateClient_FullMethodName = "/pb.ClientService/CreateClient"
-	ClientService_GetClient_FullMethodName    = "/pb.ClientService/GetClient"
-	ClientService_ListClients_FullMethodName  = "/pb.ClientService/ListClients"
+	ClientService_CreateClient_FullMethodName   = "/pb.ClientService/CreateClient"
+	ClientService_GetClient_FullMethodName      = "/pb.ClientService/GetClient"
+	ClientService_ListClients_FullMethodName    = "/pb.ClientService/ListClients"
+	ClientService_AddDependent_FullMethodName   = "/pb.ClientService/AddDependent"
+	ClientService_GetDependent_FullMethodName   = "/pb.ClientService/GetDependent"
+	ClientService_ListDependents_FullMethodName = "/pb.ClientService/ListDependents"
 )
 
 // ClientServiceClient is the client API for ClientService service.
@@ -31,6 +34,9 @@ type ClientServiceClient interface {
 	CreateClient(ctx context.Context, in *CreateClientRequest, opts ...grpc.CallOption) (*CreateClientResponse, error)
 	GetClient(ctx context.Context, in *GetClientRequest, opts ...grpc.CallOption) (*GetClientResponse, error)
 	ListClients(ctx context.Context, in *ListClientsRequest, opts ...grpc.CallOption) (*ListClientsResponse, error)
+	AddDependent(ctx context.Context, in *AddDependentRequest, opts ...grpc.CallOption) (*AddDependentResponse, error)
+	GetDependent(ctx context.Context, in *GetDependentRequest, opts ...grpc.CallOption) (*GetDependentResponse, error)
+	ListDependents(ctx context.Context, in *ListDependentsRequest, opts ...grpc.CallOption) (*ListDependentsResponse, error)
 }
 
 type clientServiceClient struct {
@@ -71,6 +77,36 @@ func (c *clientServiceClient) ListClients(ctx context.Context, in *ListClientsRe
 	return out, nil
 }
 
+func (c *clientServiceClient) AddDependent(ctx context.Context, in *AddDependentRequest, opts ...grpc.CallOption) (*AddDependentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddDependentResponse)
+	err := c.cc.Invoke(ctx, ClientService_AddDependent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clientServiceClient) GetDependent(ctx context.Context, in *GetDependentRequest, opts ...grpc.CallOption) (*GetDependentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDependentResponse)
+	err := c.cc.Invoke(ctx, ClientService_GetDependent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clientServiceClient) ListDependents(ctx context.Context, in *ListDependentsRequest, opts ...grpc.CallOption) (*ListDependentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDependentsResponse)
+	err := c.cc.Invoke(ctx, ClientService_ListDependents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ClientServiceServer is the server API for ClientService service.
 // All implementations must embed UnimplementedClientServiceServer
 // for forward compatibility.
@@ -78,6 +114,9 @@ type ClientServiceServer interface {
 	CreateClient(context.Context, *CreateClientRequest) (*CreateClientResponse, error)
 	GetClient(context.Context, *GetClientRequest) (*GetClientResponse, error)
 	ListClients(context.Context, *ListClientsRequest) (*ListClientsResponse, error)
+	AddDependent(context.Context, *AddDependentRequest) (*AddDependentResponse, error)
+	GetDependent(context.Context, *GetDependentRequest) (*GetDependentResponse, error)
+	ListDependents(context.Context, *ListDependentsRequest) (*ListDependentsResponse, error)
 	mustEmbedUnimplementedClientServiceServer()
 }
 
@@ -97,6 +136,15 @@ func (UnimplementedClientServiceServer) GetClient(context.Context, *GetClientReq
 func (UnimplementedClientServiceServer) ListClients(context.Context, *ListClientsRequest) (*ListClientsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListClients not implemented")
 }
+func (UnimplementedClientServiceServer) AddDependent(context.Context, *AddDependentRequest) (*AddDependentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddDependent not implemented")
+}
+func (UnimplementedClientServiceServer) GetDependent(context.Context, *GetDependentRequest) (*GetDependentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDependent not implemented")
+}
+func (UnimplementedClientServiceServer) ListDependents(context.Context, *ListDependentsRequest) (*ListDependentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDependents not implemented")
+}
 func (UnimplementedClientServiceServer) mustEmbedUnimplementedClientServiceServer() {}
 func (UnimplementedClientServiceServer) testEmbeddedByValue()                       {}
 
@@ -172,6 +220,60 @@ func _ClientService_ListClients_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClientService_AddDependent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddDependentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientServiceServer).AddDependent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientService_AddDependent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientServiceServer).AddDependent(ctx, req.(*AddDependentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClientService_GetDependent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDependentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientServiceServer).GetDependent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientService_GetDependent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientServiceServer).GetDependent(ctx, req.(*GetDependentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClientService_ListDependents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDependentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientServiceServer).ListDependents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientService_ListDependents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientServiceServer).ListDependents(ctx, req.(*ListDependentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ClientService_ServiceDesc is the grpc.ServiceDesc for ClientService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +293,18 @@ var ClientService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListClients",
 			Handler:    _ClientService_ListClients_Handler,
 		},
+		{
+			MethodName: "AddDependent",
+			Handler:    _ClientService_AddDependent_Handler,
+		},
+		{
+			MethodName: "GetDependent",
+			Handler:    _ClientService_GetDependent_Handler,
+		},
+		{
+			MethodName: "ListDependents",
+			Handler:    _ClientService_ListDependents_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pb/client.proto",
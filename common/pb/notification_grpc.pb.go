@@ -20,6 +20,11 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	NotificationService_SendAppointmentNotification_FullMethodName = "/pb.NotificationService/SendAppointmentNotification"
+	NotificationService_SendReviewRequest_FullMethodName           = "/pb.NotificationService/SendReviewRequest"
+	NotificationService_SendAppointmentUpdate_FullMethodName       = "/pb.NotificationService/SendAppointmentUpdate"
+	NotificationService_SendDailyDigest_FullMethodName             = "/pb.NotificationService/SendDailyDigest"
+	NotificationService_SendPasswordReset_FullMethodName           = "/pb.NotificationService/SendPasswordReset"
+	NotificationService_SendEmailVerification_FullMethodName       = "/pb.NotificationService/SendEmailVerification"
 )
 
 // NotificationServiceClient is the client API for NotificationService service.
@@ -27,6 +32,11 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type NotificationServiceClient interface {
 	SendAppointmentNotification(ctx context.Context, in *SendAppointmentNotificationRequest, opts ...grpc.CallOption) (*SendAppointmentNotificationResponse, error)
+	SendReviewRequest(ctx context.Context, in *SendReviewRequestRequest, opts ...grpc.CallOption) (*SendReviewRequestResponse, error)
+	SendAppointmentUpdate(ctx context.Context, in *SendAppointmentUpdateRequest, opts ...grpc.CallOption) (*SendAppointmentUpdateResponse, error)
+	SendDailyDigest(ctx context.Context, in *SendDailyDigestRequest, opts ...grpc.CallOption) (*SendDailyDigestResponse, error)
+	SendPasswordReset(ctx context.Context, in *SendPasswordResetRequest, opts ...grpc.CallOption) (*SendPasswordResetResponse, error)
+	SendEmailVerification(ctx context.Context, in *SendEmailVerificationRequest, opts ...grpc.CallOption) (*SendEmailVerificationResponse, error)
 }
 
 type notificationServiceClient struct {
@@ -47,11 +57,66 @@ func (c *notificationServiceClient) SendAppointmentNotification(ctx context.Cont
 	return out, nil
 }
 
+func (c *notificationServiceClient) SendReviewRequest(ctx context.Context, in *SendReviewRequestRequest, opts ...grpc.CallOption) (*SendReviewRequestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendReviewRequestResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SendReviewRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SendAppointmentUpdate(ctx context.Context, in *SendAppointmentUpdateRequest, opts ...grpc.CallOption) (*SendAppointmentUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendAppointmentUpdateResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SendAppointmentUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SendDailyDigest(ctx context.Context, in *SendDailyDigestRequest, opts ...grpc.CallOption) (*SendDailyDigestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendDailyDigestResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SendDailyDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SendPasswordReset(ctx context.Context, in *SendPasswordResetRequest, opts ...grpc.CallOption) (*SendPasswordResetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendPasswordResetResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SendPasswordReset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SendEmailVerification(ctx context.Context, in *SendEmailVerificationRequest, opts ...grpc.CallOption) (*SendEmailVerificationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendEmailVerificationResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SendEmailVerification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NotificationServiceServer is the server API for NotificationService service.
 // All implementations must embed UnimplementedNotificationServiceServer
 // for forward compatibility.
 type NotificationServiceServer interface {
 	SendAppointmentNotification(context.Context, *SendAppointmentNotificationRequest) (*SendAppointmentNotificationResponse, error)
+	SendReviewRequest(context.Context, *SendReviewRequestRequest) (*SendReviewRequestResponse, error)
+	SendAppointmentUpdate(context.Context, *SendAppointmentUpdateRequest) (*SendAppointmentUpdateResponse, error)
+	SendDailyDigest(context.Context, *SendDailyDigestRequest) (*SendDailyDigestResponse, error)
+	SendPasswordReset(context.Context, *SendPasswordResetRequest) (*SendPasswordResetResponse, error)
+	SendEmailVerification(context.Context, *SendEmailVerificationRequest) (*SendEmailVerificationResponse, error)
 	mustEmbedUnimplementedNotificationServiceServer()
 }
 
@@ -65,6 +130,21 @@ type UnimplementedNotificationServiceServer struct{}
 func (UnimplementedNotificationServiceServer) SendAppointmentNotification(context.Context, *SendAppointmentNotificationRequest) (*SendAppointmentNotificationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendAppointmentNotification not implemented")
 }
+func (UnimplementedNotificationServiceServer) SendReviewRequest(context.Context, *SendReviewRequestRequest) (*SendReviewRequestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendReviewRequest not implemented")
+}
+func (UnimplementedNotificationServiceServer) SendAppointmentUpdate(context.Context, *SendAppointmentUpdateRequest) (*SendAppointmentUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendAppointmentUpdate not implemented")
+}
+func (UnimplementedNotificationServiceServer) SendDailyDigest(context.Context, *SendDailyDigestRequest) (*SendDailyDigestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendDailyDigest not implemented")
+}
+func (UnimplementedNotificationServiceServer) SendPasswordReset(context.Context, *SendPasswordResetRequest) (*SendPasswordResetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendPasswordReset not implemented")
+}
+func (UnimplementedNotificationServiceServer) SendEmailVerification(context.Context, *SendEmailVerificationRequest) (*SendEmailVerificationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendEmailVerification not implemented")
+}
 func (UnimplementedNotificationServiceServer) mustEmbedUnimplementedNotificationServiceServer() {}
 func (UnimplementedNotificationServiceServer) testEmbeddedByValue()                             {}
 
@@ -104,6 +184,96 @@ func _NotificationService_SendAppointmentNotification_Handler(srv interface{}, c
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NotificationService_SendReviewRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendReviewRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SendReviewRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SendReviewRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SendReviewRequest(ctx, req.(*SendReviewRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SendAppointmentUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendAppointmentUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SendAppointmentUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SendAppointmentUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SendAppointmentUpdate(ctx, req.(*SendAppointmentUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SendDailyDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendDailyDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SendDailyDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SendDailyDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SendDailyDigest(ctx, req.(*SendDailyDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SendPasswordReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendPasswordResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SendPasswordReset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SendPasswordReset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SendPasswordReset(ctx, req.(*SendPasswordResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SendEmailVerification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendEmailVerificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SendEmailVerification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SendEmailVerification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SendEmailVerification(ctx, req.(*SendEmailVerificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // NotificationService_ServiceDesc is the grpc.ServiceDesc for NotificationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +285,26 @@ var NotificationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendAppointmentNotification",
 			Handler:    _NotificationService_SendAppointmentNotification_Handler,
 		},
+		{
+			MethodName: "SendReviewRequest",
+			Handler:    _NotificationService_SendReviewRequest_Handler,
+		},
+		{
+			MethodName: "SendAppointmentUpdate",
+			Handler:    _NotificationService_SendAppointmentUpdate_Handler,
+		},
+		{
+			MethodName: "SendDailyDigest",
+			Handler:    _NotificationService_SendDailyDigest_Handler,
+		},
+		{
+			MethodName: "SendPasswordReset",
+			Handler:    _NotificationService_SendPasswordReset_Handler,
+		},
+		{
+			MethodName: "SendEmailVerification",
+			Handler:    _NotificationService_SendEmailVerification_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pb/notification.proto",
@@ -0,0 +1,281 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: pb/package.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PackageService_CreatePackage_FullMethodName     = "/pb.PackageService/CreatePackage"
+	PackageService_ListPackages_FullMethodName      = "/pb.PackageService/ListPackages"
+	PackageService_PurchasePackage_FullMethodName   = "/pb.PackageService/PurchasePackage"
+	PackageService_GetCreditBalance_FullMethodName  = "/pb.PackageService/GetCreditBalance"
+	PackageService_ListCreditHistory_FullMethodName = "/pb.PackageService/ListCreditHistory"
+)
+
+// PackageServiceClient is the client API for PackageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Packages are bundles of credits for a set of services. Booking one of the
+// services with credits takes one from the client's package that expires
+// first, cancelling within the refund window gives it back.
+type PackageServiceClient interface {
+	CreatePackage(ctx context.Context, in *CreatePackageRequest, opts ...grpc.CallOption) (*CreatePackageResponse, error)
+	ListPackages(ctx context.Context, in *ListPackagesRequest, opts ...grpc.CallOption) (*ListPackagesResponse, error)
+	PurchasePackage(ctx context.Context, in *PurchasePackageRequest, opts ...grpc.CallOption) (*PurchasePackageResponse, error)
+	GetCreditBalance(ctx context.Context, in *GetCreditBalanceRequest, opts ...grpc.CallOption) (*GetCreditBalanceResponse, error)
+	ListCreditHistory(ctx context.Context, in *ListCreditHistoryRequest, opts ...grpc.CallOption) (*ListCreditHistoryResponse, error)
+}
+
+type packageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPackageServiceClient(cc grpc.ClientConnInterface) PackageServiceClient {
+	return &packageServiceClient{cc}
+}
+
+func (c *packageServiceClient) CreatePackage(ctx context.Context, in *CreatePackageRequest, opts ...grpc.CallOption) (*CreatePackageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreatePackageResponse)
+	err := c.cc.Invoke(ctx, PackageService_CreatePackage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageServiceClient) ListPackages(ctx context.Context, in *ListPackagesRequest, opts ...grpc.CallOption) (*ListPackagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPackagesResponse)
+	err := c.cc.Invoke(ctx, PackageService_ListPackages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageServiceClient) PurchasePackage(ctx context.Context, in *PurchasePackageRequest, opts ...grpc.CallOption) (*PurchasePackageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurchasePackageResponse)
+	err := c.cc.Invoke(ctx, PackageService_PurchasePackage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageServiceClient) GetCreditBalance(ctx context.Context, in *GetCreditBalanceRequest, opts ...grpc.CallOption) (*GetCreditBalanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCreditBalanceResponse)
+	err := c.cc.Invoke(ctx, PackageService_GetCreditBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageServiceClient) ListCreditHistory(ctx context.Context, in *ListCreditHistoryRequest, opts ...grpc.CallOption) (*ListCreditHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCreditHistoryResponse)
+	err := c.cc.Invoke(ctx, PackageService_ListCreditHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PackageServiceServer is the server API for PackageService service.
+// All implementations must embed UnimplementedPackageServiceServer
+// for forward compatibility.
+//
+// Packages are bundles of credits for a set of services. Booking one of the
+// services with credits takes one from the client's package that expires
+// first, cancelling within the refund window gives it back.
+type PackageServiceServer interface {
+	CreatePackage(context.Context, *CreatePackageRequest) (*CreatePackageResponse, error)
+	ListPackages(context.Context, *ListPackagesRequest) (*ListPackagesResponse, error)
+	PurchasePackage(context.Context, *PurchasePackageRequest) (*PurchasePackageResponse, error)
+	GetCreditBalance(context.Context, *GetCreditBalanceRequest) (*GetCreditBalanceResponse, error)
+	ListCreditHistory(context.Context, *ListCreditHistoryRequest) (*ListCreditHistoryResponse, error)
+	mustEmbedUnimplementedPackageServiceServer()
+}
+
+// UnimplementedPackageServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPackageServiceServer struct{}
+
+func (UnimplementedPackageServiceServer) CreatePackage(context.Context, *CreatePackageRequest) (*CreatePackageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePackage not implemented")
+}
+func (UnimplementedPackageServiceServer) ListPackages(context.Context, *ListPackagesRequest) (*ListPackagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPackages not implemented")
+}
+func (UnimplementedPackageServiceServer) PurchasePackage(context.Context, *PurchasePackageRequest) (*PurchasePackageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurchasePackage not implemented")
+}
+func (UnimplementedPackageServiceServer) GetCreditBalance(context.Context, *GetCreditBalanceRequest) (*GetCreditBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCreditBalance not implemented")
+}
+func (UnimplementedPackageServiceServer) ListCreditHistory(context.Context, *ListCreditHistoryRequest) (*ListCreditHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCreditHistory not implemented")
+}
+func (UnimplementedPackageServiceServer) mustEmbedUnimplementedPackageServiceServer() {}
+func (UnimplementedPackageServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafePackageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PackageServiceServer will
+// result in compilation errors.
+type UnsafePackageServiceServer interface {
+	mustEmbedUnimplementedPackageServiceServer()
+}
+
+func RegisterPackageServiceServer(s grpc.ServiceRegistrar, srv PackageServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPackageServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PackageService_ServiceDesc, srv)
+}
+
+func _PackageService_CreatePackage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePackageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageServiceServer).CreatePackage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageService_CreatePackage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageServiceServer).CreatePackage(ctx, req.(*CreatePackageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageService_ListPackages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPackagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageServiceServer).ListPackages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageService_ListPackages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageServiceServer).ListPackages(ctx, req.(*ListPackagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageService_PurchasePackage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchasePackageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageServiceServer).PurchasePackage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageService_PurchasePackage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageServiceServer).PurchasePackage(ctx, req.(*PurchasePackageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageService_GetCreditBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCreditBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageServiceServer).GetCreditBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageService_GetCreditBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageServiceServer).GetCreditBalance(ctx, req.(*GetCreditBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageService_ListCreditHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCreditHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageServiceServer).ListCreditHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageService_ListCreditHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageServiceServer).ListCreditHistory(ctx, req.(*ListCreditHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PackageService_ServiceDesc is the grpc.ServiceDesc for PackageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PackageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.PackageService",
+	HandlerType: (*PackageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePackage",
+			Handler:    _PackageService_CreatePackage_Handler,
+		},
+		{
+			MethodName: "ListPackages",
+			Handler:    _PackageService_ListPackages_Handler,
+		},
+		{
+			MethodName: "PurchasePackage",
+			Handler:    _PackageService_PurchasePackage_Handler,
+		},
+		{
+			MethodName: "GetCreditBalance",
+			Handler:    _PackageService_GetCreditBalance_Handler,
+		},
+		{
+			MethodName: "ListCreditHistory",
+			Handler:    _PackageService_ListCreditHistory_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/package.proto",
+}
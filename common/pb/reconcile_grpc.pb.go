@@ -0,0 +1,121 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: pb/reconcile.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReconcileService_Reconcile_FullMethodName = "/pb.ReconcileService/Reconcile"
+)
+
+// ReconcileServiceClient is the client API for ReconcileService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReconcileServiceClient interface {
+	Reconcile(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*ReconcileResponse, error)
+}
+
+type reconcileServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReconcileServiceClient(cc grpc.ClientConnInterface) ReconcileServiceClient {
+	return &reconcileServiceClient{cc}
+}
+
+func (c *reconcileServiceClient) Reconcile(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*ReconcileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconcileResponse)
+	err := c.cc.Invoke(ctx, ReconcileService_Reconcile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReconcileServiceServer is the server API for ReconcileService service.
+// All implementations must embed UnimplementedReconcileServiceServer
+// for forward compatibility.
+type ReconcileServiceServer interface {
+	Reconcile(context.Context, *ReconcileRequest) (*ReconcileResponse, error)
+	mustEmbedUnimplementedReconcileServiceServer()
+}
+
+// UnimplementedReconcileServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReconcileServiceServer struct{}
+
+func (UnimplementedReconcileServiceServer) Reconcile(context.Context, *ReconcileRequest) (*ReconcileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reconcile not implemented")
+}
+func (UnimplementedReconcileServiceServer) mustEmbedUnimplementedReconcileServiceServer() {}
+func (UnimplementedReconcileServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeReconcileServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReconcileServiceServer will
+// result in compilation errors.
+type UnsafeReconcileServiceServer interface {
+	mustEmbedUnimplementedReconcileServiceServer()
+}
+
+func RegisterReconcileServiceServer(s grpc.ServiceRegistrar, srv ReconcileServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReconcileServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReconcileService_ServiceDesc, srv)
+}
+
+func _ReconcileService_Reconcile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReconcileServiceServer).Reconcile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReconcileService_Reconcile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReconcileServiceServer).Reconcile(ctx, req.(*ReconcileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReconcileService_ServiceDesc is the grpc.ServiceDesc for ReconcileService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReconcileService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.ReconcileService",
+	HandlerType: (*ReconcileServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Reconcile",
+			Handler:    _ReconcileService_Reconcile_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/reconcile.proto",
+}
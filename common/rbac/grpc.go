@@ -0,0 +1,153 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serviceTokenTTL is how long a service token lasts, it's signed again a
+// minute before it expires.
+const serviceTokenTTL = 15 * time.Minute
+
+// UnaryServerInterceptor checks every call against the permissions, using the
+// token sent in the "authorization" metadata, and leaves the caller's claims
+// in the context for the handlers.
+func UnaryServerInterceptor(validator TokenValidator, permissions Permissions) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, validator, permissions, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming methods, the
+// token is checked once when the stream opens.
+func StreamServerInterceptor(validator TokenValidator, permissions Permissions) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), validator, permissions, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &claimsStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// claimsStream hands the context with the claims to the stream handler.
+type claimsStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *claimsStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize validates the caller's token, if any, and checks it may call the
+// method. It returns ctx carrying the claims.
+func authorize(ctx context.Context, validator TokenValidator, permissions Permissions, method string) (context.Context, error) {
+	var claims *Claims
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		token, ok := BearerToken(md.Get("authorization")[0])
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid token format")
+		}
+		var err error
+		claims, err = validator.Validate(ctx, token)
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "can't validate token")
+		}
+	}
+
+	if !permissions.Allowed(method, claims) {
+		if claims == nil {
+			return nil, status.Error(codes.Unauthenticated, "auth token needed")
+		}
+		return nil, status.Error(codes.PermissionDenied, "not allowed")
+	}
+	if claims != nil {
+		ctx = NewContext(ctx, claims)
+	}
+	return ctx, nil
+}
+
+// ServiceCredentials signs the tokens a service uses to call the others. Used
+// as grpc.WithPerRPCCredentials they go with every call of the connection.
+type ServiceCredentials struct {
+	secret []byte
+	name   string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewServiceCredentials(secret, name string) *ServiceCredentials {
+	return &ServiceCredentials{secret: []byte(secret), name: name}
+}
+
+// Token returns the current service token, signing a new one when it's about
+// to expire.
+func (c *ServiceCredentials) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.token != "" && now.Add(time.Minute).Before(c.expiresAt) {
+		return c.token, nil
+	}
+	expiresAt := now.Add(serviceTokenTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Roles: []string{RoleService},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   c.name,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(c.secret)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// Context returns a copy of ctx that sends the service token on the calls
+// made with it.
+func (c *ServiceCredentials) Context(ctx context.Context) (context.Context, error) {
+	token, err := c.Token()
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), nil
+}
+
+func (c *ServiceCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := c.Token()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity is false, the services talk over the private
+// network without TLS.
+func (c *ServiceCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// ErrNotAllowed is returned by the services when the caller may call the
+// method but not on the records asked for, ie: another client's appointments.
+var ErrNotAllowed = status.Error(codes.PermissionDenied, "not allowed")
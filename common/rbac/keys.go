@@ -0,0 +1,191 @@
+package rbac
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+// DefaultSecret is the development fallback of JWT_SECRET, it's in the repo so
+// it must never sign anything real.
+const DefaultSecret = "please-dont-use-this-key-12345"
+
+// minKeyRefresh limits how often an unknown kid fetches the keys again, a
+// flood of forged tokens can't turn into a flood of calls to auth.
+const minKeyRefresh = 10 * time.Second
+
+// CheckSecret refuses the default secret unless running in development mode.
+func CheckSecret(secret string, devMode bool) error {
+	if secret == DefaultSecret && !devMode {
+		return errors.New("JWT_SECRET is the default one, set it or DEV_MODE=true")
+	}
+	return nil
+}
+
+// JWK is an Ed25519 public key as published in the JWKS.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK describes the public key for the JWKS.
+func NewJWK(kid string, key ed25519.PublicKey) JWK {
+	return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(key), Kid: kid, Use: "sig", Alg: "EdDSA"}
+}
+
+// KeyFetcher gets the current JWKS.
+type KeyFetcher func(ctx context.Context) (*JWKS, error)
+
+// FetchJWKS gets the JWKS from the auth service.
+func FetchJWKS(client pb.AuthServiceClient) KeyFetcher {
+	return func(ctx context.Context) (*JWKS, error) {
+		resp, err := client.GetJWKS(ctx, &pb.GetJWKSRequest{})
+		if err != nil {
+			return nil, err
+		}
+		jwks := &JWKS{Keys: make([]JWK, len(resp.Keys))}
+		for i, key := range resp.Keys {
+			jwks.Keys[i] = JWK{Kty: key.Kty, Crv: key.Crv, X: key.X, Kid: key.Kid, Use: key.Use, Alg: key.Alg}
+		}
+		return jwks, nil
+	}
+}
+
+// KeySet verifies the tokens. The users' ones are signed by the auth service
+// with Ed25519 keys picked by their kid, the public keys are fetched and
+// cached. The service tokens are signed with the shared secret, which can't
+// sign anything else: an HS256 token with a user is refused.
+type KeySet struct {
+	secret []byte
+	fetch  KeyFetcher
+
+	mu        sync.RWMutex
+	keys      map[string]ed25519.PublicKey
+	jwks      JWKS
+	fetchedAt time.Time
+}
+
+func NewKeySet(secret string, fetch KeyFetcher) *KeySet {
+	return &KeySet{secret: []byte(secret), fetch: fetch, keys: map[string]ed25519.PublicKey{}}
+}
+
+// Refresh fetches the keys again.
+func (k *KeySet) Refresh(ctx context.Context) error {
+	k.mu.Lock()
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+
+	jwks, err := k.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	return k.Replace(*jwks)
+}
+
+// Replace sets the keys of the JWKS as the trusted ones.
+func (k *KeySet) Replace(jwks JWKS) error {
+	keys := make(map[string]ed25519.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+			continue
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid key %q", jwk.Kid)
+		}
+		keys[jwk.Kid] = ed25519.PublicKey(x)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys, k.jwks = keys, jwks
+	return nil
+}
+
+// JWKS returns the keys trusted now.
+func (k *KeySet) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.jwks
+}
+
+// Sync refreshes the keys every interval, forever. The auth service publishes
+// its next key ahead of using it, so it's known before the first token comes.
+func (k *KeySet) Sync(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := k.Refresh(ctx); err != nil {
+			log.Printf("Error fetching the signing keys: %v", err)
+		}
+		cancel()
+	}
+}
+
+// ParseToken validates an access token and returns its claims.
+func (k *KeySet) ParseToken(tokenString string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, k.keyFor,
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+func (k *KeySet) keyFor(token *jwt.Token) (interface{}, error) {
+	if token.Method == jwt.SigningMethodHS256 {
+		claims := token.Claims.(*Claims)
+		if claims.UserID != 0 || !slices.Equal(claims.Roles, []string{RoleService}) {
+			return nil, ErrInvalidToken
+		}
+		return k.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := k.publicKey(kid); ok {
+		return key, nil
+	}
+	// Puede ser una llave nueva que aún no se conoce
+	k.mu.RLock()
+	recent := time.Since(k.fetchedAt) < minKeyRefresh
+	k.mu.RUnlock()
+	if !recent {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := k.Refresh(ctx); err != nil {
+			log.Printf("Error fetching the signing keys: %v", err)
+		}
+	}
+	if key, ok := k.publicKey(kid); ok {
+		return key, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+func (k *KeySet) publicKey(kid string) (ed25519.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	return key, ok
+}
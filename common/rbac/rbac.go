@@ -0,0 +1,125 @@
+// Package rbac holds the roles carried in the access tokens and the checks
+// shared by the gateway and the services: every endpoint declares the roles
+// allowed to call it and anything not declared is denied.
+package rbac
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	RoleAdmin        = "admin"
+	RoleStaff        = "staff"
+	RoleProfessional = "professional"
+	RoleClient       = "client"
+	// RoleService is carried by the tokens the services sign for calling each
+	// other, it's never given to a user
+	RoleService = "service"
+
+	// Public lets in any caller, even without a token, ie: login
+	Public = "public"
+	// Authenticated lets in any valid token whatever its roles
+	Authenticated = "authenticated"
+)
+
+// UserRoles are the roles a user can be given.
+var UserRoles = []string{RoleAdmin, RoleStaff, RoleProfessional, RoleClient}
+
+var ErrInvalidToken = errors.New("invalid_token")
+
+type Claims struct {
+	UserID uint     `json:"user_id"`
+	Roles  []string `json:"roles"`
+	// ClientID and ProfessionalID are the records linked to the user, 0 when
+	// there's none
+	ClientID       uint `json:"client_id"`
+	ProfessionalID uint `json:"professional_id"`
+	jwt.RegisteredClaims
+}
+
+// HasRole reports whether the claims carry any of the roles.
+func (c *Claims) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(c.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActsForAnyone reports whether the caller can act on any record, not only
+// the ones linked to its account: admins, staff and the other services.
+func (c *Claims) ActsForAnyone() bool {
+	return c.HasRole(RoleAdmin, RoleStaff, RoleService)
+}
+
+// OwnsClient reports whether the caller can act on the client's records.
+func (c *Claims) OwnsClient(clientID uint) bool {
+	if c.ActsForAnyone() {
+		return true
+	}
+	return clientID != 0 && c.HasRole(RoleClient) && c.ClientID == clientID
+}
+
+// OwnsProfessional reports whether the caller can act on the professional's
+// records.
+func (c *Claims) OwnsProfessional(professionalID uint) bool {
+	if c.ActsForAnyone() {
+		return true
+	}
+	return professionalID != 0 && c.HasRole(RoleProfessional) && c.ProfessionalID == professionalID
+}
+
+// Permissions maps an endpoint, a gateway route pattern or a full gRPC
+// method, to the roles allowed to call it. Admins and services can call every
+// endpoint listed.
+type Permissions map[string][]string
+
+// Public reports whether the endpoint can be called without a token.
+func (p Permissions) Public(endpoint string) bool {
+	return slices.Contains(p[endpoint], Public)
+}
+
+// Allowed reports whether a caller with the claims can call the endpoint, nil
+// claims being an anonymous caller.
+func (p Permissions) Allowed(endpoint string, claims *Claims) bool {
+	allowed, ok := p[endpoint]
+	if !ok {
+		return false
+	}
+	if slices.Contains(allowed, Public) {
+		return true
+	}
+	if claims == nil {
+		return false
+	}
+	if slices.Contains(allowed, Authenticated) {
+		return true
+	}
+	return claims.HasRole(RoleAdmin, RoleService) || claims.HasRole(allowed...)
+}
+
+// BearerToken extracts the token of an "Authorization: Bearer <token>" value.
+func BearerToken(header string) (string, bool) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	return token, ok && token != ""
+}
+
+type claimsKey struct{}
+
+// NewContext returns a copy of ctx carrying the caller's claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims of the caller, nil when the call was made
+// without a token.
+func FromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*Claims)
+	return claims
+}
@@ -0,0 +1,151 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+)
+
+const (
+	// ValidationLocal checks the tokens with the published keys
+	ValidationLocal = "local"
+	// ValidationRemote asks the auth service, which also knows the revoked
+	// tokens
+	ValidationRemote = "remote"
+
+	// DefaultTokenCacheTTL is how long the remote validator trusts an answer
+	DefaultTokenCacheTTL = 30 * time.Second
+	// maxCachedTokens is when the remote validator drops the expired answers
+	maxCachedTokens = 10000
+)
+
+// TokenValidator checks a bearer token and returns its claims. An invalid
+// token gives ErrInvalidToken, any other error means it couldn't be checked.
+type TokenValidator interface {
+	Validate(ctx context.Context, token string) (*Claims, error)
+}
+
+// NewValidator returns the validator for the mode, the auth client is only
+// used by the remote one.
+func NewValidator(mode string, keys *KeySet, auth pb.AuthServiceClient) (TokenValidator, error) {
+	switch mode {
+	case ValidationLocal:
+		return NewLocalValidator(keys), nil
+	case ValidationRemote:
+		return NewRemoteValidator(auth, DefaultTokenCacheTTL), nil
+	}
+	return nil, fmt.Errorf("unknown token validation %q", mode)
+}
+
+// LocalValidator checks the signature with the auth service's published keys.
+// It doesn't know about revocations, a token lasts until it expires.
+type LocalValidator struct {
+	keys *KeySet
+}
+
+func NewLocalValidator(keys *KeySet) *LocalValidator {
+	return &LocalValidator{keys: keys}
+}
+
+func (v *LocalValidator) Validate(ctx context.Context, token string) (*Claims, error) {
+	return v.keys.ParseToken(token)
+}
+
+// RemoteValidator asks the auth service about every token it hasn't seen in
+// the last ttl, so a revoked token can still pass for that long.
+type RemoteValidator struct {
+	client pb.AuthServiceClient
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedClaims
+}
+
+type cachedClaims struct {
+	claims *Claims
+	until  time.Time
+}
+
+func NewRemoteValidator(client pb.AuthServiceClient, ttl time.Duration) *RemoteValidator {
+	return &RemoteValidator{client: client, ttl: ttl, cache: map[string]cachedClaims{}}
+}
+
+func (v *RemoteValidator) Validate(ctx context.Context, token string) (*Claims, error) {
+	now := time.Now()
+	v.mu.Lock()
+	entry, ok := v.cache[token]
+	v.mu.Unlock()
+	if ok && now.Before(entry.until) {
+		return entry.claims, nil
+	}
+
+	resp, err := v.client.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Valid {
+		return nil, ErrInvalidToken
+	}
+	claims := ClaimsFromPb(resp.Claims)
+
+	until := now.Add(v.ttl)
+	if expiresAt := time.Unix(resp.Claims.ExpiresAt, 0); expiresAt.Before(until) {
+		until = expiresAt
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.cache) >= maxCachedTokens {
+		for key, entry := range v.cache {
+			if !now.Before(entry.until) {
+				delete(v.cache, key)
+			}
+		}
+	}
+	v.cache[token] = cachedClaims{claims: claims, until: until}
+	return claims, nil
+}
+
+// ClaimsToPb converts the claims for the ValidateToken and Introspect
+// responses.
+func ClaimsToPb(c *Claims) *pb.TokenClaims {
+	claims := &pb.TokenClaims{
+		UserId:         uint32(c.UserID),
+		Roles:          c.Roles,
+		ClientId:       uint32(c.ClientID),
+		ProfessionalId: uint32(c.ProfessionalID),
+		Subject:        c.Subject,
+		Jti:            c.ID,
+	}
+	if c.IssuedAt != nil {
+		claims.IssuedAt = c.IssuedAt.Unix()
+	}
+	if c.ExpiresAt != nil {
+		claims.ExpiresAt = c.ExpiresAt.Unix()
+	}
+	return claims
+}
+
+// ClaimsFromPb is the inverse of ClaimsToPb.
+func ClaimsFromPb(c *pb.TokenClaims) *Claims {
+	claims := &Claims{
+		UserID:         uint(c.UserId),
+		Roles:          c.Roles,
+		ClientID:       uint(c.ClientId),
+		ProfessionalID: uint(c.ProfessionalId),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: c.Subject,
+			ID:      c.Jti,
+		},
+	}
+	if c.IssuedAt != 0 {
+		claims.IssuedAt = jwt.NewNumericDate(time.Unix(c.IssuedAt, 0))
+	}
+	if c.ExpiresAt != 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(c.ExpiresAt, 0))
+	}
+	return claims
+}
@@ -0,0 +1,148 @@
+package unit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "test-secret-key"
+
+// testSigner is a signing key of the auth service as the KeySet sees it.
+type testSigner struct {
+	kid     string
+	private ed25519.PrivateKey
+}
+
+func newTestSigner(t *testing.T, kid string) testSigner {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return testSigner{kid: kid, private: private}
+}
+
+func (s testSigner) jwk() rbac.JWK {
+	return rbac.NewJWK(s.kid, s.private.Public().(ed25519.PublicKey))
+}
+
+func (s testSigner) sign(t *testing.T, claims rbac.Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.private)
+	assert.NoError(t, err)
+	return signed
+}
+
+func userClaims(expiresAt time.Time, roles ...string) rbac.Claims {
+	return rbac.Claims{
+		UserID: 1,
+		Roles:  roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+}
+
+// newTestKeySet trusts the signers and testSecret.
+func newTestKeySet(t *testing.T, signers ...testSigner) *rbac.KeySet {
+	jwks := &rbac.JWKS{}
+	for _, signer := range signers {
+		jwks.Keys = append(jwks.Keys, signer.jwk())
+	}
+	keys := rbac.NewKeySet(testSecret, func(ctx context.Context) (*rbac.JWKS, error) {
+		return jwks, nil
+	})
+	assert.NoError(t, keys.Refresh(context.Background()))
+	return keys
+}
+
+func TestParseToken(t *testing.T) {
+	signer := newTestSigner(t, "current")
+	other := newTestSigner(t, "other")
+	forged := testSigner{kid: "current", private: other.private}
+	future := time.Now().Add(time.Minute)
+
+	serviceToken, err := rbac.NewServiceCredentials(testSecret, "agenda").Token()
+	assert.NoError(t, err)
+	// El secreto compartido solo firma tokens de servicio
+	userTokenWithSecret, err := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims(future, rbac.RoleAdmin)).
+		SignedString([]byte(testSecret))
+	assert.NoError(t, err)
+	serviceTokenWithOtherSecret, err := rbac.NewServiceCredentials("other-secret", "agenda").Token()
+	assert.NoError(t, err)
+	noExpiry := signer.sign(t, rbac.Claims{UserID: 1, Roles: []string{rbac.RoleClient}})
+
+	tests := []struct {
+		name          string
+		token         string
+		expectedRoles []string
+		expectedErr   error
+	}{
+		{name: "Valid", token: signer.sign(t, userClaims(future, rbac.RoleClient)), expectedRoles: []string{rbac.RoleClient}},
+		{name: "Expired", token: signer.sign(t, userClaims(time.Now().Add(-time.Minute), rbac.RoleClient)), expectedErr: rbac.ErrInvalidToken},
+		{name: "NoExpiry", token: noExpiry, expectedErr: rbac.ErrInvalidToken},
+		{name: "UnknownKid", token: other.sign(t, userClaims(future, rbac.RoleAdmin)), expectedErr: rbac.ErrInvalidToken},
+		{name: "WrongKeyForKid", token: forged.sign(t, userClaims(future, rbac.RoleAdmin)), expectedErr: rbac.ErrInvalidToken},
+		{name: "ServiceToken", token: serviceToken, expectedRoles: []string{rbac.RoleService}},
+		{name: "ServiceTokenOtherSecret", token: serviceTokenWithOtherSecret, expectedErr: rbac.ErrInvalidToken},
+		{name: "UserTokenWithSecret", token: userTokenWithSecret, expectedErr: rbac.ErrInvalidToken},
+		{name: "Garbage", token: "not-a-token", expectedErr: rbac.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := newTestKeySet(t, signer)
+			claims, err := keys.ParseToken(tt.token)
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr == nil {
+				assert.Equal(t, tt.expectedRoles, claims.Roles)
+			}
+		})
+	}
+}
+
+func TestParseTokenNewKey(t *testing.T) {
+	current := newTestSigner(t, "current")
+	next := newTestSigner(t, "next")
+	jwks := &rbac.JWKS{Keys: []rbac.JWK{current.jwk()}}
+	fetches := 0
+	keys := rbac.NewKeySet(testSecret, func(ctx context.Context) (*rbac.JWKS, error) {
+		fetches++
+		return jwks, nil
+	})
+	assert.NoError(t, keys.Refresh(context.Background()))
+
+	// Un kid desconocido no vuelve a pedir las llaves hasta pasado minKeyRefresh
+	jwks.Keys = append(jwks.Keys, next.jwk())
+	_, err := keys.ParseToken(next.sign(t, userClaims(time.Now().Add(time.Minute), rbac.RoleClient)))
+	assert.Equal(t, rbac.ErrInvalidToken, err)
+	assert.Equal(t, 1, fetches)
+
+	// Una vez conocida se acepta
+	assert.NoError(t, keys.Refresh(context.Background()))
+	claims, err := keys.ParseToken(next.sign(t, userClaims(time.Now().Add(time.Minute), rbac.RoleClient)))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+}
+
+func TestKeySetReplace(t *testing.T) {
+	signer := newTestSigner(t, "current")
+	keys := newTestKeySet(t)
+
+	assert.Error(t, keys.Replace(rbac.JWKS{Keys: []rbac.JWK{{Kty: "OKP", Crv: "Ed25519", X: "short", Kid: "bad"}}}))
+	// Las llaves que no son Ed25519 se ignoran
+	assert.NoError(t, keys.Replace(rbac.JWKS{Keys: []rbac.JWK{{Kty: "RSA", Kid: "rsa"}, signer.jwk()}}))
+	_, err := keys.ParseToken(signer.sign(t, userClaims(time.Now().Add(time.Minute), rbac.RoleClient)))
+	assert.NoError(t, err)
+}
+
+func TestCheckSecret(t *testing.T) {
+	assert.Error(t, rbac.CheckSecret(rbac.DefaultSecret, false))
+	assert.NoError(t, rbac.CheckSecret(rbac.DefaultSecret, true))
+	assert.NoError(t, rbac.CheckSecret("other-secret", false))
+}
@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testPermissions = rbac.Permissions{
+	"/pb.Test/Public":        {rbac.Public},
+	"/pb.Test/Authenticated": {rbac.Authenticated},
+	"/pb.Test/Staff":         {rbac.RoleStaff},
+	"/pb.Test/Service":       {rbac.RoleService},
+}
+
+func TestPermissionsAllowed(t *testing.T) {
+	client := &rbac.Claims{UserID: 1, Roles: []string{rbac.RoleClient}}
+	staff := &rbac.Claims{UserID: 2, Roles: []string{rbac.RoleStaff}}
+	admin := &rbac.Claims{UserID: 3, Roles: []string{rbac.RoleAdmin}}
+	service := &rbac.Claims{Roles: []string{rbac.RoleService}}
+	noRoles := &rbac.Claims{UserID: 4}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		claims   *rbac.Claims
+		allowed  bool
+	}{
+		{name: "PublicAnonymous", endpoint: "/pb.Test/Public", allowed: true},
+		{name: "AuthenticatedAnonymous", endpoint: "/pb.Test/Authenticated"},
+		{name: "AuthenticatedWithoutRoles", endpoint: "/pb.Test/Authenticated", claims: noRoles, allowed: true},
+		{name: "RoleMissing", endpoint: "/pb.Test/Staff", claims: client},
+		{name: "RoleMatches", endpoint: "/pb.Test/Staff", claims: staff, allowed: true},
+		{name: "AdminAnyListed", endpoint: "/pb.Test/Staff", claims: admin, allowed: true},
+		{name: "ServiceAnyListed", endpoint: "/pb.Test/Staff", claims: service, allowed: true},
+		{name: "ServiceOnlyAsStaff", endpoint: "/pb.Test/Service", claims: staff},
+		{name: "NotListed", endpoint: "/pb.Test/Drop", claims: admin},
+		{name: "NotListedService", endpoint: "/pb.Test/Drop", claims: service},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, testPermissions.Allowed(tt.endpoint, tt.claims))
+		})
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	client := &rbac.Claims{UserID: 1, Roles: []string{rbac.RoleClient}, ClientID: 4}
+	unlinked := &rbac.Claims{UserID: 2, Roles: []string{rbac.RoleClient}}
+	professional := &rbac.Claims{UserID: 3, Roles: []string{rbac.RoleProfessional}, ProfessionalID: 7}
+	staff := &rbac.Claims{UserID: 5, Roles: []string{rbac.RoleStaff}}
+
+	assert.True(t, client.OwnsClient(4))
+	assert.False(t, client.OwnsClient(5))
+	assert.False(t, unlinked.OwnsClient(0))
+	assert.False(t, client.OwnsProfessional(7))
+	assert.True(t, professional.OwnsProfessional(7))
+	assert.False(t, professional.OwnsProfessional(8))
+	assert.False(t, professional.OwnsClient(4))
+	assert.True(t, staff.OwnsClient(4))
+	assert.True(t, staff.OwnsProfessional(7))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	signer := newTestSigner(t, "current")
+	interceptor := rbac.UnaryServerInterceptor(rbac.NewLocalValidator(newTestKeySet(t, signer)), testPermissions)
+	future := time.Now().Add(time.Minute)
+	serviceToken, err := rbac.NewServiceCredentials(testSecret, "gateway").Token()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		expectedCode  codes.Code
+		expectedRoles []string
+	}{
+		{name: "PublicWithoutToken", method: "/pb.Test/Public", expectedCode: codes.OK},
+		{name: "StaffWithoutToken", method: "/pb.Test/Staff", expectedCode: codes.Unauthenticated},
+		{name: "BadFormat", method: "/pb.Test/Public", authorization: "Token abc", expectedCode: codes.Unauthenticated},
+		{name: "InvalidTokenOnPublic", method: "/pb.Test/Public", authorization: "Bearer abc", expectedCode: codes.Unauthenticated},
+		{
+			name:          "Expired",
+			method:        "/pb.Test/Staff",
+			authorization: "Bearer " + signer.sign(t, userClaims(time.Now().Add(-time.Minute), rbac.RoleStaff)),
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "WrongKid",
+			method:        "/pb.Test/Staff",
+			authorization: "Bearer " + newTestSigner(t, "other").sign(t, userClaims(future, rbac.RoleStaff)),
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "Denied",
+			method:        "/pb.Test/Staff",
+			authorization: "Bearer " + signer.sign(t, userClaims(future, rbac.RoleClient)),
+			expectedCode:  codes.PermissionDenied,
+		},
+		{
+			name:          "Allowed",
+			method:        "/pb.Test/Staff",
+			authorization: "Bearer " + signer.sign(t, userClaims(future, rbac.RoleStaff)),
+			expectedCode:  codes.OK,
+			expectedRoles: []string{rbac.RoleStaff},
+		},
+		{
+			name:          "ServiceCredentials",
+			method:        "/pb.Test/Service",
+			authorization: "Bearer " + serviceToken,
+			expectedCode:  codes.OK,
+			expectedRoles: []string{rbac.RoleService},
+		},
+		{
+			name:          "UnknownMethod",
+			method:        "/pb.Test/Drop",
+			authorization: "Bearer " + serviceToken,
+			expectedCode:  codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+			var handlerClaims *rbac.Claims
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				handlerClaims = rbac.FromContext(ctx)
+				return "ok", nil
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedRoles != nil {
+				assert.Equal(t, tt.expectedRoles, handlerClaims.Roles)
+			}
+		})
+	}
+}
+
+func TestServiceCredentials(t *testing.T) {
+	creds := rbac.NewServiceCredentials(testSecret, "agenda")
+	token, err := creds.Token()
+	assert.NoError(t, err)
+	// Se reutiliza hasta un minuto antes de expirar
+	again, err := creds.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer "+token, md["authorization"])
+
+	claims, err := newTestKeySet(t).ParseToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "agenda", claims.Subject)
+	assert.Equal(t, uint(0), claims.UserID)
+	assert.Equal(t, []string{rbac.RoleService}, claims.Roles)
+}
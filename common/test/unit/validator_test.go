@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+// MockAuthServiceClient only implements ValidateToken, the validators don't
+// call anything else.
+type MockAuthServiceClient struct {
+	pb.AuthServiceClient
+	mock.Mock
+}
+
+func (m *MockAuthServiceClient) ValidateToken(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
+	args := m.Called(in.Token)
+	return args.Get(0).(*pb.ValidateTokenResponse), args.Error(1)
+}
+
+func TestRemoteValidator(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	valid := &pb.ValidateTokenResponse{
+		Valid:  true,
+		Claims: &pb.TokenClaims{UserId: 1, Roles: []string{rbac.RoleClient}, ClientId: 4, Jti: "jti", ExpiresAt: expiresAt},
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockAuthServiceClient)
+		calls       int
+		expectedErr error
+	}{
+		{
+			name: "ValidCached",
+			mockSetup: func(auth *MockAuthServiceClient) {
+				auth.On("ValidateToken", "token").Return(valid, nil).Once()
+			},
+			calls: 2,
+		},
+		{
+			name: "Revoked",
+			mockSetup: func(auth *MockAuthServiceClient) {
+				auth.On("ValidateToken", "token").Return(&pb.ValidateTokenResponse{Valid: false}, nil).Once()
+			},
+			calls:       1,
+			expectedErr: rbac.ErrInvalidToken,
+		},
+		{
+			name: "AuthDown",
+			mockSetup: func(auth *MockAuthServiceClient) {
+				// Un error no se guarda, la siguiente vez se pregunta de nuevo
+				auth.On("ValidateToken", "token").Return((*pb.ValidateTokenResponse)(nil), errors.New("unavailable")).Twice()
+			},
+			calls:       2,
+			expectedErr: errors.New("unavailable"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := new(MockAuthServiceClient)
+			tt.mockSetup(auth)
+			validator := rbac.NewRemoteValidator(auth, time.Minute)
+
+			for range tt.calls {
+				claims, err := validator.Validate(context.Background(), "token")
+				assert.Equal(t, tt.expectedErr, err)
+				if tt.expectedErr == nil {
+					assert.Equal(t, uint(1), claims.UserID)
+					assert.Equal(t, uint(4), claims.ClientID)
+					assert.Equal(t, "jti", claims.ID)
+				}
+			}
+			auth.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoteValidatorExpiredCache(t *testing.T) {
+	auth := new(MockAuthServiceClient)
+	// El token expira antes que el caché, no se guarda más allá
+	auth.On("ValidateToken", "token").Return(&pb.ValidateTokenResponse{
+		Valid:  true,
+		Claims: &pb.TokenClaims{UserId: 1, ExpiresAt: time.Now().Add(-time.Second).Unix()},
+	}, nil).Twice()
+	validator := rbac.NewRemoteValidator(auth, time.Minute)
+
+	for range 2 {
+		_, err := validator.Validate(context.Background(), "token")
+		assert.NoError(t, err)
+	}
+	auth.AssertExpectations(t)
+}
+
+func TestNewValidator(t *testing.T) {
+	keys := newTestKeySet(t)
+
+	validator, err := rbac.NewValidator(rbac.ValidationLocal, keys, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &rbac.LocalValidator{}, validator)
+
+	validator, err = rbac.NewValidator(rbac.ValidationRemote, keys, new(MockAuthServiceClient))
+	assert.NoError(t, err)
+	assert.IsType(t, &rbac.RemoteValidator{}, validator)
+
+	_, err = rbac.NewValidator("other", keys, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/actionlink"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc"
+)
+
+// ActionHandler serves the self-service links sent to clients by email. The
+// clients have no account, so the signed token in the link is what
+// authenticates them.
+type ActionHandler struct {
+	Client      pb.AgendaServiceClient
+	Secret      []byte
+	Credentials *rbac.ServiceCredentials
+}
+
+func NewActionHandler(conn *grpc.ClientConn, linkSecret string, creds *rbac.ServiceCredentials) *ActionHandler {
+	return &ActionHandler{Client: pb.NewAgendaServiceClient(conn), Secret: []byte(linkSecret), Credentials: creds}
+}
+
+func (h *ActionHandler) RegisterActionRoutes(mux *http.ServeMux) {
+	// GET solo muestra la cita: los clientes de correo abren los enlaces por
+	// adelantado y no deben cancelar nada
+	mux.HandleFunc("GET /api/actions/{action}", h.ShowActionHandler)
+	mux.HandleFunc("POST /api/actions/{action}", h.ApplyActionHandler)
+}
+
+// ShowActionHandler returns the appointment a link refers to. For reschedule
+// links a date may be given to list the professional's free slots that day.
+func (h *ActionHandler) ShowActionHandler(w http.ResponseWriter, r *http.Request) {
+	action := r.PathValue("action")
+	claims, err := actionlink.Verify(h.Secret, r.URL.Query().Get("token"), action)
+	if err != nil {
+		http.Error(w, "Invalid or expired link", http.StatusUnauthorized)
+		return
+	}
+
+	// Quien abre el enlace no tiene cuenta, el gateway llama a la agenda en su nombre
+	ctx, err := h.Credentials.Context(r.Context())
+	if err != nil {
+		http.Error(w, "Error signing service token", http.StatusInternalServerError)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetAppointment(ctx, &pb.GetAppointmentRequest{Id: claims.AppointmentID})
+	if err != nil {
+		http.Error(w, "Error getting appointment", http.StatusInternalServerError)
+		return
+	}
+
+	result := map[string]interface{}{
+		"action":      action,
+		"appointment": resp.Appointment,
+		"success":     resp.Success,
+	}
+	if date := r.URL.Query().Get("date"); action == actionlink.ActionReschedule && date != "" {
+		slotsResp, err := h.Client.ListAvailableSlots(ctx, &pb.ListAvailableSlotsRequest{
+			ProfessionalId: resp.Appointment.ProfessionalId,
+			Date:           date,
+			ServiceId:      resp.Appointment.ServiceId,
+		})
+		if err != nil {
+			http.Error(w, "Error listing available slots", http.StatusInternalServerError)
+			return
+		}
+		result["slots"] = slotsResp.Slots
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ApplyActionHandler verifies the link token and applies its action. The link
+// ID goes along so the agenda accepts each link only once.
+func (h *ActionHandler) ApplyActionHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.ActionRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+	action := r.PathValue("action")
+	claims, err := actionlink.Verify(h.Secret, req.Token, action)
+	if err != nil {
+		http.Error(w, "Invalid or expired link", http.StatusUnauthorized)
+		return
+	}
+
+	// Quien abre el enlace no tiene cuenta, el gateway llama a la agenda en su nombre
+	ctx, err := h.Credentials.Context(r.Context())
+	if err != nil {
+		http.Error(w, "Error signing service token", http.StatusInternalServerError)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var result map[string]interface{}
+	switch action {
+	case actionlink.ActionConfirm:
+		resp, err := h.Client.ConfirmAppointment(ctx, &pb.ConfirmAppointmentRequest{
+			AppointmentId: claims.AppointmentID,
+			LinkId:        claims.ID,
+		})
+		if err != nil {
+			http.Error(w, "Error confirming appointment", http.StatusInternalServerError)
+			return
+		}
+		result = map[string]interface{}{"message": resp.Message, "success": resp.Success}
+	case actionlink.ActionCancel:
+		resp, err := h.Client.CancelAppointment(ctx, &pb.CancelAppointmentRequest{
+			AppointmentId: claims.AppointmentID,
+			LinkId:        claims.ID,
+		})
+		if err != nil {
+			http.Error(w, "Error cancelling appointment", http.StatusInternalServerError)
+			return
+		}
+		result = map[string]interface{}{
+			"message":        resp.Message,
+			"success":        resp.Success,
+			"refunded_cents": resp.RefundedCents,
+		}
+	case actionlink.ActionReschedule:
+		if req.SlotID == 0 && req.StartTime == "" {
+			http.Error(w, "Missing 'slot_id' or 'start_time'", http.StatusBadRequest)
+			return
+		}
+		resp, err := h.Client.RescheduleAppointment(ctx, &pb.RescheduleAppointmentRequest{
+			AppointmentId: claims.AppointmentID,
+			SlotId:        uint32(req.SlotID),
+			StartTime:     req.StartTime,
+			LinkId:        claims.ID,
+		})
+		if err != nil {
+			http.Error(w, "Error rescheduling appointment", http.StatusInternalServerError)
+			return
+		}
+		result = map[string]interface{}{
+			"message":    resp.Message,
+			"success":    resp.Success,
+			"start_time": resp.StartTime,
+			"end_time":   resp.EndTime,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
 	"google.golang.org/grpc"
@@ -21,11 +22,19 @@ func NewAgendaHandler(conn *grpc.ClientConn) *AgendaHandler {
 	return &AgendaHandler{Client: pb.NewAgendaServiceClient(conn)}
 }
 
-func (h *AgendaHandler) RegisterAgendaRoutes(mux *http.ServeMux, secretKey string) {
-	mux.HandleFunc("POST /api/create-slot", middleware.JWTAuthMiddleware(secretKey, h.CreateSlotHandler))
-	mux.HandleFunc("GET /api/list-available-slots", middleware.JWTAuthMiddleware(secretKey, h.ListAvailableSlotsHandler))
-	mux.HandleFunc("POST /api/book-appointment", middleware.JWTAuthMiddleware(secretKey, h.BookAppointmentHandler))
-	mux.HandleFunc("GET /api/list-appointments", middleware.JWTAuthMiddleware(secretKey, h.ListAppointmentsHandler))
+func (h *AgendaHandler) RegisterAgendaRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/create-slot", middleware.JWTAuthMiddleware(keys, h.CreateSlotHandler))
+	mux.HandleFunc("GET /api/list-available-slots", middleware.JWTAuthMiddleware(keys, h.ListAvailableSlotsHandler))
+	mux.HandleFunc("POST /api/book-appointment", middleware.JWTAuthMiddleware(keys, h.BookAppointmentHandler))
+	mux.HandleFunc("GET /api/list-appointments", middleware.JWTAuthMiddleware(keys, h.ListAppointmentsHandler))
+	mux.HandleFunc("GET /api/me/appointments", middleware.JWTAuthMiddleware(keys, h.MyAppointmentsHandler))
+	mux.HandleFunc("GET /api/get-appointment", middleware.JWTAuthMiddleware(keys, h.GetAppointmentHandler))
+	mux.HandleFunc("POST /api/complete-appointment", middleware.JWTAuthMiddleware(keys, h.CompleteAppointmentHandler))
+	mux.HandleFunc("POST /api/cancel-appointment", middleware.JWTAuthMiddleware(keys, h.CancelAppointmentHandler))
+	mux.HandleFunc("POST /api/approve-appointment", middleware.JWTAuthMiddleware(keys, h.ApproveAppointmentHandler))
+	mux.HandleFunc("POST /api/decline-appointment", middleware.JWTAuthMiddleware(keys, h.DeclineAppointmentHandler))
+	mux.HandleFunc("POST /api/reassign-appointments", middleware.JWTAuthMiddleware(keys, h.ReassignAppointmentsHandler))
+	mux.HandleFunc("GET /api/get-intake-answers", middleware.JWTAuthMiddleware(keys, h.GetIntakeAnswersHandler))
 
 }
 
@@ -36,13 +45,14 @@ func (h *AgendaHandler) CreateSlotHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.CreateSlot(ctx, &pb.CreateSlotRequest{
 		ProfessionalId: uint32(req.ProfessionalID),
 		StartTime:      req.StartTime,
 		EndTime:        req.EndTime,
+		LocationId:     uint32(req.LocationID),
 	})
 	if err != nil {
 		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
@@ -59,24 +69,57 @@ func (h *AgendaHandler) CreateSlotHandler(w http.ResponseWriter, r *http.Request
 
 func (h *AgendaHandler) ListAvailableSlotsHandler(w http.ResponseWriter, r *http.Request) {
 	profIDStr := r.URL.Query().Get("professional_id")
+	locationIDStr := r.URL.Query().Get("location_id")
 	date := r.URL.Query().Get("date")
-	if profIDStr == "" || date == "" {
-		http.Error(w, "Faltan parámetros 'professional_id' o 'date'", http.StatusBadRequest)
+	if (profIDStr == "" && locationIDStr == "") || date == "" {
+		http.Error(w, "Faltan parámetros 'professional_id' o 'location_id', y 'date'", http.StatusBadRequest)
 		return
 	}
 
-	profID, err := strconv.ParseUint(profIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "professional_id inválido", http.StatusBadRequest)
-		return
+	var profID, locationID uint64
+	var err error
+	if profIDStr != "" {
+		profID, err = strconv.ParseUint(profIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "professional_id inválido", http.StatusBadRequest)
+			return
+		}
+	}
+	if locationIDStr != "" {
+		locationID, err = strconv.ParseUint(locationIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "location_id inválido", http.StatusBadRequest)
+			return
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	var serviceID uint64
+	if serviceIDStr := r.URL.Query().Get("service_id"); serviceIDStr != "" {
+		serviceID, err = strconv.ParseUint(serviceIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "service_id inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var duration uint64
+	if durationStr := r.URL.Query().Get("duration_minutes"); durationStr != "" {
+		duration, err = strconv.ParseUint(durationStr, 10, 32)
+		if err != nil {
+			http.Error(w, "duration_minutes inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.ListAvailableSlots(ctx, &pb.ListAvailableSlotsRequest{
-		ProfessionalId: uint32(profID),
-		Date:           date,
+		ProfessionalId:  uint32(profID),
+		Date:            date,
+		ServiceId:       uint32(serviceID),
+		LocationId:      uint32(locationID),
+		DurationMinutes: uint32(duration),
 	})
 	if err != nil {
 		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
@@ -97,12 +140,20 @@ func (h *AgendaHandler) BookAppointmentHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.BookAppointment(ctx, &pb.BookAppointmentRequest{
-		ClientId: uint32(req.ClientID),
-		SlotId:   uint32(req.SlotID),
+		ClientId:        uint32(req.ClientID),
+		SlotId:          uint32(req.SlotID),
+		ServiceId:       uint32(req.ServiceID),
+		ProfessionalId:  uint32(req.ProfessionalID),
+		StartTime:       req.StartTime,
+		DurationMinutes: uint32(req.DurationMinutes),
+		IntakeAnswers:   req.IntakeAnswers,
+		Modality:        req.Modality,
+		UseCredits:      req.UseCredits,
+		DependentId:     uint32(req.DependentID),
 	})
 	if err != nil {
 		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
@@ -111,9 +162,13 @@ func (h *AgendaHandler) BookAppointmentHandler(w http.ResponseWriter, r *http.Re
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message":        resp.Message,
-		"success":        resp.Success,
-		"appointment_id": resp.AppointmentId,
+		"message":               resp.Message,
+		"success":               resp.Success,
+		"appointment_id":        resp.AppointmentId,
+		"payment_intent_id":     resp.PaymentIntentId,
+		"payment_client_secret": resp.PaymentClientSecret,
+		"amount_cents":          resp.AmountCents,
+		"currency":              resp.Currency,
 	})
 }
 
@@ -139,13 +194,16 @@ func (h *AgendaHandler) ListAppointmentsHandler(w http.ResponseWriter, r *http.R
 		profID = uint32(id)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.ListAppointments(ctx, &pb.ListAppointmentsRequest{
 		ClientId:       clientID,
 		ProfessionalId: profID,
 	})
+	if writeForbidden(w, err) {
+		return
+	}
 	if err != nil {
 		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
 		return
@@ -157,3 +215,242 @@ func (h *AgendaHandler) ListAppointmentsHandler(w http.ResponseWriter, r *http.R
 		"success":      resp.Success,
 	})
 }
+
+// MyAppointmentsHandler lists the appointments of the client linked to the
+// account, or with ?as=professional the ones of its professional.
+func (h *AgendaHandler) MyAppointmentsHandler(w http.ResponseWriter, r *http.Request) {
+	claims := rbac.FromContext(r.Context())
+	var req pb.ListAppointmentsRequest
+	if r.URL.Query().Get("as") == rbac.RoleProfessional {
+		req.ProfessionalId = uint32(claims.ProfessionalID)
+	} else {
+		req.ClientId = uint32(claims.ClientID)
+	}
+	if req.ClientId == 0 && req.ProfessionalId == 0 {
+		http.Error(w, "La cuenta no está vinculada", http.StatusConflict)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListAppointments(ctx, &req)
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"appointments": resp.Appointments,
+		"success":      resp.Success,
+	})
+}
+
+func (h *AgendaHandler) GetAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		http.Error(w, "Falta el parámetro 'id'", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "id inválido", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetAppointment(ctx, &pb.GetAppointmentRequest{Id: uint32(id)})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"appointment": resp.Appointment,
+		"success":     resp.Success,
+	})
+}
+
+func (h *AgendaHandler) CompleteAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CompleteAppointmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CompleteAppointment(ctx, &pb.CompleteAppointmentRequest{
+		AppointmentId: uint32(req.AppointmentID),
+	})
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *AgendaHandler) CancelAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CancelAppointmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CancelAppointment(ctx, &pb.CancelAppointmentRequest{
+		AppointmentId: uint32(req.AppointmentID),
+	})
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":          resp.Message,
+		"success":          resp.Success,
+		"refunded_cents":   resp.RefundedCents,
+		"refunded_credits": resp.RefundedCredits,
+	})
+}
+
+func (h *AgendaHandler) ApproveAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.ApproveAppointmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ApproveAppointment(ctx, &pb.ApproveAppointmentRequest{
+		AppointmentId:  uint32(req.AppointmentID),
+		ProfessionalId: uint32(req.ProfessionalID),
+	})
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *AgendaHandler) DeclineAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.DeclineAppointmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.DeclineAppointment(ctx, &pb.DeclineAppointmentRequest{
+		AppointmentId:  uint32(req.AppointmentID),
+		ProfessionalId: uint32(req.ProfessionalID),
+		Reason:         req.Reason,
+	})
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":          resp.Message,
+		"success":          resp.Success,
+		"refunded_cents":   resp.RefundedCents,
+		"refunded_credits": resp.RefundedCredits,
+	})
+}
+
+func (h *AgendaHandler) ReassignAppointmentsHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.ReassignAppointmentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
+	}
+
+	// Se mueven y notifican varias citas, por eso el plazo es mayor
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ReassignAppointments(ctx, &pb.ReassignAppointmentsRequest{
+		SourceProfessionalId: uint32(req.SourceProfessionalID),
+		TargetProfessionalId: uint32(req.TargetProfessionalID),
+		From:                 req.From,
+		To:                   req.To,
+	})
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  resp.Message,
+		"success":  resp.Success,
+		"moved":    resp.Moved,
+		"unplaced": resp.Unplaced,
+	})
+}
+
+func (h *AgendaHandler) GetIntakeAnswersHandler(w http.ResponseWriter, r *http.Request) {
+	appointmentID, err := strconv.ParseUint(r.URL.Query().Get("appointment_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "appointment_id inválido", http.StatusBadRequest)
+		return
+	}
+	profID, err := strconv.ParseUint(r.URL.Query().Get("professional_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "professional_id inválido", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetIntakeAnswers(ctx, &pb.GetIntakeAnswersRequest{
+		AppointmentId:  uint32(appointmentID),
+		ProfessionalId: uint32(profID),
+	})
+	if err != nil {
+		http.Error(w, "Error en el servicio de agenda", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":      resp.Message,
+		"success":      resp.Success,
+		"form_id":      resp.FormId,
+		"form_version": resp.FormVersion,
+		"title":        resp.Title,
+		"answers":      resp.Answers,
+	})
+}
@@ -4,30 +4,99 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type authHandler struct {
 	Client pb.AuthServiceClient
+	Keys   *rbac.KeySet
 }
 
-func NewAuthHandler(client pb.AuthServiceClient) *authHandler {
-	return &authHandler{Client: client}
+func NewAuthHandler(client pb.AuthServiceClient, keys *rbac.KeySet) *authHandler {
+	return &authHandler{Client: client, Keys: keys}
 }
 
-func (h *authHandler) RegisterAuthRoutes(mux *http.ServeMux) {
+func (h *authHandler) RegisterAuthRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
 	mux.HandleFunc("POST /api/create-user", h.createUser)
 	mux.HandleFunc("POST /api/login", h.login)
+	// Se autentican con el refresh token, el access token puede haber expirado
+	mux.HandleFunc("POST /api/refresh", h.refresh)
+	mux.HandleFunc("POST /api/logout", h.logout)
+	// Quien olvidó su contraseña no tiene token
+	mux.HandleFunc("POST /api/request-password-reset", h.requestPasswordReset)
+	mux.HandleFunc("POST /api/reset-password", h.resetPassword)
+	mux.HandleFunc("POST /api/verify-email", h.verifyEmail)
+	mux.HandleFunc("POST /api/resend-verification", h.resendVerification)
+	// Segundo paso del login con MFA, con el token que dio /api/login
+	mux.HandleFunc("POST /api/login/mfa", h.verifyMFA)
+	mux.HandleFunc("POST /api/login/mfa/enroll", h.enrollTOTP)
+	mux.HandleFunc("POST /api/login/mfa/activate", h.activateTOTP)
+	// Las llaves públicas para que otros verifiquen los tokens
+	mux.HandleFunc("GET /.well-known/jwks.json", h.jwks)
+	mux.HandleFunc("POST /api/set-roles", middleware.JWTAuthMiddleware(keys, h.setRoles))
+	mux.HandleFunc("POST /api/link-user", middleware.JWTAuthMiddleware(keys, h.linkUser))
+	mux.HandleFunc("POST /api/unlock-login", middleware.JWTAuthMiddleware(keys, h.unlockLogin))
+	mux.HandleFunc("GET /api/list-users", middleware.JWTAuthMiddleware(keys, h.listUsers))
+	mux.HandleFunc("POST /api/disable-user", middleware.JWTAuthMiddleware(keys, h.disableUser))
+	mux.HandleFunc("POST /api/enable-user", middleware.JWTAuthMiddleware(keys, h.enableUser))
+	mux.HandleFunc("POST /api/delete-user", middleware.JWTAuthMiddleware(keys, h.deleteUser))
+	mux.HandleFunc("POST /api/change-password", middleware.JWTAuthMiddleware(keys, h.changePassword))
+	mux.HandleFunc("GET /api/me", middleware.JWTAuthMiddleware(keys, h.me))
+	mux.HandleFunc("POST /api/mfa/enroll", middleware.JWTAuthMiddleware(keys, h.enrollTOTP))
+	mux.HandleFunc("POST /api/mfa/activate", middleware.JWTAuthMiddleware(keys, h.activateTOTP))
+}
+
+// clientIP is the address the request came from. The forwarded headers are
+// ignored, anyone can set them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
 func JsonDecodeInternal[T any](r *http.Request, dest *T) error {
 	return json.NewDecoder(r.Body).Decode(dest)
 }
 
+// writeForbidden answers 403 when a service refused the call because the
+// records asked for aren't the caller's.
+func writeForbidden(w http.ResponseWriter, err error) bool {
+	if status.Code(err) != codes.PermissionDenied {
+		return false
+	}
+	http.Error(w, "Forbidden", http.StatusForbidden)
+	return true
+}
+
+func (h *authHandler) jwks(w http.ResponseWriter, r *http.Request) {
+	jwks := h.Keys.JWKS()
+	if len(jwks.Keys) == 0 {
+		// Auth no respondía cuando se intentó la última vez
+		if err := h.Keys.Refresh(r.Context()); err != nil {
+			log.Printf("Error fetching the signing keys: %v", err)
+			http.Error(w, "Error fetching the signing keys", http.StatusServiceUnavailable)
+			return
+		}
+		jwks = h.Keys.JWKS()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(jwks)
+}
+
 func (h *authHandler) createUser(w http.ResponseWriter, r *http.Request) {
 	var req types.CreateUserRequest
 	if err := JsonDecodeInternal(r, &req); err != nil {
@@ -41,6 +110,7 @@ func (h *authHandler) createUser(w http.ResponseWriter, r *http.Request) {
 	resp, err := h.Client.CreateUser(ctx, &pb.CreateUserRequest{
 		Username: req.Username,
 		Password: req.Password,
+		Email:    req.Email,
 	})
 
 	if err != nil {
@@ -69,17 +139,525 @@ func (h *authHandler) login(w http.ResponseWriter, r *http.Request) {
 	resp, err := h.Client.Login(ctx, &pb.LoginRequest{
 		Username: req.Username,
 		Password: req.Password,
+		ClientIp: clientIP(r),
 	})
 
+	switch status.Convert(err).Message() {
+	case "invalid_credentials":
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		return
+	case "email_not_verified":
+		http.Error(w, "Email not verified", http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		log.Printf("Error login user: %v", err)
 		http.Error(w, "Error login user", http.StatusInternalServerError)
 		return
 	}
+	if resp.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(resp.RetryAfter, 10))
+		http.Error(w, "Too many failed logins", http.StatusTooManyRequests)
+		return
+	}
+
+	writeLogin(w, resp)
+}
+
+// writeLogin answers a login, with the tokens or with the MFA token for the
+// second step.
+func writeLogin(w http.ResponseWriter, resp *pb.LoginResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       resp.Token,
+		"success":       resp.Success,
+		"refresh_token": resp.RefreshToken,
+		"expires_in":    resp.ExpiresIn,
+		// Con UNVERIFIED_LOGIN=limited el token no lleva roles
+		"pending_verification":    resp.PendingVerification,
+		"mfa_required":            resp.MfaRequired,
+		"mfa_enrollment_required": resp.MfaEnrollmentRequired,
+		"mfa_token":               resp.MfaToken,
+	})
+}
+
+func (h *authHandler) verifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req types.VerifyMFARequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.VerifyMFA(ctx, &pb.VerifyMFARequest{
+		MfaToken: req.MfaToken,
+		Code:     req.Code,
+		ClientIp: clientIP(r),
+	})
+	switch status.Convert(err).Message() {
+	case "invalid_credentials":
+		http.Error(w, "Invalid code", http.StatusUnauthorized)
+		return
+	case "invalid_mfa_token":
+		http.Error(w, "Invalid MFA token, log in again", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Error verifying MFA: %v", err)
+		http.Error(w, "Error verifying MFA", http.StatusInternalServerError)
+		return
+	}
+	if resp.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(resp.RetryAfter, 10))
+		http.Error(w, "Too many failed logins", http.StatusTooManyRequests)
+		return
+	}
+
+	writeLogin(w, resp)
+}
+
+func (h *authHandler) enrollTOTP(w http.ResponseWriter, r *http.Request) {
+	var req types.EnrollTOTPRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{MfaToken: req.MfaToken})
+	if err != nil {
+		log.Printf("Error enrolling TOTP: %v", err)
+		http.Error(w, "Error enrolling TOTP", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":          resp.Message,
+		"success":          resp.Success,
+		"secret":           resp.Secret,
+		"provisioning_uri": resp.ProvisioningUri,
+	})
+}
+
+func (h *authHandler) activateTOTP(w http.ResponseWriter, r *http.Request) {
+	var req types.ActivateTOTPRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ActivateTOTP(ctx, &pb.ActivateTOTPRequest{MfaToken: req.MfaToken, Code: req.Code})
+	if err != nil {
+		log.Printf("Error activating TOTP: %v", err)
+		http.Error(w, "Error activating TOTP", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":        resp.Message,
+		"success":        resp.Success,
+		"recovery_codes": resp.RecoveryCodes,
+	})
+}
+
+func (h *authHandler) refresh(w http.ResponseWriter, r *http.Request) {
+	var req types.RefreshTokenRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.Refresh(ctx, &pb.RefreshRequest{RefreshToken: req.RefreshToken})
+	if err != nil {
+		log.Printf("Error refreshing token: %v", err)
+		http.Error(w, "Error refreshing token", http.StatusInternalServerError)
+		return
+	}
+	if !resp.Success {
+		http.Error(w, resp.Message, http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       resp.Message,
+		"success":       resp.Success,
+		"token":         resp.Token,
+		"refresh_token": resp.RefreshToken,
+		"expires_in":    resp.ExpiresIn,
+	})
+}
+
+func (h *authHandler) logout(w http.ResponseWriter, r *http.Request) {
+	var req types.RefreshTokenRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.Logout(ctx, &pb.LogoutRequest{RefreshToken: req.RefreshToken})
+	if err != nil {
+		log.Printf("Error logging out: %v", err)
+		http.Error(w, "Error logging out", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) requestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req types.RequestPasswordResetRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	// Auth espera a que notificaciones envíe el correo
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.Client.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{UsernameOrEmail: req.UsernameOrEmail})
+	if err != nil {
+		log.Printf("Error requesting password reset: %v", err)
+		http.Error(w, "Error requesting password reset", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var req types.ResetPasswordRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ResetPassword(ctx, &pb.ResetPasswordRequest{Token: req.Token, NewPassword: req.NewPassword})
+	if err != nil {
+		log.Printf("Error resetting password: %v", err)
+		http.Error(w, "Error resetting password", http.StatusInternalServerError)
+		return
+	}
+	if !resp.Success {
+		http.Error(w, resp.Message, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req types.VerifyEmailRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.VerifyEmail(ctx, &pb.VerifyEmailRequest{Token: req.Token})
+	if err != nil {
+		log.Printf("Error verifying email: %v", err)
+		http.Error(w, "Error verifying email", http.StatusInternalServerError)
+		return
+	}
+	if !resp.Success {
+		http.Error(w, resp.Message, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) resendVerification(w http.ResponseWriter, r *http.Request) {
+	var req types.ResendVerificationRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ResendVerification(ctx, &pb.ResendVerificationRequest{UsernameOrEmail: req.UsernameOrEmail})
+	if err != nil {
+		log.Printf("Error resending verification: %v", err)
+		http.Error(w, "Error resending verification", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) setRoles(w http.ResponseWriter, r *http.Request) {
+	var req types.SetRolesRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.SetRoles(ctx, &pb.SetRolesRequest{
+		UserId: uint32(req.UserID),
+		Roles:  req.Roles,
+	})
+	if err != nil {
+		log.Printf("Error setting roles: %v", err)
+		http.Error(w, "Error setting roles", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) linkUser(w http.ResponseWriter, r *http.Request) {
+	var req types.LinkUserRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.LinkUser(ctx, &pb.LinkUserRequest{
+		UserId:         uint32(req.UserID),
+		ClientId:       uint32(req.ClientID),
+		ProfessionalId: uint32(req.ProfessionalID),
+	})
+	if err != nil {
+		log.Printf("Error linking user: %v", err)
+		http.Error(w, "Error linking user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) unlockLogin(w http.ResponseWriter, r *http.Request) {
+	var req types.UnlockLoginRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.UnlockLogin(ctx, &pb.UnlockLoginRequest{Username: req.Username, ClientIp: req.ClientIP})
+	if err != nil {
+		log.Printf("Error unlocking login: %v", err)
+		http.Error(w, "Error unlocking login", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+// listUsers takes the optional search, page and page_size query params.
+func (h *authHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var page, pageSize uint64
+	var err error
+	if value := query.Get("page"); value != "" {
+		if page, err = strconv.ParseUint(value, 10, 32); err != nil {
+			http.Error(w, "Invalid page", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("page_size"); value != "" {
+		if pageSize, err = strconv.ParseUint(value, 10, 32); err != nil {
+			http.Error(w, "Invalid page_size", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListUsers(ctx, &pb.ListUsersRequest{
+		Search:   query.Get("search"),
+		Page:     uint32(page),
+		PageSize: uint32(pageSize),
+	})
+	if err != nil {
+		log.Printf("Error listing users: %v", err)
+		http.Error(w, "Error listing users", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": resp.Success,
+		"users":   resp.Users,
+		"total":   resp.Total,
+	})
+}
+
+func (h *authHandler) disableUser(w http.ResponseWriter, r *http.Request) {
+	var req types.UserIDRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.DisableUser(ctx, &pb.DisableUserRequest{UserId: uint32(req.UserID)})
+	if err != nil {
+		log.Printf("Error disabling user: %v", err)
+		http.Error(w, "Error disabling user", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": resp.Token,
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *authHandler) enableUser(w http.ResponseWriter, r *http.Request) {
+	var req types.UserIDRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.EnableUser(ctx, &pb.EnableUserRequest{UserId: uint32(req.UserID)})
+	if err != nil {
+		log.Printf("Error enabling user: %v", err)
+		http.Error(w, "Error enabling user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
 		"success": resp.Success,
 	})
 }
+
+func (h *authHandler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	var req types.UserIDRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.DeleteUser(ctx, &pb.DeleteUserRequest{UserId: uint32(req.UserID)})
+	if err != nil {
+		log.Printf("Error deleting user: %v", err)
+		http.Error(w, "Error deleting user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+// changePassword closes every session of the user, including the one that
+// asked, the client has to log in again.
+func (h *authHandler) changePassword(w http.ResponseWriter, r *http.Request) {
+	var req types.ChangePasswordRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		log.Printf("Error changing password: %v", err)
+		http.Error(w, "Error changing password", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+// me returns who the token belongs to and the records linked to the account.
+func (h *authHandler) me(w http.ResponseWriter, r *http.Request) {
+	claims := rbac.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":         claims.UserID,
+		"roles":           claims.Roles,
+		"client_id":       claims.ClientID,
+		"professional_id": claims.ProfessionalID,
+	})
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc"
+)
+
+type AvailabilityHandler struct {
+	Client pb.AvailabilityServiceClient
+}
+
+func NewAvailabilityHandler(conn *grpc.ClientConn) *AvailabilityHandler {
+	return &AvailabilityHandler{Client: pb.NewAvailabilityServiceClient(conn)}
+}
+
+func (h *AvailabilityHandler) RegisterAvailabilityRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/update-availability-settings", middleware.JWTAuthMiddleware(keys, h.UpdateAvailabilitySettingsHandler))
+	mux.HandleFunc("GET /api/get-availability-settings", middleware.JWTAuthMiddleware(keys, h.GetAvailabilitySettingsHandler))
+	mux.HandleFunc("POST /api/set-availability-rules", middleware.JWTAuthMiddleware(keys, h.SetAvailabilityRulesHandler))
+	mux.HandleFunc("POST /api/create-time-off", middleware.JWTAuthMiddleware(keys, h.CreateTimeOffHandler))
+	mux.HandleFunc("POST /api/set-approval-mode", middleware.JWTAuthMiddleware(keys, h.SetApprovalModeHandler))
+	mux.HandleFunc("POST /api/set-digest-preferences", middleware.JWTAuthMiddleware(keys, h.SetDigestPreferencesHandler))
+}
+
+func (h *AvailabilityHandler) UpdateAvailabilitySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateAvailabilitySettingsRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.UpdateAvailabilitySettings(ctx, &pb.UpdateAvailabilitySettingsRequest{
+		Settings: &pb.AvailabilitySettings{
+			ProfessionalId:         uint32(req.ProfessionalID),
+			Mode:                   req.Mode,
+			Timezone:               req.Timezone,
+			DefaultDurationMinutes: uint32(req.DefaultDurationMinutes),
+			StepMinutes:            uint32(req.StepMinutes),
+			BufferMinutes:          uint32(req.BufferMinutes),
+		},
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating availability settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *AvailabilityHandler) GetAvailabilitySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.URL.Query().Get("professional_id")
+	if idStr == "" {
+		http.Error(w, "professional_id param is missing", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid professional_id", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetAvailabilitySettings(ctx, &pb.GetAvailabilitySettingsRequest{
+		ProfessionalId: uint32(id),
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error getting availability settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"settings": resp.Settings,
+		"success":  resp.Success,
+	})
+}
+
+func (h *AvailabilityHandler) SetAvailabilityRulesHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.SetAvailabilityRulesRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	rules := make([]*pb.AvailabilityRule, len(req.Rules))
+	for i, rule := range req.Rules {
+		rules[i] = &pb.AvailabilityRule{
+			Weekday:    uint32(rule.Weekday),
+			StartTime:  rule.StartTime,
+			EndTime:    rule.EndTime,
+			LocationId: uint32(rule.LocationID),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.SetAvailabilityRules(ctx, &pb.SetAvailabilityRulesRequest{
+		ProfessionalId: uint32(req.ProfessionalID),
+		Rules:          rules,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error saving availability rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *AvailabilityHandler) CreateTimeOffHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateTimeOffRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CreateTimeOff(ctx, &pb.CreateTimeOffRequest{
+		ProfessionalId: uint32(req.ProfessionalID),
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		Reason:         req.Reason,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error creating time off", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     resp.Message,
+		"success":     resp.Success,
+		"time_off_id": resp.TimeOffId,
+	})
+}
+
+func (h *AvailabilityHandler) SetApprovalModeHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.SetApprovalModeRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.SetApprovalMode(ctx, &pb.SetApprovalModeRequest{
+		ProfessionalId:   uint32(req.ProfessionalID),
+		RequiresApproval: req.RequiresApproval,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating approval mode", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *AvailabilityHandler) SetDigestPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.SetDigestPreferencesRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.SetDigestPreferences(ctx, &pb.SetDigestPreferencesRequest{
+		ProfessionalId: uint32(req.ProfessionalID),
+		SendTime:       req.SendTime,
+		OptOut:         req.OptOut,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating digest preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
 	"google.golang.org/grpc"
@@ -21,10 +22,12 @@ func NewClientHandler(conn *grpc.ClientConn) *ClientHandler {
 	return &ClientHandler{Client: pb.NewClientServiceClient(conn)}
 }
 
-func (h *ClientHandler) RegisterClientRoutes(mux *http.ServeMux, secretKey string) {
-	mux.HandleFunc("POST /api/create-client", middleware.JWTAuthMiddleware(secretKey, h.CreateClientHandler))
-	mux.HandleFunc("GET /api/get-client", middleware.JWTAuthMiddleware(secretKey, h.GetClientHandler))
-	mux.HandleFunc("GET /api/list-clients", middleware.JWTAuthMiddleware(secretKey, h.ListClientsHandler))
+func (h *ClientHandler) RegisterClientRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/create-client", middleware.JWTAuthMiddleware(keys, h.CreateClientHandler))
+	mux.HandleFunc("GET /api/get-client", middleware.JWTAuthMiddleware(keys, h.GetClientHandler))
+	mux.HandleFunc("GET /api/list-clients", middleware.JWTAuthMiddleware(keys, h.ListClientsHandler))
+	mux.HandleFunc("POST /api/add-dependent", middleware.JWTAuthMiddleware(keys, h.AddDependentHandler))
+	mux.HandleFunc("GET /api/list-dependents", middleware.JWTAuthMiddleware(keys, h.ListDependentsHandler))
 }
 
 func (h *ClientHandler) CreateClientHandler(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +37,7 @@ func (h *ClientHandler) CreateClientHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.CreateClient(ctx, &pb.CreateClientRequest{
@@ -68,12 +71,15 @@ func (h *ClientHandler) GetClientHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.GetClient(ctx, &pb.GetClientRequest{
 		Id: uint32(id),
 	})
+	if writeForbidden(w, err) {
+		return
+	}
 	if err != nil {
 		http.Error(w, "Error in client service", http.StatusInternalServerError)
 		return
@@ -87,7 +93,7 @@ func (h *ClientHandler) GetClientHandler(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *ClientHandler) ListClientsHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.ListClients(ctx, &pb.ListClientsRequest{})
@@ -102,3 +108,65 @@ func (h *ClientHandler) ListClientsHandler(w http.ResponseWriter, r *http.Reques
 		"success": resp.Success,
 	})
 }
+
+func (h *ClientHandler) AddDependentHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.AddDependentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.AddDependent(ctx, &pb.AddDependentRequest{
+		ClientId:    uint32(req.ClientID),
+		Name:        req.Name,
+		DateOfBirth: req.DateOfBirth,
+	})
+	if err != nil {
+		http.Error(w, "Error in client service", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":      resp.Message,
+		"success":      resp.Success,
+		"dependent_id": resp.DependentId,
+	})
+}
+
+func (h *ClientHandler) ListDependentsHandler(w http.ResponseWriter, r *http.Request) {
+	clientIDStr := r.URL.Query().Get("client_id")
+	if clientIDStr == "" {
+		http.Error(w, "Missing required param 'client_id'", http.StatusBadRequest)
+		return
+	}
+
+	clientID, err := strconv.ParseUint(clientIDStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid client_id", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListDependents(ctx, &pb.ListDependentsRequest{
+		ClientId: uint32(clientID),
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error in client service", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dependents": resp.Dependents,
+		"success":    resp.Success,
+	})
+}
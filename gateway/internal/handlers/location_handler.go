@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc"
+)
+
+type LocationHandler struct {
+	Client pb.LocationServiceClient
+}
+
+func NewLocationHandler(conn *grpc.ClientConn) *LocationHandler {
+	return &LocationHandler{Client: pb.NewLocationServiceClient(conn)}
+}
+
+func (h *LocationHandler) RegisterLocationRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/create-location", middleware.JWTAuthMiddleware(keys, h.CreateLocationHandler))
+	mux.HandleFunc("GET /api/get-location", middleware.JWTAuthMiddleware(keys, h.GetLocationHandler))
+	mux.HandleFunc("GET /api/list-locations", middleware.JWTAuthMiddleware(keys, h.ListLocationsHandler))
+	mux.HandleFunc("POST /api/assign-professional-location", middleware.JWTAuthMiddleware(keys, h.AssignProfessionalHandler))
+}
+
+func (h *LocationHandler) CreateLocationHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateLocationRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	hours := make([]*pb.OpeningHours, len(req.OpeningHours))
+	for i, oh := range req.OpeningHours {
+		hours[i] = &pb.OpeningHours{
+			Weekday: uint32(oh.Weekday),
+			Opens:   oh.Opens,
+			Closes:  oh.Closes,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CreateLocation(ctx, &pb.CreateLocationRequest{
+		Name:         req.Name,
+		Address:      req.Address,
+		Timezone:     req.Timezone,
+		OpeningHours: hours,
+	})
+	if err != nil {
+		http.Error(w, "Error creating location", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     resp.Message,
+		"success":     resp.Success,
+		"location_id": resp.LocationId,
+	})
+}
+
+func (h *LocationHandler) GetLocationHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		http.Error(w, "id param is missing", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetLocation(ctx, &pb.GetLocationRequest{
+		Id: uint32(id),
+	})
+	if err != nil {
+		http.Error(w, "Error getting selected location", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"location": resp.Location,
+		"success":  resp.Success,
+	})
+}
+
+func (h *LocationHandler) ListLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListLocations(ctx, &pb.ListLocationsRequest{})
+	if err != nil {
+		http.Error(w, "Error getting locations list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"locations": resp.Locations,
+		"success":   resp.Success,
+	})
+}
+
+func (h *LocationHandler) AssignProfessionalHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.AssignProfessionalRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.AssignProfessional(ctx, &pb.AssignProfessionalRequest{
+		ProfessionalId: uint32(req.ProfessionalID),
+		LocationId:     uint32(req.LocationID),
+		Weekday:        uint32(req.Weekday),
+	})
+	if err != nil {
+		http.Error(w, "Error assigning professional", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc"
+)
+
+// multipartOverhead is the room left for the form fields and boundaries of an
+// upload on top of the file itself.
+const multipartOverhead = 1 << 20
+
+type NoteHandler struct {
+	Client         pb.NoteServiceClient
+	MaxUploadBytes int
+}
+
+func NewNoteHandler(conn *grpc.ClientConn, maxUploadBytes int) *NoteHandler {
+	return &NoteHandler{Client: pb.NewNoteServiceClient(conn), MaxUploadBytes: maxUploadBytes}
+}
+
+func (h *NoteHandler) RegisterNoteRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/add-note", middleware.JWTAuthMiddleware(keys, h.AddNoteHandler))
+	mux.HandleFunc("GET /api/list-notes", middleware.JWTAuthMiddleware(keys, h.ListNotesHandler))
+	mux.HandleFunc("POST /api/upload-attachment", middleware.JWTAuthMiddleware(keys, h.UploadAttachmentHandler))
+	mux.HandleFunc("GET /api/list-attachments", middleware.JWTAuthMiddleware(keys, h.ListAttachmentsHandler))
+	mux.HandleFunc("GET /api/download-attachment", middleware.JWTAuthMiddleware(keys, h.DownloadAttachmentHandler))
+}
+
+func (h *NoteHandler) AddNoteHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.AddNoteRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.AddNote(ctx, &pb.AddNoteRequest{
+		AppointmentId:  uint32(req.AppointmentID),
+		ProfessionalId: uint32(req.ProfessionalID),
+		ClientId:       uint32(req.ClientID),
+		Visibility:     req.Visibility,
+		Body:           req.Body,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error adding note", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+		"note_id": resp.NoteId,
+	})
+}
+
+func (h *NoteHandler) ListNotesHandler(w http.ResponseWriter, r *http.Request) {
+	appointmentID, profID, clientID, ok := appointmentRequester(w, r, "appointment_id")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListNotes(ctx, &pb.ListNotesRequest{
+		AppointmentId:  appointmentID,
+		ProfessionalId: profID,
+		ClientId:       clientID,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error getting notes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+		"notes":   resp.Notes,
+	})
+}
+
+// UploadAttachmentHandler takes a multipart form with the "file" part and the
+// appointment_id and visibility fields, plus professional_id or client_id when
+// staff uploads it.
+func (h *NoteHandler) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.MaxUploadBytes+multipartOverhead))
+	if err := r.ParseMultipartForm(multipartOverhead); err != nil {
+		http.Error(w, "Invalid multipart form or file too large", http.StatusBadRequest)
+		return
+	}
+	appointmentID, profID, clientID, ok := appointmentRequester(w, r, "appointment_id")
+	if !ok {
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is missing", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, int64(h.MaxUploadBytes)+1))
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+	if len(data) > h.MaxUploadBytes {
+		http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.Client.UploadAttachment(ctx, &pb.UploadAttachmentRequest{
+		AppointmentId:  appointmentID,
+		ProfessionalId: profID,
+		ClientId:       clientID,
+		Visibility:     r.FormValue("visibility"),
+		FileName:       header.Filename,
+		Data:           data,
+	}, grpc.MaxCallSendMsgSize(h.MaxUploadBytes+multipartOverhead))
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error uploading attachment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       resp.Message,
+		"success":       resp.Success,
+		"attachment_id": resp.AttachmentId,
+		"content_type":  resp.ContentType,
+	})
+}
+
+func (h *NoteHandler) ListAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	appointmentID, profID, clientID, ok := appointmentRequester(w, r, "appointment_id")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListAttachments(ctx, &pb.ListAttachmentsRequest{
+		AppointmentId:  appointmentID,
+		ProfessionalId: profID,
+		ClientId:       clientID,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error getting attachments", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     resp.Message,
+		"success":     resp.Success,
+		"attachments": resp.Attachments,
+	})
+}
+
+// DownloadAttachmentHandler answers with the file itself, or with the usual
+// JSON message when it can't be downloaded.
+func (h *NoteHandler) DownloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, profID, clientID, ok := appointmentRequester(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.Client.DownloadAttachment(ctx, &pb.DownloadAttachmentRequest{
+		Id:             id,
+		ProfessionalId: profID,
+		ClientId:       clientID,
+	}, grpc.MaxCallRecvMsgSize(h.MaxUploadBytes+multipartOverhead))
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error downloading attachment", http.StatusInternalServerError)
+		return
+	}
+	if !resp.Success {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": resp.Message,
+			"success": resp.Success,
+		})
+		return
+	}
+
+	// Se fuerza la descarga para que el navegador no interprete el archivo
+	w.Header().Set("Content-Type", resp.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": resp.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.Data)))
+	w.Write(resp.Data)
+}
+
+// appointmentRequester reads the required id field and who the request is made
+// on behalf of, professional_id or client_id. Agenda only uses them when staff
+// makes the request, anyone else is taken from the token. It answers the
+// request itself when they're invalid.
+func appointmentRequester(w http.ResponseWriter, r *http.Request, idField string) (uint32, uint32, uint32, bool) {
+	id, err := strconv.ParseUint(r.FormValue(idField), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid "+idField, http.StatusBadRequest)
+		return 0, 0, 0, false
+	}
+	var ids [2]uint32
+	for i, field := range []string{"professional_id", "client_id"} {
+		if value := r.FormValue(field); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				http.Error(w, "Invalid "+field, http.StatusBadRequest)
+				return 0, 0, 0, false
+			}
+			ids[i] = uint32(parsed)
+		}
+	}
+	return uint32(id), ids[0], ids[1], true
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc"
+)
+
+type PackageHandler struct {
+	Client pb.PackageServiceClient
+}
+
+func NewPackageHandler(conn *grpc.ClientConn) *PackageHandler {
+	return &PackageHandler{Client: pb.NewPackageServiceClient(conn)}
+}
+
+func (h *PackageHandler) RegisterPackageRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/create-package", middleware.JWTAuthMiddleware(keys, h.CreatePackageHandler))
+	mux.HandleFunc("GET /api/list-packages", middleware.JWTAuthMiddleware(keys, h.ListPackagesHandler))
+	mux.HandleFunc("POST /api/purchase-package", middleware.JWTAuthMiddleware(keys, h.PurchasePackageHandler))
+	mux.HandleFunc("GET /api/get-credit-balance", middleware.JWTAuthMiddleware(keys, h.GetCreditBalanceHandler))
+	mux.HandleFunc("GET /api/list-credit-history", middleware.JWTAuthMiddleware(keys, h.ListCreditHistoryHandler))
+}
+
+func (h *PackageHandler) CreatePackageHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CreatePackageRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	serviceIDs := make([]uint32, len(req.ServiceIDs))
+	for i, id := range req.ServiceIDs {
+		serviceIDs[i] = uint32(id)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CreatePackage(ctx, &pb.CreatePackageRequest{
+		Name:       req.Name,
+		Credits:    uint32(req.Credits),
+		ValidDays:  uint32(req.ValidDays),
+		PriceCents: uint32(req.PriceCents),
+		Currency:   req.Currency,
+		ServiceIds: serviceIDs,
+	})
+	if err != nil {
+		http.Error(w, "Error creating package", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    resp.Message,
+		"success":    resp.Success,
+		"package_id": resp.PackageId,
+	})
+}
+
+func (h *PackageHandler) ListPackagesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListPackages(ctx, &pb.ListPackagesRequest{})
+	if err != nil {
+		http.Error(w, "Error getting packages list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"packages": resp.Packages,
+		"success":  resp.Success,
+	})
+}
+
+func (h *PackageHandler) PurchasePackageHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.PurchasePackageRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.PurchasePackage(ctx, &pb.PurchasePackageRequest{
+		ClientId:  uint32(req.ClientID),
+		PackageId: uint32(req.PackageID),
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		http.Error(w, "Error purchasing package", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":           resp.Message,
+		"success":           resp.Success,
+		"client_package_id": resp.ClientPackageId,
+		"expires_at":        resp.ExpiresAt,
+	})
+}
+
+// GetCreditBalanceHandler expects the client_id query param, service_id is
+// optional.
+func (h *PackageHandler) GetCreditBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	clientID, err := strconv.ParseUint(r.URL.Query().Get("client_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid client_id", http.StatusBadRequest)
+		return
+	}
+	var serviceID uint64
+	if value := r.URL.Query().Get("service_id"); value != "" {
+		if serviceID, err = strconv.ParseUint(value, 10, 32); err != nil {
+			http.Error(w, "Invalid service_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetCreditBalance(ctx, &pb.GetCreditBalanceRequest{
+		ClientId:  uint32(clientID),
+		ServiceId: uint32(serviceID),
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error getting credit balance", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       resp.Message,
+		"success":       resp.Success,
+		"total_credits": resp.TotalCredits,
+		"packages":      resp.Packages,
+	})
+}
+
+func (h *PackageHandler) ListCreditHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	clientID, err := strconv.ParseUint(r.URL.Query().Get("client_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid client_id", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListCreditHistory(ctx, &pb.ListCreditHistoryRequest{
+		ClientId: uint32(clientID),
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error getting credit history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":      resp.Message,
+		"success":      resp.Success,
+		"transactions": resp.Transactions,
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"google.golang.org/grpc"
+)
+
+// maxWebhookBody limits the payload accepted from the payment provider.
+const maxWebhookBody = 64 << 10
+
+type PaymentHandler struct {
+	Client pb.PaymentServiceClient
+}
+
+func NewPaymentHandler(conn *grpc.ClientConn) *PaymentHandler {
+	return &PaymentHandler{Client: pb.NewPaymentServiceClient(conn)}
+}
+
+func (h *PaymentHandler) RegisterPaymentRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	// El proveedor no tiene JWT, el webhook se autentica con su firma
+	mux.HandleFunc("POST /api/payments/webhook", h.WebhookHandler)
+	mux.HandleFunc("GET /api/get-payment", middleware.JWTAuthMiddleware(keys, h.GetPaymentHandler))
+}
+
+func (h *PaymentHandler) WebhookHandler(w http.ResponseWriter, r *http.Request) {
+	// The signature is computed over the raw body, so it's forwarded untouched
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "Error reading body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	signature := r.Header.Get("X-Payment-Signature")
+	if signature == "" {
+		signature = r.Header.Get("Stripe-Signature")
+	}
+	resp, err := h.Client.HandlePaymentWebhook(ctx, &pb.PaymentWebhookRequest{
+		Payload:   payload,
+		Signature: signature,
+	})
+	if err != nil {
+		// Any non 2xx answer makes the provider retry the webhook later
+		http.Error(w, "Error handling webhook", http.StatusInternalServerError)
+		return
+	}
+	if !resp.Success {
+		http.Error(w, resp.Message, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *PaymentHandler) GetPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	appointmentIDStr := r.URL.Query().Get("appointment_id")
+	if appointmentIDStr == "" {
+		http.Error(w, "Missing 'appointment_id' parameter", http.StatusBadRequest)
+		return
+	}
+	appointmentID, err := strconv.ParseUint(appointmentIDStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid appointment_id", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetPayment(ctx, &pb.GetPaymentRequest{AppointmentId: uint32(appointmentID)})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error getting payment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"payment": resp.Payment,
+		"success": resp.Success,
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
 	"google.golang.org/grpc"
@@ -21,10 +22,10 @@ func NewProfessionalHandler(conn *grpc.ClientConn) *ProfessionalHandler {
 	return &ProfessionalHandler{Client: pb.NewProfessionalServiceClient(conn)}
 }
 
-func (h *ProfessionalHandler) RegisterProfessionalRoutes(mux *http.ServeMux, secretKey string) {
-	mux.HandleFunc("POST /api/create-professional", middleware.JWTAuthMiddleware(secretKey, h.CreateProfessionalHandler))
-	mux.HandleFunc("GET /api/get-professional", middleware.JWTAuthMiddleware(secretKey, h.GetProfessionalHandler))
-	mux.HandleFunc("GET /api/list-professionals", middleware.JWTAuthMiddleware(secretKey, h.ListProfessionalsHandler))
+func (h *ProfessionalHandler) RegisterProfessionalRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/create-professional", middleware.JWTAuthMiddleware(keys, h.CreateProfessionalHandler))
+	mux.HandleFunc("GET /api/get-professional", middleware.JWTAuthMiddleware(keys, h.GetProfessionalHandler))
+	mux.HandleFunc("GET /api/list-professionals", middleware.JWTAuthMiddleware(keys, h.ListProfessionalsHandler))
 }
 
 func (h *ProfessionalHandler) CreateProfessionalHandler(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +35,7 @@ func (h *ProfessionalHandler) CreateProfessionalHandler(w http.ResponseWriter, r
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.CreateProfessional(ctx, &pb.CreateProfessionalRequest{
@@ -68,7 +69,7 @@ func (h *ProfessionalHandler) GetProfessionalHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
 	resp, err := h.Client.GetProfessional(ctx, &pb.GetProfessionalRequest{
@@ -87,10 +88,23 @@ func (h *ProfessionalHandler) GetProfessionalHandler(w http.ResponseWriter, r *h
 }
 
 func (h *ProfessionalHandler) ListProfessionalsHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	var locationID uint64
+	if locationIDStr := r.URL.Query().Get("location_id"); locationIDStr != "" {
+		id, err := strconv.ParseUint(locationIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid location_id", http.StatusBadRequest)
+			return
+		}
+		locationID = id
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
-	resp, err := h.Client.ListProfessionals(ctx, &pb.ListProfessionalsRequest{})
+	resp, err := h.Client.ListProfessionals(ctx, &pb.ListProfessionalsRequest{
+		LocationId: uint32(locationID),
+		Date:       r.URL.Query().Get("date"),
+	})
 	if err != nil {
 		http.Error(w, "Error getting professionals list", http.StatusInternalServerError)
 		return
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"google.golang.org/grpc"
+)
+
+type ReconcileHandler struct {
+	Client pb.ReconcileServiceClient
+}
+
+func NewReconcileHandler(conn *grpc.ClientConn) *ReconcileHandler {
+	return &ReconcileHandler{Client: pb.NewReconcileServiceClient(conn)}
+}
+
+func (h *ReconcileHandler) RegisterReconcileRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/reconcile-agenda", middleware.JWTAuthMiddleware(keys, h.ReconcileHandler))
+}
+
+// ReconcileHandler runs the agenda reconciler, ?dry_run=true only reports.
+func (h *ReconcileHandler) ReconcileHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	// Revisa todas las citas y slots, puede tardar más que las demás llamadas
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	resp, err := h.Client.Reconcile(ctx, &pb.ReconcileRequest{DryRun: dryRun})
+	if err != nil {
+		http.Error(w, "Error in reconcile service", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+		"issues":  resp.Issues,
+	})
+}
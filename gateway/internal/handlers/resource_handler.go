@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc"
+)
+
+type ResourceHandler struct {
+	Client pb.ResourceServiceClient
+}
+
+func NewResourceHandler(conn *grpc.ClientConn) *ResourceHandler {
+	return &ResourceHandler{Client: pb.NewResourceServiceClient(conn)}
+}
+
+func (h *ResourceHandler) RegisterResourceRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/create-resource", middleware.JWTAuthMiddleware(keys, h.CreateResourceHandler))
+	mux.HandleFunc("GET /api/list-resources", middleware.JWTAuthMiddleware(keys, h.ListResourcesHandler))
+	mux.HandleFunc("POST /api/block-resource", middleware.JWTAuthMiddleware(keys, h.BlockResourceHandler))
+	mux.HandleFunc("POST /api/create-service", middleware.JWTAuthMiddleware(keys, h.CreateServiceHandler))
+	mux.HandleFunc("GET /api/get-service", middleware.JWTAuthMiddleware(keys, h.GetServiceHandler))
+	mux.HandleFunc("GET /api/list-services", middleware.JWTAuthMiddleware(keys, h.ListServicesHandler))
+	mux.HandleFunc("POST /api/create-intake-form", middleware.JWTAuthMiddleware(keys, h.CreateIntakeFormHandler))
+	mux.HandleFunc("GET /api/get-intake-form", middleware.JWTAuthMiddleware(keys, h.GetIntakeFormHandler))
+}
+
+func (h *ResourceHandler) CreateResourceHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateResourceRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CreateResource(ctx, &pb.CreateResourceRequest{
+		Name: req.Name,
+		Type: req.Type,
+	})
+	if err != nil {
+		http.Error(w, "Error creating resource", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     resp.Message,
+		"success":     resp.Success,
+		"resource_id": resp.ResourceId,
+	})
+}
+
+func (h *ResourceHandler) ListResourcesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListResources(ctx, &pb.ListResourcesRequest{
+		Type: r.URL.Query().Get("type"),
+	})
+	if err != nil {
+		http.Error(w, "Error getting resources list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"resources": resp.Resources,
+		"success":   resp.Success,
+	})
+}
+
+func (h *ResourceHandler) BlockResourceHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.BlockResourceRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.BlockResource(ctx, &pb.BlockResourceRequest{
+		ResourceId: uint32(req.ResourceID),
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Reason:     req.Reason,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error blocking resource", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":        resp.Message,
+		"success":        resp.Success,
+		"reservation_id": resp.ReservationId,
+	})
+}
+
+func (h *ResourceHandler) CreateServiceHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateServiceRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	resourceIDs := make([]uint32, len(req.ResourceIDs))
+	for i, id := range req.ResourceIDs {
+		resourceIDs[i] = uint32(id)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CreateService(ctx, &pb.CreateServiceRequest{
+		Name:            req.Name,
+		DurationMinutes: uint32(req.DurationMinutes),
+		ResourceIds:     resourceIDs,
+		PriceCents:      uint32(req.PriceCents),
+		DepositCents:    uint32(req.DepositCents),
+		Currency:        req.Currency,
+		Modality:        req.Modality,
+		RequiresCredits: req.RequiresCredits,
+	})
+	if err != nil {
+		http.Error(w, "Error creating service", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    resp.Message,
+		"success":    resp.Success,
+		"service_id": resp.ServiceId,
+	})
+}
+
+func (h *ResourceHandler) GetServiceHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		http.Error(w, "id param is missing", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetService(ctx, &pb.GetServiceRequest{
+		Id: uint32(id),
+	})
+	if err != nil {
+		http.Error(w, "Error getting selected service", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"service": resp.Service,
+		"success": resp.Success,
+	})
+}
+
+func (h *ResourceHandler) ListServicesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListServices(ctx, &pb.ListServicesRequest{})
+	if err != nil {
+		http.Error(w, "Error getting services list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"services": resp.Services,
+		"success":  resp.Success,
+	})
+}
+
+func (h *ResourceHandler) CreateIntakeFormHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateIntakeFormRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	fields := make([]*pb.IntakeField, len(req.Fields))
+	for i, field := range req.Fields {
+		fields[i] = &pb.IntakeField{
+			Key:      field.Key,
+			Label:    field.Label,
+			Type:     field.Type,
+			Required: field.Required,
+			Options:  field.Options,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CreateIntakeForm(ctx, &pb.CreateIntakeFormRequest{
+		ServiceId:      uint32(req.ServiceID),
+		ProfessionalId: uint32(req.ProfessionalID),
+		Title:          req.Title,
+		Fields:         fields,
+	})
+	if writeForbidden(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error creating intake form", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+		"form_id": resp.FormId,
+		"version": resp.Version,
+	})
+}
+
+func (h *ResourceHandler) GetIntakeFormHandler(w http.ResponseWriter, r *http.Request) {
+	var serviceID, profID uint32
+	if idStr := r.URL.Query().Get("service_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid service_id", http.StatusBadRequest)
+			return
+		}
+		serviceID = uint32(id)
+	}
+	if idStr := r.URL.Query().Get("professional_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid professional_id", http.StatusBadRequest)
+			return
+		}
+		profID = uint32(id)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.GetIntakeForm(ctx, &pb.GetIntakeFormRequest{
+		ServiceId:      serviceID,
+		ProfessionalId: profID,
+	})
+	if err != nil {
+		http.Error(w, "Error getting intake form", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"form":    resp.Form,
+		"success": resp.Success,
+	})
+}
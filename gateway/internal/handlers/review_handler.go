@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/types"
+	"google.golang.org/grpc"
+)
+
+type ReviewHandler struct {
+	Client pb.ReviewServiceClient
+}
+
+func NewReviewHandler(conn *grpc.ClientConn) *ReviewHandler {
+	return &ReviewHandler{Client: pb.NewReviewServiceClient(conn)}
+}
+
+func (h *ReviewHandler) RegisterReviewRoutes(mux *http.ServeMux, keys *rbac.KeySet) {
+	mux.HandleFunc("POST /api/create-review", middleware.JWTAuthMiddleware(keys, h.CreateReviewHandler))
+	mux.HandleFunc("POST /api/moderate-review", middleware.JWTAuthMiddleware(keys, h.ModerateReviewHandler))
+	mux.HandleFunc("POST /api/reply-review", middleware.JWTAuthMiddleware(keys, h.ReplyToReviewHandler))
+	mux.HandleFunc("GET /api/list-reviews", middleware.JWTAuthMiddleware(keys, h.ListReviewsHandler))
+}
+
+func (h *ReviewHandler) CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateReviewRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.CreateReview(ctx, &pb.CreateReviewRequest{
+		AppointmentId: uint32(req.AppointmentID),
+		ClientId:      uint32(req.ClientID),
+		Rating:        uint32(req.Rating),
+		Comment:       req.Comment,
+	})
+	if err != nil {
+		http.Error(w, "Error creating review", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   resp.Message,
+		"success":   resp.Success,
+		"review_id": resp.ReviewId,
+	})
+}
+
+func (h *ReviewHandler) ModerateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.ModerateReviewRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ModerateReview(ctx, &pb.ModerateReviewRequest{
+		ReviewId: uint32(req.ReviewID),
+		Status:   req.Status,
+	})
+	if err != nil {
+		http.Error(w, "Error moderating review", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *ReviewHandler) ReplyToReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var req types.ReplyToReviewRequest
+	if err := JsonDecodeInternal(r, &req); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ReplyToReview(ctx, &pb.ReplyToReviewRequest{
+		ReviewId:       uint32(req.ReviewID),
+		ProfessionalId: uint32(req.ProfessionalID),
+		Reply:          req.Reply,
+	})
+	if err != nil {
+		http.Error(w, "Error replying to review", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": resp.Message,
+		"success": resp.Success,
+	})
+}
+
+func (h *ReviewHandler) ListReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	var professionalID uint64
+	if idStr := r.URL.Query().Get("professional_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid professional_id", http.StatusBadRequest)
+			return
+		}
+		professionalID = id
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	resp, err := h.Client.ListReviews(ctx, &pb.ListReviewsRequest{
+		ProfessionalId: uint32(professionalID),
+		Status:         r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		http.Error(w, "Error getting reviews list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"reviews": resp.Reviews,
+		"success": resp.Success,
+	})
+}
@@ -5,10 +5,14 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"google.golang.org/grpc/metadata"
 )
 
-func JWTAuthMiddleware(secretKey string, next http.HandlerFunc) http.HandlerFunc {
+// JWTAuthMiddleware lets through the requests with a valid, unrevoked token
+// whose roles are allowed on the route by Permissions. The token is forwarded
+// to the services, which check it again.
+func JWTAuthMiddleware(keys *rbac.KeySet, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -25,28 +29,27 @@ func JWTAuthMiddleware(secretKey string, next http.HandlerFunc) http.HandlerFunc
 
 		tokenString := parts[1]
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// sign HS256 validation
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				log.Printf("Unexpected jwt sign method: %v", token.Header["alg"])
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secretKey), nil
-		})
-
+		// Parse and validate token against the keys published by auth
+		claims, err := keys.ParseToken(tokenString)
 		if err != nil {
 			log.Printf("Error validatin token: %v", err)
 			http.Error(w, "Invalid token", http.StatusUnauthorized)
 			return
 		}
 
-		if !token.Valid {
+		// Tokens without an ID can't be revoked, so they aren't accepted
+		if claims.ID == "" || Revocations.Revoked(claims.ID) {
 			http.Error(w, "Invalid token", http.StatusUnauthorized)
 			return
 		}
 
+		if !Permissions.Allowed(r.Pattern, claims) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
 		// Valid token
-		next.ServeHTTP(w, r)
+		ctx := metadata.AppendToOutgoingContext(r.Context(), "authorization", "Bearer "+tokenString)
+		next.ServeHTTP(w, r.WithContext(rbac.NewContext(ctx, claims)))
 	}
 }
@@ -0,0 +1,80 @@
+package middleware
+
+import "github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+
+// Permissions lists the roles allowed on each route behind JWTAuthMiddleware,
+// admins can use all of them. A route missing here is denied to everyone.
+var Permissions = rbac.Permissions{
+	// auth
+	"POST /api/set-roles":       {rbac.RoleAdmin},
+	"POST /api/link-user":       {rbac.RoleAdmin},
+	"POST /api/unlock-login":    {rbac.RoleAdmin},
+	"GET /api/list-users":       {rbac.RoleAdmin},
+	"POST /api/disable-user":    {rbac.RoleAdmin},
+	"POST /api/enable-user":     {rbac.RoleAdmin},
+	"POST /api/delete-user":     {rbac.RoleAdmin},
+	"GET /api/me":               {rbac.Authenticated},
+	"POST /api/change-password": {rbac.Authenticated},
+	"POST /api/mfa/enroll":      {rbac.Authenticated},
+	"POST /api/mfa/activate":    {rbac.Authenticated},
+
+	// professional
+	"POST /api/create-professional":          {rbac.RoleStaff},
+	"GET /api/get-professional":              {rbac.Authenticated},
+	"GET /api/list-professionals":            {rbac.Authenticated},
+	"POST /api/create-location":              {rbac.RoleStaff},
+	"GET /api/get-location":                  {rbac.Authenticated},
+	"GET /api/list-locations":                {rbac.Authenticated},
+	"POST /api/assign-professional-location": {rbac.RoleStaff},
+	"POST /api/create-review":                {rbac.RoleClient},
+	"POST /api/moderate-review":              {rbac.RoleStaff},
+	"POST /api/reply-review":                 {rbac.RoleStaff, rbac.RoleProfessional},
+	"GET /api/list-reviews":                  {rbac.Authenticated},
+
+	// client
+	"POST /api/create-client":  {rbac.RoleStaff, rbac.RoleClient},
+	"GET /api/get-client":      {rbac.RoleStaff, rbac.RoleClient},
+	"GET /api/list-clients":    {rbac.RoleStaff},
+	"POST /api/add-dependent":  {rbac.RoleStaff, rbac.RoleClient},
+	"GET /api/list-dependents": {rbac.RoleStaff, rbac.RoleClient},
+
+	// agenda
+	"POST /api/create-slot":                  {rbac.RoleStaff, rbac.RoleProfessional},
+	"GET /api/list-available-slots":          {rbac.Authenticated},
+	"POST /api/book-appointment":             {rbac.RoleStaff, rbac.RoleClient},
+	"GET /api/list-appointments":             {rbac.Authenticated},
+	"GET /api/me/appointments":               {rbac.Authenticated},
+	"GET /api/get-appointment":               {rbac.Authenticated},
+	"POST /api/complete-appointment":         {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/cancel-appointment":           {rbac.Authenticated},
+	"POST /api/approve-appointment":          {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/decline-appointment":          {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/reassign-appointments":        {rbac.RoleStaff},
+	"GET /api/get-intake-answers":            {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/create-resource":              {rbac.RoleStaff},
+	"GET /api/list-resources":                {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/block-resource":               {rbac.RoleStaff},
+	"POST /api/create-service":               {rbac.RoleStaff},
+	"GET /api/get-service":                   {rbac.Authenticated},
+	"GET /api/list-services":                 {rbac.Authenticated},
+	"POST /api/create-intake-form":           {rbac.RoleStaff, rbac.RoleProfessional},
+	"GET /api/get-intake-form":               {rbac.Authenticated},
+	"POST /api/update-availability-settings": {rbac.RoleStaff, rbac.RoleProfessional},
+	"GET /api/get-availability-settings":     {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/set-availability-rules":       {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/create-time-off":              {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/set-approval-mode":            {rbac.RoleStaff, rbac.RoleProfessional},
+	"POST /api/set-digest-preferences":       {rbac.RoleStaff, rbac.RoleProfessional},
+	"GET /api/get-payment":                   {rbac.Authenticated},
+	"POST /api/add-note":                     {rbac.Authenticated},
+	"GET /api/list-notes":                    {rbac.Authenticated},
+	"POST /api/upload-attachment":            {rbac.Authenticated},
+	"GET /api/list-attachments":              {rbac.Authenticated},
+	"GET /api/download-attachment":           {rbac.Authenticated},
+	"POST /api/create-package":               {rbac.RoleStaff},
+	"GET /api/list-packages":                 {rbac.Authenticated},
+	"POST /api/purchase-package":             {rbac.RoleStaff},
+	"GET /api/get-credit-balance":            {rbac.RoleStaff, rbac.RoleClient},
+	"GET /api/list-credit-history":           {rbac.RoleStaff, rbac.RoleClient},
+	"POST /api/reconcile-agenda":             {rbac.RoleAdmin},
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+)
+
+// RevocationList holds the IDs (jti) of the access tokens revoked before
+// they expire. It's copied from the auth service every so often, so a revoked
+// token may still get through until the next sync.
+type RevocationList struct {
+	mu   sync.RWMutex
+	jtis map[string]struct{}
+}
+
+// Revocations is the list checked by JWTAuthMiddleware.
+var Revocations = &RevocationList{jtis: map[string]struct{}{}}
+
+func (l *RevocationList) Revoked(jti string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.jtis[jti]
+	return ok
+}
+
+// Replace swaps the whole list, the auth service only returns the revoked
+// tokens that haven't expired yet.
+func (l *RevocationList) Replace(jtis []string) {
+	revoked := make(map[string]struct{}, len(jtis))
+	for _, jti := range jtis {
+		revoked[jti] = struct{}{}
+	}
+	l.mu.Lock()
+	l.jtis = revoked
+	l.mu.Unlock()
+}
+
+// SyncRevocations refreshes Revocations from the auth service once per
+// interval, forever. A failed sync keeps the previous list.
+func SyncRevocations(client pb.AuthServiceClient, creds *rbac.ServiceCredentials, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		ctx, err := creds.Context(context.Background())
+		if err != nil {
+			log.Printf("Error syncing revoked tokens: %v", err)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		resp, err := client.ListRevokedTokens(ctx, &pb.ListRevokedTokensRequest{})
+		cancel()
+		if err != nil {
+			log.Printf("Error syncing revoked tokens: %v", err)
+			continue
+		}
+		Revocations.Replace(resp.Jtis)
+	}
+}
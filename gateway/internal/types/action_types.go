@@ -0,0 +1,8 @@
+package types
+
+type ActionRequest struct {
+	Token string `json:"token"`
+	// Nuevo horario, solo para reagendar
+	SlotID    uint   `json:"slot_id,omitempty"`
+	StartTime string `json:"start_time,omitempty"`
+}
@@ -4,19 +4,62 @@ type CreateSlotRequest struct {
 	ProfessionalID uint   `json:"professional_id"`
 	StartTime      string `json:"start_time"`
 	EndTime        string `json:"end_time"`
+	LocationID     uint   `json:"location_id,omitempty"`
 }
 
 type ListAvailableSlotsRequest struct {
-	ProfessionalID uint   `json:"professional_id"`
-	Date           string `json:"date"`
+	ProfessionalID  uint   `json:"professional_id"`
+	Date            string `json:"date"`
+	ServiceID       uint   `json:"service_id,omitempty"`
+	LocationID      uint   `json:"location_id,omitempty"`
+	DurationMinutes uint   `json:"duration_minutes,omitempty"`
 }
 
 type BookAppointmentRequest struct {
-	ClientID uint `json:"client_id"`
-	SlotID   uint `json:"slot_id"`
+	ClientID  uint `json:"client_id"`
+	SlotID    uint `json:"slot_id"`
+	ServiceID uint `json:"service_id,omitempty"`
+	// Profesionales en modo calculado se reservan por hora de inicio
+	ProfessionalID  uint   `json:"professional_id,omitempty"`
+	StartTime       string `json:"start_time,omitempty"`
+	DurationMinutes uint   `json:"duration_minutes,omitempty"`
+	// Respuestas al formulario de admisión por clave del campo
+	IntakeAnswers map[string]string `json:"intake_answers,omitempty"`
+	// "in_person" o "remote", por defecto la del servicio
+	Modality string `json:"modality,omitempty"`
+	// Paga el servicio con un crédito de un paquete del cliente
+	UseCredits bool `json:"use_credits,omitempty"`
+	// Familiar del cliente que asiste a la cita
+	DependentID uint `json:"dependent_id,omitempty"`
 }
 
 type ListAppointmentsRequest struct {
 	ClientID       uint `json:"client_id,omitempty"`
 	ProfessionalID uint `json:"professional_id,omitempty"`
 }
+
+type CompleteAppointmentRequest struct {
+	AppointmentID uint `json:"appointment_id"`
+}
+
+type CancelAppointmentRequest struct {
+	AppointmentID uint `json:"appointment_id"`
+}
+
+type ApproveAppointmentRequest struct {
+	AppointmentID  uint `json:"appointment_id"`
+	ProfessionalID uint `json:"professional_id"`
+}
+
+type DeclineAppointmentRequest struct {
+	AppointmentID  uint   `json:"appointment_id"`
+	ProfessionalID uint   `json:"professional_id"`
+	Reason         string `json:"reason"`
+}
+
+type ReassignAppointmentsRequest struct {
+	SourceProfessionalID uint   `json:"source_professional_id"`
+	TargetProfessionalID uint   `json:"target_professional_id"`
+	From                 string `json:"from"`
+	To                   string `json:"to"`
+}
@@ -3,9 +3,73 @@ package types
 type CreateUserRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Email    string `json:"email"`
 }
 
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SetRolesRequest struct {
+	UserID uint     `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
+
+type LinkUserRequest struct {
+	UserID         uint `json:"user_id"`
+	ClientID       uint `json:"client_id"`
+	ProfessionalID uint `json:"professional_id"`
+}
+
+// UserIDRequest is the body of the routes acting on a user, ie: disable-user.
+type UserIDRequest struct {
+	UserID uint `json:"user_id"`
+}
+
+type RequestPasswordResetRequest struct {
+	UsernameOrEmail string `json:"username_or_email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	UsernameOrEmail string `json:"username_or_email"`
+}
+
+type UnlockLoginRequest struct {
+	Username string `json:"username"`
+	ClientIP string `json:"client_ip"`
+}
+
+type VerifyMFARequest struct {
+	MfaToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// EnrollTOTPRequest and ActivateTOTPRequest carry the MFA token when an
+// account that must use MFA enrolls while logging in.
+type EnrollTOTPRequest struct {
+	MfaToken string `json:"mfa_token"`
+}
+
+type ActivateTOTPRequest struct {
+	MfaToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
@@ -0,0 +1,40 @@
+package types
+
+type UpdateAvailabilitySettingsRequest struct {
+	ProfessionalID         uint   `json:"professional_id"`
+	Mode                   string `json:"mode"`
+	Timezone               string `json:"timezone"`
+	DefaultDurationMinutes uint   `json:"default_duration_minutes"`
+	StepMinutes            uint   `json:"step_minutes"`
+	BufferMinutes          uint   `json:"buffer_minutes"`
+}
+
+type AvailabilityRule struct {
+	Weekday    uint   `json:"weekday"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	LocationID uint   `json:"location_id,omitempty"`
+}
+
+type SetAvailabilityRulesRequest struct {
+	ProfessionalID uint               `json:"professional_id"`
+	Rules          []AvailabilityRule `json:"rules"`
+}
+
+type CreateTimeOffRequest struct {
+	ProfessionalID uint   `json:"professional_id"`
+	StartTime      string `json:"start_time"`
+	EndTime        string `json:"end_time"`
+	Reason         string `json:"reason"`
+}
+
+type SetApprovalModeRequest struct {
+	ProfessionalID   uint `json:"professional_id"`
+	RequiresApproval bool `json:"requires_approval"`
+}
+
+type SetDigestPreferencesRequest struct {
+	ProfessionalID uint   `json:"professional_id"`
+	SendTime       string `json:"send_time"` // "HH:MM" en la zona horaria del profesional
+	OptOut         bool   `json:"opt_out"`
+}
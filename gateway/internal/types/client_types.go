@@ -5,3 +5,9 @@ type CreateClientRequest struct {
 	Email string `json:"email"`
 	Phone string `json:"phone"`
 }
+
+type AddDependentRequest struct {
+	ClientID    uint   `json:"client_id"`
+	Name        string `json:"name"`
+	DateOfBirth string `json:"date_of_birth"`
+}
@@ -0,0 +1,20 @@
+package types
+
+type OpeningHours struct {
+	Weekday uint   `json:"weekday"`
+	Opens   string `json:"opens"`
+	Closes  string `json:"closes"`
+}
+
+type CreateLocationRequest struct {
+	Name         string         `json:"name"`
+	Address      string         `json:"address"`
+	Timezone     string         `json:"timezone"`
+	OpeningHours []OpeningHours `json:"opening_hours"`
+}
+
+type AssignProfessionalRequest struct {
+	ProfessionalID uint `json:"professional_id"`
+	LocationID     uint `json:"location_id"`
+	Weekday        uint `json:"weekday"`
+}
@@ -0,0 +1,9 @@
+package types
+
+type AddNoteRequest struct {
+	AppointmentID  uint   `json:"appointment_id"`
+	ProfessionalID uint   `json:"professional_id,omitempty"`
+	ClientID       uint   `json:"client_id,omitempty"`
+	Visibility     string `json:"visibility,omitempty"`
+	Body           string `json:"body"`
+}
@@ -0,0 +1,16 @@
+package types
+
+type CreatePackageRequest struct {
+	Name       string `json:"name"`
+	Credits    uint   `json:"credits"`
+	ValidDays  uint   `json:"valid_days"`
+	PriceCents uint   `json:"price_cents"`
+	Currency   string `json:"currency"`
+	ServiceIDs []uint `json:"service_ids"`
+}
+
+type PurchasePackageRequest struct {
+	ClientID  uint   `json:"client_id"`
+	PackageID uint   `json:"package_id"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,39 @@
+package types
+
+type CreateResourceRequest struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type BlockResourceRequest struct {
+	ResourceID uint   `json:"resource_id"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	Reason     string `json:"reason"`
+}
+
+type CreateServiceRequest struct {
+	Name            string `json:"name"`
+	DurationMinutes uint   `json:"duration_minutes"`
+	ResourceIDs     []uint `json:"resource_ids"`
+	PriceCents      uint   `json:"price_cents"`
+	DepositCents    uint   `json:"deposit_cents"`
+	Currency        string `json:"currency"`
+	Modality        string `json:"modality,omitempty"`
+	RequiresCredits bool   `json:"requires_credits,omitempty"`
+}
+
+type IntakeField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+}
+
+type CreateIntakeFormRequest struct {
+	ServiceID      uint          `json:"service_id,omitempty"`
+	ProfessionalID uint          `json:"professional_id,omitempty"`
+	Title          string        `json:"title"`
+	Fields         []IntakeField `json:"fields"`
+}
@@ -0,0 +1,19 @@
+package types
+
+type CreateReviewRequest struct {
+	AppointmentID uint   `json:"appointment_id"`
+	ClientID      uint   `json:"client_id"`
+	Rating        uint   `json:"rating"`
+	Comment       string `json:"comment"`
+}
+
+type ModerateReviewRequest struct {
+	ReviewID uint   `json:"review_id"`
+	Status   string `json:"status"`
+}
+
+type ReplyToReviewRequest struct {
+	ReviewID       uint   `json:"review_id"`
+	ProfessionalID uint   `json:"professional_id"`
+	Reply          string `json:"reply"`
+}
@@ -3,22 +3,44 @@ package main
 import (
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/common"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/actionlink"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/handlers"
+	"github.com/lpsaldana/go-appointment-booking-microservices/gateway/internal/middleware"
 )
 
 var (
-	httpAddr  = common.EnvString("HTTP_ADDR", ":3000")
-	secretKey = common.EnvString("JWT_SECRET", "please-dont-use-this-key-12345")
+	httpAddr = common.EnvString("HTTP_ADDR", ":3000")
+	// Firma los tokens del gateway como servicio, los de los usuarios se
+	// verifican con las llaves publicadas por auth
+	secretKey = common.EnvString("JWT_SECRET", rbac.DefaultSecret)
+	devMode   = common.EnvString("DEV_MODE", "false") == "true"
+	// Debe coincidir con el del servicio de notificaciones, que firma los enlaces
+	actionLinkSecret = common.EnvString("ACTION_LINK_SECRET", actionlink.DefaultSecret)
+	// Debe coincidir con el límite de adjuntos del servicio de agenda
+	attachmentMaxBytes = common.EnvString("ATTACHMENT_MAX_BYTES", "5242880")
+	// Cada cuánto se copian los tokens revocados desde el servicio de auth
+	revocationSyncInterval = common.EnvString("REVOCATION_SYNC_INTERVAL", "10s")
+	// Cada cuánto se traen las llaves de auth, menos que SIGNING_KEY_PUBLISH_AHEAD
+	jwksSyncInterval = common.EnvString("JWKS_SYNC_INTERVAL", "5m")
 )
 
 func main() {
+	if err := rbac.CheckSecret(secretKey, devMode); err != nil {
+		log.Fatal(err)
+	}
+	if err := actionlink.CheckSecret(actionLinkSecret, devMode); err != nil {
+		log.Fatal(err)
+	}
 
 	mux := http.NewServeMux()
 
@@ -30,8 +52,23 @@ func main() {
 	defer authConn.Close()
 
 	authClient := pb.NewAuthServiceClient(authConn)
-	authHandler := handlers.NewAuthHandler(authClient)
-	authHandler.RegisterAuthRoutes(mux)
+	keysInterval, err := time.ParseDuration(jwksSyncInterval)
+	if err != nil {
+		log.Fatalf("Invalid JWKS_SYNC_INTERVAL: %v", err)
+	}
+	keys := rbac.NewKeySet(secretKey, rbac.FetchJWKS(authClient))
+	go keys.Sync(keysInterval)
+
+	authHandler := handlers.NewAuthHandler(authClient, keys)
+	authHandler.RegisterAuthRoutes(mux, keys)
+
+	syncInterval, err := time.ParseDuration(revocationSyncInterval)
+	if err != nil {
+		log.Fatalf("Invalid REVOCATION_SYNC_INTERVAL: %v", err)
+	}
+	// El gateway se identifica como servicio donde no hay un usuario detrás
+	serviceCreds := rbac.NewServiceCredentials(secretKey, "gateway")
+	go middleware.SyncRevocations(authClient, serviceCreds, syncInterval)
 
 	//professional_server
 	profConn, err := grpc.NewClient("localhost:50052", grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -41,7 +78,11 @@ func main() {
 	defer profConn.Close()
 
 	profHandler := handlers.NewProfessionalHandler(profConn)
-	profHandler.RegisterProfessionalRoutes(mux, secretKey)
+	profHandler.RegisterProfessionalRoutes(mux, keys)
+	locationHandler := handlers.NewLocationHandler(profConn)
+	locationHandler.RegisterLocationRoutes(mux, keys)
+	reviewHandler := handlers.NewReviewHandler(profConn)
+	reviewHandler.RegisterReviewRoutes(mux, keys)
 
 	//client_server
 	clientConn, err := grpc.NewClient("localhost:50053", grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -51,7 +92,7 @@ func main() {
 	defer clientConn.Close()
 
 	clientHandler := handlers.NewClientHandler(clientConn)
-	clientHandler.RegisterClientRoutes(mux, secretKey)
+	clientHandler.RegisterClientRoutes(mux, keys)
 
 	//agenda_server
 	agendaConn, err := grpc.NewClient("localhost:50054", grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -60,7 +101,25 @@ func main() {
 	}
 	defer agendaConn.Close()
 	agendaHandler := handlers.NewAgendaHandler(agendaConn)
-	agendaHandler.RegisterAgendaRoutes(mux, secretKey)
+	agendaHandler.RegisterAgendaRoutes(mux, keys)
+	resourceHandler := handlers.NewResourceHandler(agendaConn)
+	resourceHandler.RegisterResourceRoutes(mux, keys)
+	availabilityHandler := handlers.NewAvailabilityHandler(agendaConn)
+	availabilityHandler.RegisterAvailabilityRoutes(mux, keys)
+	paymentHandler := handlers.NewPaymentHandler(agendaConn)
+	paymentHandler.RegisterPaymentRoutes(mux, keys)
+	actionHandler := handlers.NewActionHandler(agendaConn, actionLinkSecret, serviceCreds)
+	actionHandler.RegisterActionRoutes(mux)
+	maxUploadBytes, err := strconv.Atoi(attachmentMaxBytes)
+	if err != nil || maxUploadBytes <= 0 {
+		log.Fatalf("Invalid ATTACHMENT_MAX_BYTES: %s", attachmentMaxBytes)
+	}
+	noteHandler := handlers.NewNoteHandler(agendaConn, maxUploadBytes)
+	noteHandler.RegisterNoteRoutes(mux, keys)
+	packageHandler := handlers.NewPackageHandler(agendaConn)
+	packageHandler.RegisterPackageRoutes(mux, keys)
+	reconcileHandler := handlers.NewReconcileHandler(agendaConn)
+	reconcileHandler.RegisterReconcileRoutes(mux, keys)
 
 	log.Printf("Starting HTTP server at %s", httpAddr)
 
@@ -3,6 +3,7 @@ module github.com/lpsaldana/go-appointment-booking-microservices/notification
 go 1.23.2
 
 require (
+	github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
 	golang.org/x/net v0.32.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
@@ -17,9 +17,49 @@ func NewNotificationHandler(svc services.NotificationService) *NotificationHandl
 }
 
 func (h *NotificationHandler) SendAppointmentNotification(ctx context.Context, req *pb.SendAppointmentNotificationRequest) (*pb.SendAppointmentNotificationResponse, error) {
-	msg, success, err := h.Service.SendAppointmentNotification(req.ClientId, req.ProfessionalId, req.AppointmentId, req.StartTime, req.EndTime)
+	msg, success, err := h.Service.SendAppointmentNotification(req.ClientId, req.ProfessionalId, req.AppointmentId, req.LocationId, req.DependentId, req.StartTime, req.EndTime, req.MeetingUrl)
 	if err != nil {
 		return &pb.SendAppointmentNotificationResponse{Message: msg, Success: false}, err
 	}
 	return &pb.SendAppointmentNotificationResponse{Message: msg, Success: success}, nil
 }
+
+func (h *NotificationHandler) SendReviewRequest(ctx context.Context, req *pb.SendReviewRequestRequest) (*pb.SendReviewRequestResponse, error) {
+	msg, success, err := h.Service.SendReviewRequest(req.ClientId, req.ProfessionalId, req.AppointmentId)
+	if err != nil {
+		return &pb.SendReviewRequestResponse{Message: msg, Success: false}, err
+	}
+	return &pb.SendReviewRequestResponse{Message: msg, Success: success}, nil
+}
+
+func (h *NotificationHandler) SendAppointmentUpdate(ctx context.Context, req *pb.SendAppointmentUpdateRequest) (*pb.SendAppointmentUpdateResponse, error) {
+	msg, success, err := h.Service.SendAppointmentUpdate(req)
+	if err != nil {
+		return &pb.SendAppointmentUpdateResponse{Message: msg, Success: false}, err
+	}
+	return &pb.SendAppointmentUpdateResponse{Message: msg, Success: success}, nil
+}
+
+func (h *NotificationHandler) SendDailyDigest(ctx context.Context, req *pb.SendDailyDigestRequest) (*pb.SendDailyDigestResponse, error) {
+	msg, success, err := h.Service.SendDailyDigest(req)
+	if err != nil {
+		return &pb.SendDailyDigestResponse{Message: msg, Success: false}, err
+	}
+	return &pb.SendDailyDigestResponse{Message: msg, Success: success}, nil
+}
+
+func (h *NotificationHandler) SendPasswordReset(ctx context.Context, req *pb.SendPasswordResetRequest) (*pb.SendPasswordResetResponse, error) {
+	msg, success, err := h.Service.SendPasswordReset(req)
+	if err != nil {
+		return &pb.SendPasswordResetResponse{Message: msg, Success: false}, err
+	}
+	return &pb.SendPasswordResetResponse{Message: msg, Success: success}, nil
+}
+
+func (h *NotificationHandler) SendEmailVerification(ctx context.Context, req *pb.SendEmailVerificationRequest) (*pb.SendEmailVerificationResponse, error) {
+	msg, success, err := h.Service.SendEmailVerification(req)
+	if err != nil {
+		return &pb.SendEmailVerificationResponse{Message: msg, Success: false}, err
+	}
+	return &pb.SendEmailVerificationResponse{Message: msg, Success: success}, nil
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import "github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+
+// Permissions lists the roles allowed to call each method, the notifications
+// are only sent on behalf of the other services.
+var Permissions = rbac.Permissions{
+	"/pb.NotificationService/SendAppointmentNotification": {rbac.RoleService},
+	"/pb.NotificationService/SendReviewRequest":           {rbac.RoleService},
+	"/pb.NotificationService/SendAppointmentUpdate":       {rbac.RoleService},
+	"/pb.NotificationService/SendDailyDigest":             {rbac.RoleService},
+	"/pb.NotificationService/SendPasswordReset":           {rbac.RoleService},
+	"/pb.NotificationService/SendEmailVerification":       {rbac.RoleService},
+}
@@ -4,31 +4,64 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/actionlink"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
 	"github.com/lpsaldana/go-appointment-booking-microservices/notification/internal/config"
 	"google.golang.org/grpc"
 )
 
 type NotificationService interface {
-	SendAppointmentNotification(clientID, professionalID, appointmentID uint32, startTime, endTime string) (string, bool, error)
+	SendAppointmentNotification(clientID, professionalID, appointmentID, locationID, dependentID uint32, startTime, endTime, meetingURL string) (string, bool, error)
+	SendReviewRequest(clientID, professionalID, appointmentID uint32) (string, bool, error)
+	SendAppointmentUpdate(req *pb.SendAppointmentUpdateRequest) (string, bool, error)
+	SendDailyDigest(req *pb.SendDailyDigestRequest) (string, bool, error)
+	SendPasswordReset(req *pb.SendPasswordResetRequest) (string, bool, error)
+	SendEmailVerification(req *pb.SendEmailVerificationRequest) (string, bool, error)
+}
+
+// appointmentUpdates holds the subject and opening line of the email sent to
+// the client for each appointment event.
+var appointmentUpdates = map[string]struct{ subject, text string }{
+	"requested":   {"Solicitud de Cita Recibida", "Su solicitud de cita fue enviada al profesional y está a la espera de su aprobación."},
+	"approved":    {"Cita Aprobada", "El profesional aprobó su solicitud, su cita quedó confirmada."},
+	"declined":    {"Solicitud de Cita Rechazada", "Lamentablemente el profesional no pudo aceptar su solicitud de cita."},
+	"expired":     {"Solicitud de Cita Expirada", "Su solicitud de cita expiró sin respuesta del profesional y el horario fue liberado."},
+	"rescheduled": {"Cita Reagendada", "Su cita fue cambiada a un nuevo horario."},
+	"reassigned":  {"Cambio de Profesional", "Su cita fue reasignada a otro profesional, se mantiene el mismo horario."},
+}
+
+// linkedUpdates are the events after which the client can still manage the
+// appointment, their emails carry the self-service links.
+var linkedUpdates = map[string]bool{"requested": true, "approved": true, "rescheduled": true, "reassigned": true}
+
+// ActionLinkConfig holds what's needed to sign the self-service links sent to
+// clients. The gateway verifies them with the same secret.
+type ActionLinkConfig struct {
+	Secret  []byte
+	BaseURL string // ie: "http://localhost:3000/api/actions"
 }
 
 type NotificationServiceImpl struct {
-	SMTPConfig    *config.SMTPConfig
-	ClientsClient pb.ClientServiceClient
-	ProfClient    pb.ProfessionalServiceClient
+	SMTPConfig     *config.SMTPConfig
+	ClientsClient  pb.ClientServiceClient
+	ProfClient     pb.ProfessionalServiceClient
+	LocationClient pb.LocationServiceClient
+	Links          ActionLinkConfig
 }
 
-func NewNotificationService(smtpConfig *config.SMTPConfig, clientsConn, profConn *grpc.ClientConn) NotificationService {
+func NewNotificationService(smtpConfig *config.SMTPConfig, links ActionLinkConfig, clientsConn, profConn *grpc.ClientConn) NotificationService {
 	return &NotificationServiceImpl{
-		SMTPConfig:    smtpConfig,
-		ClientsClient: pb.NewClientServiceClient(clientsConn),
-		ProfClient:    pb.NewProfessionalServiceClient(profConn),
+		SMTPConfig:     smtpConfig,
+		Links:          links,
+		ClientsClient:  pb.NewClientServiceClient(clientsConn),
+		ProfClient:     pb.NewProfessionalServiceClient(profConn),
+		LocationClient: pb.NewLocationServiceClient(profConn),
 	}
 }
 
-func (s *NotificationServiceImpl) SendAppointmentNotification(clientID, professionalID, appointmentID uint32, startTime, endTime string) (string, bool, error) {
+func (s *NotificationServiceImpl) SendAppointmentNotification(clientID, professionalID, appointmentID, locationID, dependentID uint32, startTime, endTime, meetingURL string) (string, bool, error) {
 	clientResp, err := s.ClientsClient.GetClient(context.TODO(), &pb.GetClientRequest{Id: clientID})
 	if err != nil {
 		log.Printf("Error obtaining client data: %v", err)
@@ -41,27 +74,368 @@ func (s *NotificationServiceImpl) SendAppointmentNotification(clientID, professi
 		return "Error obtaining professional data", false, err
 	}
 
+	branch := ""
+	if locationID != 0 {
+		locResp, err := s.LocationClient.GetLocation(context.TODO(), &pb.GetLocationRequest{Id: locationID})
+		if err != nil {
+			log.Printf("Error obtaining location data: %v", err)
+			return "Error obtaining location data", false, err
+		}
+		startTime, endTime = localTime(startTime, locResp.Location.Timezone), localTime(endTime, locResp.Location.Timezone)
+		branch = fmt.Sprintf("- Sucursal: %s\n- Dirección: %s\n", locResp.Location.Name, locResp.Location.Address)
+	}
+	attendee, err := s.dependentDetails(dependentID)
+	if err != nil {
+		log.Printf("Error obtaining dependent data: %v", err)
+		return "Error obtaining dependent data", false, err
+	}
+
 	clientEmail := clientResp.Client.Email
 	profEmail := profResp.Professional.Contact
 
 	subject := "Cita Registrada Exitosamente"
-	body := fmt.Sprintf("Estimado/a,\n\nSu cita ha sido registrada exitosamente.\n\n"+
+	details := fmt.Sprintf("Estimado/a,\n\nSu cita ha sido registrada exitosamente.\n\n"+
 		"Detalles de la cita:\n"+
 		"- ID de la cita: %d\n"+
+		"%s"+
 		"- Inicio: %s\n"+
-		"- Fin: %s\n\n"+
-		"Gracias por usar nuestro sistema.\nSaludos,\nEquipo de Agendamiento",
-		appointmentID, startTime, endTime)
+		"- Fin: %s\n"+
+		"%s%s\n",
+		appointmentID, attendee, startTime, endTime, branch, meetingDetails(meetingURL))
+	closing := "Gracias por usar nuestro sistema.\nSaludos,\nEquipo de Agendamiento"
 
-	err = s.SMTPConfig.SendMail([]string{clientEmail}, subject, body)
+	// Solo el correo del cliente lleva los enlaces para gestionar la cita
+	err = s.SMTPConfig.SendMail([]string{clientEmail}, subject, details+s.actionLinks(appointmentID, startTime)+closing)
 	if err != nil {
 		return "Error sending client notification", false, err
 	}
 
-	err = s.SMTPConfig.SendMail([]string{profEmail}, subject, body)
+	err = s.SMTPConfig.SendMail([]string{profEmail}, subject, details+closing)
 	if err != nil {
 		return "Error sending client notification", false, err
 	}
 
 	return "Notification send success", true, nil
 }
+
+func (s *NotificationServiceImpl) SendReviewRequest(clientID, professionalID, appointmentID uint32) (string, bool, error) {
+	clientResp, err := s.ClientsClient.GetClient(context.TODO(), &pb.GetClientRequest{Id: clientID})
+	if err != nil {
+		log.Printf("Error obtaining client data: %v", err)
+		return "Error obtaining client data", false, err
+	}
+
+	profResp, err := s.ProfClient.GetProfessional(context.TODO(), &pb.GetProfessionalRequest{Id: professionalID})
+	if err != nil {
+		log.Printf("Error obtaining professional data: %v", err)
+		return "Error obtaining professional data", false, err
+	}
+
+	subject := "¿Cómo fue su cita?"
+	body := fmt.Sprintf("Estimado/a %s,\n\nGracias por asistir a su cita con %s.\n\n"+
+		"Nos gustaría conocer su opinión. Puede calificar la atención de 1 a 5 y dejar un comentario "+
+		"indicando el ID de la cita: %d\n\n"+
+		"Saludos,\nEquipo de Agendamiento",
+		clientResp.Client.Name, profResp.Professional.Name, appointmentID)
+
+	if err := s.SMTPConfig.SendMail([]string{clientResp.Client.Email}, subject, body); err != nil {
+		return "Error sending review request", false, err
+	}
+
+	return "Review request send success", true, nil
+}
+
+func (s *NotificationServiceImpl) SendAppointmentUpdate(req *pb.SendAppointmentUpdateRequest) (string, bool, error) {
+	update, ok := appointmentUpdates[req.Event]
+	if !ok {
+		return "Unknown appointment event", false, nil
+	}
+
+	clientResp, err := s.ClientsClient.GetClient(context.TODO(), &pb.GetClientRequest{Id: req.ClientId})
+	if err != nil {
+		log.Printf("Error obtaining client data: %v", err)
+		return "Error obtaining client data", false, err
+	}
+
+	profResp, err := s.ProfClient.GetProfessional(context.TODO(), &pb.GetProfessionalRequest{Id: req.ProfessionalId})
+	if err != nil {
+		log.Printf("Error obtaining professional data: %v", err)
+		return "Error obtaining professional data", false, err
+	}
+
+	startTime, endTime, branch := req.StartTime, req.EndTime, ""
+	if req.LocationId != 0 {
+		locResp, err := s.LocationClient.GetLocation(context.TODO(), &pb.GetLocationRequest{Id: req.LocationId})
+		if err != nil {
+			log.Printf("Error obtaining location data: %v", err)
+			return "Error obtaining location data", false, err
+		}
+		startTime, endTime = localTime(startTime, locResp.Location.Timezone), localTime(endTime, locResp.Location.Timezone)
+		branch = fmt.Sprintf("- Sucursal: %s\n- Dirección: %s\n", locResp.Location.Name, locResp.Location.Address)
+	}
+
+	attendee, err := s.dependentDetails(req.DependentId)
+	if err != nil {
+		log.Printf("Error obtaining dependent data: %v", err)
+		return "Error obtaining dependent data", false, err
+	}
+
+	reason := ""
+	if req.Reason != "" {
+		reason = fmt.Sprintf("Motivo: %s\n\n", req.Reason)
+	}
+	details := fmt.Sprintf("Detalles de la cita:\n"+
+		"- ID de la cita: %d\n"+
+		"%s"+
+		"- Profesional: %s\n"+
+		"- Inicio: %s\n"+
+		"- Fin: %s\n"+
+		"%s%s\n", req.AppointmentId, attendee, profResp.Professional.Name, startTime, endTime, branch, meetingDetails(req.MeetingUrl))
+
+	links := ""
+	if linkedUpdates[req.Event] {
+		links = s.actionLinks(req.AppointmentId, req.StartTime)
+	}
+	body := fmt.Sprintf("Estimado/a %s,\n\n%s\n\n%s%s%sSaludos,\nEquipo de Agendamiento",
+		clientResp.Client.Name, update.text, reason, details, links)
+	if err := s.SMTPConfig.SendMail([]string{clientResp.Client.Email}, update.subject, body); err != nil {
+		return "Error sending client notification", false, err
+	}
+
+	// El profesional debe enterarse de las solicitudes que tiene que responder
+	if req.Event == "requested" {
+		body := fmt.Sprintf("Estimado/a %s,\n\n%s solicitó una cita que requiere su aprobación.\n\n%s"+
+			"Puede aprobarla o rechazarla antes de que expire.\n\nSaludos,\nEquipo de Agendamiento",
+			profResp.Professional.Name, clientResp.Client.Name, details)
+		if err := s.SMTPConfig.SendMail([]string{profResp.Professional.Contact}, "Nueva Solicitud de Cita", body); err != nil {
+			return "Error sending professional notification", false, err
+		}
+	}
+	if req.Event == "rescheduled" {
+		body := fmt.Sprintf("Estimado/a %s,\n\n%s cambió su cita a un nuevo horario.\n\n%sSaludos,\nEquipo de Agendamiento",
+			profResp.Professional.Name, clientResp.Client.Name, details)
+		if err := s.SMTPConfig.SendMail([]string{profResp.Professional.Contact}, "Cita Reagendada", body); err != nil {
+			return "Error sending professional notification", false, err
+		}
+	}
+
+	return "Notification send success", true, nil
+}
+
+// SendDailyDigest emails the professional the summary of their day, with the
+// name and phone of each client.
+func (s *NotificationServiceImpl) SendDailyDigest(req *pb.SendDailyDigestRequest) (string, bool, error) {
+	profResp, err := s.ProfClient.GetProfessional(context.TODO(), &pb.GetProfessionalRequest{Id: req.ProfessionalId})
+	if err != nil {
+		log.Printf("Error obtaining professional data: %v", err)
+		return "Error obtaining professional data", false, err
+	}
+	tz, err := time.LoadLocation(req.Timezone)
+	if err != nil {
+		tz = time.UTC
+	}
+
+	// Un cliente puede tener varias citas en el día, se consulta una sola vez
+	clients := map[uint32]*pb.Client{}
+	lines := func(entries []*pb.DigestEntry) (string, error) {
+		text := ""
+		for _, entry := range entries {
+			client, ok := clients[entry.ClientId]
+			if !ok {
+				clientResp, err := s.ClientsClient.GetClient(context.TODO(), &pb.GetClientRequest{Id: entry.ClientId})
+				if err != nil {
+					return "", err
+				}
+				client = clientResp.Client
+				clients[entry.ClientId] = client
+			}
+			name := client.Name
+			if entry.DependentId != 0 {
+				dependentResp, err := s.ClientsClient.GetDependent(context.TODO(), &pb.GetDependentRequest{Id: entry.DependentId})
+				if err != nil {
+					return "", err
+				}
+				name = fmt.Sprintf("%s (a cargo de %s)", dependentResp.Dependent.Name, client.Name)
+			}
+			text += fmt.Sprintf("- %s - %s: %s, teléfono %s (cita %d",
+				digestTime(entry.StartTime, tz, "02/01 15:04"), digestTime(entry.EndTime, tz, "15:04"),
+				name, client.Phone, entry.AppointmentId)
+			if entry.Status == "pending_approval" {
+				text += ", pendiente de aprobación"
+			}
+			if entry.MeetingUrl != "" {
+				text += ", en línea: " + entry.MeetingUrl
+			}
+			text += ")\n"
+		}
+		return text, nil
+	}
+
+	appointments, err := lines(req.Appointments)
+	if err != nil {
+		log.Printf("Error obtaining client data: %v", err)
+		return "Error obtaining client data", false, err
+	}
+	if appointments == "" {
+		appointments = "No tiene citas agendadas para hoy.\n"
+	}
+	cancellations, err := lines(req.Cancellations)
+	if err != nil {
+		log.Printf("Error obtaining client data: %v", err)
+		return "Error obtaining client data", false, err
+	}
+	if cancellations != "" {
+		cancellations = "\nCitas canceladas desde el último resumen:\n" + cancellations
+	}
+
+	subject := fmt.Sprintf("Su Agenda del %s", req.Date)
+	body := fmt.Sprintf("Estimado/a %s,\n\nEstas son sus citas de hoy:\n%s%s\n"+
+		"Si no desea recibir este resumen puede desactivarlo en sus preferencias.\n\nSaludos,\nEquipo de Agendamiento",
+		profResp.Professional.Name, appointments, cancellations)
+	if err := s.SMTPConfig.SendMail([]string{profResp.Professional.Contact}, subject, body); err != nil {
+		return "Error sending daily digest", false, err
+	}
+
+	return "Daily digest send success", true, nil
+}
+
+// SendPasswordReset emails the link to reset the password. Accounts without an
+// email address get it at the one of their client or professional record.
+func (s *NotificationServiceImpl) SendPasswordReset(req *pb.SendPasswordResetRequest) (string, bool, error) {
+	email := req.Email
+	if email == "" && req.ClientId != 0 {
+		clientResp, err := s.ClientsClient.GetClient(context.TODO(), &pb.GetClientRequest{Id: req.ClientId})
+		if err != nil {
+			log.Printf("Error obtaining client data: %v", err)
+			return "Error obtaining client data", false, err
+		}
+		email = clientResp.Client.Email
+	}
+	if email == "" && req.ProfessionalId != 0 {
+		profResp, err := s.ProfClient.GetProfessional(context.TODO(), &pb.GetProfessionalRequest{Id: req.ProfessionalId})
+		if err != nil {
+			log.Printf("Error obtaining professional data: %v", err)
+			return "Error obtaining professional data", false, err
+		}
+		email = profResp.Professional.Contact
+	}
+	if email == "" {
+		return "No email address to send to", false, nil
+	}
+
+	subject := "Restablecer Contraseña"
+	body := fmt.Sprintf("Estimado/a,\n\nRecibimos una solicitud para restablecer la contraseña de su cuenta.\n\n"+
+		"Puede elegir una nueva con el siguiente enlace, válido una sola vez hasta el %s:\n%s\n\n"+
+		"Si no la solicitó puede ignorar este correo, su contraseña no cambiará.\n\nSaludos,\nEquipo de Agendamiento",
+		emailTime(req.ExpiresAt), req.ResetUrl)
+	if err := s.SMTPConfig.SendMail([]string{email}, subject, body); err != nil {
+		return "Error sending password reset", false, err
+	}
+
+	return "Password reset send success", true, nil
+}
+
+// SendEmailVerification emails the link that confirms the address of a new
+// account.
+func (s *NotificationServiceImpl) SendEmailVerification(req *pb.SendEmailVerificationRequest) (string, bool, error) {
+	if req.Email == "" {
+		return "No email address to send to", false, nil
+	}
+
+	subject := "Confirme su Correo"
+	body := fmt.Sprintf("Estimado/a,\n\nGracias por registrarse. Para activar su cuenta confirme su correo "+
+		"con el siguiente enlace, válido hasta el %s:\n%s\n\n"+
+		"Si no creó esta cuenta puede ignorar este correo.\n\nSaludos,\nEquipo de Agendamiento",
+		emailTime(req.ExpiresAt), req.VerifyUrl)
+	if err := s.SMTPConfig.SendMail([]string{req.Email}, subject, body); err != nil {
+		return "Error sending email verification", false, err
+	}
+
+	return "Email verification send success", true, nil
+}
+
+// dependentDetails returns the line naming the family member the appointment
+// is for, appointments of the client themself have none.
+func (s *NotificationServiceImpl) dependentDetails(dependentID uint32) (string, error) {
+	if dependentID == 0 {
+		return "", nil
+	}
+	resp, err := s.ClientsClient.GetDependent(context.TODO(), &pb.GetDependentRequest{Id: dependentID})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("- Cita para: %s\n", resp.Dependent.Name), nil
+}
+
+// meetingDetails returns the lines of a remote appointment with the link to
+// join it, in-person appointments have none.
+func meetingDetails(meetingURL string) string {
+	if meetingURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("- Modalidad: en línea\n- Enlace de la reunión: %s\n", meetingURL)
+}
+
+// actionLinks returns the email section with the signed links the client uses
+// to confirm, cancel or reschedule the appointment. The links stop working when
+// the appointment starts. It returns an empty section when they can't be signed.
+func (s *NotificationServiceImpl) actionLinks(appointmentID uint32, startTime string) string {
+	if len(s.Links.Secret) == 0 {
+		return ""
+	}
+	expiresAt, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		log.Printf("Error signing action links: %v", err)
+		return ""
+	}
+
+	labels := map[string]string{
+		actionlink.ActionConfirm:    "Confirmar asistencia",
+		actionlink.ActionCancel:     "Cancelar la cita",
+		actionlink.ActionReschedule: "Elegir otro horario",
+	}
+	section := "Puede gestionar su cita con los siguientes enlaces:\n"
+	for _, action := range actionlink.Actions {
+		token, err := actionlink.Sign(s.Links.Secret, appointmentID, action, expiresAt)
+		if err != nil {
+			log.Printf("Error signing action links: %v", err)
+			return ""
+		}
+		section += fmt.Sprintf("- %s: %s/%s?token=%s\n", labels[action], s.Links.BaseURL, action, token)
+	}
+	return section + "\n"
+}
+
+// digestTime formats an RFC 3339 timestamp in the professional timezone,
+// leaving it untouched when it can't be parsed.
+func digestTime(value string, tz *time.Location, layout string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.In(tz).Format(layout)
+}
+
+// emailTime formats an RFC 3339 timestamp for the account emails, leaving it
+// untouched when it can't be parsed.
+func emailTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Format("02/01/2006 15:04 MST")
+}
+
+// localTime expresses an RFC 3339 timestamp in the branch timezone, leaving it
+// untouched when either value can't be parsed.
+func localTime(value, timezone string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	tz, err := time.LoadLocation(timezone)
+	if err != nil {
+		return value
+	}
+	return t.In(tz).Format(time.RFC3339)
+}
@@ -3,8 +3,12 @@ package main
 import (
 	"log"
 	"net"
+	"time"
 
+	"github.com/lpsaldana/go-appointment-booking-microservices/common"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/actionlink"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/lpsaldana/go-appointment-booking-microservices/notification/internal/config"
 	"github.com/lpsaldana/go-appointment-booking-microservices/notification/internal/handlers"
 	"github.com/lpsaldana/go-appointment-booking-microservices/notification/internal/services"
@@ -12,22 +16,55 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+var (
+	// Debe coincidir con el del servicio de auth, firma también los tokens entre servicios
+	secretKey = common.EnvString("JWT_SECRET", rbac.DefaultSecret)
+	devMode   = common.EnvString("DEV_MODE", "false") == "true"
+	// Secreto compartido con el gateway para firmar los enlaces de autogestión
+	actionLinkSecret  = common.EnvString("ACTION_LINK_SECRET", actionlink.DefaultSecret)
+	actionLinkBaseURL = common.EnvString("ACTION_LINK_BASE_URL", "http://localhost:3000/api/actions")
+	// "remote" le pregunta a auth y así respeta los tokens revocados, "local"
+	// solo revisa las llaves publicadas y acepta un token hasta que expira
+	tokenValidation = common.EnvString("TOKEN_VALIDATION", rbac.ValidationRemote)
+	// Cada cuánto se traen las llaves de auth, menos que SIGNING_KEY_PUBLISH_AHEAD
+	jwksSyncInterval = common.EnvString("JWKS_SYNC_INTERVAL", "5m")
+)
+
 func main() {
+	if err := rbac.CheckSecret(secretKey, devMode); err != nil {
+		log.Fatal(err)
+	}
+	if err := actionlink.CheckSecret(actionLinkSecret, devMode); err != nil {
+		log.Fatal(err)
+	}
+
+	// Las llamadas a otros servicios van con un token de servicio
+	serviceCreds := rbac.NewServiceCredentials(secretKey, "notification")
+	authConn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to auth server: %v", err)
+	}
+	defer authConn.Close()
+
 	smtpConfig := config.NewSMTPConfig()
 
-	clientsConn, err := grpc.NewClient("localhost:50053", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	clientsConn, err := grpc.NewClient("localhost:50053", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
 	if err != nil {
 		log.Fatalf("Cannot connect to client service: %v", err)
 	}
 	defer clientsConn.Close()
 
-	profConn, err := grpc.NewClient("localhost:50052", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	profConn, err := grpc.NewClient("localhost:50052", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
 	if err != nil {
 		log.Fatalf("Cannot connect to profesional service: %v", err)
 	}
 	defer profConn.Close()
 
-	svc := services.NewNotificationService(smtpConfig, clientsConn, profConn)
+	svc := services.NewNotificationService(smtpConfig,
+		services.ActionLinkConfig{Secret: []byte(actionLinkSecret), BaseURL: actionLinkBaseURL}, clientsConn, profConn)
 	handler := handlers.NewNotificationHandler(svc)
 
 	lis, err := net.Listen("tcp", ":50055")
@@ -35,7 +72,19 @@ func main() {
 		log.Fatalf("Error listening to port 50055: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	syncInterval, err := time.ParseDuration(jwksSyncInterval)
+	if err != nil {
+		log.Fatalf("Invalid JWKS_SYNC_INTERVAL: %v", err)
+	}
+	authClient := pb.NewAuthServiceClient(authConn)
+	keys := rbac.NewKeySet(secretKey, rbac.FetchJWKS(authClient))
+	go keys.Sync(syncInterval)
+	validator, err := rbac.NewValidator(tokenValidation, keys, authClient)
+	if err != nil {
+		log.Fatalf("Invalid TOKEN_VALIDATION: %v", err)
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(rbac.UnaryServerInterceptor(validator, handlers.Permissions)),
+		grpc.StreamInterceptor(rbac.StreamServerInterceptor(validator, handlers.Permissions)))
 	pb.RegisterNotificationServiceServer(grpcServer, handler)
 
 	log.Println("Server runing on port :50055...")
@@ -23,7 +23,8 @@ func (c *DBConfig) ConnectDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&models.Professional{}); err != nil {
+	if err := db.AutoMigrate(&models.Professional{}, &models.ProfessionalLocation{},
+		&models.Location{}, &models.OpeningHours{}, &models.Review{}); err != nil {
 		log.Printf("Error in DB migration: %v", err)
 		return nil, err
 	}
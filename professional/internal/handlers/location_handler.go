@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/services"
+)
+
+type LocationHandler struct {
+	pb.UnimplementedLocationServiceServer
+	Service services.LocationService
+}
+
+func NewLocationHandler(service services.LocationService) *LocationHandler {
+	return &LocationHandler{Service: service}
+}
+
+func (h *LocationHandler) CreateLocation(ctx context.Context, req *pb.CreateLocationRequest) (*pb.CreateLocationResponse, error) {
+	return h.Service.CreateLocation(req)
+}
+
+func (h *LocationHandler) GetLocation(ctx context.Context, req *pb.GetLocationRequest) (*pb.GetLocationResponse, error) {
+	return h.Service.GetLocation(req)
+}
+
+func (h *LocationHandler) ListLocations(ctx context.Context, req *pb.ListLocationsRequest) (*pb.ListLocationsResponse, error) {
+	return h.Service.ListLocations(req)
+}
+
+func (h *LocationHandler) AssignProfessional(ctx context.Context, req *pb.AssignProfessionalRequest) (*pb.AssignProfessionalResponse, error) {
+	return h.Service.AssignProfessional(req)
+}
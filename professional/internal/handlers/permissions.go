@@ -0,0 +1,21 @@
+package handlers
+
+import "github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+
+// Permissions lists the roles allowed to call each method, admins and the
+// other services can call all of them.
+var Permissions = rbac.Permissions{
+	"/pb.ProfessionalService/CreateProfessional": {rbac.RoleStaff},
+	"/pb.ProfessionalService/GetProfessional":    {rbac.Authenticated},
+	"/pb.ProfessionalService/ListProfessionals":  {rbac.Authenticated},
+
+	"/pb.LocationService/CreateLocation":     {rbac.RoleStaff},
+	"/pb.LocationService/GetLocation":        {rbac.Authenticated},
+	"/pb.LocationService/ListLocations":      {rbac.Authenticated},
+	"/pb.LocationService/AssignProfessional": {rbac.RoleStaff},
+
+	"/pb.ReviewService/CreateReview":   {rbac.RoleClient},
+	"/pb.ReviewService/ModerateReview": {rbac.RoleStaff},
+	"/pb.ReviewService/ReplyToReview":  {rbac.RoleStaff, rbac.RoleProfessional},
+	"/pb.ReviewService/ListReviews":    {rbac.Authenticated},
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/services"
+)
+
+type ReviewHandler struct {
+	pb.UnimplementedReviewServiceServer
+	Service services.ReviewService
+}
+
+func NewReviewHandler(service services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{Service: service}
+}
+
+func (h *ReviewHandler) CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (*pb.CreateReviewResponse, error) {
+	return h.Service.CreateReview(ctx, req)
+}
+
+func (h *ReviewHandler) ModerateReview(ctx context.Context, req *pb.ModerateReviewRequest) (*pb.ModerateReviewResponse, error) {
+	return h.Service.ModerateReview(req)
+}
+
+func (h *ReviewHandler) ReplyToReview(ctx context.Context, req *pb.ReplyToReviewRequest) (*pb.ReplyToReviewResponse, error) {
+	return h.Service.ReplyToReview(ctx, req)
+}
+
+func (h *ReviewHandler) ListReviews(ctx context.Context, req *pb.ListReviewsRequest) (*pb.ListReviewsResponse, error) {
+	return h.Service.ListReviews(ctx, req)
+}
@@ -0,0 +1,18 @@
+package models
+
+type Location struct {
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"not null"`
+	Address      string `gorm:"not null"`
+	Timezone     string `gorm:"not null"`
+	OpeningHours []OpeningHours
+}
+
+// OpeningHours holds the local opening time of a location for one day of the week.
+type OpeningHours struct {
+	ID         uint   `gorm:"primaryKey"`
+	LocationID uint   `gorm:"not null;index"`
+	Weekday    uint   `gorm:"not null"`
+	Opens      string `gorm:"not null"`
+	Closes     string `gorm:"not null"`
+}
@@ -5,4 +5,13 @@ type Professional struct {
 	Name       string `gorm:"not null"`
 	Profession string `gorm:"not null"`
 	Contact    string `gorm:"not null"`
+	Locations  []ProfessionalLocation
+}
+
+// ProfessionalLocation assigns a professional to a location for one day of the week.
+type ProfessionalLocation struct {
+	ID             uint `gorm:"primaryKey"`
+	ProfessionalID uint `gorm:"not null;uniqueIndex:idx_professional_weekday"`
+	LocationID     uint `gorm:"not null;index"`
+	Weekday        uint `gorm:"not null;uniqueIndex:idx_professional_weekday"`
 }
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Review is a client's rating of a completed appointment. Only approved
+// reviews are public and count towards the professional's rating.
+type Review struct {
+	ID             uint `gorm:"primaryKey"`
+	AppointmentID  uint `gorm:"not null;uniqueIndex"`
+	ProfessionalID uint `gorm:"not null;index"`
+	ClientID       uint `gorm:"not null"`
+	Rating         uint `gorm:"not null"`
+	Comment        string
+	Status         string `gorm:"not null;default:pending"`
+	Reply          string
+	RepliedAt      *time.Time
+	CreatedAt      time.Time
+}
+
+// RatingSummary aggregates the approved reviews of a professional.
+type RatingSummary struct {
+	ProfessionalID uint
+	Average        float64
+	Count          uint
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LocationRepository interface {
+	CreateLocation(location *models.Location) error
+	GetLocationByID(id uint) (*models.Location, error)
+	ListLocations() ([]models.Location, error)
+	AssignProfessional(assignment *models.ProfessionalLocation) error
+}
+
+type locationRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewLocationRepository(db *gorm.DB) LocationRepository {
+	return &locationRepositoryImpl{DB: db}
+}
+
+func (r *locationRepositoryImpl) CreateLocation(location *models.Location) error {
+	return r.DB.Create(location).Error
+}
+
+func (r *locationRepositoryImpl) GetLocationByID(id uint) (*models.Location, error) {
+	var location models.Location
+	err := r.DB.Preload("OpeningHours").First(&location, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *locationRepositoryImpl) ListLocations() ([]models.Location, error) {
+	var locations []models.Location
+	err := r.DB.Preload("OpeningHours").Find(&locations).Error
+	return locations, err
+}
+
+// AssignProfessional sets the professional's location for the assignment's
+// weekday, replacing any previous assignment for that day.
+func (r *locationRepositoryImpl) AssignProfessional(assignment *models.ProfessionalLocation) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "professional_id"}, {Name: "weekday"}},
+		DoUpdates: clause.AssignmentColumns([]string{"location_id"}),
+	}).Create(assignment).Error
+}
@@ -8,6 +8,7 @@ import (
 type ProfessionalRepository interface {
 	CreateProfessional(professional *models.Professional) error
 	ListProfessionals() ([]models.Professional, error)
+	ListProfessionalsByLocation(locationID uint, weekdays []uint) ([]models.Professional, error)
 	GetProfessionalByID(id uint) (*models.Professional, error)
 }
 
@@ -25,13 +26,23 @@ func (r *professionalRepositoryImpl) CreateProfessional(professional *models.Pro
 
 func (r *professionalRepositoryImpl) ListProfessionals() ([]models.Professional, error) {
 	var professionals []models.Professional
-	err := r.DB.Find(&professionals).Error
+	err := r.DB.Preload("Locations").Find(&professionals).Error
+	return professionals, err
+}
+
+// ListProfessionalsByLocation returns the professionals assigned to the
+// location on any of the given weekdays.
+func (r *professionalRepositoryImpl) ListProfessionalsByLocation(locationID uint, weekdays []uint) ([]models.Professional, error) {
+	var professionals []models.Professional
+	assigned := r.DB.Model(&models.ProfessionalLocation{}).Select("professional_id").
+		Where("location_id = ? AND weekday IN ?", locationID, weekdays)
+	err := r.DB.Preload("Locations").Where("id IN (?)", assigned).Find(&professionals).Error
 	return professionals, err
 }
 
 func (r *professionalRepositoryImpl) GetProfessionalByID(id uint) (*models.Professional, error) {
 	var professional models.Professional
-	err := r.DB.First(&professional, id).Error
+	err := r.DB.Preload("Locations").First(&professional, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReviewRepository interface {
+	CreateReview(review *models.Review) error
+	GetReviewByID(id uint) (*models.Review, error)
+	ExistsForAppointment(appointmentID uint) (bool, error)
+	UpdateStatus(id uint, status string) error
+	SetReply(id uint, reply string, at time.Time) error
+	ListReviews(professionalID uint, status string) ([]models.Review, error)
+	GetRatingSummaries(professionalIDs []uint) (map[uint]models.RatingSummary, error)
+}
+
+type reviewRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepositoryImpl{DB: db}
+}
+
+func (r *reviewRepositoryImpl) CreateReview(review *models.Review) error {
+	return r.DB.Create(review).Error
+}
+
+func (r *reviewRepositoryImpl) GetReviewByID(id uint) (*models.Review, error) {
+	var review models.Review
+	err := r.DB.First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepositoryImpl) ExistsForAppointment(appointmentID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.Review{}).Where("appointment_id = ?", appointmentID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *reviewRepositoryImpl) UpdateStatus(id uint, status string) error {
+	return r.DB.Model(&models.Review{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *reviewRepositoryImpl) SetReply(id uint, reply string, at time.Time) error {
+	return r.DB.Model(&models.Review{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"reply": reply, "replied_at": at}).Error
+}
+
+// ListReviews returns the reviews in the given status, newest first.
+// professionalID is an optional filter when set to 0.
+func (r *reviewRepositoryImpl) ListReviews(professionalID uint, status string) ([]models.Review, error) {
+	var reviews []models.Review
+	query := r.DB.Where("status = ?", status)
+	if professionalID != 0 {
+		query = query.Where("professional_id = ?", professionalID)
+	}
+	err := query.Order("created_at DESC").Find(&reviews).Error
+	return reviews, err
+}
+
+// GetRatingSummaries returns the approved reviews aggregate of each given
+// professional. Professionals without approved reviews are left out.
+func (r *reviewRepositoryImpl) GetRatingSummaries(professionalIDs []uint) (map[uint]models.RatingSummary, error) {
+	var rows []models.RatingSummary
+	err := r.DB.Model(&models.Review{}).
+		Select("professional_id, AVG(rating) AS average, COUNT(*) AS count").
+		Where("status = ? AND professional_id IN ?", models.ReviewApproved, professionalIDs).
+		Group("professional_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[uint]models.RatingSummary, len(rows))
+	for _, row := range rows {
+		summaries[row.ProfessionalID] = row
+	}
+	return summaries, nil
+}
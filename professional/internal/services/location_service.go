@@ -0,0 +1,155 @@
+package services
+
+import (
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/repositories"
+)
+
+type LocationService interface {
+	CreateLocation(req *pb.CreateLocationRequest) (*pb.CreateLocationResponse, error)
+	GetLocation(req *pb.GetLocationRequest) (*pb.GetLocationResponse, error)
+	ListLocations(req *pb.ListLocationsRequest) (*pb.ListLocationsResponse, error)
+	AssignProfessional(req *pb.AssignProfessionalRequest) (*pb.AssignProfessionalResponse, error)
+}
+
+type locationServiceImpl struct {
+	Repo     repositories.LocationRepository
+	ProfRepo repositories.ProfessionalRepository
+}
+
+func NewLocationService(repo repositories.LocationRepository, profRepo repositories.ProfessionalRepository) LocationService {
+	return &locationServiceImpl{Repo: repo, ProfRepo: profRepo}
+}
+
+func (s *locationServiceImpl) CreateLocation(req *pb.CreateLocationRequest) (*pb.CreateLocationResponse, error) {
+	if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "" {
+		return &pb.CreateLocationResponse{
+			Message: "Invalid timezone",
+			Success: false,
+		}, nil
+	}
+
+	hours := make([]models.OpeningHours, len(req.OpeningHours))
+	for i, h := range req.OpeningHours {
+		opens, errOpens := time.Parse("15:04", h.Opens)
+		closes, errCloses := time.Parse("15:04", h.Closes)
+		if h.Weekday > 6 || errOpens != nil || errCloses != nil || !closes.After(opens) {
+			return &pb.CreateLocationResponse{
+				Message: "Invalid opening hours",
+				Success: false,
+			}, nil
+		}
+		hours[i] = models.OpeningHours{Weekday: uint(h.Weekday), Opens: h.Opens, Closes: h.Closes}
+	}
+
+	location := &models.Location{
+		Name:         req.Name,
+		Address:      req.Address,
+		Timezone:     req.Timezone,
+		OpeningHours: hours,
+	}
+	if err := s.Repo.CreateLocation(location); err != nil {
+		return &pb.CreateLocationResponse{
+			Message: "Error creating location",
+			Success: false,
+		}, err
+	}
+
+	return &pb.CreateLocationResponse{
+		Message:    "Location created",
+		Success:    true,
+		LocationId: uint32(location.ID),
+	}, nil
+}
+
+func (s *locationServiceImpl) GetLocation(req *pb.GetLocationRequest) (*pb.GetLocationResponse, error) {
+	location, err := s.Repo.GetLocationByID(uint(req.Id))
+	if err != nil {
+		return &pb.GetLocationResponse{
+			Success: false,
+		}, err
+	}
+
+	return &pb.GetLocationResponse{
+		Location: toPbLocation(location),
+		Success:  true,
+	}, nil
+}
+
+func (s *locationServiceImpl) ListLocations(req *pb.ListLocationsRequest) (*pb.ListLocationsResponse, error) {
+	locations, err := s.Repo.ListLocations()
+	if err != nil {
+		return &pb.ListLocationsResponse{
+			Success: false,
+		}, err
+	}
+
+	pbLocations := make([]*pb.Location, len(locations))
+	for i := range locations {
+		pbLocations[i] = toPbLocation(&locations[i])
+	}
+
+	return &pb.ListLocationsResponse{
+		Locations: pbLocations,
+		Success:   true,
+	}, nil
+}
+
+func (s *locationServiceImpl) AssignProfessional(req *pb.AssignProfessionalRequest) (*pb.AssignProfessionalResponse, error) {
+	if req.Weekday > 6 {
+		return &pb.AssignProfessionalResponse{
+			Message: "weekday must be between 0 (sunday) and 6 (saturday)",
+			Success: false,
+		}, nil
+	}
+	if _, err := s.ProfRepo.GetProfessionalByID(uint(req.ProfessionalId)); err != nil {
+		return &pb.AssignProfessionalResponse{
+			Message: "Professional not found",
+			Success: false,
+		}, err
+	}
+	if _, err := s.Repo.GetLocationByID(uint(req.LocationId)); err != nil {
+		return &pb.AssignProfessionalResponse{
+			Message: "Location not found",
+			Success: false,
+		}, err
+	}
+
+	assignment := &models.ProfessionalLocation{
+		ProfessionalID: uint(req.ProfessionalId),
+		LocationID:     uint(req.LocationId),
+		Weekday:        uint(req.Weekday),
+	}
+	if err := s.Repo.AssignProfessional(assignment); err != nil {
+		return &pb.AssignProfessionalResponse{
+			Message: "Error assigning professional",
+			Success: false,
+		}, err
+	}
+
+	return &pb.AssignProfessionalResponse{
+		Message: "Professional assigned",
+		Success: true,
+	}, nil
+}
+
+func toPbLocation(location *models.Location) *pb.Location {
+	hours := make([]*pb.OpeningHours, len(location.OpeningHours))
+	for i, h := range location.OpeningHours {
+		hours[i] = &pb.OpeningHours{
+			Weekday: uint32(h.Weekday),
+			Opens:   h.Opens,
+			Closes:  h.Closes,
+		}
+	}
+	return &pb.Location{
+		Id:           uint32(location.ID),
+		Name:         location.Name,
+		Address:      location.Address,
+		Timezone:     location.Timezone,
+		OpeningHours: hours,
+	}
+}
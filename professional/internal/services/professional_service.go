@@ -1,6 +1,8 @@
 package services
 
 import (
+	"time"
+
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
 	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
 	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/repositories"
@@ -13,11 +15,12 @@ type ProfessionalService interface {
 }
 
 type professionalServiceImpl struct {
-	Repo repositories.ProfessionalRepository
+	Repo       repositories.ProfessionalRepository
+	ReviewRepo repositories.ReviewRepository
 }
 
-func NewProfessionalService(repo repositories.ProfessionalRepository) ProfessionalService {
-	return &professionalServiceImpl{Repo: repo}
+func NewProfessionalService(repo repositories.ProfessionalRepository, reviewRepo repositories.ReviewRepository) ProfessionalService {
+	return &professionalServiceImpl{Repo: repo, ReviewRepo: reviewRepo}
 }
 
 func (s *professionalServiceImpl) CreateProfessional(req *pb.CreateProfessionalRequest) (*pb.CreateProfessionalResponse, error) {
@@ -48,34 +51,60 @@ func (s *professionalServiceImpl) GetProfessional(req *pb.GetProfessionalRequest
 		}, err
 	}
 
+	summaries, err := s.ReviewRepo.GetRatingSummaries([]uint{professional.ID})
+	if err != nil {
+		return &pb.GetProfessionalResponse{
+			Success: false,
+		}, err
+	}
+
 	return &pb.GetProfessionalResponse{
-		Success: true,
-		Professional: &pb.Professional{
-			Id:         uint32(professional.ID),
-			Name:       professional.Name,
-			Profession: professional.Profession,
-			Contact:    professional.Contact,
-		},
+		Success:      true,
+		Professional: toPbProfessional(professional, summaries[professional.ID]),
 	}, nil
 }
 
 func (s *professionalServiceImpl) ListProfessionals(req *pb.ListProfessionalsRequest) (*pb.ListProfessionalsResponse, error) {
-	professionals, err := s.Repo.ListProfessionals()
+	var professionals []models.Professional
+	var err error
+	if req.LocationId != 0 {
+		weekdays := []uint{0, 1, 2, 3, 4, 5, 6}
+		if req.Date != "" {
+			date, err := time.Parse("2006-01-02", req.Date)
+			if err != nil {
+				return &pb.ListProfessionalsResponse{
+					Success: false,
+				}, err
+			}
+			weekdays = []uint{uint(date.Weekday())}
+		}
+		professionals, err = s.Repo.ListProfessionalsByLocation(uint(req.LocationId), weekdays)
+	} else {
+		professionals, err = s.Repo.ListProfessionals()
+	}
 	if err != nil {
 		return &pb.ListProfessionalsResponse{
 			Success: false,
 		}, err
 	}
 
+	summaries := map[uint]models.RatingSummary{}
+	if len(professionals) > 0 {
+		ids := make([]uint, len(professionals))
+		for i, professional := range professionals {
+			ids[i] = professional.ID
+		}
+		if summaries, err = s.ReviewRepo.GetRatingSummaries(ids); err != nil {
+			return &pb.ListProfessionalsResponse{
+				Success: false,
+			}, err
+		}
+	}
+
 	responseProfessionals := make([]*pb.Professional, len(professionals))
 
-	for i, prof := range professionals {
-		responseProfessionals[i] = &pb.Professional{
-			Id:         uint32(prof.ID),
-			Name:       prof.Name,
-			Profession: prof.Profession,
-			Contact:    prof.Contact,
-		}
+	for i := range professionals {
+		responseProfessionals[i] = toPbProfessional(&professionals[i], summaries[professionals[i].ID])
 	}
 
 	return &pb.ListProfessionalsResponse{
@@ -83,3 +112,22 @@ func (s *professionalServiceImpl) ListProfessionals(req *pb.ListProfessionalsReq
 		Success:       true,
 	}, nil
 }
+
+func toPbProfessional(professional *models.Professional, rating models.RatingSummary) *pb.Professional {
+	var locations []*pb.ProfessionalLocation
+	for _, assignment := range professional.Locations {
+		locations = append(locations, &pb.ProfessionalLocation{
+			LocationId: uint32(assignment.LocationID),
+			Weekday:    uint32(assignment.Weekday),
+		})
+	}
+	return &pb.Professional{
+		Id:            uint32(professional.ID),
+		Name:          professional.Name,
+		Profession:    professional.Profession,
+		Contact:       professional.Contact,
+		Locations:     locations,
+		RatingAverage: rating.Average,
+		RatingCount:   uint32(rating.Count),
+	}
+}
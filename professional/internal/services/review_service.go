@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/repositories"
+)
+
+// Estado de cita del servicio de agenda que habilita la reseña
+const appointmentCompleted = "completed"
+
+type ReviewService interface {
+	CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (*pb.CreateReviewResponse, error)
+	ModerateReview(req *pb.ModerateReviewRequest) (*pb.ModerateReviewResponse, error)
+	ReplyToReview(ctx context.Context, req *pb.ReplyToReviewRequest) (*pb.ReplyToReviewResponse, error)
+	ListReviews(ctx context.Context, req *pb.ListReviewsRequest) (*pb.ListReviewsResponse, error)
+}
+
+type reviewServiceImpl struct {
+	Repo         repositories.ReviewRepository
+	AgendaClient pb.AgendaServiceClient
+}
+
+func NewReviewService(repo repositories.ReviewRepository, agendaClient pb.AgendaServiceClient) ReviewService {
+	return &reviewServiceImpl{Repo: repo, AgendaClient: agendaClient}
+}
+
+func (s *reviewServiceImpl) CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (*pb.CreateReviewResponse, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return &pb.CreateReviewResponse{
+			Message: "rating must be between 1 and 5",
+			Success: false,
+		}, nil
+	}
+
+	clientID := req.ClientId
+	if claims := rbac.FromContext(ctx); claims != nil && !claims.ActsForAnyone() {
+		// Un cliente solo reseña en su nombre, el client_id de la petición no cuenta
+		clientID = uint32(claims.ClientID)
+	}
+
+	apptResp, err := s.AgendaClient.GetAppointment(ctx, &pb.GetAppointmentRequest{Id: req.AppointmentId})
+	if err != nil {
+		return &pb.CreateReviewResponse{
+			Message: "Appointment not found",
+			Success: false,
+		}, err
+	}
+	appointment := apptResp.Appointment
+	if clientID == 0 || appointment.ClientId != clientID {
+		return &pb.CreateReviewResponse{
+			Message: "Appointment does not belong to this client",
+			Success: false,
+		}, nil
+	}
+	if appointment.Status != appointmentCompleted {
+		return &pb.CreateReviewResponse{
+			Message: "Only completed appointments can be reviewed",
+			Success: false,
+		}, nil
+	}
+
+	exists, err := s.Repo.ExistsForAppointment(uint(req.AppointmentId))
+	if err != nil {
+		return &pb.CreateReviewResponse{
+			Message: "Error creating review",
+			Success: false,
+		}, err
+	}
+	if exists {
+		return &pb.CreateReviewResponse{
+			Message: "This appointment has already been reviewed",
+			Success: false,
+		}, nil
+	}
+
+	review := &models.Review{
+		AppointmentID:  uint(req.AppointmentId),
+		ProfessionalID: uint(appointment.ProfessionalId),
+		ClientID:       uint(clientID),
+		Rating:         uint(req.Rating),
+		Comment:        req.Comment,
+		Status:         models.ReviewPending,
+	}
+	if err := s.Repo.CreateReview(review); err != nil {
+		return &pb.CreateReviewResponse{
+			Message: "Error creating review",
+			Success: false,
+		}, err
+	}
+
+	return &pb.CreateReviewResponse{
+		Message:  "Review created, pending moderation",
+		Success:  true,
+		ReviewId: uint32(review.ID),
+	}, nil
+}
+
+func (s *reviewServiceImpl) ModerateReview(req *pb.ModerateReviewRequest) (*pb.ModerateReviewResponse, error) {
+	if req.Status != models.ReviewApproved && req.Status != models.ReviewRejected {
+		return &pb.ModerateReviewResponse{
+			Message: "status must be 'approved' or 'rejected'",
+			Success: false,
+		}, nil
+	}
+	if _, err := s.Repo.GetReviewByID(uint(req.ReviewId)); err != nil {
+		return &pb.ModerateReviewResponse{
+			Message: "Review not found",
+			Success: false,
+		}, err
+	}
+
+	if err := s.Repo.UpdateStatus(uint(req.ReviewId), req.Status); err != nil {
+		return &pb.ModerateReviewResponse{
+			Message: "Error moderating review",
+			Success: false,
+		}, err
+	}
+
+	return &pb.ModerateReviewResponse{
+		Message: "Review moderated",
+		Success: true,
+	}, nil
+}
+
+func (s *reviewServiceImpl) ReplyToReview(ctx context.Context, req *pb.ReplyToReviewRequest) (*pb.ReplyToReviewResponse, error) {
+	if req.Reply == "" {
+		return &pb.ReplyToReviewResponse{
+			Message: "reply is required",
+			Success: false,
+		}, nil
+	}
+	review, err := s.Repo.GetReviewByID(uint(req.ReviewId))
+	if err != nil {
+		return &pb.ReplyToReviewResponse{
+			Message: "Review not found",
+			Success: false,
+		}, err
+	}
+	professionalID := uint(req.ProfessionalId)
+	if claims := rbac.FromContext(ctx); claims != nil && !claims.ActsForAnyone() {
+		professionalID = claims.ProfessionalID
+	}
+	if professionalID == 0 || review.ProfessionalID != professionalID {
+		return &pb.ReplyToReviewResponse{
+			Message: "Review does not belong to this professional",
+			Success: false,
+		}, nil
+	}
+
+	if err := s.Repo.SetReply(review.ID, req.Reply, time.Now()); err != nil {
+		return &pb.ReplyToReviewResponse{
+			Message: "Error replying to review",
+			Success: false,
+		}, err
+	}
+
+	return &pb.ReplyToReviewResponse{
+		Message: "Reply saved",
+		Success: true,
+	}, nil
+}
+
+// ListReviews returns a professional's reviews. Only staff and the reviewed
+// professional can see the ones still pending or rejected, everyone else gets
+// the approved ones.
+func (s *reviewServiceImpl) ListReviews(ctx context.Context, req *pb.ListReviewsRequest) (*pb.ListReviewsResponse, error) {
+	status := req.Status
+	claims := rbac.FromContext(ctx)
+	if status == "" || (claims != nil && !claims.OwnsProfessional(uint(req.ProfessionalId))) {
+		status = models.ReviewApproved
+	}
+
+	reviews, err := s.Repo.ListReviews(uint(req.ProfessionalId), status)
+	if err != nil {
+		return &pb.ListReviewsResponse{
+			Success: false,
+		}, err
+	}
+
+	pbReviews := make([]*pb.Review, len(reviews))
+	for i, review := range reviews {
+		pbReviews[i] = &pb.Review{
+			Id:             uint32(review.ID),
+			AppointmentId:  uint32(review.AppointmentID),
+			ProfessionalId: uint32(review.ProfessionalID),
+			ClientId:       uint32(review.ClientID),
+			Rating:         uint32(review.Rating),
+			Comment:        review.Comment,
+			Status:         review.Status,
+			Reply:          review.Reply,
+			CreatedAt:      review.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &pb.ListReviewsResponse{
+		Reviews: pbReviews,
+		Success: true,
+	}, nil
+}
@@ -3,38 +3,86 @@ package main
 import (
 	"log"
 	"net"
+	"time"
 
 	"github.com/lpsaldana/go-appointment-booking-microservices/common"
 	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
 	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/config"
 	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/handlers"
 	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/repositories"
 	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/services"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
-	dsn = common.EnvString("PROFESSIONAL_DB", "host=localhost user=postgres password=postgres dbname=Professionals port=5432 sslmode=disable")
+	// Debe coincidir con el del servicio de auth, firma también los tokens entre servicios
+	secretKey = common.EnvString("JWT_SECRET", rbac.DefaultSecret)
+	devMode   = common.EnvString("DEV_MODE", "false") == "true"
+	dsn       = common.EnvString("PROFESSIONAL_DB", "host=localhost user=postgres password=postgres dbname=Professionals port=5432 sslmode=disable")
+	// "remote" le pregunta a auth y así respeta los tokens revocados, "local"
+	// solo revisa las llaves publicadas y acepta un token hasta que expira
+	tokenValidation = common.EnvString("TOKEN_VALIDATION", rbac.ValidationRemote)
+	// Cada cuánto se traen las llaves de auth, menos que SIGNING_KEY_PUBLISH_AHEAD
+	jwksSyncInterval = common.EnvString("JWKS_SYNC_INTERVAL", "5m")
 )
 
 func main() {
+	if err := rbac.CheckSecret(secretKey, devMode); err != nil {
+		log.Fatal(err)
+	}
+
+	// Las llamadas a otros servicios van con un token de servicio
+	serviceCreds := rbac.NewServiceCredentials(secretKey, "professional")
+	authConn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to auth server: %v", err)
+	}
+	defer authConn.Close()
+
 	dbConfig := config.NewDBConfig(dsn)
 	db, err := dbConfig.ConnectDB()
 	if err != nil {
 		log.Fatalf("Cannot connect to DB: %v", err)
 	}
 
+	agendaConn, err := grpc.NewClient("localhost:50054", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceCreds))
+	if err != nil {
+		log.Fatalf("Cannot connect to agenda server: %v", err)
+	}
+	defer agendaConn.Close()
+
 	repo := repositories.NewProfessionalRepository(db)
-	svc := services.NewProfessionalService(repo)
+	reviewRepo := repositories.NewReviewRepository(db)
+	svc := services.NewProfessionalService(repo, reviewRepo)
 	handler := handlers.NewProfessionalHandler(svc)
+	locationHandler := handlers.NewLocationHandler(services.NewLocationService(repositories.NewLocationRepository(db), repo))
+	reviewHandler := handlers.NewReviewHandler(services.NewReviewService(reviewRepo, pb.NewAgendaServiceClient(agendaConn)))
 
 	lis, err := net.Listen("tcp", ":50052") // Puerto diferente a auth y tasks
 	if err != nil {
 		log.Fatalf("Error listening to port 50052: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	syncInterval, err := time.ParseDuration(jwksSyncInterval)
+	if err != nil {
+		log.Fatalf("Invalid JWKS_SYNC_INTERVAL: %v", err)
+	}
+	authClient := pb.NewAuthServiceClient(authConn)
+	keys := rbac.NewKeySet(secretKey, rbac.FetchJWKS(authClient))
+	go keys.Sync(syncInterval)
+	validator, err := rbac.NewValidator(tokenValidation, keys, authClient)
+	if err != nil {
+		log.Fatalf("Invalid TOKEN_VALIDATION: %v", err)
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(rbac.UnaryServerInterceptor(validator, handlers.Permissions)),
+		grpc.StreamInterceptor(rbac.StreamServerInterceptor(validator, handlers.Permissions)))
 	pb.RegisterProfessionalServiceServer(grpcServer, handler)
+	pb.RegisterLocationServiceServer(grpcServer, locationHandler)
+	pb.RegisterReviewServiceServer(grpcServer, reviewHandler)
 
 	log.Println("Server runing on port :50052...")
 	if err := grpcServer.Serve(lis); err != nil {
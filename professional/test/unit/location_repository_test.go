@@ -0,0 +1,60 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupLocationMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.LocationRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewLocationRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestCreateLocation(t *testing.T) {
+	sqlDB, mock, repo := setupLocationMockDB(t)
+	defer sqlDB.Close()
+
+	location := &models.Location{Name: "Sucursal Centro", Address: "Av. Principal 123", Timezone: "UTC",
+		OpeningHours: []models.OpeningHours{{Weekday: 1, Opens: "09:00", Closes: "18:00"}}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "locations" ("name","address","timezone") VALUES ($1,$2,$3) RETURNING "id"`)).
+		WithArgs("Sucursal Centro", "Av. Principal 123", "UTC").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "opening_hours" ("location_id","weekday","opens","closes") VALUES ($1,$2,$3,$4) ON CONFLICT ("id") DO UPDATE SET "location_id"="excluded"."location_id" RETURNING "id"`)).
+		WithArgs(uint(1), uint(1), "09:00", "18:00").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	err := repo.CreateLocation(location)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), location.ID, "El ID debería haberse asignado")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAssignProfessional(t *testing.T) {
+	sqlDB, mock, repo := setupLocationMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "professional_locations" ("professional_id","location_id","weekday") VALUES ($1,$2,$3) ON CONFLICT ("professional_id","weekday") DO UPDATE SET "location_id"="excluded"."location_id" RETURNING "id"`)).
+		WithArgs(uint(1), uint(2), uint(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	err := repo.AssignProfessional(&models.ProfessionalLocation{ProfessionalID: 1, LocationID: 2, Weekday: 3})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,167 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLocationRepository struct {
+	mock.Mock
+}
+
+func (m *MockLocationRepository) CreateLocation(location *models.Location) error {
+	args := m.Called(location)
+	return args.Error(0)
+}
+
+func (m *MockLocationRepository) GetLocationByID(id uint) (*models.Location, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Location), args.Error(1)
+}
+
+func (m *MockLocationRepository) ListLocations() ([]models.Location, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Location), args.Error(1)
+}
+
+func (m *MockLocationRepository) AssignProfessional(assignment *models.ProfessionalLocation) error {
+	args := m.Called(assignment)
+	return args.Error(0)
+}
+
+func TestCreateLocationService(t *testing.T) {
+	mockRepo := new(MockLocationRepository)
+	srv := services.NewLocationService(mockRepo, new(MockProfessionalRepository))
+
+	tests := []struct {
+		name         string
+		req          *pb.CreateLocationRequest
+		mockSetup    func()
+		expectedResp *pb.CreateLocationResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req: &pb.CreateLocationRequest{Name: "Sucursal Centro", Address: "Av. Principal 123", Timezone: "America/Santiago",
+				OpeningHours: []*pb.OpeningHours{{Weekday: 1, Opens: "09:00", Closes: "18:00"}}},
+			mockSetup: func() {
+				(mockRepo).On("CreateLocation", mock.MatchedBy(func(l *models.Location) bool {
+					return len(l.OpeningHours) == 1 && l.OpeningHours[0].Weekday == 1
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.CreateLocationResponse{Message: "Location created", Success: true},
+			expectedErr:  nil,
+		},
+		{
+			name:         "InvalidTimezone",
+			req:          &pb.CreateLocationRequest{Name: "Sucursal Centro", Address: "Av. Principal 123", Timezone: "Mars/Olympus"},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateLocationResponse{Message: "Invalid timezone", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "ClosesBeforeOpens",
+			req: &pb.CreateLocationRequest{Name: "Sucursal Centro", Address: "Av. Principal 123", Timezone: "UTC",
+				OpeningHours: []*pb.OpeningHours{{Weekday: 1, Opens: "18:00", Closes: "09:00"}}},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateLocationResponse{Message: "Invalid opening hours", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "DatabaseError",
+			req:  &pb.CreateLocationRequest{Name: "Sucursal Centro", Address: "Av. Principal 123", Timezone: "UTC"},
+			mockSetup: func() {
+				(mockRepo).On("CreateLocation", mock.AnythingOfType("*models.Location")).Return(errors.New("db error")).Once()
+			},
+			expectedResp: &pb.CreateLocationResponse{Message: "Error creating location", Success: false},
+			expectedErr:  errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.CreateLocation(tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			(mockRepo).AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetLocationService(t *testing.T) {
+	mockRepo := new(MockLocationRepository)
+	srv := services.NewLocationService(mockRepo, new(MockProfessionalRepository))
+
+	(mockRepo).On("GetLocationByID", uint(1)).Return(&models.Location{ID: 1, Name: "Sucursal Centro", Address: "Av. Principal 123",
+		Timezone: "UTC", OpeningHours: []models.OpeningHours{{Weekday: 1, Opens: "09:00", Closes: "18:00"}}}, nil).Once()
+
+	resp, err := srv.GetLocation(&pb.GetLocationRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GetLocationResponse{
+		Location: &pb.Location{Id: 1, Name: "Sucursal Centro", Address: "Av. Principal 123", Timezone: "UTC",
+			OpeningHours: []*pb.OpeningHours{{Weekday: 1, Opens: "09:00", Closes: "18:00"}}},
+		Success: true,
+	}, resp)
+	(mockRepo).AssertExpectations(t)
+}
+
+func TestAssignProfessionalService(t *testing.T) {
+	mockRepo := new(MockLocationRepository)
+	mockProfRepo := new(MockProfessionalRepository)
+	srv := services.NewLocationService(mockRepo, mockProfRepo)
+
+	tests := []struct {
+		name         string
+		req          *pb.AssignProfessionalRequest
+		mockSetup    func()
+		expectedResp *pb.AssignProfessionalResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req:  &pb.AssignProfessionalRequest{ProfessionalId: 1, LocationId: 2, Weekday: 3},
+			mockSetup: func() {
+				(mockProfRepo).On("GetProfessionalByID", uint(1)).Return(&models.Professional{ID: 1}, nil).Once()
+				(mockRepo).On("GetLocationByID", uint(2)).Return(&models.Location{ID: 2}, nil).Once()
+				(mockRepo).On("AssignProfessional", &models.ProfessionalLocation{ProfessionalID: 1, LocationID: 2, Weekday: 3}).Return(nil).Once()
+			},
+			expectedResp: &pb.AssignProfessionalResponse{Message: "Professional assigned", Success: true},
+			expectedErr:  nil,
+		},
+		{
+			name:         "InvalidWeekday",
+			req:          &pb.AssignProfessionalRequest{ProfessionalId: 1, LocationId: 2, Weekday: 7},
+			mockSetup:    func() {},
+			expectedResp: &pb.AssignProfessionalResponse{Message: "weekday must be between 0 (sunday) and 6 (saturday)", Success: false},
+			expectedErr:  nil,
+		},
+		{
+			name: "LocationNotFound",
+			req:  &pb.AssignProfessionalRequest{ProfessionalId: 1, LocationId: 9, Weekday: 3},
+			mockSetup: func() {
+				(mockProfRepo).On("GetProfessionalByID", uint(1)).Return(&models.Professional{ID: 1}, nil).Once()
+				(mockRepo).On("GetLocationByID", uint(9)).Return((*models.Location)(nil), errors.New("not found")).Once()
+			},
+			expectedResp: &pb.AssignProfessionalResponse{Message: "Location not found", Success: false},
+			expectedErr:  errors.New("not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			resp, err := srv.AssignProfessional(tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			(mockRepo).AssertExpectations(t)
+			(mockProfRepo).AssertExpectations(t)
+		})
+	}
+}
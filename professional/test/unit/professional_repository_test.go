@@ -94,8 +94,11 @@ func TestGetProfessionalByID(t *testing.T) {
 				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "professionals" WHERE "professionals"."id" = $1 ORDER BY "professionals"."id" LIMIT $2`)).
 					WithArgs(sqlmock.AnyArg(), 1). // Dos argumentos: id y LIMIT
 					WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "professional_locations" WHERE "professional_locations"."professional_id" = $1`)).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "professional_id", "location_id", "weekday"}))
 			},
-			expectedProf: &models.Professional{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com"},
+			expectedProf: &models.Professional{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com", Locations: []models.ProfessionalLocation{}},
 			expectedErr:  nil,
 		},
 		{
@@ -140,10 +143,15 @@ func TestListProfessionals(t *testing.T) {
 					AddRow(2, "Dr. Perez", "Medico", "perez@email.com")
 				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "professionals"`)).
 					WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "professional_locations" WHERE "professional_locations"."professional_id" IN ($1,$2)`)).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "professional_id", "location_id", "weekday"}).
+						AddRow(1, 2, 3, 1))
 			},
 			expectedProfs: []models.Professional{
-				{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com"},
-				{ID: 2, Name: "Dr. Perez", Profession: "Medico", Contact: "perez@email.com"},
+				{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com", Locations: []models.ProfessionalLocation{}},
+				{ID: 2, Name: "Dr. Perez", Profession: "Medico", Contact: "perez@email.com",
+					Locations: []models.ProfessionalLocation{{ID: 1, ProfessionalID: 2, LocationID: 3, Weekday: 1}}},
 			},
 			expectedErr: nil,
 		},
@@ -178,3 +186,25 @@ func TestListProfessionals(t *testing.T) {
 		})
 	}
 }
+
+func TestListProfessionalsByLocation(t *testing.T) {
+	sqlDB, mock, repo := setupMockDB(t)
+	defer sqlDB.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "profession", "contact"}).
+		AddRow(1, "Dr. Lopez", "Dentista", "lopez@email.com")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "professionals" WHERE id IN (SELECT "professional_id" FROM "professional_locations" WHERE location_id = $1 AND weekday IN ($2))`)).
+		WithArgs(uint(3), uint(1)).
+		WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "professional_locations" WHERE "professional_locations"."professional_id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "professional_id", "location_id", "weekday"}).AddRow(1, 1, 3, 1))
+
+	profs, err := repo.ListProfessionalsByLocation(3, []uint{1})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Professional{
+		{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com",
+			Locations: []models.ProfessionalLocation{{ID: 1, ProfessionalID: 1, LocationID: 3, Weekday: 1}}},
+	}, profs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
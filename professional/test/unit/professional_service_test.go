@@ -30,9 +30,14 @@ func (m *MockProfessionalRepository) ListProfessionals() ([]models.Professional,
 	return args.Get(0).([]models.Professional), args.Error(1)
 }
 
+func (m *MockProfessionalRepository) ListProfessionalsByLocation(locationID uint, weekdays []uint) ([]models.Professional, error) {
+	args := m.Called(locationID, weekdays)
+	return args.Get(0).([]models.Professional), args.Error(1)
+}
+
 func TestCreateProfessionalService(t *testing.T) {
 	mockRepo := new(MockProfessionalRepository)
-	srv := services.NewProfessionalService(mockRepo)
+	srv := services.NewProfessionalService(mockRepo, new(MockReviewRepository))
 
 	tests := []struct {
 		name         string
@@ -78,7 +83,8 @@ func TestCreateProfessionalService(t *testing.T) {
 
 func TestGetProfessional(t *testing.T) {
 	mockRepo := new(MockProfessionalRepository)
-	srv := services.NewProfessionalService(mockRepo)
+	mockReviewRepo := new(MockReviewRepository)
+	srv := services.NewProfessionalService(mockRepo, mockReviewRepo)
 
 	tests := []struct {
 		name         string
@@ -92,10 +98,13 @@ func TestGetProfessional(t *testing.T) {
 			req:  &pb.GetProfessionalRequest{Id: 1},
 			mockSetup: func() {
 				(mockRepo).On("GetProfessionalByID", uint(1)).Return(&models.Professional{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com"}, nil).Once()
+				(mockReviewRepo).On("GetRatingSummaries", []uint{1}).
+					Return(map[uint]models.RatingSummary{1: {ProfessionalID: 1, Average: 4.5, Count: 2}}, nil).Once()
 			},
 			expectedResp: &pb.GetProfessionalResponse{
-				Professional: &pb.Professional{Id: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com"},
-				Success:      true,
+				Professional: &pb.Professional{Id: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com",
+					RatingAverage: 4.5, RatingCount: 2},
+				Success: true,
 			},
 			expectedErr: nil,
 		},
@@ -117,13 +126,15 @@ func TestGetProfessional(t *testing.T) {
 			assert.Equal(t, tt.expectedResp, resp)
 			assert.Equal(t, tt.expectedErr, err)
 			(mockRepo).AssertExpectations(t)
+			(mockReviewRepo).AssertExpectations(t)
 		})
 	}
 }
 
 func TestListProfessionalsService(t *testing.T) {
 	mockRepo := new(MockProfessionalRepository)
-	srv := services.NewProfessionalService(mockRepo)
+	mockReviewRepo := new(MockReviewRepository)
+	srv := services.NewProfessionalService(mockRepo, mockReviewRepo)
 
 	tests := []struct {
 		name         string
@@ -140,16 +151,37 @@ func TestListProfessionalsService(t *testing.T) {
 					{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com"},
 					{ID: 2, Name: "Dr. Perez", Profession: "Medico", Contact: "perez@email.com"},
 				}, nil).Once()
+				(mockReviewRepo).On("GetRatingSummaries", []uint{1, 2}).
+					Return(map[uint]models.RatingSummary{1: {ProfessionalID: 1, Average: 4, Count: 3}}, nil).Once()
 			},
 			expectedResp: &pb.ListProfessionalsResponse{
 				Professionals: []*pb.Professional{
-					{Id: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com"},
+					{Id: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com", RatingAverage: 4, RatingCount: 3},
 					{Id: 2, Name: "Dr. Perez", Profession: "Medico", Contact: "perez@email.com"},
 				},
 				Success: true,
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "FilteredByLocationAndDate",
+			req:  &pb.ListProfessionalsRequest{LocationId: 3, Date: "2025-03-10"},
+			mockSetup: func() {
+				(mockRepo).On("ListProfessionalsByLocation", uint(3), []uint{1}).Return([]models.Professional{
+					{ID: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com",
+						Locations: []models.ProfessionalLocation{{ProfessionalID: 1, LocationID: 3, Weekday: 1}}},
+				}, nil).Once()
+				(mockReviewRepo).On("GetRatingSummaries", []uint{1}).Return(map[uint]models.RatingSummary{}, nil).Once()
+			},
+			expectedResp: &pb.ListProfessionalsResponse{
+				Professionals: []*pb.Professional{
+					{Id: 1, Name: "Dr. Lopez", Profession: "Dentista", Contact: "lopez@email.com",
+						Locations: []*pb.ProfessionalLocation{{LocationId: 3, Weekday: 1}}},
+				},
+				Success: true,
+			},
+			expectedErr: nil,
+		},
 		{
 			name: "EmptyList",
 			req:  &pb.ListProfessionalsRequest{},
@@ -177,6 +209,7 @@ func TestListProfessionalsService(t *testing.T) {
 			assert.Equal(t, tt.expectedResp, resp)
 			assert.Equal(t, tt.expectedErr, err)
 			(mockRepo).AssertExpectations(t)
+			(mockReviewRepo).AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupReviewMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, repositories.ReviewRepository) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.NoError(t, err)
+	repo := repositories.NewReviewRepository(gormDB)
+	return sqlDB, mock, repo
+}
+
+func TestGetRatingSummaries(t *testing.T) {
+	sqlDB, mock, repo := setupReviewMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT professional_id, AVG(rating) AS average, COUNT(*) AS count FROM "reviews" WHERE status = $1 AND professional_id IN ($2,$3) GROUP BY "professional_id"`)).
+		WithArgs("approved", uint(1), uint(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"professional_id", "average", "count"}).AddRow(1, 4.5, 2))
+
+	summaries, err := repo.GetRatingSummaries([]uint{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]models.RatingSummary{1: {ProfessionalID: 1, Average: 4.5, Count: 2}}, summaries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExistsForAppointment(t *testing.T) {
+	sqlDB, mock, repo := setupReviewMockDB(t)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "reviews" WHERE appointment_id = $1`)).
+		WithArgs(uint(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	exists, err := repo.ExistsForAppointment(3)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,296 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/pb"
+	"github.com/lpsaldana/go-appointment-booking-microservices/common/rbac"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/models"
+	"github.com/lpsaldana/go-appointment-booking-microservices/professional/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) CreateReview(review *models.Review) error {
+	args := m.Called(review)
+	review.ID = 1
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) GetReviewByID(id uint) (*models.Review, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) ExistsForAppointment(appointmentID uint) (bool, error) {
+	args := m.Called(appointmentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReviewRepository) UpdateStatus(id uint, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) SetReply(id uint, reply string, at time.Time) error {
+	args := m.Called(id, reply, at)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) ListReviews(professionalID uint, status string) ([]models.Review, error) {
+	args := m.Called(professionalID, status)
+	return args.Get(0).([]models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetRatingSummaries(professionalIDs []uint) (map[uint]models.RatingSummary, error) {
+	args := m.Called(professionalIDs)
+	return args.Get(0).(map[uint]models.RatingSummary), args.Error(1)
+}
+
+// Embebe la interfaz para sólo implementar los métodos usados
+type MockAgendaServiceClient struct {
+	mock.Mock
+	pb.AgendaServiceClient
+}
+
+func (m *MockAgendaServiceClient) GetAppointment(ctx context.Context, in *pb.GetAppointmentRequest, opts ...grpc.CallOption) (*pb.GetAppointmentResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.GetAppointmentResponse), args.Error(1)
+}
+
+func TestCreateReview(t *testing.T) {
+	mockRepo := new(MockReviewRepository)
+	mockAgenda := new(MockAgendaServiceClient)
+	srv := services.NewReviewService(mockRepo, mockAgenda)
+
+	completed := &pb.GetAppointmentResponse{Appointment: &pb.Appointment{Id: 1, ClientId: 5, ProfessionalId: 2, Status: "completed"}, Success: true}
+
+	client := func(id uint) context.Context {
+		return rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: id})
+	}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		req          *pb.CreateReviewRequest
+		mockSetup    func()
+		expectedResp *pb.CreateReviewResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req:  &pb.CreateReviewRequest{AppointmentId: 1, ClientId: 5, Rating: 5, Comment: "Excelente atención"},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointment", mock.Anything, &pb.GetAppointmentRequest{Id: 1}).Return(completed, nil).Once()
+				mockRepo.On("ExistsForAppointment", uint(1)).Return(false, nil).Once()
+				mockRepo.On("CreateReview", mock.MatchedBy(func(r *models.Review) bool {
+					return r.ProfessionalID == 2 && r.Rating == 5 && r.Status == models.ReviewPending
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.CreateReviewResponse{Message: "Review created, pending moderation", Success: true, ReviewId: 1},
+		},
+		{
+			name:         "InvalidRating",
+			req:          &pb.CreateReviewRequest{AppointmentId: 1, ClientId: 5, Rating: 6},
+			mockSetup:    func() {},
+			expectedResp: &pb.CreateReviewResponse{Message: "rating must be between 1 and 5", Success: false},
+		},
+		{
+			name: "NotCompleted",
+			req:  &pb.CreateReviewRequest{AppointmentId: 1, ClientId: 5, Rating: 4},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointment", mock.Anything, &pb.GetAppointmentRequest{Id: 1}).Return(&pb.GetAppointmentResponse{
+					Appointment: &pb.Appointment{Id: 1, ClientId: 5, ProfessionalId: 2, Status: "booked"}, Success: true}, nil).Once()
+			},
+			expectedResp: &pb.CreateReviewResponse{Message: "Only completed appointments can be reviewed", Success: false},
+		},
+		{
+			name: "OtherClient",
+			req:  &pb.CreateReviewRequest{AppointmentId: 1, ClientId: 9, Rating: 4},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointment", mock.Anything, &pb.GetAppointmentRequest{Id: 1}).Return(completed, nil).Once()
+			},
+			expectedResp: &pb.CreateReviewResponse{Message: "Appointment does not belong to this client", Success: false},
+		},
+		{
+			// El client_id de la petición no cuenta cuando lo hace el propio cliente
+			name: "ClientFromToken",
+			ctx:  client(5),
+			req:  &pb.CreateReviewRequest{AppointmentId: 1, ClientId: 9, Rating: 5},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointment", mock.Anything, &pb.GetAppointmentRequest{Id: 1}).Return(completed, nil).Once()
+				mockRepo.On("ExistsForAppointment", uint(1)).Return(false, nil).Once()
+				mockRepo.On("CreateReview", mock.MatchedBy(func(r *models.Review) bool {
+					return r.ClientID == 5
+				})).Return(nil).Once()
+			},
+			expectedResp: &pb.CreateReviewResponse{Message: "Review created, pending moderation", Success: true, ReviewId: 1},
+		},
+		{
+			name: "ImpersonatedClient",
+			ctx:  client(9),
+			req:  &pb.CreateReviewRequest{AppointmentId: 1, ClientId: 5, Rating: 1},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointment", mock.Anything, &pb.GetAppointmentRequest{Id: 1}).Return(completed, nil).Once()
+			},
+			expectedResp: &pb.CreateReviewResponse{Message: "Appointment does not belong to this client", Success: false},
+		},
+		{
+			name: "AlreadyReviewed",
+			req:  &pb.CreateReviewRequest{AppointmentId: 1, ClientId: 5, Rating: 4},
+			mockSetup: func() {
+				mockAgenda.On("GetAppointment", mock.Anything, &pb.GetAppointmentRequest{Id: 1}).Return(completed, nil).Once()
+				mockRepo.On("ExistsForAppointment", uint(1)).Return(true, nil).Once()
+			},
+			expectedResp: &pb.CreateReviewResponse{Message: "This appointment has already been reviewed", Success: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			tt.mockSetup()
+			resp, err := srv.CreateReview(ctx, tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			mockRepo.AssertExpectations(t)
+			mockAgenda.AssertExpectations(t)
+		})
+	}
+}
+
+func TestModerateReview(t *testing.T) {
+	mockRepo := new(MockReviewRepository)
+	srv := services.NewReviewService(mockRepo, nil)
+
+	mockRepo.On("GetReviewByID", uint(1)).Return(&models.Review{ID: 1, Status: models.ReviewPending}, nil).Once()
+	mockRepo.On("UpdateStatus", uint(1), models.ReviewApproved).Return(nil).Once()
+
+	resp, err := srv.ModerateReview(&pb.ModerateReviewRequest{ReviewId: 1, Status: "approved"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.ModerateReviewResponse{Message: "Review moderated", Success: true}, resp)
+
+	resp, err = srv.ModerateReview(&pb.ModerateReviewRequest{ReviewId: 1, Status: "hidden"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.ModerateReviewResponse{Message: "status must be 'approved' or 'rejected'", Success: false}, resp)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReplyToReview(t *testing.T) {
+	mockRepo := new(MockReviewRepository)
+	srv := services.NewReviewService(mockRepo, nil)
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		req          *pb.ReplyToReviewRequest
+		mockSetup    func()
+		expectedResp *pb.ReplyToReviewResponse
+		expectedErr  error
+	}{
+		{
+			name: "Success",
+			req:  &pb.ReplyToReviewRequest{ReviewId: 1, ProfessionalId: 2, Reply: "¡Gracias por su visita!"},
+			mockSetup: func() {
+				mockRepo.On("GetReviewByID", uint(1)).Return(&models.Review{ID: 1, ProfessionalID: 2}, nil).Once()
+				mockRepo.On("SetReply", uint(1), "¡Gracias por su visita!", mock.AnythingOfType("time.Time")).Return(nil).Once()
+			},
+			expectedResp: &pb.ReplyToReviewResponse{Message: "Reply saved", Success: true},
+		},
+		{
+			name: "OtherProfessional",
+			req:  &pb.ReplyToReviewRequest{ReviewId: 1, ProfessionalId: 3, Reply: "Gracias"},
+			mockSetup: func() {
+				mockRepo.On("GetReviewByID", uint(1)).Return(&models.Review{ID: 1, ProfessionalID: 2}, nil).Once()
+			},
+			expectedResp: &pb.ReplyToReviewResponse{Message: "Review does not belong to this professional", Success: false},
+		},
+		{
+			// Otro profesional no responde por el de la reseña aunque ponga su id
+			name: "ProfessionalFromToken",
+			ctx:  rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 3}),
+			req:  &pb.ReplyToReviewRequest{ReviewId: 1, ProfessionalId: 2, Reply: "Gracias"},
+			mockSetup: func() {
+				mockRepo.On("GetReviewByID", uint(1)).Return(&models.Review{ID: 1, ProfessionalID: 2}, nil).Once()
+			},
+			expectedResp: &pb.ReplyToReviewResponse{Message: "Review does not belong to this professional", Success: false},
+		},
+		{
+			name: "NotFound",
+			req:  &pb.ReplyToReviewRequest{ReviewId: 9, ProfessionalId: 2, Reply: "Gracias"},
+			mockSetup: func() {
+				mockRepo.On("GetReviewByID", uint(9)).Return((*models.Review)(nil), errors.New("not found")).Once()
+			},
+			expectedResp: &pb.ReplyToReviewResponse{Message: "Review not found", Success: false},
+			expectedErr:  errors.New("not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			tt.mockSetup()
+			resp, err := srv.ReplyToReview(ctx, tt.req)
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedErr, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListReviewsDefaultsToApproved(t *testing.T) {
+	mockRepo := new(MockReviewRepository)
+	srv := services.NewReviewService(mockRepo, nil)
+
+	mockRepo.On("ListReviews", uint(2), models.ReviewApproved).Return([]models.Review{
+		{ID: 1, AppointmentID: 3, ProfessionalID: 2, ClientID: 5, Rating: 5, Status: models.ReviewApproved},
+	}, nil).Once()
+
+	resp, err := srv.ListReviews(context.Background(), &pb.ListReviewsRequest{ProfessionalId: 2})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Reviews, 1)
+	assert.Equal(t, uint32(5), resp.Reviews[0].Rating)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListReviewsPendingOnlyForStaffAndOwner(t *testing.T) {
+	mockRepo := new(MockReviewRepository)
+	srv := services.NewReviewService(mockRepo, nil)
+
+	client := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleClient}, ClientID: 5})
+	owner := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 2})
+	other := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleProfessional}, ProfessionalID: 3})
+	staff := rbac.NewContext(context.Background(), &rbac.Claims{Roles: []string{rbac.RoleStaff}})
+
+	// Los demás piden las pendientes pero solo reciben las aprobadas
+	mockRepo.On("ListReviews", uint(2), models.ReviewApproved).Return([]models.Review{}, nil).Twice()
+	_, err := srv.ListReviews(client, &pb.ListReviewsRequest{ProfessionalId: 2, Status: models.ReviewPending})
+	assert.NoError(t, err)
+	_, err = srv.ListReviews(other, &pb.ListReviewsRequest{ProfessionalId: 2, Status: models.ReviewRejected})
+	assert.NoError(t, err)
+
+	mockRepo.On("ListReviews", uint(2), models.ReviewPending).Return([]models.Review{
+		{ID: 1, AppointmentID: 3, ProfessionalID: 2, ClientID: 5, Rating: 2, Status: models.ReviewPending},
+	}, nil).Twice()
+	resp, err := srv.ListReviews(owner, &pb.ListReviewsRequest{ProfessionalId: 2, Status: models.ReviewPending})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Reviews, 1)
+	_, err = srv.ListReviews(staff, &pb.ListReviewsRequest{ProfessionalId: 2, Status: models.ReviewPending})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}